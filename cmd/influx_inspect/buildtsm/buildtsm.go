@@ -0,0 +1,163 @@
+// Package buildtsm builds TSM files offline from InfluxDB line protocol.
+package buildtsm
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb/engine/tsm1"
+)
+
+const (
+	// batchSize is the number of lines parsed at a time.
+	batchSize = 5000
+
+	// maxLineSize is the size of the longest line that can be read.
+	maxLineSize = 4 * 1024 * 1024
+)
+
+// Command represents the program execution for "influx_inspect buildtsm".
+type Command struct {
+	// Standard input/output, overridden for testing.
+	Stdin  io.Reader
+	Stderr io.Writer
+	Stdout io.Writer
+
+	in        string
+	out       string
+	precision string
+	maxValues int
+}
+
+// NewCommand returns a new instance of Command.
+func NewCommand() *Command {
+	return &Command{
+		Stdin:  os.Stdin,
+		Stderr: os.Stderr,
+		Stdout: os.Stdout,
+	}
+}
+
+// Run executes the command.
+func (cmd *Command) Run(args ...string) error {
+	fs := flag.NewFlagSet("buildtsm", flag.ExitOnError)
+	fs.StringVar(&cmd.in, "in", "-", "Line protocol file to read, or - for stdin.  Files ending in .gz are decompressed")
+	fs.StringVar(&cmd.out, "out", "", "Directory to write TSM files to")
+	fs.StringVar(&cmd.precision, "precision", "ns", "Precision of the timestamps in the input (ns, u, ms, s, m or h)")
+	fs.IntVar(&cmd.maxValues, "max-values", tsm1.DefaultBuilderMaxValues, "Number of values buffered in memory before a TSM file is written")
+
+	fs.SetOutput(cmd.Stdout)
+	fs.Usage = func() {
+		fmt.Fprintf(cmd.Stdout, "Builds TSM files from InfluxDB line protocol.\n\n")
+		fmt.Fprintf(cmd.Stdout, "Usage: %s buildtsm [flags]\n\n", filepath.Base(os.Args[0]))
+		fs.PrintDefaults()
+		fmt.Fprintf(cmd.Stdout, "\nThe TSM files can be attached to an existing shard with tsdb.Store.IngestShardFiles.\n")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() > 0 || cmd.out == "" {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+
+	switch cmd.precision {
+	case "n", "ns", "u", "ms", "s", "m", "h":
+	default:
+		return fmt.Errorf("invalid precision: %q", cmd.precision)
+	}
+
+	return cmd.build()
+}
+
+func (cmd *Command) build() error {
+	r := cmd.Stdin
+	if cmd.in != "-" {
+		f, err := os.Open(cmd.in)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+
+		if strings.HasSuffix(cmd.in, ".gz") {
+			gr, err := gzip.NewReader(f)
+			if err != nil {
+				return err
+			}
+			defer gr.Close()
+			r = gr
+		}
+	}
+
+	if err := os.MkdirAll(cmd.out, 0777); err != nil {
+		return err
+	}
+
+	b, err := tsm1.NewBuilder(cmd.out)
+	if err != nil {
+		return err
+	}
+	b.MaxValues = cmd.maxValues
+
+	var (
+		buf bytes.Buffer
+		n   int
+	)
+	flush := func() error {
+		if buf.Len() == 0 {
+			return nil
+		}
+		points, err := models.ParsePointsWithPrecision(buf.Bytes(), time.Now().UTC(), cmd.precision)
+		if err != nil {
+			return err
+		}
+		if err := b.WritePoints(points); err != nil {
+			return err
+		}
+		buf.Reset()
+		n = 0
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		// Skip blank lines and comments, such as the context lines written by export.
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+
+		buf.Write(line)
+		buf.WriteByte('\n')
+		if n++; n >= batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if err := flush(); err != nil {
+		return err
+	} else if err := b.Close(); err != nil {
+		return err
+	}
+
+	for _, path := range b.Files() {
+		fmt.Fprintln(cmd.Stdout, path)
+	}
+	return nil
+}
@@ -294,16 +294,16 @@ var (
 		"none", "gor",
 	}
 	intEnc = []string{
-		"none", "s8b", "rle",
+		"none", "s8b", "rle", "dod",
 	}
 	boolEnc = []string{
 		"none", "bp",
 	}
 	stringEnc = []string{
-		"none", "snpy",
+		"none", "snpy", "dfl", "dict",
 	}
 	unsignedEnc = []string{
-		"none", "s8b", "rle",
+		"none", "s8b", "rle", "dod",
 	}
 	encDescs = [][]string{
 		timeEnc, floatEnc, intEnc, boolEnc, stringEnc, unsignedEnc,
@@ -31,13 +31,14 @@ Usage: influx_inspect [[command] [arguments]]
 
 The commands are:
 
+    buildtsm             builds tsm1 files from line protocol for bulk ingestion
     dumptsi              dumps low-level details about tsi1 files.
     dumptsm              dumps low-level details about tsm1 files.
     export               exports raw data from a shard to line protocol
     inmem2tsi            generates a tsi1 index from an in-memory index shard
     help                 display this help message
     report               displays a shard level report
-    verify               verifies integrity of TSM files
+    verify               verifies integrity of TSM files and sealed shards
 
 "help" is the default command.
 
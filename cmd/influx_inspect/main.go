@@ -8,6 +8,7 @@ import (
 	"os"
 
 	"github.com/influxdata/influxdb/cmd"
+	"github.com/influxdata/influxdb/cmd/influx_inspect/buildtsm"
 	"github.com/influxdata/influxdb/cmd/influx_inspect/dumptsi"
 	"github.com/influxdata/influxdb/cmd/influx_inspect/dumptsm"
 	"github.com/influxdata/influxdb/cmd/influx_inspect/export"
@@ -55,6 +56,11 @@ func (m *Main) Run(args ...string) error {
 		if err := help.NewCommand().Run(args...); err != nil {
 			return fmt.Errorf("help: %s", err)
 		}
+	case "buildtsm":
+		name := buildtsm.NewCommand()
+		if err := name.Run(args...); err != nil {
+			return fmt.Errorf("buildtsm: %s", err)
+		}
 	case "dumptsi":
 		name := dumptsi.NewCommand()
 		if err := name.Run(args...); err != nil {
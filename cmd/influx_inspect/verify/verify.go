@@ -30,9 +30,10 @@ func NewCommand() *Command {
 
 // Run executes the command.
 func (cmd *Command) Run(args ...string) error {
-	var path string
+	var path, coldDir string
 	fs := flag.NewFlagSet("verify", flag.ExitOnError)
 	fs.StringVar(&path, "dir", os.Getenv("HOME")+"/.influxdb", "Root storage path. [$HOME/.influxdb]")
+	fs.StringVar(&coldDir, "cold-dir", "", "Cold tier data path.")
 
 	fs.SetOutput(cmd.Stdout)
 	fs.Usage = cmd.printUsage
@@ -50,14 +51,17 @@ func (cmd *Command) Run(args ...string) error {
 	// No need to do this in a loop
 	ext := fmt.Sprintf(".%s", tsm1.TSMFileExtension)
 
-	// Get all TSM files by walking through the data dir
+	// Get all TSM files and seal manifests by walking through the data dir
 	files := []string{}
+	manifests := []string{}
 	err := filepath.Walk(dataPath, func(path string, f os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 		if filepath.Ext(path) == ext {
 			files = append(files, path)
+		} else if filepath.Base(path) == tsm1.SealFileName {
+			manifests = append(manifests, path)
 		}
 		return nil
 	})
@@ -100,20 +104,68 @@ func (cmd *Command) Run(args ...string) error {
 		reader.Close()
 	}
 
+	// Verify the checksums of the files of every sealed shard
+	brokenFiles := 0
+	totalFiles := 0
+	for _, f := range manifests {
+		dir := filepath.Dir(f)
+		m, err := tsm1.ReadSealManifest(dir)
+		if err != nil {
+			return err
+		}
+
+		// Cold files are in the same relative location under the cold tier.
+		var shardColdDir string
+		if coldDir != "" {
+			rel, err := filepath.Rel(dataPath, dir)
+			if err != nil {
+				return err
+			}
+			shardColdDir = filepath.Join(coldDir, rel)
+		}
+
+		brokenManifestFiles := 0
+		for _, sf := range m.Files {
+			fileDir := dir
+			if sf.Cold {
+				if shardColdDir == "" {
+					fmt.Fprintf(tw, "%s: skipped cold file %s, -cold-dir not set\n", f, sf.Name)
+					continue
+				}
+				fileDir = shardColdDir
+			}
+
+			totalFiles++
+			if err := sf.Verify(fileDir); err != nil {
+				brokenFiles++
+				brokenManifestFiles++
+				fmt.Fprintf(tw, "%s: %v\n", f, err)
+			}
+		}
+		if brokenManifestFiles == 0 {
+			fmt.Fprintf(tw, "%s: sealed %s, healthy\n", f, m.SealedAt.Format(time.RFC3339))
+		}
+	}
+
 	fmt.Fprintf(tw, "Broken Blocks: %d / %d, in %vs\n", brokenBlocks, totalBlocks, time.Since(start).Seconds())
+	if len(manifests) > 0 {
+		fmt.Fprintf(tw, "Broken Sealed Files: %d / %d\n", brokenFiles, totalFiles)
+	}
 	tw.Flush()
 	return nil
 }
 
 // printUsage prints the usage message to STDERR.
 func (cmd *Command) printUsage() {
-	usage := fmt.Sprintf(`Verifies the integrity of TSM files.
+	usage := fmt.Sprintf(`Verifies the integrity of TSM files and of the files of sealed shards.
 
 Usage: influx_inspect verify [flags]
 
     -dir <path>
             Root storage path
             Defaults to "%[1]s/.influxdb".
+    -cold-dir <path>
+            Cold tier data path, used to verify sealed files stored on the cold tier
  `, os.Getenv("HOME"))
 
 	fmt.Fprintf(cmd.Stdout, usage)
@@ -1,3 +1,94 @@
 package verify_test
 
-// TODO: write some tests
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/cmd/influx_inspect/verify"
+	"github.com/influxdata/influxdb/tsdb/engine/tsm1"
+)
+
+func TestCommand_Run_SealedManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "verify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	shardDir := filepath.Join(dir, "data", "db0", "rp0", "1")
+	if err := os.MkdirAll(shardDir, 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(shardDir, "000000001-000000001.tsm")
+	MustWriteTSM(path, []byte("cpu,host=A#!~#value"), tsm1.Values{tsm1.NewValue(0, 1.0)})
+
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(buf)
+	m := tsm1.SealManifest{Files: []tsm1.SealedFile{{
+		Name:   filepath.Base(path),
+		Size:   int64(len(buf)),
+		SHA256: hex.EncodeToString(sum[:]),
+	}}}
+	if buf, err := json.Marshal(m); err != nil {
+		t.Fatal(err)
+	} else if err := ioutil.WriteFile(filepath.Join(shardDir, tsm1.SealFileName), buf, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	if out := MustRun(dir); !strings.Contains(out, "Broken Sealed Files: 0 / 1") {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+
+	// Changing a block of a sealed file breaks the manifest.
+	buf[10]++
+	if err := ioutil.WriteFile(path, buf, 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	if out := MustRun(dir); !strings.Contains(out, "Broken Sealed Files: 1 / 1") {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+}
+
+// MustRun runs the verify command against dir and returns its output.
+func MustRun(dir string) string {
+	var out bytes.Buffer
+	cmd := verify.NewCommand()
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run("-dir", dir); err != nil {
+		panic(err)
+	}
+	return out.String()
+}
+
+// MustWriteTSM writes values for key to a new TSM file at path.
+func MustWriteTSM(path string, key []byte, values tsm1.Values) {
+	f, err := os.Create(path)
+	if err != nil {
+		panic(err)
+	}
+
+	w, err := tsm1.NewTSMWriter(f)
+	if err != nil {
+		panic(err)
+	}
+	if err := w.Write(key, values); err != nil {
+		panic(err)
+	} else if err := w.WriteIndex(); err != nil {
+		panic(err)
+	} else if err := w.Close(); err != nil {
+		panic(err)
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/influxdata/influxdb/services/meta"
 	"github.com/influxdata/influxdb/services/opentsdb"
 	"github.com/influxdata/influxdb/services/precreator"
+	"github.com/influxdata/influxdb/services/rebalance"
 	"github.com/influxdata/influxdb/services/retention"
 	"github.com/influxdata/influxdb/services/storage"
 	"github.com/influxdata/influxdb/services/subscriber"
@@ -45,6 +46,7 @@ type Config struct {
 	Coordinator coordinator.Config `toml:"coordinator"`
 	Retention   retention.Config   `toml:"retention"`
 	Precreator  precreator.Config  `toml:"shard-precreation"`
+	Rebalance   rebalance.Config   `toml:"shard-rebalance"`
 
 	Monitor        monitor.Config    `toml:"monitor"`
 	Subscriber     subscriber.Config `toml:"subscriber"`
@@ -84,6 +86,7 @@ func NewConfig() *Config {
 
 	c.ContinuousQuery = continuous_querier.NewConfig()
 	c.Retention = retention.NewConfig()
+	c.Rebalance = rebalance.NewConfig()
 	c.BindAddress = DefaultBindAddress
 
 	return c
@@ -107,6 +110,7 @@ func NewDemoConfig() (*Config, error) {
 	c.Meta.Dir = filepath.Join(homeDir, ".influxdb/meta")
 	c.Data.Dir = filepath.Join(homeDir, ".influxdb/data")
 	c.Data.WALDir = filepath.Join(homeDir, ".influxdb/wal")
+	c.Rebalance.Dir = filepath.Join(homeDir, ".influxdb/rebalance")
 
 	return c, nil
 }
@@ -171,6 +175,10 @@ func (c *Config) Validate() error {
 		return err
 	}
 
+	if err := c.Rebalance.Validate(); err != nil {
+		return err
+	}
+
 	if err := c.Subscriber.Validate(); err != nil {
 		return err
 	}
@@ -332,6 +340,7 @@ func (c *Config) diagnosticsClients() map[string]diagnostics.Client {
 		"config-coordinator": c.Coordinator,
 		"config-retention":   c.Retention,
 		"config-precreator":  c.Precreator,
+		"config-rebalance":   c.Rebalance,
 
 		"config-monitor":    c.Monitor,
 		"config-subscriber": c.Subscriber,
@@ -24,6 +24,7 @@ import (
 	"github.com/influxdata/influxdb/services/meta"
 	"github.com/influxdata/influxdb/services/opentsdb"
 	"github.com/influxdata/influxdb/services/precreator"
+	"github.com/influxdata/influxdb/services/rebalance"
 	"github.com/influxdata/influxdb/services/retention"
 	"github.com/influxdata/influxdb/services/snapshotter"
 	"github.com/influxdata/influxdb/services/subscriber"
@@ -184,8 +185,9 @@ func NewServer(c *Config, buildInfo *BuildInfo) (*Server, error) {
 		TaskManager: s.QueryExecutor.TaskManager,
 		TSDBStore:   coordinator.LocalTSDBStore{Store: s.TSDBStore},
 		ShardMapper: &coordinator.LocalShardMapper{
-			MetaClient: s.MetaClient,
-			TSDBStore:  coordinator.LocalTSDBStore{Store: s.TSDBStore},
+			MetaClient:    s.MetaClient,
+			TSDBStore:     coordinator.LocalTSDBStore{Store: s.TSDBStore},
+			ReadSnapshots: c.Coordinator.ReadSnapshotsEnabled,
 		},
 		Monitor:           s.Monitor,
 		PointsWriter:      s.PointsWriter,
@@ -249,6 +251,17 @@ func (s *Server) appendRetentionPolicyService(c retention.Config) {
 	s.Services = append(s.Services, srv)
 }
 
+func (s *Server) appendRebalanceService(c rebalance.Config) {
+	if !c.Enabled {
+		return
+	}
+	srv := rebalance.NewService(c)
+	srv.MetaClient = s.MetaClient
+	srv.TSDBStore = s.TSDBStore
+	srv.Monitor = s.Monitor
+	s.Services = append(s.Services, srv)
+}
+
 func (s *Server) appendHTTPDService(c httpd.Config) {
 	if !c.Enabled {
 		return
@@ -379,6 +392,7 @@ func (s *Server) Open() error {
 	s.appendHTTPDService(s.config.HTTPD)
 	s.appendStorageService(s.config.Storage)
 	s.appendRetentionPolicyService(s.config.Retention)
+	s.appendRebalanceService(s.config.Rebalance)
 	for _, i := range s.config.GraphiteInputs {
 		if err := s.appendGraphiteService(i); err != nil {
 			return err
@@ -36,6 +36,7 @@ type Config struct {
 	MaxSelectPointN      int           `toml:"max-select-point"`
 	MaxSelectSeriesN     int           `toml:"max-select-series"`
 	MaxSelectBucketsN    int           `toml:"max-select-buckets"`
+	ReadSnapshotsEnabled bool          `toml:"read-snapshots-enabled"`
 }
 
 // NewConfig returns an instance of Config with defaults.
@@ -59,5 +60,6 @@ func (c Config) Diagnostics() (*diagnostics.Diagnostics, error) {
 		"max-select-point":       c.MaxSelectPointN,
 		"max-select-series":      c.MaxSelectSeriesN,
 		"max-select-buckets":     c.MaxSelectBucketsN,
+		"read-snapshots-enabled": c.ReadSnapshotsEnabled,
 	}), nil
 }
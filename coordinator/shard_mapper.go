@@ -27,6 +27,10 @@ type LocalShardMapper struct {
 	TSDBStore interface {
 		ShardGroup(ids []uint64) tsdb.ShardGroup
 	}
+
+	// ReadSnapshots sets whether the mapped shards are read from snapshots taken when
+	// they are mapped.
+	ReadSnapshots bool
 }
 
 // MapShards maps the sources to the appropriate shards into an IteratorCreator.
@@ -41,6 +45,12 @@ func (e *LocalShardMapper) MapShards(sources influxql.Sources, t influxql.TimeRa
 		return nil, err
 	}
 	a.MinTime, a.MaxTime = tmin, tmax
+
+	if e.ReadSnapshots {
+		if err := a.readSnapshots(); err != nil {
+			return nil, err
+		}
+	}
 	return a, nil
 }
 
@@ -96,6 +106,37 @@ type LocalShardMapping struct {
 	// Any attempt to use a time after this one will automatically result in using
 	// this time instead.
 	MaxTime time.Time
+
+	// ReadSnapshots are the snapshots the mapped shards are read from, if any.
+	ReadSnapshots tsdb.ReadSnapshots
+}
+
+// readSnapshots takes a read snapshot of each mapped shard.
+func (a *LocalShardMapping) readSnapshots() error {
+	a.ReadSnapshots = make(tsdb.ReadSnapshots)
+	for _, sg := range a.ShardMap {
+		sg, ok := sg.(interface {
+			ReadSnapshots() (tsdb.ReadSnapshots, error)
+		})
+		if !ok {
+			continue
+		}
+
+		snapshots, err := sg.ReadSnapshots()
+		if err != nil {
+			a.ReadSnapshots.Release()
+			a.ReadSnapshots = nil
+			return err
+		}
+		for id, s := range snapshots {
+			if _, ok := a.ReadSnapshots[id]; ok {
+				s.Release()
+				continue
+			}
+			a.ReadSnapshots[id] = s
+		}
+	}
+	return nil
 }
 
 func (a *LocalShardMapping) FieldDimensions(m *influxql.Measurement) (fields map[string]influxql.DataType, dimensions map[string]struct{}, err error) {
@@ -182,6 +223,10 @@ func (a *LocalShardMapping) CreateIterator(ctx context.Context, m *influxql.Meas
 		opt.EndTime = a.MaxTime.UnixNano()
 	}
 
+	if a.ReadSnapshots != nil {
+		ctx = tsdb.NewContextWithReadSnapshots(ctx, a.ReadSnapshots)
+	}
+
 	if m.Regex != nil {
 		measurements := sg.MeasurementsByRegex(m.Regex.Val)
 		inputs := make([]query.Iterator, 0, len(measurements))
@@ -242,9 +287,11 @@ func (a *LocalShardMapping) IteratorCost(m *influxql.Measurement, opt query.Iter
 	return sg.IteratorCost(m.Name, opt)
 }
 
-// Close clears out the list of mapped shards.
+// Close clears out the list of mapped shards and releases their read snapshots.
 func (a *LocalShardMapping) Close() error {
 	a.ShardMap = nil
+	a.ReadSnapshots.Release()
+	a.ReadSnapshots = nil
 	return nil
 }
 
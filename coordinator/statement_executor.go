@@ -493,6 +493,8 @@ func (e *StatementExecutor) executeExplainAnalyzeStatement(q *influxql.ExplainSt
 	}
 
 CLEANUP:
+	// Gather the points and storage reads of the iterators before they're closed.
+	stats := query.Iterators(itrs).Stats()
 	em.Close()
 	if err != nil {
 		return nil, err
@@ -506,6 +508,13 @@ CLEANUP:
 		fields.Duration("total_time", totalTime),
 		fields.Duration("planning_time", iterTime),
 		fields.Duration("execution_time", totalTime-iterTime),
+		fields.Int64("series", int64(stats.SeriesN)),
+		fields.Int64("points", int64(stats.PointN)),
+		fields.Int64("blocks_read", int64(stats.BlockReadN)),
+		fields.Int64("blocks_decoded", int64(stats.BlockDecodeN)),
+		fields.Int64("block_bytes", stats.BlockBytes),
+		fields.Int64("cache_hits", int64(stats.CacheHitN)),
+		fields.Int64("block_merges", int64(stats.BlockMergeN)),
 	)
 	span.Finish()
 
@@ -742,7 +751,23 @@ func (e *StatementExecutor) executeShowMeasurementsStatement(q *influxql.ShowMea
 		return ErrDatabaseNameRequired
 	}
 
-	names, err := e.TSDBStore.MeasurementNames(q.Database, q.Condition)
+	// Determine appropriate time range. If one or fewer time boundaries provided
+	// then min/max possible time should be used instead.
+	valuer := &influxql.NowValuer{Now: time.Now()}
+	cond, timeRange, err := influxql.ConditionExpr(q.Condition, valuer)
+	if err != nil {
+		return err
+	}
+
+	// Restrict the measurements to the shards overlapping a time range, if one is given.
+	var shardIDs []uint64
+	if !timeRange.Min.IsZero() || !timeRange.Max.IsZero() {
+		if shardIDs, err = e.shardIDsByTimeRange(q.Database, timeRange); err != nil {
+			return err
+		}
+	}
+
+	names, err := e.TSDBStore.MeasurementNames(q.Database, shardIDs, timeRange, cond)
 	if err != nil || len(names) == 0 {
 		return ctx.Send(&query.Result{
 			StatementID: ctx.StatementID,
@@ -816,10 +841,11 @@ func (e *StatementExecutor) executeShowRetentionPoliciesStatement(q *influxql.Sh
 
 func (e *StatementExecutor) executeShowShardsStatement(stmt *influxql.ShowShardsStatement) (models.Rows, error) {
 	dis := e.MetaClient.Databases()
+	rebalance := e.rebalanceProgress()
 
 	rows := []*models.Row{}
 	for _, di := range dis {
-		row := &models.Row{Columns: []string{"id", "database", "retention_policy", "shard_group", "start_time", "end_time", "expiry_time", "owners"}, Name: di.Name}
+		row := &models.Row{Columns: []string{"id", "database", "retention_policy", "shard_group", "start_time", "end_time", "expiry_time", "owners", "rebalance"}, Name: di.Name}
 		for _, rpi := range di.RetentionPolicies {
 			for _, sgi := range rpi.ShardGroups {
 				// Shards associated with deleted shard groups are effectively deleted.
@@ -843,6 +869,7 @@ func (e *StatementExecutor) executeShowShardsStatement(stmt *influxql.ShowShards
 						sgi.EndTime.UTC().Format(time.RFC3339),
 						sgi.EndTime.Add(rpi.Duration).UTC().Format(time.RFC3339),
 						joinUint64(ownerIDs),
+						rebalance[si.ID],
 					})
 				}
 			}
@@ -852,6 +879,42 @@ func (e *StatementExecutor) executeShowShardsStatement(stmt *influxql.ShowShards
 	return rows, nil
 }
 
+// rebalanceProgress returns the phase and progress of the shard rebalance job
+// for each shard it merges or imports into, keyed by shard ID.
+func (e *StatementExecutor) rebalanceProgress() map[uint64]string {
+	if e.Monitor == nil {
+		return nil
+	}
+
+	diags, err := e.Monitor.Diagnostics()
+	if err != nil {
+		return nil
+	}
+	d := diags["shard-rebalance"]
+	if d == nil {
+		return nil
+	}
+
+	col := make(map[string]int, len(d.Columns))
+	for i, c := range d.Columns {
+		col[c] = i
+	}
+
+	progress := make(map[uint64]string)
+	for _, r := range d.Rows {
+		status := fmt.Sprintf("%v (%.1f%%)", r[col["phase"]], r[col["progress"]])
+		for _, id := range strings.Split(fmt.Sprint(r[col["source_shards"]]), ",") {
+			if n, err := strconv.ParseUint(id, 10, 64); err == nil {
+				progress[n] = status
+			}
+		}
+		if n, ok := r[col["target_shard"]].(uint64); ok {
+			progress[n] = status
+		}
+	}
+	return progress
+}
+
 func (e *StatementExecutor) executeShowSeriesCardinalityStatement(stmt *influxql.ShowSeriesCardinalityStatement) (models.Rows, error) {
 	n, err := e.TSDBStore.SeriesCardinality(stmt.Database)
 	if err != nil {
@@ -939,13 +1002,6 @@ func (e *StatementExecutor) executeShowTagKeys(q *influxql.ShowTagKeysStatement,
 		return ErrDatabaseNameRequired
 	}
 
-	// Determine shard set based on database and time range.
-	// SHOW TAG KEYS returns all tag keys for the default retention policy.
-	di := e.MetaClient.Database(q.Database)
-	if di == nil {
-		return fmt.Errorf("database not found: %s", q.Database)
-	}
-
 	// Determine appropriate time range. If one or fewer time boundaries provided
 	// then min/max possible time should be used instead.
 	valuer := &influxql.NowValuer{Now: time.Now()}
@@ -955,23 +1011,12 @@ func (e *StatementExecutor) executeShowTagKeys(q *influxql.ShowTagKeysStatement,
 	}
 
 	// Get all shards for all retention policies.
-	var allGroups []meta.ShardGroupInfo
-	for _, rpi := range di.RetentionPolicies {
-		sgis, err := e.MetaClient.ShardGroupsByTimeRange(q.Database, rpi.Name, timeRange.MinTime(), timeRange.MaxTime())
-		if err != nil {
-			return err
-		}
-		allGroups = append(allGroups, sgis...)
-	}
-
-	var shardIDs []uint64
-	for _, sgi := range allGroups {
-		for _, si := range sgi.Shards {
-			shardIDs = append(shardIDs, si.ID)
-		}
+	shardIDs, err := e.shardIDsByTimeRange(q.Database, timeRange)
+	if err != nil {
+		return err
 	}
 
-	tagKeys, err := e.TSDBStore.TagKeys(ctx.Authorizer, shardIDs, cond)
+	tagKeys, err := e.TSDBStore.TagKeys(ctx.Authorizer, shardIDs, timeRange, cond)
 	if err != nil {
 		return ctx.Send(&query.Result{
 			StatementID: ctx.StatementID,
@@ -1025,18 +1070,34 @@ func (e *StatementExecutor) executeShowTagKeys(q *influxql.ShowTagKeysStatement,
 	return nil
 }
 
+// shardIDsByTimeRange returns the IDs of the shards of all retention policies of the
+// database that overlap the time range.  The returned slice is never nil.
+func (e *StatementExecutor) shardIDsByTimeRange(database string, timeRange influxql.TimeRange) ([]uint64, error) {
+	di := e.MetaClient.Database(database)
+	if di == nil {
+		return nil, fmt.Errorf("database not found: %s", database)
+	}
+
+	shardIDs := []uint64{}
+	for _, rpi := range di.RetentionPolicies {
+		sgis, err := e.MetaClient.ShardGroupsByTimeRange(database, rpi.Name, timeRange.MinTime(), timeRange.MaxTime())
+		if err != nil {
+			return nil, err
+		}
+		for _, sgi := range sgis {
+			for _, si := range sgi.Shards {
+				shardIDs = append(shardIDs, si.ID)
+			}
+		}
+	}
+	return shardIDs, nil
+}
+
 func (e *StatementExecutor) executeShowTagValues(q *influxql.ShowTagValuesStatement, ctx *query.ExecutionContext) error {
 	if q.Database == "" {
 		return ErrDatabaseNameRequired
 	}
 
-	// Determine shard set based on database and time range.
-	// SHOW TAG VALUES returns all tag values for the default retention policy.
-	di := e.MetaClient.Database(q.Database)
-	if di == nil {
-		return fmt.Errorf("database not found: %s", q.Database)
-	}
-
 	// Determine appropriate time range. If one or fewer time boundaries provided
 	// then min/max possible time should be used instead.
 	valuer := &influxql.NowValuer{Now: time.Now()}
@@ -1046,23 +1107,12 @@ func (e *StatementExecutor) executeShowTagValues(q *influxql.ShowTagValuesStatem
 	}
 
 	// Get all shards for all retention policies.
-	var allGroups []meta.ShardGroupInfo
-	for _, rpi := range di.RetentionPolicies {
-		sgis, err := e.MetaClient.ShardGroupsByTimeRange(q.Database, rpi.Name, timeRange.MinTime(), timeRange.MaxTime())
-		if err != nil {
-			return err
-		}
-		allGroups = append(allGroups, sgis...)
-	}
-
-	var shardIDs []uint64
-	for _, sgi := range allGroups {
-		for _, si := range sgi.Shards {
-			shardIDs = append(shardIDs, si.ID)
-		}
+	shardIDs, err := e.shardIDsByTimeRange(q.Database, timeRange)
+	if err != nil {
+		return err
 	}
 
-	tagValues, err := e.TSDBStore.TagValues(ctx.Authorizer, shardIDs, cond)
+	tagValues, err := e.TSDBStore.TagValues(ctx.Authorizer, shardIDs, timeRange, cond)
 	if err != nil {
 		return ctx.Send(&query.Result{
 			StatementID: ctx.StatementID,
@@ -1378,9 +1428,9 @@ type TSDBStore interface {
 	DeleteSeries(database string, sources []influxql.Source, condition influxql.Expr) error
 	DeleteShard(id uint64) error
 
-	MeasurementNames(database string, cond influxql.Expr) ([][]byte, error)
-	TagKeys(auth query.Authorizer, shardIDs []uint64, cond influxql.Expr) ([]tsdb.TagKeys, error)
-	TagValues(auth query.Authorizer, shardIDs []uint64, cond influxql.Expr) ([]tsdb.TagValues, error)
+	MeasurementNames(database string, shardIDs []uint64, tr influxql.TimeRange, cond influxql.Expr) ([][]byte, error)
+	TagKeys(auth query.Authorizer, shardIDs []uint64, tr influxql.TimeRange, cond influxql.Expr) ([]tsdb.TagKeys, error)
+	TagValues(auth query.Authorizer, shardIDs []uint64, tr influxql.TimeRange, cond influxql.Expr) ([]tsdb.TagValues, error)
 
 	SeriesCardinality(database string) (int64, error)
 	MeasurementsCardinality(database string) (int64, error)
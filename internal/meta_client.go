@@ -34,6 +34,9 @@ type MetaClientMock struct {
 
 	PruneShardGroupsFn func() error
 
+	ReplaceShardGroupsFn func(database, policy string, ids []uint64, id uint64) error
+	ReserveShardGroupFn  func(database, policy string, timestamp time.Time) (*meta.ShardGroupInfo, error)
+
 	RetentionPolicyFn func(database, name string) (rpi *meta.RetentionPolicyInfo, err error)
 
 	AuthenticateFn           func(username, password string) (ui meta.User, err error)
@@ -168,3 +171,11 @@ func (c *MetaClientMock) Data() meta.Data            { return c.DataFn() }
 func (c *MetaClientMock) SetData(d *meta.Data) error { return c.SetDataFn(d) }
 
 func (c *MetaClientMock) PruneShardGroups() error { return c.PruneShardGroupsFn() }
+
+func (c *MetaClientMock) ReplaceShardGroups(database, policy string, ids []uint64, id uint64) error {
+	return c.ReplaceShardGroupsFn(database, policy, ids, id)
+}
+
+func (c *MetaClientMock) ReserveShardGroup(database, policy string, timestamp time.Time) (*meta.ShardGroupInfo, error) {
+	return c.ReserveShardGroupFn(database, policy, timestamp)
+}
@@ -22,27 +22,34 @@ type TSDBStoreMock struct {
 	DeleteMeasurementFn       func(database, name string) error
 	DeleteRetentionPolicyFn   func(database, name string) error
 	DeleteSeriesFn            func(database string, sources []influxql.Source, condition influxql.Expr) error
+	DeleteShardSeriesFn       func(database string, shardIDs []uint64, sources []influxql.Source, condition influxql.Expr) error
 	DeleteShardFn             func(id uint64) error
 	DiskSizeFn                func() (int64, error)
 	ExpandSourcesFn           func(sources influxql.Sources) (influxql.Sources, error)
 	ImportShardFn             func(id uint64, r io.Reader) error
+	IngestShardFilesFn        func(shardID uint64, paths []string) error
 	MeasurementSeriesCountsFn func(database string) (measuments int, series int)
 	MeasurementsCardinalityFn func(database string) (int64, error)
-	MeasurementNamesFn        func(database string, cond influxql.Expr) ([][]byte, error)
+	MeasurementNamesFn        func(database string, shardIDs []uint64, tr influxql.TimeRange, cond influxql.Expr) ([][]byte, error)
+	OnDeleteSeriesFn          func(fn func(database string, shardIDs []uint64, sources []influxql.Source, condition influxql.Expr) error)
 	OpenFn                    func() error
 	PathFn                    func() string
+	RemoveShardSnapshotFn     func(id uint64, path string) error
 	RestoreShardFn            func(id uint64, r io.Reader) error
 	SeriesCardinalityFn       func(database string) (int64, error)
 	SetShardEnabledFn         func(shardID uint64, enabled bool) error
+	SetShardReadOnlyFn        func(shardID uint64, readOnly bool) error
+	SetShardRollupRuleFn      func(shardID uint64, rule *tsdb.RollupRule) error
 	ShardFn                   func(id uint64) *tsdb.Shard
 	ShardGroupFn              func(ids []uint64) tsdb.ShardGroup
 	ShardIDsFn                func() []uint64
+	ShardLastModifiedFn       func(id uint64) time.Time
 	ShardNFn                  func() int
 	ShardRelativePathFn       func(id uint64) (string, error)
 	ShardsFn                  func(ids []uint64) []*tsdb.Shard
 	StatisticsFn              func(tags map[string]string) []models.Statistic
-	TagKeysFn                 func(auth query.Authorizer, shardIDs []uint64, cond influxql.Expr) ([]tsdb.TagKeys, error)
-	TagValuesFn               func(auth query.Authorizer, shardIDs []uint64, cond influxql.Expr) ([]tsdb.TagValues, error)
+	TagKeysFn                 func(auth query.Authorizer, shardIDs []uint64, tr influxql.TimeRange, cond influxql.Expr) ([]tsdb.TagKeys, error)
+	TagValuesFn               func(auth query.Authorizer, shardIDs []uint64, tr influxql.TimeRange, cond influxql.Expr) ([]tsdb.TagValues, error)
 	WithLoggerFn              func(log *zap.Logger)
 	WriteToShardFn            func(shardID uint64, points []models.Point) error
 }
@@ -57,6 +64,9 @@ func (s *TSDBStoreMock) CreateShard(database string, retentionPolicy string, sha
 func (s *TSDBStoreMock) CreateShardSnapshot(id uint64) (string, error) {
 	return s.CreateShardSnapshotFn(id)
 }
+func (s *TSDBStoreMock) RemoveShardSnapshot(id uint64, path string) error {
+	return s.RemoveShardSnapshotFn(id, path)
+}
 func (s *TSDBStoreMock) Databases() []string {
 	return s.DatabasesFn()
 }
@@ -72,6 +82,9 @@ func (s *TSDBStoreMock) DeleteRetentionPolicy(database string, name string) erro
 func (s *TSDBStoreMock) DeleteSeries(database string, sources []influxql.Source, condition influxql.Expr) error {
 	return s.DeleteSeriesFn(database, sources, condition)
 }
+func (s *TSDBStoreMock) DeleteShardSeries(database string, shardIDs []uint64, sources []influxql.Source, condition influxql.Expr) error {
+	return s.DeleteShardSeriesFn(database, shardIDs, sources, condition)
+}
 func (s *TSDBStoreMock) DeleteShard(shardID uint64) error {
 	return s.DeleteShardFn(shardID)
 }
@@ -84,8 +97,11 @@ func (s *TSDBStoreMock) ExpandSources(sources influxql.Sources) (influxql.Source
 func (s *TSDBStoreMock) ImportShard(id uint64, r io.Reader) error {
 	return s.ImportShardFn(id, r)
 }
-func (s *TSDBStoreMock) MeasurementNames(database string, cond influxql.Expr) ([][]byte, error) {
-	return s.MeasurementNamesFn(database, cond)
+func (s *TSDBStoreMock) IngestShardFiles(shardID uint64, paths []string) error {
+	return s.IngestShardFilesFn(shardID, paths)
+}
+func (s *TSDBStoreMock) MeasurementNames(database string, shardIDs []uint64, tr influxql.TimeRange, cond influxql.Expr) ([][]byte, error) {
+	return s.MeasurementNamesFn(database, shardIDs, tr, cond)
 }
 func (s *TSDBStoreMock) MeasurementSeriesCounts(database string) (measuments int, series int) {
 	return s.MeasurementSeriesCountsFn(database)
@@ -93,6 +109,9 @@ func (s *TSDBStoreMock) MeasurementSeriesCounts(database string) (measuments int
 func (s *TSDBStoreMock) MeasurementsCardinality(database string) (int64, error) {
 	return s.MeasurementsCardinalityFn(database)
 }
+func (s *TSDBStoreMock) OnDeleteSeries(fn func(database string, shardIDs []uint64, sources []influxql.Source, condition influxql.Expr) error) {
+	s.OnDeleteSeriesFn(fn)
+}
 func (s *TSDBStoreMock) Open() error {
 	return s.OpenFn()
 }
@@ -108,6 +127,12 @@ func (s *TSDBStoreMock) SeriesCardinality(database string) (int64, error) {
 func (s *TSDBStoreMock) SetShardEnabled(shardID uint64, enabled bool) error {
 	return s.SetShardEnabledFn(shardID, enabled)
 }
+func (s *TSDBStoreMock) SetShardReadOnly(shardID uint64, readOnly bool) error {
+	return s.SetShardReadOnlyFn(shardID, readOnly)
+}
+func (s *TSDBStoreMock) SetShardRollupRule(shardID uint64, rule *tsdb.RollupRule) error {
+	return s.SetShardRollupRuleFn(shardID, rule)
+}
 func (s *TSDBStoreMock) Shard(id uint64) *tsdb.Shard {
 	return s.ShardFn(id)
 }
@@ -117,6 +142,9 @@ func (s *TSDBStoreMock) ShardGroup(ids []uint64) tsdb.ShardGroup {
 func (s *TSDBStoreMock) ShardIDs() []uint64 {
 	return s.ShardIDsFn()
 }
+func (s *TSDBStoreMock) ShardLastModified(id uint64) time.Time {
+	return s.ShardLastModifiedFn(id)
+}
 func (s *TSDBStoreMock) ShardN() int {
 	return s.ShardNFn()
 }
@@ -129,11 +157,11 @@ func (s *TSDBStoreMock) Shards(ids []uint64) []*tsdb.Shard {
 func (s *TSDBStoreMock) Statistics(tags map[string]string) []models.Statistic {
 	return s.StatisticsFn(tags)
 }
-func (s *TSDBStoreMock) TagKeys(auth query.Authorizer, shardIDs []uint64, cond influxql.Expr) ([]tsdb.TagKeys, error) {
-	return s.TagKeysFn(auth, shardIDs, cond)
+func (s *TSDBStoreMock) TagKeys(auth query.Authorizer, shardIDs []uint64, tr influxql.TimeRange, cond influxql.Expr) ([]tsdb.TagKeys, error) {
+	return s.TagKeysFn(auth, shardIDs, tr, cond)
 }
-func (s *TSDBStoreMock) TagValues(auth query.Authorizer, shardIDs []uint64, cond influxql.Expr) ([]tsdb.TagValues, error) {
-	return s.TagValuesFn(auth, shardIDs, cond)
+func (s *TSDBStoreMock) TagValues(auth query.Authorizer, shardIDs []uint64, tr influxql.TimeRange, cond influxql.Expr) ([]tsdb.TagValues, error) {
+	return s.TagValuesFn(auth, shardIDs, tr, cond)
 }
 func (s *TSDBStoreMock) WithLogger(log *zap.Logger) {
 	s.WithLoggerFn(log)
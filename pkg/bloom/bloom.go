@@ -57,17 +57,36 @@ func (f *Filter) Clone() *Filter {
 
 // Insert inserts data to the filter.
 func (f *Filter) Insert(v []byte) {
-	h := f.hash(v)
+	f.insert(f.hash(v))
+}
+
+// Contains returns true if the filter possibly contains v.
+// Returns false if the filter definitely does not contain v.
+func (f *Filter) Contains(v []byte) bool {
+	return f.contains(f.hash(v))
+}
+
+// InsertKey inserts data to the filter.  Unlike Insert, v is hashed once and is never
+// modified, so it may be shared or read-only.  A filter must only be used with either
+// InsertKey and ContainsKey or Insert and Contains.
+func (f *Filter) InsertKey(v []byte) {
+	f.insert(keyHash(v))
+}
+
+// ContainsKey returns true if the filter possibly contains v inserted by InsertKey.
+// Returns false if the filter definitely does not contain v.  v is never modified.
+func (f *Filter) ContainsKey(v []byte) bool {
+	return f.contains(keyHash(v))
+}
+
+func (f *Filter) insert(h [2]uint64) {
 	for i := uint64(0); i < f.k; i++ {
 		loc := f.location(h, i)
 		f.b[loc>>3] |= 1 << (loc & 7)
 	}
 }
 
-// Contains returns true if the filter possibly contains v.
-// Returns false if the filter definitely does not contain v.
-func (f *Filter) Contains(v []byte) bool {
-	h := f.hash(v)
+func (f *Filter) contains(h [2]uint64) bool {
 	for i := uint64(0); i < f.k; i++ {
 		loc := f.location(h, i)
 		if f.b[loc>>3]&(1<<(loc&7)) == 0 {
@@ -117,6 +136,17 @@ func (f *Filter) hash(data []byte) [2]uint64 {
 	return [2]uint64{v1, v2}
 }
 
+// keyHash returns two 64-bit hashes based on a single xxhash of data.  The second is
+// derived by mixing the first with the splitmix64 finalizer.
+func keyHash(data []byte) [2]uint64 {
+	v1 := xxhash.Sum64(data)
+	v2 := v1
+	v2 = (v2 ^ (v2 >> 30)) * 0xbf58476d1ce4e5b9
+	v2 = (v2 ^ (v2 >> 27)) * 0x94d049bb133111eb
+	v2 ^= v2 >> 31
+	return [2]uint64{v1, v2}
+}
+
 // Estimate returns an estimated bit count and hash count given the element count and false positive rate.
 func Estimate(n uint64, p float64) (m uint64, k uint64) {
 	m = uint64(math.Ceil(-1 * float64(n) * math.Log(p) / math.Pow(math.Log(2), 2)))
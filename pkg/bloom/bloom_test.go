@@ -81,6 +81,33 @@ func testShortFilter_InsertContains(t *testing.T) {
 	})
 }
 
+// Ensure keys can be inserted and verified without being modified.
+func TestFilter_InsertKeyContainsKey(t *testing.T) {
+	f := bloom.NewFilter(1<<16, 7)
+	v := make([]byte, 4)
+	for i := 0; i < 1000; i++ {
+		binary.BigEndian.PutUint32(v, uint32(i))
+		f.InsertKey(v)
+	}
+
+	var fp int
+	for i := 0; i < 11000; i++ {
+		binary.BigEndian.PutUint32(v, uint32(i))
+		exp := append([]byte(nil), v...)
+		ok := f.ContainsKey(v)
+		if string(v) != string(exp) {
+			t.Fatalf("value modified: %x, expected %x", v, exp)
+		} else if i < 1000 && !ok {
+			t.Fatalf("got false for value %x, expected true", v)
+		} else if i >= 1000 && ok {
+			fp++
+		}
+	}
+	if fp > 100 {
+		t.Fatalf("got %d false positives in 10000 values", fp)
+	}
+}
+
 var benchCases = []struct {
 	m, k uint64
 	n    int
@@ -0,0 +1,70 @@
+package limiter
+
+import (
+	"sync"
+	"time"
+)
+
+// Rate is a token bucket limiter that limits the rate at which a resource,
+// such as bytes written to disk, is consumed.  Tokens accumulate at a fixed
+// rate up to a burst size.  Callers taking more tokens than are available are
+// delayed until the bucket would have refilled.
+type Rate struct {
+	mu     sync.Mutex
+	limit  float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewRate returns a Rate that allows limit tokens per second with bursts of up
+// to burst tokens.  A burst smaller than limit is set to limit.
+func NewRate(limit, burst int) *Rate {
+	if burst < limit {
+		burst = limit
+	}
+	return &Rate{
+		limit:  float64(limit),
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Limit returns the number of tokens allowed per second.
+func (r *Rate) Limit() int {
+	if r == nil {
+		return 0
+	}
+	return int(r.limit)
+}
+
+// WaitN takes n tokens and blocks until they are available.  It returns how
+// long the caller was delayed.  A nil Rate never delays callers.
+func (r *Rate) WaitN(n int) time.Duration {
+	if r == nil || r.limit <= 0 || n <= 0 {
+		return 0
+	}
+
+	r.mu.Lock()
+	now := time.Now()
+	r.tokens += now.Sub(r.last).Seconds() * r.limit
+	if r.tokens > r.burst {
+		r.tokens = r.burst
+	}
+	r.last = now
+
+	// Reserve the tokens even if there are not enough.  Later callers wait
+	// for the deficit to be refilled before their own tokens.
+	r.tokens -= float64(n)
+	var d time.Duration
+	if r.tokens < 0 {
+		d = time.Duration(-r.tokens / r.limit * float64(time.Second))
+	}
+	r.mu.Unlock()
+
+	if d > 0 {
+		time.Sleep(d)
+	}
+	return d
+}
@@ -0,0 +1,36 @@
+package limiter_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/pkg/limiter"
+)
+
+func TestRate_WaitN(t *testing.T) {
+	r := limiter.NewRate(1000, 1000)
+
+	// The initial burst is available immediately.
+	if d := r.WaitN(1000); d != 0 {
+		t.Fatalf("unexpected delay: %v", d)
+	}
+
+	// Further tokens are delayed until the bucket refills.
+	start := time.Now()
+	if d := r.WaitN(100); d < 50*time.Millisecond || d > 100*time.Millisecond {
+		t.Fatalf("unexpected delay: %v", d)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Fatalf("expected to be delayed, got %v", elapsed)
+	}
+}
+
+func TestRate_WaitN_Nil(t *testing.T) {
+	var r *limiter.Rate
+	if d := r.WaitN(1 << 30); d != 0 {
+		t.Fatalf("unexpected delay: %v", d)
+	}
+	if exp, got := 0, r.Limit(); exp != got {
+		t.Fatalf("limit mismatch: exp %v, got %v", exp, got)
+	}
+}
@@ -492,6 +492,11 @@ func (m *Interval) GetOffset() int64 {
 type IteratorStats struct {
 	SeriesN          *int64 `protobuf:"varint,1,opt,name=SeriesN" json:"SeriesN,omitempty"`
 	PointN           *int64 `protobuf:"varint,2,opt,name=PointN" json:"PointN,omitempty"`
+	BlockReadN       *int64 `protobuf:"varint,3,opt,name=BlockReadN" json:"BlockReadN,omitempty"`
+	BlockDecodeN     *int64 `protobuf:"varint,4,opt,name=BlockDecodeN" json:"BlockDecodeN,omitempty"`
+	BlockBytes       *int64 `protobuf:"varint,5,opt,name=BlockBytes" json:"BlockBytes,omitempty"`
+	CacheHitN        *int64 `protobuf:"varint,6,opt,name=CacheHitN" json:"CacheHitN,omitempty"`
+	BlockMergeN      *int64 `protobuf:"varint,7,opt,name=BlockMergeN" json:"BlockMergeN,omitempty"`
 	XXX_unrecognized []byte `json:"-"`
 }
 
@@ -514,6 +519,41 @@ func (m *IteratorStats) GetPointN() int64 {
 	return 0
 }
 
+func (m *IteratorStats) GetBlockReadN() int64 {
+	if m != nil && m.BlockReadN != nil {
+		return *m.BlockReadN
+	}
+	return 0
+}
+
+func (m *IteratorStats) GetBlockDecodeN() int64 {
+	if m != nil && m.BlockDecodeN != nil {
+		return *m.BlockDecodeN
+	}
+	return 0
+}
+
+func (m *IteratorStats) GetBlockBytes() int64 {
+	if m != nil && m.BlockBytes != nil {
+		return *m.BlockBytes
+	}
+	return 0
+}
+
+func (m *IteratorStats) GetCacheHitN() int64 {
+	if m != nil && m.CacheHitN != nil {
+		return *m.CacheHitN
+	}
+	return 0
+}
+
+func (m *IteratorStats) GetBlockMergeN() int64 {
+	if m != nil && m.BlockMergeN != nil {
+		return *m.BlockMergeN
+	}
+	return 0
+}
+
 type VarRef struct {
 	Val              *string `protobuf:"bytes,1,req,name=Val" json:"Val,omitempty"`
 	Type             *int32  `protobuf:"varint,2,opt,name=Type" json:"Type,omitempty"`
@@ -553,55 +593,59 @@ func init() {
 func init() { proto.RegisterFile("internal/internal.proto", fileDescriptorInternal) }
 
 var fileDescriptorInternal = []byte{
-	// 796 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x55, 0x6d, 0x6f, 0xe3, 0x44,
-	0x10, 0x96, 0xe3, 0x3a, 0x8d, 0x27, 0xcd, 0xf5, 0x58, 0x4a, 0x59, 0xa1, 0x13, 0xb2, 0x2c, 0x40,
-	0x16, 0xa0, 0x22, 0xf5, 0x13, 0x9f, 0x90, 0x72, 0xf4, 0x8a, 0x2a, 0xdd, 0xb5, 0xa7, 0x4d, 0xe9,
-	0xf7, 0x25, 0x9e, 0x5a, 0x2b, 0x39, 0xeb, 0xb0, 0x5e, 0xa3, 0xe4, 0x07, 0xf4, 0x87, 0xf1, 0x13,
-	0xf8, 0x47, 0x68, 0x67, 0xd7, 0x89, 0x53, 0x81, 0x7a, 0x9f, 0x32, 0xcf, 0x33, 0x93, 0x7d, 0x79,
-	0xe6, 0x99, 0x35, 0x7c, 0xa9, 0xb4, 0x45, 0xa3, 0x65, 0xfd, 0x53, 0x1f, 0x5c, 0xac, 0x4d, 0x63,
-	0x1b, 0x96, 0xfc, 0xd9, 0xa1, 0xd9, 0xe6, 0x4f, 0x31, 0x24, 0x1f, 0x1b, 0xa5, 0x2d, 0x63, 0x70,
-	0x74, 0x2b, 0x57, 0xc8, 0xa3, 0x6c, 0x54, 0xa4, 0x82, 0x62, 0xc7, 0xdd, 0xcb, 0xaa, 0xe5, 0x23,
-	0xcf, 0xb9, 0x98, 0x38, 0xb5, 0x42, 0x1e, 0x67, 0xa3, 0x22, 0x16, 0x14, 0xb3, 0xd7, 0x10, 0xdf,
-	0xaa, 0x9a, 0x1f, 0x65, 0xa3, 0x62, 0x22, 0x5c, 0xc8, 0xde, 0x40, 0x3c, 0xef, 0x36, 0x3c, 0xc9,
-	0xe2, 0x62, 0x7a, 0x09, 0x17, 0xb4, 0xd9, 0xc5, 0xbc, 0xdb, 0x08, 0x47, 0xb3, 0xaf, 0x01, 0xe6,
-	0x55, 0x65, 0xb0, 0x92, 0x16, 0x4b, 0x3e, 0xce, 0xa2, 0x62, 0x26, 0x06, 0x8c, 0xcb, 0x5f, 0xd7,
-	0x8d, 0xb4, 0x0f, 0xb2, 0xee, 0x90, 0x1f, 0x67, 0x51, 0x11, 0x89, 0x01, 0xc3, 0x72, 0x38, 0xb9,
-	0xd1, 0x16, 0x2b, 0x34, 0xbe, 0x62, 0x92, 0x45, 0x45, 0x2c, 0x0e, 0x38, 0x96, 0xc1, 0x74, 0x61,
-	0x8d, 0xd2, 0x95, 0x2f, 0x49, 0xb3, 0xa8, 0x48, 0xc5, 0x90, 0x72, 0xab, 0xbc, 0x6d, 0x9a, 0x1a,
-	0xa5, 0xf6, 0x25, 0x90, 0x45, 0xc5, 0x44, 0x1c, 0x70, 0xec, 0x1b, 0x98, 0xfd, 0xae, 0x5b, 0x55,
-	0x69, 0x2c, 0x7d, 0xd1, 0x49, 0x16, 0x15, 0x47, 0xe2, 0x90, 0x64, 0xdf, 0x43, 0xb2, 0xb0, 0xd2,
-	0xb6, 0x7c, 0x9a, 0x45, 0xc5, 0xf4, 0xf2, 0x2c, 0xdc, 0xf7, 0xc6, 0xa2, 0x91, 0xb6, 0x31, 0x94,
-	0x13, 0xbe, 0x84, 0x9d, 0x41, 0x72, 0x6f, 0xe4, 0x12, 0xf9, 0x2c, 0x8b, 0x8a, 0x13, 0xe1, 0x41,
-	0xfe, 0x4f, 0x44, 0x82, 0xb1, 0xaf, 0x60, 0x72, 0x25, 0xad, 0xbc, 0xdf, 0xae, 0x7d, 0x27, 0x12,
-	0xb1, 0xc3, 0xcf, 0x54, 0x19, 0xbd, 0xa8, 0x4a, 0xfc, 0xb2, 0x2a, 0x47, 0x2f, 0xab, 0x92, 0x7c,
-	0x8a, 0x2a, 0xe3, 0xff, 0x50, 0x25, 0x7f, 0x4a, 0xe0, 0xb4, 0x97, 0xe0, 0x6e, 0x6d, 0x55, 0xa3,
-	0xc9, 0x3d, 0xef, 0x36, 0x6b, 0xc3, 0x23, 0xda, 0x98, 0x62, 0xe7, 0x1e, 0xe7, 0x95, 0x51, 0x16,
-	0x17, 0xa9, 0xf7, 0xc7, 0xb7, 0x30, 0xbe, 0x56, 0x58, 0x97, 0x2d, 0xff, 0x8c, 0x0c, 0x34, 0x0b,
-	0x82, 0x3e, 0x48, 0x23, 0xf0, 0x51, 0x84, 0x24, 0xfb, 0x11, 0x8e, 0x17, 0x4d, 0x67, 0x96, 0xd8,
-	0xf2, 0x98, 0xea, 0x58, 0xa8, 0xfb, 0x80, 0xb2, 0xed, 0x0c, 0xae, 0x50, 0x5b, 0xd1, 0x97, 0xb0,
-	0x1f, 0x60, 0xe2, 0xa4, 0x30, 0x7f, 0xc9, 0x9a, 0xee, 0x3d, 0xbd, 0x3c, 0xed, 0xfb, 0x14, 0x68,
-	0xb1, 0x2b, 0x70, 0x5a, 0x5f, 0xa9, 0x15, 0xea, 0xd6, 0x9d, 0x9a, 0x6c, 0x9c, 0x8a, 0x01, 0xc3,
-	0x38, 0x1c, 0xff, 0x66, 0x9a, 0x6e, 0xfd, 0x76, 0xcb, 0x3f, 0xa7, 0x64, 0x0f, 0xdd, 0x0d, 0xaf,
-	0x55, 0x5d, 0x93, 0x24, 0x89, 0xa0, 0x98, 0xbd, 0x81, 0xd4, 0xfd, 0x0e, 0xed, 0xbc, 0x27, 0x5c,
-	0xf6, 0xd7, 0x46, 0x97, 0xca, 0x29, 0x44, 0x56, 0x4e, 0xc5, 0x9e, 0x70, 0xd9, 0x85, 0x95, 0xc6,
-	0xd2, 0xd0, 0xa5, 0xd4, 0xd2, 0x3d, 0xe1, 0xce, 0xf1, 0x4e, 0x97, 0x94, 0x03, 0xca, 0xf5, 0xd0,
-	0x39, 0xe9, 0x7d, 0xb3, 0x94, 0xb4, 0xe8, 0x17, 0xb4, 0xe8, 0x0e, 0xbb, 0x35, 0xe7, 0xed, 0x12,
-	0x75, 0xa9, 0x74, 0x45, 0x9e, 0x9d, 0x88, 0x3d, 0xe1, 0x1c, 0xfa, 0x5e, 0xad, 0x94, 0x25, 0xaf,
-	0xc7, 0xc2, 0x03, 0x76, 0x0e, 0xe3, 0xbb, 0xc7, 0xc7, 0x16, 0x2d, 0x19, 0x37, 0x16, 0x01, 0x39,
-	0x7e, 0xe1, 0xcb, 0x5f, 0x79, 0xde, 0x23, 0x77, 0xb2, 0x45, 0xf8, 0xc3, 0xa9, 0x3f, 0x59, 0x80,
-	0xfe, 0x46, 0x46, 0xad, 0xe9, 0xb9, 0x39, 0xf7, 0xbb, 0xef, 0x08, 0xb7, 0xde, 0x15, 0x96, 0xdd,
-	0x1a, 0xf9, 0x6b, 0x4a, 0x05, 0xe4, 0x3a, 0xf2, 0x41, 0x6e, 0x16, 0x68, 0x14, 0xb6, 0xb7, 0x9c,
-	0xd1, 0x92, 0x03, 0xc6, 0xed, 0x77, 0x67, 0x4a, 0x34, 0x58, 0xf2, 0x33, 0xfa, 0x63, 0x0f, 0xf3,
-	0x9f, 0xe1, 0x64, 0x60, 0x88, 0x96, 0x15, 0x90, 0xdc, 0x58, 0x5c, 0xb5, 0x3c, 0xfa, 0x5f, 0xd3,
-	0xf8, 0x82, 0xfc, 0xef, 0x08, 0xa6, 0x03, 0xba, 0x9f, 0xce, 0x3f, 0x64, 0x8b, 0xc1, 0xc1, 0x3b,
-	0xcc, 0x0a, 0x38, 0x15, 0x68, 0x51, 0x3b, 0x81, 0x3f, 0x36, 0xb5, 0x5a, 0x6e, 0x69, 0x44, 0x53,
-	0xf1, 0x9c, 0xde, 0xbd, 0xb4, 0xb1, 0x9f, 0x01, 0xba, 0xf5, 0x19, 0x24, 0x02, 0x2b, 0xdc, 0x84,
-	0x89, 0xf4, 0xc0, 0xed, 0x77, 0xd3, 0xde, 0x4b, 0x53, 0xa1, 0x0d, 0x73, 0xb8, 0xc3, 0xec, 0x3b,
-	0x78, 0xb5, 0xd8, 0xb6, 0x16, 0x57, 0xfd, 0x88, 0x91, 0xe3, 0x52, 0xf1, 0x8c, 0xcd, 0x7f, 0xd9,
-	0xdb, 0x9e, 0xce, 0xdf, 0x19, 0xef, 0x89, 0x88, 0x14, 0xdc, 0xe1, 0x41, 0x7f, 0x47, 0xc3, 0xfe,
-	0xe6, 0x73, 0x98, 0x1d, 0xbc, 0x63, 0xd4, 0xd8, 0xd0, 0x85, 0x28, 0x34, 0x36, 0xb4, 0xe0, 0x1c,
-	0xc6, 0xf4, 0x2d, 0xb9, 0xed, 0x97, 0xf0, 0x28, 0xbf, 0x80, 0xb1, 0x9f, 0x5c, 0x37, 0xea, 0x0f,
-	0xb2, 0x0e, 0xdf, 0x18, 0x17, 0xd2, 0xe7, 0xc4, 0x3d, 0x76, 0x23, 0x3f, 0x2e, 0x2e, 0xfe, 0x37,
-	0x00, 0x00, 0xff, 0xff, 0x07, 0x98, 0x54, 0xa1, 0xb5, 0x06, 0x00, 0x00,
+	// 859 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x55, 0xe1, 0x6e, 0xe3, 0x44,
+	0x10, 0xd6, 0xc6, 0x75, 0x1a, 0x6f, 0x9a, 0x6b, 0x59, 0xca, 0xb1, 0x42, 0x27, 0x64, 0x59, 0x80,
+	0x2c, 0x40, 0x45, 0xea, 0x2f, 0x7e, 0x21, 0xb5, 0xf4, 0x0a, 0x95, 0xae, 0xe9, 0x69, 0x53, 0xfa,
+	0x7f, 0x89, 0xa7, 0x66, 0x85, 0x63, 0x87, 0xf5, 0x1a, 0x25, 0x0f, 0xd0, 0x07, 0xe3, 0x11, 0x78,
+	0x08, 0xde, 0x03, 0xcd, 0xac, 0xed, 0x38, 0x15, 0xa8, 0xf7, 0x2b, 0xf3, 0x7d, 0x33, 0x99, 0xdd,
+	0x9d, 0xf9, 0x66, 0xcc, 0x3f, 0x35, 0xa5, 0x03, 0x5b, 0xea, 0xe2, 0xbb, 0xce, 0x38, 0x5b, 0xdb,
+	0xca, 0x55, 0x22, 0xfc, 0xa3, 0x01, 0xbb, 0x4d, 0x9e, 0x02, 0x1e, 0xbe, 0xaf, 0x4c, 0xe9, 0x84,
+	0xe0, 0x07, 0x73, 0xbd, 0x02, 0xc9, 0xe2, 0x51, 0x1a, 0x29, 0xb2, 0x91, 0xbb, 0xd7, 0x79, 0x2d,
+	0x47, 0x9e, 0x43, 0x9b, 0x38, 0xb3, 0x02, 0x19, 0xc4, 0xa3, 0x34, 0x50, 0x64, 0x8b, 0x13, 0x1e,
+	0xcc, 0x4d, 0x21, 0x0f, 0xe2, 0x51, 0x3a, 0x51, 0x68, 0x8a, 0x37, 0x3c, 0xb8, 0x68, 0x36, 0x32,
+	0x8c, 0x83, 0x74, 0x7a, 0xce, 0xcf, 0xe8, 0xb0, 0xb3, 0x8b, 0x66, 0xa3, 0x90, 0x16, 0x9f, 0x73,
+	0x7e, 0x91, 0xe7, 0x16, 0x72, 0xed, 0x20, 0x93, 0xe3, 0x98, 0xa5, 0x33, 0x35, 0x60, 0xd0, 0x7f,
+	0x5d, 0x54, 0xda, 0x3d, 0xe8, 0xa2, 0x01, 0x79, 0x18, 0xb3, 0x94, 0xa9, 0x01, 0x23, 0x12, 0x7e,
+	0x74, 0x53, 0x3a, 0xc8, 0xc1, 0xfa, 0x88, 0x49, 0xcc, 0xd2, 0x40, 0xed, 0x71, 0x22, 0xe6, 0xd3,
+	0x85, 0xb3, 0xa6, 0xcc, 0x7d, 0x48, 0x14, 0xb3, 0x34, 0x52, 0x43, 0x0a, 0xb3, 0x5c, 0x56, 0x55,
+	0x01, 0xba, 0xf4, 0x21, 0x3c, 0x66, 0xe9, 0x44, 0xed, 0x71, 0xe2, 0x0b, 0x3e, 0xfb, 0xa5, 0xac,
+	0x4d, 0x5e, 0x42, 0xe6, 0x83, 0x8e, 0x62, 0x96, 0x1e, 0xa8, 0x7d, 0x52, 0x7c, 0xcd, 0xc3, 0x85,
+	0xd3, 0xae, 0x96, 0xd3, 0x98, 0xa5, 0xd3, 0xf3, 0xd3, 0xf6, 0xbd, 0x37, 0x0e, 0xac, 0x76, 0x95,
+	0x25, 0x9f, 0xf2, 0x21, 0xe2, 0x94, 0x87, 0xf7, 0x56, 0x2f, 0x41, 0xce, 0x62, 0x96, 0x1e, 0x29,
+	0x0f, 0x92, 0xbf, 0x19, 0x15, 0x4c, 0x7c, 0xc6, 0x27, 0x57, 0xda, 0xe9, 0xfb, 0xed, 0xda, 0x77,
+	0x22, 0x54, 0x3d, 0x7e, 0x56, 0x95, 0xd1, 0x8b, 0x55, 0x09, 0x5e, 0xae, 0xca, 0xc1, 0xcb, 0x55,
+	0x09, 0x3f, 0xa4, 0x2a, 0xe3, 0xff, 0xa8, 0x4a, 0xf2, 0x14, 0xf2, 0xe3, 0xae, 0x04, 0x77, 0x6b,
+	0x67, 0xaa, 0x92, 0xd4, 0xf3, 0x76, 0xb3, 0xb6, 0x92, 0xd1, 0xc1, 0x64, 0x8b, 0x13, 0xaf, 0x95,
+	0x51, 0x1c, 0xa4, 0x91, 0xd7, 0xc7, 0x97, 0x7c, 0x7c, 0x6d, 0xa0, 0xc8, 0x6a, 0xf9, 0x11, 0x09,
+	0x68, 0xd6, 0x16, 0xf4, 0x41, 0x5b, 0x05, 0x8f, 0xaa, 0x75, 0x8a, 0x6f, 0xf9, 0xe1, 0xa2, 0x6a,
+	0xec, 0x12, 0x6a, 0x19, 0x50, 0x9c, 0x68, 0xe3, 0x6e, 0x41, 0xd7, 0x8d, 0x85, 0x15, 0x94, 0x4e,
+	0x75, 0x21, 0xe2, 0x1b, 0x3e, 0xc1, 0x52, 0xd8, 0x3f, 0x75, 0x41, 0xef, 0x9e, 0x9e, 0x1f, 0x77,
+	0x7d, 0x6a, 0x69, 0xd5, 0x07, 0x60, 0xad, 0xaf, 0xcc, 0x0a, 0xca, 0x1a, 0x6f, 0x4d, 0x32, 0x8e,
+	0xd4, 0x80, 0x11, 0x92, 0x1f, 0xfe, 0x64, 0xab, 0x66, 0x7d, 0xb9, 0x95, 0x1f, 0x93, 0xb3, 0x83,
+	0xf8, 0xc2, 0x6b, 0x53, 0x14, 0x54, 0x92, 0x50, 0x91, 0x2d, 0xde, 0xf0, 0x08, 0x7f, 0x87, 0x72,
+	0xde, 0x11, 0xe8, 0xfd, 0xb1, 0x2a, 0x33, 0x83, 0x15, 0x22, 0x29, 0x47, 0x6a, 0x47, 0xa0, 0x77,
+	0xe1, 0xb4, 0x75, 0x34, 0x74, 0x11, 0xb5, 0x74, 0x47, 0xe0, 0x3d, 0xde, 0x96, 0x19, 0xf9, 0x38,
+	0xf9, 0x3a, 0x88, 0x4a, 0x7a, 0x57, 0x2d, 0x35, 0x25, 0xfd, 0x84, 0x92, 0xf6, 0x18, 0x73, 0x5e,
+	0xd4, 0x4b, 0x28, 0x33, 0x53, 0xe6, 0xa4, 0xd9, 0x89, 0xda, 0x11, 0xa8, 0xd0, 0x77, 0x66, 0x65,
+	0x1c, 0x69, 0x3d, 0x50, 0x1e, 0x88, 0xd7, 0x7c, 0x7c, 0xf7, 0xf8, 0x58, 0x83, 0x23, 0xe1, 0x06,
+	0xaa, 0x45, 0xc8, 0x2f, 0x7c, 0xf8, 0x2b, 0xcf, 0x7b, 0x84, 0x37, 0x5b, 0xb4, 0x7f, 0x38, 0xf6,
+	0x37, 0x6b, 0xa1, 0x7f, 0x91, 0x35, 0x6b, 0x5a, 0x37, 0xaf, 0xfd, 0xe9, 0x3d, 0x81, 0xf9, 0xae,
+	0x20, 0x6b, 0xd6, 0x20, 0x4f, 0xc8, 0xd5, 0x22, 0xec, 0xc8, 0xad, 0xde, 0x2c, 0xc0, 0x1a, 0xa8,
+	0xe7, 0x52, 0x50, 0xca, 0x01, 0x83, 0xe7, 0xdd, 0xd9, 0x0c, 0x2c, 0x64, 0xf2, 0x94, 0xfe, 0xd8,
+	0xc1, 0xe4, 0x7b, 0x7e, 0x34, 0x10, 0x44, 0x2d, 0x52, 0x1e, 0xde, 0x38, 0x58, 0xd5, 0x92, 0xfd,
+	0xaf, 0x68, 0x7c, 0x40, 0xf2, 0x17, 0xe3, 0xd3, 0x01, 0xdd, 0x4d, 0xe7, 0xaf, 0xba, 0x86, 0x56,
+	0xc1, 0x3d, 0x16, 0x29, 0x3f, 0x56, 0xe0, 0xa0, 0xc4, 0x02, 0xbf, 0xaf, 0x0a, 0xb3, 0xdc, 0xd2,
+	0x88, 0x46, 0xea, 0x39, 0xdd, 0x6f, 0xda, 0xc0, 0xcf, 0x00, 0xbd, 0xfa, 0x94, 0x87, 0x0a, 0x72,
+	0xd8, 0xb4, 0x13, 0xe9, 0x01, 0x9e, 0x77, 0x53, 0xdf, 0x6b, 0x9b, 0x83, 0x6b, 0xe7, 0xb0, 0xc7,
+	0xe2, 0x2b, 0xfe, 0x6a, 0xb1, 0xad, 0x1d, 0xac, 0xba, 0x11, 0x23, 0xc5, 0x45, 0xea, 0x19, 0x9b,
+	0xfc, 0xb0, 0x93, 0x3d, 0xdd, 0xbf, 0xb1, 0x5e, 0x13, 0x8c, 0x2a, 0xd8, 0xe3, 0x41, 0x7f, 0x47,
+	0xc3, 0xfe, 0x26, 0xff, 0x30, 0x3e, 0xdb, 0x5b, 0x64, 0xd4, 0xd9, 0xb6, 0x0d, 0xac, 0xed, 0xac,
+	0x87, 0x98, 0x83, 0x3e, 0x26, 0xf3, 0x2e, 0x87, 0x47, 0xd8, 0xbb, 0xcb, 0xa2, 0x5a, 0xfe, 0xae,
+	0x40, 0x67, 0xf3, 0x76, 0x2f, 0x0d, 0x18, 0xda, 0x39, 0x88, 0xae, 0x60, 0x59, 0x65, 0x30, 0xa7,
+	0x22, 0x04, 0x6a, 0x8f, 0xeb, 0x73, 0x5c, 0x6e, 0x1d, 0xd4, 0x32, 0x1c, 0xe4, 0x20, 0x86, 0xa6,
+	0x48, 0x2f, 0x7f, 0x83, 0x9f, 0x8d, 0x9b, 0x53, 0x29, 0x02, 0xb5, 0x23, 0x70, 0xef, 0x51, 0xec,
+	0x2d, 0xd8, 0x1c, 0xe6, 0x34, 0x83, 0x81, 0x1a, 0x52, 0xc9, 0x19, 0x1f, 0xfb, 0xf5, 0x82, 0xfb,
+	0xe8, 0x41, 0x17, 0xed, 0x87, 0x10, 0x4d, 0xfa, 0xe6, 0xe1, 0x46, 0x1e, 0xf9, 0x99, 0x46, 0xfb,
+	0xdf, 0x01, 0x00, 0x79, 0xcf, 0xdc, 0xdc, 0x5a, 0x07, 0x00, 0x00,
 }
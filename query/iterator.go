@@ -1408,25 +1408,47 @@ func (itr *unsignedFloatCastIterator) Next() (*FloatPoint, error) {
 type IteratorStats struct {
 	SeriesN int // series represented
 	PointN  int // points returned
+
+	// Reads done by the storage engine to produce the points.
+	BlockReadN   int   // blocks read, including those summarized or cached without decoding
+	BlockDecodeN int   // blocks decoded
+	BlockBytes   int64 // bytes of the blocks decoded
+	CacheHitN    int   // values read from the cache
+	BlockMergeN  int   // overlapping blocks merged
 }
 
 // Add aggregates fields from s and other together. Overwrites s.
 func (s *IteratorStats) Add(other IteratorStats) {
 	s.SeriesN += other.SeriesN
 	s.PointN += other.PointN
+	s.BlockReadN += other.BlockReadN
+	s.BlockDecodeN += other.BlockDecodeN
+	s.BlockBytes += other.BlockBytes
+	s.CacheHitN += other.CacheHitN
+	s.BlockMergeN += other.BlockMergeN
 }
 
 func encodeIteratorStats(stats *IteratorStats) *internal.IteratorStats {
 	return &internal.IteratorStats{
-		SeriesN: proto.Int64(int64(stats.SeriesN)),
-		PointN:  proto.Int64(int64(stats.PointN)),
+		SeriesN:      proto.Int64(int64(stats.SeriesN)),
+		PointN:       proto.Int64(int64(stats.PointN)),
+		BlockReadN:   proto.Int64(int64(stats.BlockReadN)),
+		BlockDecodeN: proto.Int64(int64(stats.BlockDecodeN)),
+		BlockBytes:   proto.Int64(stats.BlockBytes),
+		CacheHitN:    proto.Int64(int64(stats.CacheHitN)),
+		BlockMergeN:  proto.Int64(int64(stats.BlockMergeN)),
 	}
 }
 
 func decodeIteratorStats(pb *internal.IteratorStats) IteratorStats {
 	return IteratorStats{
-		SeriesN: int(pb.GetSeriesN()),
-		PointN:  int(pb.GetPointN()),
+		SeriesN:      int(pb.GetSeriesN()),
+		PointN:       int(pb.GetPointN()),
+		BlockReadN:   int(pb.GetBlockReadN()),
+		BlockDecodeN: int(pb.GetBlockDecodeN()),
+		BlockBytes:   pb.GetBlockBytes(),
+		CacheHitN:    int(pb.GetCacheHitN()),
+		BlockMergeN:  int(pb.GetBlockMergeN()),
 	}
 }
 
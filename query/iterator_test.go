@@ -1552,6 +1552,44 @@ func TestIterator_EncodeDecode(t *testing.T) {
 	}
 }
 
+// Ensure the stats of an iterator, including its storage reads, are encoded with it.
+func TestIterator_EncodeDecode_Stats(t *testing.T) {
+	var buf bytes.Buffer
+
+	stats := query.IteratorStats{
+		SeriesN:      2,
+		PointN:       1,
+		BlockReadN:   3,
+		BlockDecodeN: 2,
+		BlockBytes:   4096,
+		CacheHitN:    5,
+		BlockMergeN:  1,
+	}
+	itr := &FloatIterator{
+		Points: []query.FloatPoint{{Name: "cpu", Tags: ParseTags("host=A"), Time: 0, Value: 0}},
+		stats:  stats,
+	}
+
+	enc := query.NewIteratorEncoder(&buf)
+	if err := enc.EncodeIterator(itr); err != nil {
+		t.Fatal(err)
+	}
+
+	// Read the iterator to the end so the final stats are decoded.
+	dec := query.NewReaderIterator(context.Background(), &buf, influxql.Float, query.IteratorStats{}).(query.FloatIterator)
+	for {
+		if p, err := dec.Next(); err != nil {
+			t.Fatal(err)
+		} else if p == nil {
+			break
+		}
+	}
+
+	if got := dec.Stats(); !reflect.DeepEqual(got, stats) {
+		t.Fatalf("unexpected stats: %#v", got)
+	}
+}
+
 // IteratorCreator is a mockable implementation of SelectStatementExecutor.IteratorCreator.
 type IteratorCreator struct {
 	CreateIteratorFn  func(ctx context.Context, m *influxql.Measurement, opt query.IteratorOptions) (query.Iterator, error)
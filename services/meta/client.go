@@ -768,6 +768,52 @@ func (c *Client) DeleteShardGroup(database, policy string, id uint64) error {
 	return nil
 }
 
+// ReserveShardGroup creates a shard group for the shard group duration containing
+// timestamp that receives no writes and isn't queried until ReplaceShardGroups makes
+// it live in place of the shard groups it overlaps.
+func (c *Client) ReserveShardGroup(database, policy string, timestamp time.Time) (*ShardGroupInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	id, err := data.ReserveShardGroup(database, policy, timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	rpi, err := data.RetentionPolicy(database, policy)
+	if err != nil {
+		return nil, err
+	}
+	var sgi ShardGroupInfo
+	for i := range rpi.ShardGroups {
+		if rpi.ShardGroups[i].ID == id {
+			sgi = rpi.ShardGroups[i].clone()
+		}
+	}
+
+	if err := c.commit(data); err != nil {
+		return nil, err
+	}
+	return &sgi, nil
+}
+
+// ReplaceShardGroups deletes the shard groups ids and makes the shard group id
+// reserved by ReserveShardGroup live in their place, so writes and queries move
+// from one to the other at once.
+func (c *Client) ReplaceShardGroups(database, policy string, ids []uint64, id uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data := c.cacheData.Clone()
+
+	if err := data.ReplaceShardGroups(database, policy, ids, id); err != nil {
+		return err
+	}
+	return c.commit(data)
+}
+
 // PrecreateShardGroups creates shard groups whose endtime is before the 'to' time passed in, but
 // is yet to expire before 'from'. This is to avoid the need for these shards to be created when data
 // for the corresponding time range arrives. Shard creation involves Raft consensus, and precreation
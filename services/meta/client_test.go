@@ -524,6 +524,70 @@ func TestMetaClient_UpdateRetentionPolicy(t *testing.T) {
 	}
 }
 
+func TestMetaClient_UpdateRetentionPolicy_Rollup(t *testing.T) {
+	t.Parallel()
+
+	d, c := newClient()
+	defer os.RemoveAll(d)
+	defer c.Close()
+
+	if _, err := c.CreateDatabaseWithRetentionPolicy("db0", &meta.RetentionPolicySpec{
+		Name: "rp0",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A rollup without any functions is invalid.
+	var rpu meta.RetentionPolicyUpdate
+	rpu.SetRollup(meta.RollupInfo{After: 24 * time.Hour, Interval: 5 * time.Minute})
+	if err := c.UpdateRetentionPolicy("db0", "rp0", &rpu, false); err != meta.ErrInvalidRollup {
+		t.Fatalf("expected error '%s', got '%v'", meta.ErrInvalidRollup, err)
+	}
+
+	// Unknown aggregates are rejected when the rollup is set.
+	rpu.SetRollup(meta.RollupInfo{After: 24 * time.Hour, Interval: 5 * time.Minute, Functions: []string{"mean", "median"}})
+	if err := c.UpdateRetentionPolicy("db0", "rp0", &rpu, false); err != meta.ErrInvalidRollup {
+		t.Fatalf("expected error '%s', got '%v'", meta.ErrInvalidRollup, err)
+	}
+
+	rollup := meta.RollupInfo{After: 24 * time.Hour, Interval: 5 * time.Minute, Functions: []string{"mean", "max"}}
+	rpu.SetRollup(rollup)
+	if err := c.UpdateRetentionPolicy("db0", "rp0", &rpu, false); err != nil {
+		t.Fatal(err)
+	}
+
+	rpi, err := c.RetentionPolicy("db0", "rp0")
+	if err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(rpi.Rollup, &rollup) {
+		t.Fatalf("unexpected rollup: %#v", rpi.Rollup)
+	}
+
+	// Ensure the rollup survives serialization.
+	buf, err := rpi.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var other meta.RetentionPolicyInfo
+	if err := other.UnmarshalBinary(buf); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(other.Rollup, &rollup) {
+		t.Fatalf("unexpected rollup: %#v", other.Rollup)
+	}
+
+	// A zero interval removes the rollup.
+	rpu.SetRollup(meta.RollupInfo{})
+	if err := c.UpdateRetentionPolicy("db0", "rp0", &rpu, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if rpi, err := c.RetentionPolicy("db0", "rp0"); err != nil {
+		t.Fatal(err)
+	} else if rpi.Rollup != nil {
+		t.Fatalf("unexpected rollup: %#v", rpi.Rollup)
+	}
+}
+
 func TestMetaClient_DropRetentionPolicy(t *testing.T) {
 	t.Parallel()
 
@@ -140,6 +140,10 @@ func (data *Data) CreateRetentionPolicy(database string, rpi *RetentionPolicyInf
 		return ErrIncompatibleDurations
 	}
 
+	if rpi.Rollup != nil && !rpi.Rollup.valid() {
+		return ErrInvalidRollup
+	}
+
 	// Find database.
 	di := data.Database(database)
 	if di == nil {
@@ -193,6 +197,7 @@ type RetentionPolicyUpdate struct {
 	Duration           *time.Duration
 	ReplicaN           *int
 	ShardGroupDuration *time.Duration
+	Rollup             *RollupInfo
 }
 
 // SetName sets the RetentionPolicyUpdate.Name.
@@ -207,6 +212,10 @@ func (rpu *RetentionPolicyUpdate) SetReplicaN(v int) { rpu.ReplicaN = &v }
 // SetShardGroupDuration sets the RetentionPolicyUpdate.ShardGroupDuration.
 func (rpu *RetentionPolicyUpdate) SetShardGroupDuration(v time.Duration) { rpu.ShardGroupDuration = &v }
 
+// SetRollup sets the RetentionPolicyUpdate.Rollup.  A rollup with a zero interval
+// removes the rollup from the retention policy.
+func (rpu *RetentionPolicyUpdate) SetRollup(v RollupInfo) { rpu.Rollup = &v }
+
 // UpdateRetentionPolicy updates an existing retention policy.
 func (data *Data) UpdateRetentionPolicy(database, name string, rpu *RetentionPolicyUpdate, makeDefault bool) error {
 	// Find database.
@@ -240,6 +249,11 @@ func (data *Data) UpdateRetentionPolicy(database, name string, rpu *RetentionPol
 		return ErrIncompatibleDurations
 	}
 
+	// Enforce a valid rollup, unless it is being removed.
+	if rpu.Rollup != nil && rpu.Rollup.Interval != 0 && !rpu.Rollup.valid() {
+		return ErrInvalidRollup
+	}
+
 	// Update fields.
 	if rpu.Name != nil {
 		rpi.Name = *rpu.Name
@@ -253,6 +267,14 @@ func (data *Data) UpdateRetentionPolicy(database, name string, rpu *RetentionPol
 	if rpu.ShardGroupDuration != nil {
 		rpi.ShardGroupDuration = normalisedShardDuration(*rpu.ShardGroupDuration, rpi.Duration)
 	}
+	if rpu.Rollup != nil {
+		if rpu.Rollup.Interval == 0 {
+			rpi.Rollup = nil
+		} else {
+			rollup := rpu.Rollup.clone()
+			rpi.Rollup = &rollup
+		}
+	}
 
 	if di.DefaultRetentionPolicy != rpi.Name && makeDefault {
 		di.DefaultRetentionPolicy = rpi.Name
@@ -385,6 +407,73 @@ func (data *Data) CreateShardGroup(database, policy string, timestamp time.Time)
 	return nil
 }
 
+// ReserveShardGroup creates a shard group for the shard group duration containing
+// timestamp, even if other shard groups overlap it.  The shard group is created as
+// deleted, so it receives no writes and isn't queried until ReplaceShardGroups makes it
+// live.  It returns the ID of the shard group.
+func (data *Data) ReserveShardGroup(database, policy string, timestamp time.Time) (uint64, error) {
+	rpi, err := data.RetentionPolicy(database, policy)
+	if err != nil {
+		return 0, err
+	} else if rpi == nil {
+		return 0, influxdb.ErrRetentionPolicyNotFound(policy)
+	}
+
+	data.MaxShardGroupID++
+	sgi := ShardGroupInfo{}
+	sgi.ID = data.MaxShardGroupID
+	sgi.StartTime = timestamp.Truncate(rpi.ShardGroupDuration).UTC()
+	sgi.EndTime = sgi.StartTime.Add(rpi.ShardGroupDuration).UTC()
+	if sgi.EndTime.After(time.Unix(0, models.MaxNanoTime)) {
+		// Shard group range is [start, end) so add one to the max time.
+		sgi.EndTime = time.Unix(0, models.MaxNanoTime+1)
+	}
+	sgi.DeletedAt = time.Now().UTC()
+
+	data.MaxShardID++
+	sgi.Shards = []ShardInfo{
+		{ID: data.MaxShardID},
+	}
+
+	rpi.ShardGroups = append(rpi.ShardGroups, sgi)
+	sort.Sort(ShardGroupInfos(rpi.ShardGroups))
+
+	return sgi.ID, nil
+}
+
+// ReplaceShardGroups deletes the shard groups ids and makes the shard group id
+// reserved by ReserveShardGroup live in their place.
+func (data *Data) ReplaceShardGroups(database, policy string, ids []uint64, id uint64) error {
+	rpi, err := data.RetentionPolicy(database, policy)
+	if err != nil {
+		return err
+	} else if rpi == nil {
+		return influxdb.ErrRetentionPolicyNotFound(policy)
+	}
+
+	var replacement *ShardGroupInfo
+	for i := range rpi.ShardGroups {
+		if rpi.ShardGroups[i].ID == id {
+			replacement = &rpi.ShardGroups[i]
+		}
+	}
+	if replacement == nil {
+		return ErrShardGroupNotFound
+	}
+
+	now := time.Now().UTC()
+	for i := range rpi.ShardGroups {
+		sgi := &rpi.ShardGroups[i]
+		for _, other := range ids {
+			if sgi.ID == other && !sgi.Deleted() {
+				sgi.DeletedAt = now
+			}
+		}
+	}
+	replacement.DeletedAt = time.Time{}
+	return nil
+}
+
 // DeleteShardGroup removes a shard group from a database and retention policy by id.
 func (data *Data) DeleteShardGroup(database, policy string, id uint64) error {
 	// Find retention policy.
@@ -978,6 +1067,7 @@ type RetentionPolicyInfo struct {
 	ShardGroupDuration time.Duration
 	ShardGroups        []ShardGroupInfo
 	Subscriptions      []SubscriptionInfo
+	Rollup             *RollupInfo
 }
 
 // NewRetentionPolicyInfo returns a new instance of RetentionPolicyInfo
@@ -1074,6 +1164,10 @@ func (rpi *RetentionPolicyInfo) marshal() *internal.RetentionPolicyInfo {
 		pb.Subscriptions[i] = sub.marshal()
 	}
 
+	if rpi.Rollup != nil {
+		pb.Rollup = rpi.Rollup.marshal()
+	}
+
 	return pb
 }
 
@@ -1096,6 +1190,10 @@ func (rpi *RetentionPolicyInfo) unmarshal(pb *internal.RetentionPolicyInfo) {
 			rpi.Subscriptions[i].unmarshal(x)
 		}
 	}
+	if pb.Rollup != nil {
+		rpi.Rollup = &RollupInfo{}
+		rpi.Rollup.unmarshal(pb.GetRollup())
+	}
 }
 
 // clone returns a deep copy of rpi.
@@ -1109,6 +1207,11 @@ func (rpi RetentionPolicyInfo) clone() RetentionPolicyInfo {
 		}
 	}
 
+	if rpi.Rollup != nil {
+		rollup := rpi.Rollup.clone()
+		other.Rollup = &rollup
+	}
+
 	return other
 }
 
@@ -1388,6 +1491,73 @@ func (si *SubscriptionInfo) unmarshal(pb *internal.SubscriptionInfo) {
 	}
 }
 
+// RollupInfo represents a rule downsampling the raw values of a retention policy's
+// shards once all of their data is older than After.  Each function is applied to
+// every field over windows of Interval.
+type RollupInfo struct {
+	After     time.Duration
+	Interval  time.Duration
+	Functions []string
+}
+
+// rollupFunctions are the aggregates a rollup can apply.  They must match the functions
+// supported by the storage engine's rollup compactions.
+var rollupFunctions = map[string]struct{}{
+	"count": {}, "first": {}, "last": {}, "max": {}, "mean": {}, "min": {}, "sum": {},
+}
+
+// valid returns true if the rollup has a positive interval and at least one function,
+// and its functions are known aggregates that are each used once.
+func (ri RollupInfo) valid() bool {
+	if ri.Interval <= 0 || ri.After < 0 || len(ri.Functions) == 0 {
+		return false
+	}
+
+	seen := make(map[string]struct{}, len(ri.Functions))
+	for _, fn := range ri.Functions {
+		if _, ok := rollupFunctions[fn]; !ok {
+			return false
+		} else if _, ok := seen[fn]; ok {
+			return false
+		}
+		seen[fn] = struct{}{}
+	}
+	return true
+}
+
+// clone returns a deep copy of ri.
+func (ri RollupInfo) clone() RollupInfo {
+	other := ri
+	if ri.Functions != nil {
+		other.Functions = make([]string, len(ri.Functions))
+		copy(other.Functions, ri.Functions)
+	}
+	return other
+}
+
+// marshal serializes to a protobuf representation.
+func (ri RollupInfo) marshal() *internal.RollupInfo {
+	pb := &internal.RollupInfo{
+		After:    proto.Int64(int64(ri.After)),
+		Interval: proto.Int64(int64(ri.Interval)),
+	}
+
+	pb.Functions = make([]string, len(ri.Functions))
+	copy(pb.Functions, ri.Functions)
+	return pb
+}
+
+// unmarshal deserializes from a protobuf representation.
+func (ri *RollupInfo) unmarshal(pb *internal.RollupInfo) {
+	ri.After = time.Duration(pb.GetAfter())
+	ri.Interval = time.Duration(pb.GetInterval())
+
+	if len(pb.GetFunctions()) > 0 {
+		ri.Functions = make([]string, len(pb.GetFunctions()))
+		copy(ri.Functions, pb.GetFunctions())
+	}
+}
+
 // ShardOwner represents a node that owns a shard.
 type ShardOwner struct {
 	NodeID uint64
@@ -65,6 +65,10 @@ var (
 	// ErrReplicationFactorTooLow is returned when the replication factor is not in an
 	// acceptable range.
 	ErrReplicationFactorTooLow = errors.New("replication factor must be greater than 0")
+
+	// ErrInvalidRollup is returned when a retention policy rollup does not have
+	// a positive interval and at least one function, or uses an unknown function.
+	ErrInvalidRollup = errors.New("rollup requires a positive interval and at least one known function")
 )
 
 var (
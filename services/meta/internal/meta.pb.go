@@ -17,6 +17,7 @@ It has these top-level messages:
 	ShardGroupInfo
 	ShardInfo
 	SubscriptionInfo
+	RollupInfo
 	ShardOwner
 	ContinuousQueryInfo
 	UserInfo
@@ -183,7 +184,7 @@ func (x *Command_Type) UnmarshalJSON(data []byte) error {
 	*x = Command_Type(value)
 	return nil
 }
-func (Command_Type) EnumDescriptor() ([]byte, []int) { return fileDescriptorMeta, []int{12, 0} }
+func (Command_Type) EnumDescriptor() ([]byte, []int) { return fileDescriptorMeta, []int{13, 0} }
 
 type Data struct {
 	Term            *uint64         `protobuf:"varint,1,req,name=Term" json:"Term,omitempty"`
@@ -405,6 +406,7 @@ type RetentionPolicyInfo struct {
 	ReplicaN           *uint32             `protobuf:"varint,4,req,name=ReplicaN" json:"ReplicaN,omitempty"`
 	ShardGroups        []*ShardGroupInfo   `protobuf:"bytes,5,rep,name=ShardGroups" json:"ShardGroups,omitempty"`
 	Subscriptions      []*SubscriptionInfo `protobuf:"bytes,6,rep,name=Subscriptions" json:"Subscriptions,omitempty"`
+	Rollup             *RollupInfo         `protobuf:"bytes,7,opt,name=Rollup" json:"Rollup,omitempty"`
 	XXX_unrecognized   []byte              `json:"-"`
 }
 
@@ -455,6 +457,13 @@ func (m *RetentionPolicyInfo) GetSubscriptions() []*SubscriptionInfo {
 	return nil
 }
 
+func (m *RetentionPolicyInfo) GetRollup() *RollupInfo {
+	if m != nil {
+		return m.Rollup
+	}
+	return nil
+}
+
 type ShardGroupInfo struct {
 	ID               *uint64      `protobuf:"varint,1,req,name=ID" json:"ID,omitempty"`
 	StartTime        *int64       `protobuf:"varint,2,req,name=StartTime" json:"StartTime,omitempty"`
@@ -578,6 +587,39 @@ func (m *SubscriptionInfo) GetDestinations() []string {
 	return nil
 }
 
+type RollupInfo struct {
+	After            *int64   `protobuf:"varint,1,req,name=After" json:"After,omitempty"`
+	Interval         *int64   `protobuf:"varint,2,req,name=Interval" json:"Interval,omitempty"`
+	Functions        []string `protobuf:"bytes,3,rep,name=Functions" json:"Functions,omitempty"`
+	XXX_unrecognized []byte   `json:"-"`
+}
+
+func (m *RollupInfo) Reset()                    { *m = RollupInfo{} }
+func (m *RollupInfo) String() string            { return proto.CompactTextString(m) }
+func (*RollupInfo) ProtoMessage()               {}
+func (*RollupInfo) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{8} }
+
+func (m *RollupInfo) GetAfter() int64 {
+	if m != nil && m.After != nil {
+		return *m.After
+	}
+	return 0
+}
+
+func (m *RollupInfo) GetInterval() int64 {
+	if m != nil && m.Interval != nil {
+		return *m.Interval
+	}
+	return 0
+}
+
+func (m *RollupInfo) GetFunctions() []string {
+	if m != nil {
+		return m.Functions
+	}
+	return nil
+}
+
 type ShardOwner struct {
 	NodeID           *uint64 `protobuf:"varint,1,req,name=NodeID" json:"NodeID,omitempty"`
 	XXX_unrecognized []byte  `json:"-"`
@@ -586,7 +628,7 @@ type ShardOwner struct {
 func (m *ShardOwner) Reset()                    { *m = ShardOwner{} }
 func (m *ShardOwner) String() string            { return proto.CompactTextString(m) }
 func (*ShardOwner) ProtoMessage()               {}
-func (*ShardOwner) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{8} }
+func (*ShardOwner) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{9} }
 
 func (m *ShardOwner) GetNodeID() uint64 {
 	if m != nil && m.NodeID != nil {
@@ -604,7 +646,7 @@ type ContinuousQueryInfo struct {
 func (m *ContinuousQueryInfo) Reset()                    { *m = ContinuousQueryInfo{} }
 func (m *ContinuousQueryInfo) String() string            { return proto.CompactTextString(m) }
 func (*ContinuousQueryInfo) ProtoMessage()               {}
-func (*ContinuousQueryInfo) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{9} }
+func (*ContinuousQueryInfo) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{10} }
 
 func (m *ContinuousQueryInfo) GetName() string {
 	if m != nil && m.Name != nil {
@@ -631,7 +673,7 @@ type UserInfo struct {
 func (m *UserInfo) Reset()                    { *m = UserInfo{} }
 func (m *UserInfo) String() string            { return proto.CompactTextString(m) }
 func (*UserInfo) ProtoMessage()               {}
-func (*UserInfo) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{10} }
+func (*UserInfo) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{11} }
 
 func (m *UserInfo) GetName() string {
 	if m != nil && m.Name != nil {
@@ -670,7 +712,7 @@ type UserPrivilege struct {
 func (m *UserPrivilege) Reset()                    { *m = UserPrivilege{} }
 func (m *UserPrivilege) String() string            { return proto.CompactTextString(m) }
 func (*UserPrivilege) ProtoMessage()               {}
-func (*UserPrivilege) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{11} }
+func (*UserPrivilege) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{12} }
 
 func (m *UserPrivilege) GetDatabase() string {
 	if m != nil && m.Database != nil {
@@ -695,7 +737,7 @@ type Command struct {
 func (m *Command) Reset()                    { *m = Command{} }
 func (m *Command) String() string            { return proto.CompactTextString(m) }
 func (*Command) ProtoMessage()               {}
-func (*Command) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{12} }
+func (*Command) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{13} }
 
 var extRange_Command = []proto.ExtensionRange{
 	{Start: 100, End: 536870911},
@@ -723,7 +765,7 @@ type CreateNodeCommand struct {
 func (m *CreateNodeCommand) Reset()                    { *m = CreateNodeCommand{} }
 func (m *CreateNodeCommand) String() string            { return proto.CompactTextString(m) }
 func (*CreateNodeCommand) ProtoMessage()               {}
-func (*CreateNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{13} }
+func (*CreateNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{14} }
 
 func (m *CreateNodeCommand) GetHost() string {
 	if m != nil && m.Host != nil {
@@ -756,7 +798,7 @@ type DeleteNodeCommand struct {
 func (m *DeleteNodeCommand) Reset()                    { *m = DeleteNodeCommand{} }
 func (m *DeleteNodeCommand) String() string            { return proto.CompactTextString(m) }
 func (*DeleteNodeCommand) ProtoMessage()               {}
-func (*DeleteNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{14} }
+func (*DeleteNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{15} }
 
 func (m *DeleteNodeCommand) GetID() uint64 {
 	if m != nil && m.ID != nil {
@@ -789,7 +831,7 @@ type CreateDatabaseCommand struct {
 func (m *CreateDatabaseCommand) Reset()                    { *m = CreateDatabaseCommand{} }
 func (m *CreateDatabaseCommand) String() string            { return proto.CompactTextString(m) }
 func (*CreateDatabaseCommand) ProtoMessage()               {}
-func (*CreateDatabaseCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{15} }
+func (*CreateDatabaseCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{16} }
 
 func (m *CreateDatabaseCommand) GetName() string {
 	if m != nil && m.Name != nil {
@@ -821,7 +863,7 @@ type DropDatabaseCommand struct {
 func (m *DropDatabaseCommand) Reset()                    { *m = DropDatabaseCommand{} }
 func (m *DropDatabaseCommand) String() string            { return proto.CompactTextString(m) }
 func (*DropDatabaseCommand) ProtoMessage()               {}
-func (*DropDatabaseCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{16} }
+func (*DropDatabaseCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{17} }
 
 func (m *DropDatabaseCommand) GetName() string {
 	if m != nil && m.Name != nil {
@@ -848,7 +890,7 @@ func (m *CreateRetentionPolicyCommand) Reset()         { *m = CreateRetentionPol
 func (m *CreateRetentionPolicyCommand) String() string { return proto.CompactTextString(m) }
 func (*CreateRetentionPolicyCommand) ProtoMessage()    {}
 func (*CreateRetentionPolicyCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptorMeta, []int{17}
+	return fileDescriptorMeta, []int{18}
 }
 
 func (m *CreateRetentionPolicyCommand) GetDatabase() string {
@@ -882,7 +924,7 @@ type DropRetentionPolicyCommand struct {
 func (m *DropRetentionPolicyCommand) Reset()                    { *m = DropRetentionPolicyCommand{} }
 func (m *DropRetentionPolicyCommand) String() string            { return proto.CompactTextString(m) }
 func (*DropRetentionPolicyCommand) ProtoMessage()               {}
-func (*DropRetentionPolicyCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{18} }
+func (*DropRetentionPolicyCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{19} }
 
 func (m *DropRetentionPolicyCommand) GetDatabase() string {
 	if m != nil && m.Database != nil {
@@ -916,7 +958,7 @@ func (m *SetDefaultRetentionPolicyCommand) Reset()         { *m = SetDefaultRete
 func (m *SetDefaultRetentionPolicyCommand) String() string { return proto.CompactTextString(m) }
 func (*SetDefaultRetentionPolicyCommand) ProtoMessage()    {}
 func (*SetDefaultRetentionPolicyCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptorMeta, []int{19}
+	return fileDescriptorMeta, []int{20}
 }
 
 func (m *SetDefaultRetentionPolicyCommand) GetDatabase() string {
@@ -954,7 +996,7 @@ func (m *UpdateRetentionPolicyCommand) Reset()         { *m = UpdateRetentionPol
 func (m *UpdateRetentionPolicyCommand) String() string { return proto.CompactTextString(m) }
 func (*UpdateRetentionPolicyCommand) ProtoMessage()    {}
 func (*UpdateRetentionPolicyCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptorMeta, []int{20}
+	return fileDescriptorMeta, []int{21}
 }
 
 func (m *UpdateRetentionPolicyCommand) GetDatabase() string {
@@ -1010,7 +1052,7 @@ type CreateShardGroupCommand struct {
 func (m *CreateShardGroupCommand) Reset()                    { *m = CreateShardGroupCommand{} }
 func (m *CreateShardGroupCommand) String() string            { return proto.CompactTextString(m) }
 func (*CreateShardGroupCommand) ProtoMessage()               {}
-func (*CreateShardGroupCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{21} }
+func (*CreateShardGroupCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{22} }
 
 func (m *CreateShardGroupCommand) GetDatabase() string {
 	if m != nil && m.Database != nil {
@@ -1051,7 +1093,7 @@ type DeleteShardGroupCommand struct {
 func (m *DeleteShardGroupCommand) Reset()                    { *m = DeleteShardGroupCommand{} }
 func (m *DeleteShardGroupCommand) String() string            { return proto.CompactTextString(m) }
 func (*DeleteShardGroupCommand) ProtoMessage()               {}
-func (*DeleteShardGroupCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{22} }
+func (*DeleteShardGroupCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{23} }
 
 func (m *DeleteShardGroupCommand) GetDatabase() string {
 	if m != nil && m.Database != nil {
@@ -1093,7 +1135,7 @@ func (m *CreateContinuousQueryCommand) Reset()         { *m = CreateContinuousQu
 func (m *CreateContinuousQueryCommand) String() string { return proto.CompactTextString(m) }
 func (*CreateContinuousQueryCommand) ProtoMessage()    {}
 func (*CreateContinuousQueryCommand) Descriptor() ([]byte, []int) {
-	return fileDescriptorMeta, []int{23}
+	return fileDescriptorMeta, []int{24}
 }
 
 func (m *CreateContinuousQueryCommand) GetDatabase() string {
@@ -1134,7 +1176,7 @@ type DropContinuousQueryCommand struct {
 func (m *DropContinuousQueryCommand) Reset()                    { *m = DropContinuousQueryCommand{} }
 func (m *DropContinuousQueryCommand) String() string            { return proto.CompactTextString(m) }
 func (*DropContinuousQueryCommand) ProtoMessage()               {}
-func (*DropContinuousQueryCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{24} }
+func (*DropContinuousQueryCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{25} }
 
 func (m *DropContinuousQueryCommand) GetDatabase() string {
 	if m != nil && m.Database != nil {
@@ -1168,7 +1210,7 @@ type CreateUserCommand struct {
 func (m *CreateUserCommand) Reset()                    { *m = CreateUserCommand{} }
 func (m *CreateUserCommand) String() string            { return proto.CompactTextString(m) }
 func (*CreateUserCommand) ProtoMessage()               {}
-func (*CreateUserCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{25} }
+func (*CreateUserCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{26} }
 
 func (m *CreateUserCommand) GetName() string {
 	if m != nil && m.Name != nil {
@@ -1207,7 +1249,7 @@ type DropUserCommand struct {
 func (m *DropUserCommand) Reset()                    { *m = DropUserCommand{} }
 func (m *DropUserCommand) String() string            { return proto.CompactTextString(m) }
 func (*DropUserCommand) ProtoMessage()               {}
-func (*DropUserCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{26} }
+func (*DropUserCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{27} }
 
 func (m *DropUserCommand) GetName() string {
 	if m != nil && m.Name != nil {
@@ -1233,7 +1275,7 @@ type UpdateUserCommand struct {
 func (m *UpdateUserCommand) Reset()                    { *m = UpdateUserCommand{} }
 func (m *UpdateUserCommand) String() string            { return proto.CompactTextString(m) }
 func (*UpdateUserCommand) ProtoMessage()               {}
-func (*UpdateUserCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{27} }
+func (*UpdateUserCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{28} }
 
 func (m *UpdateUserCommand) GetName() string {
 	if m != nil && m.Name != nil {
@@ -1267,7 +1309,7 @@ type SetPrivilegeCommand struct {
 func (m *SetPrivilegeCommand) Reset()                    { *m = SetPrivilegeCommand{} }
 func (m *SetPrivilegeCommand) String() string            { return proto.CompactTextString(m) }
 func (*SetPrivilegeCommand) ProtoMessage()               {}
-func (*SetPrivilegeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{28} }
+func (*SetPrivilegeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{29} }
 
 func (m *SetPrivilegeCommand) GetUsername() string {
 	if m != nil && m.Username != nil {
@@ -1306,7 +1348,7 @@ type SetDataCommand struct {
 func (m *SetDataCommand) Reset()                    { *m = SetDataCommand{} }
 func (m *SetDataCommand) String() string            { return proto.CompactTextString(m) }
 func (*SetDataCommand) ProtoMessage()               {}
-func (*SetDataCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{29} }
+func (*SetDataCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{30} }
 
 func (m *SetDataCommand) GetData() *Data {
 	if m != nil {
@@ -1332,7 +1374,7 @@ type SetAdminPrivilegeCommand struct {
 func (m *SetAdminPrivilegeCommand) Reset()                    { *m = SetAdminPrivilegeCommand{} }
 func (m *SetAdminPrivilegeCommand) String() string            { return proto.CompactTextString(m) }
 func (*SetAdminPrivilegeCommand) ProtoMessage()               {}
-func (*SetAdminPrivilegeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{30} }
+func (*SetAdminPrivilegeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{31} }
 
 func (m *SetAdminPrivilegeCommand) GetUsername() string {
 	if m != nil && m.Username != nil {
@@ -1365,7 +1407,7 @@ type UpdateNodeCommand struct {
 func (m *UpdateNodeCommand) Reset()                    { *m = UpdateNodeCommand{} }
 func (m *UpdateNodeCommand) String() string            { return proto.CompactTextString(m) }
 func (*UpdateNodeCommand) ProtoMessage()               {}
-func (*UpdateNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{31} }
+func (*UpdateNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{32} }
 
 func (m *UpdateNodeCommand) GetID() uint64 {
 	if m != nil && m.ID != nil {
@@ -1401,7 +1443,7 @@ type CreateSubscriptionCommand struct {
 func (m *CreateSubscriptionCommand) Reset()                    { *m = CreateSubscriptionCommand{} }
 func (m *CreateSubscriptionCommand) String() string            { return proto.CompactTextString(m) }
 func (*CreateSubscriptionCommand) ProtoMessage()               {}
-func (*CreateSubscriptionCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{32} }
+func (*CreateSubscriptionCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{33} }
 
 func (m *CreateSubscriptionCommand) GetName() string {
 	if m != nil && m.Name != nil {
@@ -1456,7 +1498,7 @@ type DropSubscriptionCommand struct {
 func (m *DropSubscriptionCommand) Reset()                    { *m = DropSubscriptionCommand{} }
 func (m *DropSubscriptionCommand) String() string            { return proto.CompactTextString(m) }
 func (*DropSubscriptionCommand) ProtoMessage()               {}
-func (*DropSubscriptionCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{33} }
+func (*DropSubscriptionCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{34} }
 
 func (m *DropSubscriptionCommand) GetName() string {
 	if m != nil && m.Name != nil {
@@ -1496,7 +1538,7 @@ type RemovePeerCommand struct {
 func (m *RemovePeerCommand) Reset()                    { *m = RemovePeerCommand{} }
 func (m *RemovePeerCommand) String() string            { return proto.CompactTextString(m) }
 func (*RemovePeerCommand) ProtoMessage()               {}
-func (*RemovePeerCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{34} }
+func (*RemovePeerCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{35} }
 
 func (m *RemovePeerCommand) GetID() uint64 {
 	if m != nil && m.ID != nil {
@@ -1530,7 +1572,7 @@ type CreateMetaNodeCommand struct {
 func (m *CreateMetaNodeCommand) Reset()                    { *m = CreateMetaNodeCommand{} }
 func (m *CreateMetaNodeCommand) String() string            { return proto.CompactTextString(m) }
 func (*CreateMetaNodeCommand) ProtoMessage()               {}
-func (*CreateMetaNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{35} }
+func (*CreateMetaNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{36} }
 
 func (m *CreateMetaNodeCommand) GetHTTPAddr() string {
 	if m != nil && m.HTTPAddr != nil {
@@ -1570,7 +1612,7 @@ type CreateDataNodeCommand struct {
 func (m *CreateDataNodeCommand) Reset()                    { *m = CreateDataNodeCommand{} }
 func (m *CreateDataNodeCommand) String() string            { return proto.CompactTextString(m) }
 func (*CreateDataNodeCommand) ProtoMessage()               {}
-func (*CreateDataNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{36} }
+func (*CreateDataNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{37} }
 
 func (m *CreateDataNodeCommand) GetHTTPAddr() string {
 	if m != nil && m.HTTPAddr != nil {
@@ -1604,7 +1646,7 @@ type UpdateDataNodeCommand struct {
 func (m *UpdateDataNodeCommand) Reset()                    { *m = UpdateDataNodeCommand{} }
 func (m *UpdateDataNodeCommand) String() string            { return proto.CompactTextString(m) }
 func (*UpdateDataNodeCommand) ProtoMessage()               {}
-func (*UpdateDataNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{37} }
+func (*UpdateDataNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{38} }
 
 func (m *UpdateDataNodeCommand) GetID() uint64 {
 	if m != nil && m.ID != nil {
@@ -1643,7 +1685,7 @@ type DeleteMetaNodeCommand struct {
 func (m *DeleteMetaNodeCommand) Reset()                    { *m = DeleteMetaNodeCommand{} }
 func (m *DeleteMetaNodeCommand) String() string            { return proto.CompactTextString(m) }
 func (*DeleteMetaNodeCommand) ProtoMessage()               {}
-func (*DeleteMetaNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{38} }
+func (*DeleteMetaNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{39} }
 
 func (m *DeleteMetaNodeCommand) GetID() uint64 {
 	if m != nil && m.ID != nil {
@@ -1668,7 +1710,7 @@ type DeleteDataNodeCommand struct {
 func (m *DeleteDataNodeCommand) Reset()                    { *m = DeleteDataNodeCommand{} }
 func (m *DeleteDataNodeCommand) String() string            { return proto.CompactTextString(m) }
 func (*DeleteDataNodeCommand) ProtoMessage()               {}
-func (*DeleteDataNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{39} }
+func (*DeleteDataNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{40} }
 
 func (m *DeleteDataNodeCommand) GetID() uint64 {
 	if m != nil && m.ID != nil {
@@ -1695,7 +1737,7 @@ type Response struct {
 func (m *Response) Reset()                    { *m = Response{} }
 func (m *Response) String() string            { return proto.CompactTextString(m) }
 func (*Response) ProtoMessage()               {}
-func (*Response) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{40} }
+func (*Response) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{41} }
 
 func (m *Response) GetOK() bool {
 	if m != nil && m.OK != nil {
@@ -1730,7 +1772,7 @@ type SetMetaNodeCommand struct {
 func (m *SetMetaNodeCommand) Reset()                    { *m = SetMetaNodeCommand{} }
 func (m *SetMetaNodeCommand) String() string            { return proto.CompactTextString(m) }
 func (*SetMetaNodeCommand) ProtoMessage()               {}
-func (*SetMetaNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{41} }
+func (*SetMetaNodeCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{42} }
 
 func (m *SetMetaNodeCommand) GetHTTPAddr() string {
 	if m != nil && m.HTTPAddr != nil {
@@ -1769,7 +1811,7 @@ type DropShardCommand struct {
 func (m *DropShardCommand) Reset()                    { *m = DropShardCommand{} }
 func (m *DropShardCommand) String() string            { return proto.CompactTextString(m) }
 func (*DropShardCommand) ProtoMessage()               {}
-func (*DropShardCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{42} }
+func (*DropShardCommand) Descriptor() ([]byte, []int) { return fileDescriptorMeta, []int{43} }
 
 func (m *DropShardCommand) GetID() uint64 {
 	if m != nil && m.ID != nil {
@@ -1795,6 +1837,7 @@ func init() {
 	proto.RegisterType((*ShardGroupInfo)(nil), "meta.ShardGroupInfo")
 	proto.RegisterType((*ShardInfo)(nil), "meta.ShardInfo")
 	proto.RegisterType((*SubscriptionInfo)(nil), "meta.SubscriptionInfo")
+	proto.RegisterType((*RollupInfo)(nil), "meta.RollupInfo")
 	proto.RegisterType((*ShardOwner)(nil), "meta.ShardOwner")
 	proto.RegisterType((*ContinuousQueryInfo)(nil), "meta.ContinuousQueryInfo")
 	proto.RegisterType((*UserInfo)(nil), "meta.UserInfo")
@@ -1865,107 +1908,109 @@ func init() {
 func init() { proto.RegisterFile("internal/meta.proto", fileDescriptorMeta) }
 
 var fileDescriptorMeta = []byte{
-	// 1617 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x9c, 0x58, 0x5b, 0x6f, 0x1b, 0xc5,
-	0x17, 0xd7, 0xda, 0x6b, 0xc7, 0x7b, 0x62, 0x27, 0xf6, 0x38, 0x97, 0x4d, 0x9b, 0xa4, 0xee, 0xe8,
-	0x7f, 0xf1, 0xff, 0x2f, 0x51, 0x24, 0x2b, 0x15, 0x42, 0x5c, 0xdb, 0xb8, 0xa5, 0x11, 0x4a, 0x1a,
-	0x62, 0x17, 0xde, 0xaa, 0x6e, 0xed, 0x49, 0xb3, 0x60, 0xef, 0x9a, 0xdd, 0x75, 0xd3, 0x50, 0x68,
-	0x03, 0x12, 0x42, 0x20, 0x21, 0xc1, 0x0b, 0x2f, 0x3c, 0xf1, 0xc6, 0x37, 0x40, 0x3c, 0xf0, 0x29,
-	0xf8, 0x42, 0x68, 0x66, 0xf6, 0x32, 0xbb, 0x3b, 0xb3, 0x69, 0xfb, 0x66, 0xcf, 0x39, 0x73, 0x7e,
-	0xbf, 0x39, 0xb7, 0x39, 0xb3, 0xd0, 0xb6, 0x9d, 0x80, 0x78, 0x8e, 0x35, 0x79, 0x7d, 0x4a, 0x02,
-	0xeb, 0xda, 0xcc, 0x73, 0x03, 0x17, 0xe9, 0xf4, 0x37, 0xfe, 0xad, 0x04, 0x7a, 0xdf, 0x0a, 0x2c,
-	0x54, 0x07, 0x7d, 0x48, 0xbc, 0xa9, 0xa9, 0x75, 0x4a, 0x5d, 0x1d, 0x35, 0xa0, 0xb2, 0xe7, 0x8c,
-	0xc9, 0x13, 0xb3, 0xc4, 0xfe, 0xb6, 0xc0, 0xd8, 0x9d, 0xcc, 0xfd, 0x80, 0x78, 0x7b, 0x7d, 0xb3,
-	0xcc, 0x96, 0xb6, 0xa0, 0x72, 0xe0, 0x8e, 0x89, 0x6f, 0xea, 0x9d, 0x72, 0x77, 0xb1, 0xb7, 0x74,
-	0x8d, 0x99, 0xa6, 0x4b, 0x7b, 0xce, 0xb1, 0x8b, 0xfe, 0x0d, 0x06, 0x35, 0xfb, 0xd0, 0xf2, 0x89,
-	0x6f, 0x56, 0x98, 0x0a, 0xe2, 0x2a, 0xd1, 0x32, 0x53, 0xdb, 0x82, 0xca, 0x3d, 0x9f, 0x78, 0xbe,
-	0x59, 0x15, 0xad, 0xd0, 0x25, 0x26, 0x6e, 0x81, 0xb1, 0x6f, 0x3d, 0x61, 0x46, 0xfb, 0xe6, 0x02,
-	0xc3, 0x5d, 0x87, 0xe5, 0x7d, 0xeb, 0xc9, 0xe0, 0xc4, 0xf2, 0xc6, 0x1f, 0x78, 0xee, 0x7c, 0xb6,
-	0xd7, 0x37, 0x6b, 0x4c, 0x80, 0x00, 0x22, 0xc1, 0x5e, 0xdf, 0x34, 0xd8, 0xda, 0x55, 0xce, 0x82,
-	0x13, 0x05, 0x29, 0xd1, 0xab, 0x60, 0xec, 0x93, 0x48, 0x65, 0x51, 0xa6, 0x82, 0xaf, 0x43, 0x2d,
-	0x56, 0x07, 0x28, 0xed, 0xf5, 0x43, 0x27, 0xd5, 0x41, 0xbf, 0xe3, 0xfa, 0x01, 0xf3, 0x91, 0x81,
-	0x96, 0x61, 0x61, 0xb8, 0x7b, 0xc8, 0x16, 0xca, 0x1d, 0xad, 0x6b, 0xe0, 0xdf, 0x35, 0xa8, 0xa7,
-	0x0e, 0x5b, 0x07, 0xfd, 0xc0, 0x9a, 0x12, 0xb6, 0xdb, 0x40, 0xdb, 0xb0, 0xd6, 0x27, 0xc7, 0xd6,
-	0x7c, 0x12, 0x1c, 0x91, 0x80, 0x38, 0x81, 0xed, 0x3a, 0x87, 0xee, 0xc4, 0x1e, 0x9d, 0x85, 0xf6,
-	0x76, 0xa0, 0x95, 0x16, 0xd8, 0xc4, 0x37, 0xcb, 0x8c, 0xe0, 0x06, 0x27, 0x98, 0xd9, 0xc7, 0x30,
-	0x76, 0xa0, 0xb5, 0xeb, 0x3a, 0x81, 0xed, 0xcc, 0xdd, 0xb9, 0xff, 0xd1, 0x9c, 0x78, 0x76, 0x1c,
-	0xa2, 0x70, 0x57, 0x5a, 0xcc, 0x76, 0xe1, 0x11, 0xb4, 0x33, 0xc6, 0x06, 0x33, 0x32, 0x12, 0x08,
-	0x6b, 0x5d, 0x03, 0x35, 0xa1, 0xd6, 0x9f, 0x7b, 0x16, 0xd5, 0x31, 0x4b, 0x1d, 0xad, 0x5b, 0x46,
-	0x97, 0x00, 0x25, 0x81, 0x88, 0x65, 0x65, 0x26, 0x6b, 0x42, 0xed, 0x88, 0xcc, 0x26, 0xf6, 0xc8,
-	0x3a, 0x30, 0xf5, 0x8e, 0xd6, 0x6d, 0xe0, 0xbf, 0xb4, 0x1c, 0x8a, 0xc4, 0x2d, 0x69, 0x94, 0x52,
-	0x01, 0x4a, 0x29, 0x87, 0x52, 0xea, 0x36, 0xd0, 0xff, 0x60, 0x31, 0xd1, 0x8e, 0x52, 0x6f, 0x85,
-	0x1f, 0x5d, 0xc8, 0x1a, 0x0a, 0xfc, 0x1a, 0x34, 0x06, 0xf3, 0x87, 0xfe, 0xc8, 0xb3, 0x67, 0xd4,
-	0x64, 0x94, 0x84, 0x6b, 0xa1, 0xb2, 0x20, 0x62, 0x4e, 0xfa, 0x5e, 0x83, 0xa5, 0x8c, 0x05, 0x31,
-	0x1b, 0x5a, 0x60, 0x0c, 0x02, 0xcb, 0x0b, 0x86, 0xf6, 0x94, 0x84, 0xcc, 0x97, 0x61, 0xe1, 0x96,
-	0x33, 0x66, 0x0b, 0x9c, 0x6e, 0x0b, 0x8c, 0x3e, 0x99, 0x90, 0x80, 0x8c, 0x6f, 0x04, 0x8c, 0x6f,
-	0x19, 0x5d, 0x81, 0x2a, 0x33, 0x1a, 0x51, 0x5d, 0x16, 0xa8, 0x32, 0x8c, 0x36, 0x2c, 0x0e, 0xbd,
-	0xb9, 0x33, 0xb2, 0xf8, 0xae, 0x2a, 0xf5, 0x2e, 0xbe, 0x0b, 0x46, 0xa2, 0x21, 0xb2, 0x58, 0x81,
-	0xda, 0xdd, 0x53, 0x87, 0xd6, 0xa9, 0x6f, 0x96, 0x3a, 0xe5, 0xae, 0x7e, 0xb3, 0x64, 0x6a, 0xa8,
-	0x03, 0x55, 0xb6, 0x1a, 0x25, 0x50, 0x53, 0x00, 0x61, 0x02, 0xdc, 0x87, 0x66, 0xf6, 0xc0, 0x99,
-	0xc0, 0xd4, 0x41, 0xdf, 0x77, 0xc7, 0x24, 0xcc, 0xce, 0x15, 0xa8, 0xf7, 0x89, 0x1f, 0xd8, 0x8e,
-	0xc5, 0x5d, 0x47, 0xed, 0x1a, 0x78, 0x13, 0x20, 0xb1, 0x89, 0x96, 0xa0, 0x1a, 0x96, 0x2e, 0xe3,
-	0x86, 0x7b, 0xd0, 0x96, 0x24, 0x5f, 0x06, 0xa6, 0x01, 0x15, 0x26, 0xe2, 0x38, 0xf8, 0x3e, 0xd4,
-	0xe2, 0x6e, 0x90, 0xe3, 0x73, 0xc7, 0xf2, 0x4f, 0x42, 0x3e, 0x0d, 0xa8, 0xdc, 0x18, 0x4f, 0x6d,
-	0x9e, 0x17, 0x35, 0xf4, 0x5f, 0x80, 0x43, 0xcf, 0x7e, 0x6c, 0x4f, 0xc8, 0xa3, 0x38, 0xff, 0xdb,
-	0x49, 0x73, 0x89, 0x65, 0x78, 0x07, 0x1a, 0xa9, 0x05, 0x96, 0x7f, 0x61, 0xd1, 0x86, 0x40, 0x2d,
-	0x30, 0x62, 0x31, 0x43, 0xab, 0xe0, 0xbf, 0xab, 0xb0, 0xb0, 0xeb, 0x4e, 0xa7, 0x96, 0x33, 0x46,
-	0x1d, 0xd0, 0x83, 0xb3, 0x19, 0x57, 0x5e, 0x8a, 0x9a, 0x5c, 0x28, 0xbc, 0x36, 0x3c, 0x9b, 0x11,
-	0xfc, 0x6b, 0x15, 0x74, 0xfa, 0x03, 0xad, 0x42, 0x6b, 0xd7, 0x23, 0x56, 0x40, 0xa8, 0x5b, 0x42,
-	0x95, 0xa6, 0x46, 0x97, 0x79, 0x56, 0x88, 0xcb, 0x25, 0xb4, 0x01, 0xab, 0x5c, 0x3b, 0xe2, 0x13,
-	0x89, 0xca, 0x68, 0x1d, 0xda, 0x7d, 0xcf, 0x9d, 0x65, 0x05, 0x3a, 0xea, 0xc0, 0x26, 0xdf, 0x93,
-	0x29, 0xb4, 0x48, 0xa3, 0x82, 0xb6, 0xe1, 0x12, 0xdd, 0xaa, 0x90, 0x57, 0xd1, 0xbf, 0xa0, 0x33,
-	0x20, 0x81, 0xbc, 0x33, 0x45, 0x5a, 0x0b, 0x14, 0xe7, 0xde, 0x6c, 0xac, 0xc6, 0xa9, 0xa1, 0xcb,
-	0xb0, 0xce, 0x99, 0x24, 0x25, 0x13, 0x09, 0x0d, 0x2a, 0xe4, 0x27, 0xce, 0x0b, 0x21, 0x39, 0x43,
-	0x26, 0x59, 0x22, 0x8d, 0xc5, 0xe8, 0x0c, 0x0a, 0x79, 0x3d, 0xf1, 0x33, 0x0d, 0x6d, 0xb4, 0xdc,
-	0x40, 0x6d, 0x58, 0xa6, 0xdb, 0xc4, 0xc5, 0x25, 0xaa, 0xcb, 0x4f, 0x22, 0x2e, 0x2f, 0x53, 0x0f,
-	0x0f, 0x48, 0x10, 0xc7, 0x3d, 0x12, 0x34, 0x11, 0x82, 0x25, 0xea, 0x1f, 0x2b, 0xb0, 0xa2, 0xb5,
-	0x16, 0xda, 0x04, 0x73, 0x40, 0x02, 0x96, 0x7f, 0xb9, 0x1d, 0x28, 0x41, 0x10, 0xc3, 0xdb, 0x46,
-	0x5b, 0xb0, 0x11, 0x3a, 0x48, 0xa8, 0xbb, 0x48, 0xbc, 0xca, 0x5c, 0xe4, 0xb9, 0x33, 0x99, 0x70,
-	0x8d, 0x9a, 0x3c, 0x22, 0x53, 0xf7, 0x31, 0x39, 0x24, 0x09, 0xe9, 0xf5, 0x24, 0x63, 0xa2, 0x1b,
-	0x2d, 0x12, 0x99, 0xe9, 0x64, 0x12, 0x45, 0x1b, 0x54, 0xc4, 0xf9, 0x65, 0x45, 0x97, 0xa8, 0x88,
-	0xc7, 0x29, 0x6b, 0xf0, 0x72, 0x22, 0xca, 0xee, 0xda, 0x44, 0x6b, 0x80, 0x06, 0x24, 0xc8, 0x6e,
-	0xd9, 0x42, 0x2b, 0xd0, 0x64, 0x47, 0xa2, 0x31, 0x8f, 0x56, 0xb7, 0xff, 0x5f, 0xab, 0x8d, 0x9b,
-	0xe7, 0xe7, 0xe7, 0xe7, 0x25, 0x7c, 0x22, 0x29, 0x8f, 0xf8, 0x92, 0x8d, 0x8b, 0xfe, 0xc8, 0x72,
-	0xc6, 0x7c, 0x2c, 0xe9, 0xbd, 0x01, 0x0b, 0xa3, 0x50, 0xad, 0x91, 0xaa, 0x3b, 0x93, 0x74, 0xb4,
-	0xee, 0x62, 0x6f, 0x3d, 0x5c, 0xcc, 0x1a, 0xc5, 0x8f, 0x24, 0x15, 0x97, 0x6a, 0xa3, 0x0d, 0xa8,
-	0xdc, 0x76, 0xbd, 0x11, 0xaf, 0xf7, 0x5a, 0x01, 0xd0, 0xb1, 0x08, 0x94, 0xb3, 0x89, 0x7f, 0xd1,
-	0x14, 0x45, 0x9c, 0x69, 0x66, 0x3d, 0x58, 0xce, 0x4f, 0x01, 0x5a, 0xe1, 0x55, 0xdf, 0x7b, 0x4b,
-	0x49, 0xea, 0x11, 0xdb, 0x7a, 0x59, 0x3c, 0x7d, 0x06, 0x1e, 0xdf, 0x97, 0x76, 0x90, 0x34, 0xab,
-	0xde, 0x9b, 0x4a, 0x84, 0x13, 0x91, 0x9c, 0xc4, 0x10, 0x1d, 0x7e, 0x0a, 0x3b, 0x91, 0xa4, 0xcf,
-	0x4a, 0x7d, 0x50, 0x2a, 0xf6, 0xc1, 0x4d, 0x25, 0x43, 0x9b, 0x31, 0xc4, 0xa2, 0x0f, 0xe4, 0x4c,
-	0xf0, 0xb3, 0xa2, 0x8e, 0x28, 0xe1, 0x19, 0xf9, 0x88, 0x5d, 0x3c, 0xbd, 0xf7, 0x95, 0x0c, 0x3e,
-	0x65, 0x0c, 0x3a, 0x89, 0x8f, 0x14, 0xf8, 0x3f, 0x68, 0x17, 0xb7, 0xdc, 0x0b, 0x69, 0xdc, 0x56,
-	0xd2, 0xf8, 0x8c, 0xd1, 0xf8, 0x4f, 0x78, 0xe3, 0x5f, 0x80, 0x83, 0xff, 0xd0, 0x8a, 0x3b, 0xfb,
-	0x45, 0x44, 0xe8, 0xcc, 0x73, 0x40, 0x4e, 0xd9, 0x42, 0x39, 0x37, 0x36, 0xea, 0xb9, 0xd1, 0xb0,
-	0x42, 0x47, 0xc3, 0x82, 0x30, 0x4e, 0xc4, 0x30, 0x16, 0x11, 0xc3, 0x3f, 0x6a, 0xca, 0x1b, 0x47,
-	0x42, 0x7a, 0x09, 0xaa, 0xa9, 0x69, 0xbb, 0x05, 0x06, 0x9d, 0xd3, 0xfc, 0xc0, 0x9a, 0xce, 0xf8,
-	0xb0, 0xd6, 0x7b, 0x47, 0x49, 0x6a, 0xca, 0x48, 0x6d, 0x89, 0xb9, 0x95, 0xc3, 0xc4, 0x3f, 0x69,
-	0xca, 0x4b, 0xee, 0x05, 0xf8, 0xac, 0x40, 0x3d, 0xf5, 0xc6, 0x61, 0x8f, 0xae, 0x02, 0x4a, 0x8e,
-	0x48, 0x49, 0x01, 0x8b, 0x7f, 0xd6, 0x8a, 0xaf, 0xd6, 0x0b, 0x83, 0x1b, 0x0f, 0x67, 0x65, 0x96,
-	0x74, 0xea, 0xb0, 0xb9, 0xf9, 0xea, 0x93, 0x43, 0x46, 0xd5, 0xf7, 0x6a, 0x84, 0x0a, 0xaa, 0x6f,
-	0x96, 0xad, 0x3e, 0x05, 0xfe, 0xa9, 0x64, 0x56, 0x78, 0x89, 0x49, 0xb3, 0xe0, 0x6a, 0xf8, 0x3c,
-	0x7f, 0x07, 0x09, 0x18, 0xf8, 0xe3, 0xdc, 0x34, 0x92, 0xe9, 0xbe, 0xd7, 0x95, 0x96, 0x3d, 0x66,
-	0x79, 0x35, 0x39, 0x9b, 0x68, 0xf7, 0x44, 0x32, 0xd0, 0x14, 0x1d, 0xa8, 0xe0, 0x04, 0xbe, 0x78,
-	0x82, 0x9c, 0x51, 0xfc, 0x9d, 0x26, 0x1d, 0x92, 0x68, 0xd0, 0xa8, 0x9a, 0x93, 0x7e, 0xd4, 0x45,
-	0x61, 0x2c, 0xe5, 0x87, 0x6a, 0xea, 0xc9, 0x4a, 0xc1, 0x6d, 0x13, 0x88, 0xb7, 0x8d, 0x04, 0x11,
-	0x3f, 0xc8, 0x0e, 0x65, 0xc8, 0xe4, 0x9f, 0x35, 0x18, 0xfe, 0x62, 0x0f, 0x92, 0x4f, 0x0f, 0xbd,
-	0x1d, 0x25, 0xcc, 0x9c, 0xc1, 0xac, 0x24, 0x9d, 0x32, 0xb1, 0x87, 0x9f, 0xaa, 0x47, 0x3c, 0xc9,
-	0x79, 0xe3, 0x1c, 0xe1, 0xe3, 0xc3, 0xbb, 0x4a, 0xc8, 0xc7, 0x0c, 0x72, 0x3b, 0x86, 0x94, 0x02,
-	0xe0, 0x63, 0xc9, 0x04, 0xa9, 0xfe, 0x12, 0x51, 0x10, 0xd0, 0xd3, 0x7c, 0x40, 0xc5, 0x69, 0xe5,
-	0x4f, 0xad, 0x60, 0x26, 0x95, 0xbc, 0xd3, 0xd3, 0x21, 0x5d, 0xcf, 0xdf, 0xdf, 0xe5, 0xd4, 0xcb,
-	0x51, 0x97, 0xbe, 0x1c, 0xe9, 0xb3, 0xd7, 0xe8, 0xbd, 0xa7, 0xe4, 0x7c, 0xc6, 0x38, 0x5f, 0x49,
-	0x35, 0xdb, 0x3c, 0x3b, 0xda, 0xdb, 0x54, 0x03, 0xf3, 0x2b, 0x33, 0x2f, 0xe8, 0xb7, 0x5f, 0xa4,
-	0xfa, 0xad, 0x1c, 0x97, 0xc6, 0x2d, 0x37, 0xa6, 0xc7, 0x71, 0xd3, 0x78, 0xdc, 0x6e, 0x8c, 0xc7,
-	0xde, 0x85, 0x71, 0x7b, 0x2a, 0xc6, 0x2d, 0x67, 0x12, 0x7f, 0xab, 0x29, 0x06, 0x7f, 0x7a, 0xd6,
-	0x3b, 0xc3, 0xe1, 0x21, 0x03, 0xd1, 0x84, 0xcf, 0x54, 0x09, 0x6a, 0x3c, 0x52, 0xf3, 0x1b, 0x46,
-	0x3d, 0x54, 0x7e, 0x99, 0x1f, 0x2a, 0x33, 0x68, 0xf8, 0x54, 0xf1, 0xc8, 0x78, 0x01, 0x1a, 0x05,
-	0xc0, 0x5f, 0xc9, 0xa7, 0x59, 0x11, 0xf8, 0xb9, 0xe2, 0x09, 0xf3, 0xa2, 0x9f, 0xeb, 0x8a, 0x09,
-	0x3c, 0x13, 0x09, 0x48, 0x71, 0xf0, 0x03, 0xc5, 0x43, 0x49, 0x24, 0x50, 0x80, 0xf0, 0x5c, 0x44,
-	0x90, 0x1a, 0xc2, 0x96, 0xe2, 0xbd, 0x95, 0x42, 0x78, 0x5b, 0x89, 0x70, 0xae, 0xe5, 0x21, 0xb2,
-	0x87, 0xd8, 0xa1, 0x73, 0x99, 0x3f, 0x73, 0x1d, 0x9f, 0x50, 0xab, 0x77, 0x3f, 0x64, 0x56, 0x6b,
-	0xb4, 0x9b, 0xdd, 0xf2, 0x3c, 0xd7, 0x63, 0x4f, 0x12, 0x23, 0xf9, 0x36, 0x4c, 0xe7, 0x3b, 0x1d,
-	0x9f, 0x6b, 0xb2, 0xe7, 0xde, 0xcb, 0x67, 0x9e, 0xba, 0xfd, 0x7f, 0xcd, 0xb9, 0x9b, 0x71, 0x97,
-	0xcc, 0xfa, 0xe6, 0x93, 0xfc, 0xc3, 0x32, 0xe5, 0x16, 0x75, 0x61, 0x7d, 0xc3, 0x4d, 0xaf, 0x09,
-	0x75, 0x2c, 0x18, 0xf9, 0x27, 0x00, 0x00, 0xff, 0xff, 0x5d, 0xc6, 0xc9, 0x45, 0x39, 0x17, 0x00,
-	0x00,
+	// 1660 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x58, 0x6d, 0x6f, 0x1b, 0xc5,
+	0x13, 0xd7, 0xd9, 0x67, 0xc7, 0x37, 0xb1, 0x13, 0x7b, 0x9d, 0x87, 0x4b, 0x9b, 0xa4, 0xee, 0xea,
+	0xff, 0x60, 0x90, 0x28, 0x92, 0x95, 0x0a, 0x21, 0x9e, 0xda, 0xc6, 0x2d, 0x8d, 0x50, 0xd2, 0x10,
+	0xa7, 0xf0, 0xae, 0xea, 0xd5, 0xde, 0x34, 0x06, 0xfb, 0xee, 0xb8, 0x3b, 0x27, 0x0d, 0x85, 0x36,
+	0x20, 0x21, 0x04, 0x12, 0x12, 0xbc, 0xe1, 0x0d, 0xaf, 0x78, 0xc7, 0x37, 0x40, 0x7c, 0x0e, 0x3e,
+	0x00, 0x5f, 0x05, 0xed, 0xee, 0x3d, 0xec, 0xdd, 0xed, 0x5e, 0xda, 0xbe, 0xb3, 0x67, 0x66, 0xe7,
+	0xf7, 0x9b, 0x99, 0xdd, 0xd9, 0xd9, 0x83, 0xf6, 0xd8, 0x0e, 0x88, 0x67, 0x5b, 0x93, 0x37, 0xa7,
+	0x24, 0xb0, 0xae, 0xb9, 0x9e, 0x13, 0x38, 0x48, 0xa7, 0xbf, 0xf1, 0xef, 0x25, 0xd0, 0xfb, 0x56,
+	0x60, 0xa1, 0x3a, 0xe8, 0x87, 0xc4, 0x9b, 0x9a, 0x5a, 0xa7, 0xd4, 0xd5, 0x51, 0x03, 0x2a, 0x3b,
+	0xf6, 0x88, 0x3c, 0x31, 0x4b, 0xec, 0x6f, 0x0b, 0x8c, 0xed, 0xc9, 0xcc, 0x0f, 0x88, 0xb7, 0xd3,
+	0x37, 0xcb, 0x4c, 0xb4, 0x01, 0x95, 0x3d, 0x67, 0x44, 0x7c, 0x53, 0xef, 0x94, 0xbb, 0xf3, 0xbd,
+	0x85, 0x6b, 0xcc, 0x35, 0x15, 0xed, 0xd8, 0x47, 0x0e, 0xfa, 0x2f, 0x18, 0xd4, 0xed, 0x23, 0xcb,
+	0x27, 0xbe, 0x59, 0x61, 0x26, 0x88, 0x9b, 0x44, 0x62, 0x66, 0xb6, 0x01, 0x95, 0xfb, 0x3e, 0xf1,
+	0x7c, 0xb3, 0x2a, 0x7a, 0xa1, 0x22, 0xa6, 0x6e, 0x81, 0xb1, 0x6b, 0x3d, 0x61, 0x4e, 0xfb, 0xe6,
+	0x1c, 0xc3, 0x5d, 0x85, 0xc5, 0x5d, 0xeb, 0xc9, 0xe0, 0xd8, 0xf2, 0x46, 0x1f, 0x7a, 0xce, 0xcc,
+	0xdd, 0xe9, 0x9b, 0x35, 0xa6, 0x40, 0x00, 0x91, 0x62, 0xa7, 0x6f, 0x1a, 0x4c, 0x76, 0x95, 0xb3,
+	0xe0, 0x44, 0x41, 0x4a, 0xf4, 0x2a, 0x18, 0xbb, 0x24, 0x32, 0x99, 0x97, 0x99, 0xe0, 0xeb, 0x50,
+	0x8b, 0xcd, 0x01, 0x4a, 0x3b, 0xfd, 0x30, 0x49, 0x75, 0xd0, 0xef, 0x3a, 0x7e, 0xc0, 0x72, 0x64,
+	0xa0, 0x45, 0x98, 0x3b, 0xdc, 0xde, 0x67, 0x82, 0x72, 0x47, 0xeb, 0x1a, 0xf8, 0x0f, 0x0d, 0xea,
+	0xa9, 0x60, 0xeb, 0xa0, 0xef, 0x59, 0x53, 0xc2, 0x56, 0x1b, 0x68, 0x13, 0x56, 0xfa, 0xe4, 0xc8,
+	0x9a, 0x4d, 0x82, 0x03, 0x12, 0x10, 0x3b, 0x18, 0x3b, 0xf6, 0xbe, 0x33, 0x19, 0x0f, 0xcf, 0x42,
+	0x7f, 0x5b, 0xd0, 0x4a, 0x2b, 0xc6, 0xc4, 0x37, 0xcb, 0x8c, 0xe0, 0x1a, 0x27, 0x98, 0x59, 0xc7,
+	0x30, 0xb6, 0xa0, 0xb5, 0xed, 0xd8, 0xc1, 0xd8, 0x9e, 0x39, 0x33, 0xff, 0xe3, 0x19, 0xf1, 0xc6,
+	0x71, 0x89, 0xc2, 0x55, 0x69, 0x35, 0x5b, 0x85, 0x87, 0xd0, 0xce, 0x38, 0x1b, 0xb8, 0x64, 0x28,
+	0x10, 0xd6, 0xba, 0x06, 0x6a, 0x42, 0xad, 0x3f, 0xf3, 0x2c, 0x6a, 0x63, 0x96, 0x3a, 0x5a, 0xb7,
+	0x8c, 0x2e, 0x01, 0x4a, 0x0a, 0x11, 0xeb, 0xca, 0x4c, 0xd7, 0x84, 0xda, 0x01, 0x71, 0x27, 0xe3,
+	0xa1, 0xb5, 0x67, 0xea, 0x1d, 0xad, 0xdb, 0xc0, 0xff, 0x68, 0x39, 0x14, 0x49, 0x5a, 0xd2, 0x28,
+	0xa5, 0x02, 0x94, 0x52, 0x0e, 0xa5, 0xd4, 0x6d, 0xa0, 0xd7, 0x60, 0x3e, 0xb1, 0x8e, 0xb6, 0xde,
+	0x12, 0x0f, 0x5d, 0xd8, 0x35, 0x14, 0xf8, 0x0d, 0x68, 0x0c, 0x66, 0x8f, 0xfc, 0xa1, 0x37, 0x76,
+	0xa9, 0xcb, 0x68, 0x13, 0xae, 0x84, 0xc6, 0x82, 0x8a, 0x99, 0x77, 0xa0, 0x7a, 0xe0, 0x4c, 0x26,
+	0x33, 0xd7, 0x9c, 0xeb, 0x68, 0xdd, 0xf9, 0x5e, 0x33, 0xac, 0x02, 0x93, 0xb1, 0x34, 0xfe, 0xa0,
+	0xc1, 0x42, 0x06, 0x43, 0xdc, 0x2f, 0x2d, 0x30, 0x06, 0x81, 0xe5, 0x05, 0x87, 0xe3, 0x29, 0x09,
+	0x63, 0x5b, 0x84, 0xb9, 0xdb, 0xf6, 0x88, 0x09, 0x78, 0x40, 0x2d, 0x30, 0xfa, 0x64, 0x42, 0x02,
+	0x32, 0xba, 0x19, 0xb0, 0x88, 0xca, 0xe8, 0x0a, 0x54, 0x99, 0xd3, 0x28, 0x98, 0x45, 0x21, 0x18,
+	0x86, 0xd1, 0x86, 0xf9, 0x43, 0x6f, 0x66, 0x0f, 0x2d, 0xbe, 0xaa, 0x4a, 0xf3, 0x8f, 0xef, 0x81,
+	0x91, 0x58, 0x88, 0x2c, 0x96, 0xa0, 0x76, 0xef, 0xd4, 0xa6, 0x27, 0xd9, 0x37, 0x4b, 0x9d, 0x72,
+	0x57, 0xbf, 0x55, 0x32, 0x35, 0x1a, 0x1c, 0x93, 0x46, 0x5b, 0xac, 0x29, 0x80, 0x30, 0x05, 0xee,
+	0x43, 0x33, 0x97, 0x92, 0x74, 0xe9, 0xea, 0xa0, 0xef, 0x3a, 0x23, 0x12, 0xee, 0xdf, 0x25, 0xa8,
+	0xf7, 0x89, 0x1f, 0x8c, 0x6d, 0x8b, 0x27, 0x97, 0xfa, 0x35, 0xf0, 0x0d, 0x80, 0x24, 0x61, 0xb4,
+	0xcd, 0xdc, 0x3c, 0x0a, 0x88, 0x67, 0x6a, 0x51, 0x35, 0x77, 0x68, 0xa7, 0x3a, 0xb1, 0x26, 0x61,
+	0x7e, 0x5a, 0x60, 0xdc, 0x99, 0xd9, 0x43, 0xd1, 0xc3, 0x3a, 0x40, 0xc2, 0x0a, 0x2d, 0x40, 0x35,
+	0x6c, 0x0f, 0x2c, 0x3a, 0xdc, 0x83, 0xb6, 0x64, 0x83, 0x67, 0x88, 0x36, 0xa0, 0xc2, 0x54, 0x9c,
+	0x29, 0x7e, 0x00, 0xb5, 0xb8, 0xe3, 0xe4, 0x22, 0xba, 0x6b, 0xf9, 0xc7, 0x61, 0x44, 0x94, 0xed,
+	0x68, 0x3a, 0xe6, 0x7b, 0xaf, 0x86, 0xfe, 0x0f, 0xb0, 0xef, 0x8d, 0x4f, 0xc6, 0x13, 0xf2, 0x38,
+	0x3e, 0x63, 0xed, 0xa4, 0x81, 0xc5, 0x3a, 0xbc, 0x05, 0x8d, 0x94, 0x80, 0xed, 0xf1, 0xb0, 0x31,
+	0x84, 0x40, 0x2d, 0x30, 0x62, 0x35, 0x43, 0xab, 0xe0, 0xbf, 0xab, 0x30, 0xb7, 0xed, 0x4c, 0xa7,
+	0x96, 0x3d, 0x42, 0x1d, 0xd0, 0x83, 0x33, 0x97, 0x1b, 0x2f, 0x44, 0x8d, 0x34, 0x54, 0x5e, 0x3b,
+	0x3c, 0x73, 0x09, 0xfe, 0xad, 0x0a, 0x3a, 0xfd, 0x81, 0x96, 0xa1, 0xb5, 0xed, 0x11, 0x2b, 0x20,
+	0x34, 0x2d, 0xa1, 0x49, 0x53, 0xa3, 0x62, 0xbe, 0xaf, 0x44, 0x71, 0x09, 0xad, 0xc1, 0x32, 0xb7,
+	0x8e, 0xf8, 0x44, 0xaa, 0x32, 0x5a, 0x85, 0x76, 0xdf, 0x73, 0xdc, 0xac, 0x42, 0x47, 0x1d, 0x58,
+	0xe7, 0x6b, 0x32, 0x87, 0x39, 0xb2, 0xa8, 0xa0, 0x4d, 0xb8, 0x44, 0x97, 0x2a, 0xf4, 0x55, 0xf4,
+	0x1f, 0xe8, 0x0c, 0x48, 0x20, 0xef, 0x7e, 0x91, 0xd5, 0x1c, 0xc5, 0xb9, 0xef, 0x8e, 0xd4, 0x38,
+	0x35, 0x74, 0x19, 0x56, 0x39, 0x93, 0xe4, 0xd0, 0x45, 0x4a, 0x83, 0x2a, 0x79, 0xc4, 0x79, 0x25,
+	0x24, 0x31, 0x64, 0x36, 0x4b, 0x64, 0x31, 0x1f, 0xc5, 0xa0, 0xd0, 0xd7, 0x93, 0x3c, 0xd3, 0xd2,
+	0x46, 0xe2, 0x06, 0x6a, 0xc3, 0x22, 0x5d, 0x26, 0x0a, 0x17, 0xa8, 0x2d, 0x8f, 0x44, 0x14, 0x2f,
+	0xd2, 0x0c, 0x0f, 0x48, 0x10, 0xd7, 0x3d, 0x52, 0x34, 0x11, 0x82, 0x05, 0x9a, 0x1f, 0x2b, 0xb0,
+	0x22, 0x59, 0x0b, 0xad, 0x83, 0x39, 0x20, 0x01, 0xdb, 0x7f, 0xb9, 0x15, 0x28, 0x41, 0x10, 0xcb,
+	0xdb, 0x46, 0x1b, 0xb0, 0x16, 0x26, 0x48, 0x38, 0xb9, 0x91, 0x7a, 0x99, 0xa5, 0xc8, 0x73, 0x5c,
+	0x99, 0x72, 0x85, 0xba, 0x3c, 0x20, 0x53, 0xe7, 0x84, 0xec, 0x93, 0x84, 0xf4, 0x6a, 0xb2, 0x63,
+	0xa2, 0x5b, 0x33, 0x52, 0x99, 0xe9, 0xcd, 0x24, 0xaa, 0xd6, 0xa8, 0x8a, 0xf3, 0xcb, 0xaa, 0x2e,
+	0x51, 0x15, 0xaf, 0x53, 0xd6, 0xe1, 0xe5, 0x44, 0x95, 0x5d, 0xb5, 0x8e, 0x56, 0x00, 0x0d, 0x48,
+	0x90, 0x5d, 0xb2, 0x81, 0x96, 0xa0, 0xc9, 0x42, 0xa2, 0x35, 0x8f, 0xa4, 0x9b, 0xaf, 0xd7, 0x6a,
+	0xa3, 0xe6, 0xf9, 0xf9, 0xf9, 0x79, 0x09, 0x1f, 0x4b, 0x8e, 0x47, 0x7c, 0x91, 0xc7, 0x87, 0xfe,
+	0xc0, 0xb2, 0x47, 0x7c, 0xf4, 0xe9, 0xbd, 0x05, 0x73, 0xc3, 0xd0, 0xac, 0x91, 0x3a, 0x77, 0x26,
+	0x61, 0xb7, 0xc0, 0x6a, 0x28, 0xcc, 0x3a, 0xc5, 0x8f, 0x25, 0x27, 0x2e, 0xd5, 0x88, 0x1b, 0x50,
+	0xb9, 0xe3, 0x78, 0x43, 0x7e, 0xde, 0x6b, 0x05, 0x40, 0x47, 0x22, 0x50, 0xce, 0x27, 0xfe, 0x55,
+	0x53, 0x1c, 0xe2, 0x4c, 0x33, 0xeb, 0xc1, 0x62, 0x7e, 0xd2, 0xd0, 0x0a, 0xc7, 0x89, 0xde, 0x3b,
+	0x4a, 0x52, 0x8f, 0xd9, 0xd2, 0xcb, 0x62, 0xf4, 0x19, 0x78, 0xfc, 0x40, 0xda, 0x41, 0xd2, 0xac,
+	0x7a, 0x6f, 0x2b, 0x11, 0x8e, 0x45, 0x72, 0x12, 0x47, 0x74, 0xc0, 0x2a, 0xec, 0x44, 0x92, 0x3e,
+	0x2b, 0xcd, 0x41, 0xa9, 0x38, 0x07, 0xb7, 0x94, 0x0c, 0xc7, 0x8c, 0x21, 0x16, 0x73, 0x20, 0x67,
+	0x82, 0x9f, 0x15, 0x75, 0x44, 0x09, 0xcf, 0x28, 0x47, 0xec, 0xe2, 0xe9, 0xdd, 0x50, 0x32, 0xf8,
+	0x8c, 0x31, 0xe8, 0x24, 0x39, 0x52, 0xe0, 0xff, 0xa8, 0x5d, 0xdc, 0x72, 0x2f, 0xa4, 0x71, 0x47,
+	0x49, 0xe3, 0x73, 0x46, 0xe3, 0x7f, 0x5c, 0x78, 0x11, 0x0e, 0xfe, 0x53, 0x2b, 0xee, 0xec, 0x17,
+	0x11, 0xa1, 0x53, 0xd3, 0x1e, 0x39, 0x65, 0x82, 0x72, 0x6e, 0x34, 0xd5, 0x73, 0xe3, 0x67, 0x85,
+	0x8e, 0x9f, 0x05, 0x65, 0x9c, 0x88, 0x65, 0x2c, 0x22, 0x86, 0x7f, 0xd2, 0x94, 0x37, 0x8e, 0x84,
+	0xf4, 0x02, 0x54, 0x53, 0x13, 0x7d, 0x0b, 0x0c, 0x3a, 0xe9, 0xf9, 0x81, 0x35, 0x75, 0xf9, 0xb8,
+	0xd7, 0x7b, 0x4f, 0x49, 0x6a, 0xca, 0x48, 0x6d, 0x88, 0x7b, 0x2b, 0x87, 0x89, 0x7f, 0xd6, 0x94,
+	0x97, 0xdc, 0x0b, 0xf0, 0x59, 0x82, 0x7a, 0xea, 0x1d, 0xc5, 0x1e, 0x76, 0x05, 0x94, 0x6c, 0x91,
+	0x92, 0x02, 0x16, 0xff, 0xa2, 0x15, 0x5f, 0xad, 0x17, 0x16, 0x37, 0x1e, 0xce, 0x28, 0x1d, 0xa3,
+	0xa0, 0x6c, 0x4e, 0xfe, 0xf4, 0xc9, 0x21, 0xa3, 0xd3, 0xf7, 0x6a, 0x84, 0x0a, 0x4e, 0x9f, 0x9b,
+	0x3d, 0x7d, 0x0a, 0xfc, 0x53, 0xc9, 0xac, 0xf0, 0x12, 0x93, 0x66, 0xc1, 0xd5, 0xf0, 0x45, 0xfe,
+	0x0e, 0x12, 0x30, 0xf0, 0x27, 0xb9, 0x69, 0x24, 0xd3, 0x7d, 0xaf, 0x2b, 0x3d, 0x7b, 0xcc, 0xf3,
+	0x72, 0x12, 0x9b, 0xe8, 0xf7, 0x58, 0x32, 0xd0, 0x14, 0x05, 0x54, 0x10, 0x81, 0x2f, 0x46, 0x90,
+	0x73, 0x8a, 0xbf, 0xd7, 0xa4, 0x43, 0x12, 0x2d, 0x1a, 0x35, 0xb3, 0xd3, 0x0f, 0xc7, 0xa8, 0x8c,
+	0xa5, 0xfc, 0x50, 0x4d, 0x33, 0x59, 0x29, 0xb8, 0x6d, 0x02, 0xf1, 0xb6, 0x91, 0x20, 0xe2, 0x87,
+	0xd9, 0xa1, 0x0c, 0x99, 0xfc, 0xd3, 0x09, 0xc3, 0x9f, 0xef, 0x41, 0xf2, 0x79, 0xa3, 0xb7, 0xa5,
+	0x84, 0x99, 0x75, 0x34, 0xe1, 0x3d, 0x9a, 0xf2, 0x87, 0x9f, 0xaa, 0x47, 0x3c, 0x49, 0xbc, 0xf1,
+	0x1e, 0xe1, 0xe3, 0xc3, 0xfb, 0x4a, 0xc8, 0x13, 0x06, 0xb9, 0x19, 0x43, 0x4a, 0x01, 0xf0, 0x91,
+	0x64, 0x82, 0x54, 0x7f, 0xed, 0x28, 0x28, 0xe8, 0x69, 0xbe, 0xa0, 0xe2, 0xb4, 0xf2, 0x97, 0x56,
+	0x30, 0x93, 0x4a, 0xbe, 0x05, 0xa4, 0x4b, 0xba, 0x9a, 0xbf, 0xbf, 0xcb, 0xa9, 0xb7, 0xa7, 0x2e,
+	0x7d, 0x7b, 0xd2, 0x87, 0xb3, 0xd1, 0xfb, 0x40, 0xc9, 0xf9, 0x8c, 0x71, 0xbe, 0x92, 0x6a, 0xb6,
+	0x79, 0x76, 0xb4, 0xb7, 0xa9, 0x06, 0xe6, 0x57, 0x66, 0x5e, 0xd0, 0x6f, 0xbf, 0x4c, 0xf5, 0x5b,
+	0x39, 0x2e, 0x3e, 0x92, 0x8c, 0xe9, 0x71, 0xdd, 0x34, 0x5e, 0xb7, 0x9b, 0xa3, 0x91, 0x77, 0x61,
+	0xdd, 0x9e, 0x8a, 0x75, 0xcb, 0xb9, 0xc4, 0xdf, 0x69, 0x8a, 0xc1, 0x9f, 0xc6, 0x7a, 0xf7, 0xf0,
+	0x70, 0x9f, 0x81, 0x68, 0xc2, 0xa7, 0xb0, 0x04, 0x35, 0x1e, 0xa9, 0xf9, 0x0d, 0xa3, 0x1e, 0x2a,
+	0xbf, 0xca, 0x0f, 0x95, 0x19, 0x34, 0x7c, 0xaa, 0x78, 0x64, 0xbc, 0x00, 0x8d, 0x02, 0xe0, 0xaf,
+	0xe5, 0xd3, 0xac, 0x08, 0xfc, 0x5c, 0xf1, 0x84, 0x79, 0xd1, 0x4f, 0x82, 0xc5, 0x04, 0x9e, 0x89,
+	0x04, 0xa4, 0x38, 0xf8, 0xa1, 0xe2, 0xa1, 0x24, 0x12, 0x28, 0x40, 0x78, 0x2e, 0x22, 0x48, 0x1d,
+	0x61, 0x4b, 0xf1, 0xde, 0x4a, 0x21, 0xbc, 0xab, 0x44, 0x38, 0xd7, 0xf2, 0x10, 0xd9, 0x20, 0xb6,
+	0xe8, 0x5c, 0xe6, 0xbb, 0x8e, 0xed, 0x13, 0xea, 0xf5, 0xde, 0x47, 0xcc, 0x6b, 0x8d, 0x76, 0xb3,
+	0xdb, 0x9e, 0xe7, 0x78, 0xec, 0x49, 0x62, 0x24, 0xdf, 0x9f, 0xe9, 0x7c, 0xa7, 0xe3, 0x73, 0x4d,
+	0xf6, 0xdc, 0x7b, 0xf9, 0x9d, 0xa7, 0x6e, 0xff, 0xdf, 0x70, 0xee, 0x66, 0xdc, 0x25, 0xb3, 0xb9,
+	0xf9, 0x34, 0xff, 0xb0, 0x4c, 0xa5, 0x45, 0x7d, 0xb0, 0xbe, 0xe5, 0xae, 0x57, 0x84, 0x73, 0x2c,
+	0x38, 0xf9, 0x77, 0x00, 0x8b, 0x3a, 0x94, 0xd4, 0x9d, 0x17, 0x00, 0x00,
 }
@@ -0,0 +1,62 @@
+package rebalance
+
+import (
+	"errors"
+	"time"
+
+	"github.com/influxdata/influxdb/monitor/diagnostics"
+	"github.com/influxdata/influxdb/toml"
+)
+
+const (
+	// DefaultCheckInterval is the default interval between searches for
+	// shard groups to merge.
+	DefaultCheckInterval = 30 * time.Minute
+)
+
+// Config represents the configuration for the shard rebalance service.
+type Config struct {
+	Enabled       bool          `toml:"enabled"`
+	Dir           string        `toml:"dir"`
+	CheckInterval toml.Duration `toml:"check-interval"`
+}
+
+// NewConfig returns an instance of Config with defaults.
+func NewConfig() Config {
+	return Config{
+		Enabled:       false,
+		CheckInterval: toml.Duration(DefaultCheckInterval),
+	}
+}
+
+// Validate returns an error if the Config is invalid.
+func (c Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if c.Dir == "" {
+		return errors.New("shard-rebalance dir must be specified")
+	}
+
+	if c.CheckInterval <= 0 {
+		return errors.New("check-interval must be positive")
+	}
+
+	return nil
+}
+
+// Diagnostics returns a diagnostics representation of a subset of the Config.
+func (c Config) Diagnostics() (*diagnostics.Diagnostics, error) {
+	if !c.Enabled {
+		return diagnostics.RowFromMap(map[string]interface{}{
+			"enabled": false,
+		}), nil
+	}
+
+	return diagnostics.RowFromMap(map[string]interface{}{
+		"enabled":        true,
+		"dir":            c.Dir,
+		"check-interval": c.CheckInterval,
+	}), nil
+}
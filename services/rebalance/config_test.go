@@ -0,0 +1,51 @@
+package rebalance_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/influxdata/influxdb/services/rebalance"
+)
+
+func TestConfig_Parse(t *testing.T) {
+	// Parse configuration.
+	var c rebalance.Config
+	if _, err := toml.Decode(`
+enabled = true
+dir = "/tmp/rebalance"
+check-interval = "1s"
+`, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	// Validate configuration.
+	if c.Enabled != true {
+		t.Fatalf("unexpected enabled state: %v", c.Enabled)
+	} else if c.Dir != "/tmp/rebalance" {
+		t.Fatalf("unexpected dir: %s", c.Dir)
+	} else if time.Duration(c.CheckInterval) != time.Second {
+		t.Fatalf("unexpected check interval: %v", c.CheckInterval)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	c := rebalance.NewConfig()
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected validation fail from NewConfig: %s", err)
+	}
+
+	c = rebalance.NewConfig()
+	c.Enabled = true
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected error for missing dir, got nil")
+	}
+
+	c = rebalance.NewConfig()
+	c.Enabled = true
+	c.Dir = "/tmp/rebalance"
+	c.CheckInterval = 0
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected error for check-interval = 0, got nil")
+	}
+}
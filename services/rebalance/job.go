@@ -0,0 +1,233 @@
+package rebalance
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/influxdata/influxdb/services/meta"
+)
+
+// The phases of a job, in the order they run.  The phase of a job is persisted once
+// the previous phase completes, so a job interrupted by a crash resumes from the
+// start of the phase it was running.  Every phase can safely be run again.
+const (
+	// phaseMerge merges the TSM files of the source shards into the staging directory.
+	phaseMerge = "merge"
+
+	// phaseReserve reserves the target shard group in the meta store.  It receives
+	// no writes and isn't queried until the switch.
+	phaseReserve = "reserve"
+
+	// phaseImport imports the merged files, and any data written to the source
+	// shards while they were merged, into the target shard.
+	phaseImport = "import"
+
+	// phaseSwitch replaces the source shard groups with the target shard group
+	// in the meta store.
+	phaseSwitch = "switch"
+
+	// phaseVerify imports any data written to the source shards before the switch
+	// that hasn't been imported, until every file of the source shards has been,
+	// and then replays the deletes from the source shards on the target shard.
+	phaseVerify = "verify"
+
+	// phaseCleanup deletes the local source shards.
+	phaseCleanup = "cleanup"
+)
+
+// job merges the short shard groups within a single window of the shard group
+// duration of a retention policy into one shard group covering the window.
+type job struct {
+	Database        string    `json:"database"`
+	RetentionPolicy string    `json:"retention_policy"`
+	StartTime       time.Time `json:"start_time"`
+	EndTime         time.Time `json:"end_time"`
+
+	// ShardGroupIDs and ShardIDs are the source shard groups and their shards.
+	ShardGroupIDs []uint64 `json:"shard_group_ids"`
+	ShardIDs      []uint64 `json:"shard_ids"`
+
+	// Files are the names of the TSM files of each source shard that were merged
+	// or imported into the target shard.
+	Files map[uint64][]string `json:"files,omitempty"`
+
+	// ShardGroupID and ShardID are the target shard group and its shard, once the
+	// shard group has been reserved.
+	ShardGroupID uint64 `json:"shard_group_id,omitempty"`
+	ShardID      uint64 `json:"shard_id,omitempty"`
+
+	// Deletes are the deletes of series from the source shards while the job runs,
+	// the first DeletesReplayed of which have been replayed on the target shard.
+	// Values written to the target shard after a delete within the series and time
+	// range it deleted are deleted again when it is replayed.
+	Deletes         []deleteRecord `json:"deletes,omitempty"`
+	DeletesReplayed int            `json:"deletes_replayed,omitempty"`
+
+	Phase string `json:"phase"`
+
+	// BytesMerged is the size of the blocks merged so far out of BytesTotal, the
+	// size of the source TSM files.
+	BytesMerged int64 `json:"bytes_merged"`
+	BytesTotal  int64 `json:"bytes_total"`
+}
+
+// deleteRecord is a delete of the series of Measurements, or of every measurement if
+// it is empty, matching Condition.
+type deleteRecord struct {
+	Measurements []string `json:"measurements,omitempty"`
+	Condition    string   `json:"condition,omitempty"`
+}
+
+// hasSourceShard returns true if any of shardIDs is a source shard of j.
+func (j *job) hasSourceShard(shardIDs []uint64) bool {
+	for _, id := range shardIDs {
+		for _, src := range j.ShardIDs {
+			if id == src {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// progress returns the percentage of the job that has completed.  The merge is
+// by far the longest phase, so the progress of a job is that of its merge.  The
+// merge skips duplicate and deleted values so it may finish before reaching 100.
+func (j *job) progress() float64 {
+	if j.Phase != phaseMerge {
+		return 100
+	} else if j.BytesTotal == 0 {
+		return 0
+	}
+
+	p := float64(j.BytesMerged) / float64(j.BytesTotal) * 100
+	if p > 100 {
+		p = 100
+	}
+	return p
+}
+
+// planJob returns a job merging the shard groups of the first window of rpi that
+// needs it, or nil if there is none.  A window is merged once it has ended if it
+// contains at least two shard groups, none of which cross the boundaries of the
+// window, and every shard of those groups is stored locally.
+func planJob(database string, rpi *meta.RetentionPolicyInfo, localShardIDs map[uint64]struct{}, now time.Time) *job {
+	d := rpi.ShardGroupDuration
+	if d <= 0 {
+		return nil
+	}
+
+	type window struct {
+		groups  []meta.ShardGroupInfo
+		invalid bool
+	}
+
+	var starts []time.Time
+	windows := make(map[int64]*window)
+	lookup := func(start time.Time) *window {
+		w := windows[start.UnixNano()]
+		if w == nil {
+			w = &window{}
+			windows[start.UnixNano()] = w
+			starts = append(starts, start)
+		}
+		return w
+	}
+
+	// Shard groups are sorted by start time so windows are found in order.
+	for _, g := range rpi.ShardGroups {
+		if g.Deleted() {
+			continue
+		}
+
+		start := g.StartTime.Truncate(d).UTC()
+		w := lookup(start)
+
+		short := g.EndTime.Sub(g.StartTime) < d && !g.EndTime.After(start.Add(d))
+		if !short || g.Truncated() {
+			// The group either already has the target duration or overlaps
+			// other windows, so none of the windows it covers can be merged.
+			w.invalid = true
+			for t := start.Add(d); t.Before(g.EndTime); t = t.Add(d) {
+				lookup(t).invalid = true
+			}
+			continue
+		}
+
+		for _, sh := range g.Shards {
+			if _, ok := localShardIDs[sh.ID]; !ok {
+				w.invalid = true
+			}
+		}
+		w.groups = append(w.groups, g)
+	}
+
+	for _, start := range starts {
+		w := windows[start.UnixNano()]
+		end := start.Add(d)
+		if w.invalid || len(w.groups) < 2 || end.After(now) {
+			continue
+		}
+
+		j := &job{
+			Database:        database,
+			RetentionPolicy: rpi.Name,
+			StartTime:       start,
+			EndTime:         end,
+			Phase:           phaseMerge,
+		}
+		for _, g := range w.groups {
+			j.ShardGroupIDs = append(j.ShardGroupIDs, g.ID)
+			for _, sh := range g.Shards {
+				j.ShardIDs = append(j.ShardIDs, sh.ID)
+			}
+		}
+		return j
+	}
+	return nil
+}
+
+// readJobFile returns the job recorded at path, or nil if there is none.
+func readJobFile(path string) (*job, error) {
+	buf, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var j job
+	if err := json.Unmarshal(buf, &j); err != nil {
+		return nil, fmt.Errorf("invalid rebalance job file: %s", err)
+	}
+	return &j, nil
+}
+
+// writeJobFile atomically records j at path.
+func writeJobFile(path string, j *job) error {
+	buf, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
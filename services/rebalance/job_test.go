@@ -0,0 +1,75 @@
+package rebalance
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/services/meta"
+)
+
+func TestPlanJob(t *testing.T) {
+	day := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	group := func(id uint64, start time.Time, d time.Duration) meta.ShardGroupInfo {
+		return meta.ShardGroupInfo{
+			ID:        id,
+			StartTime: start,
+			EndTime:   start.Add(d),
+			Shards:    []meta.ShardInfo{{ID: id * 10}},
+		}
+	}
+
+	rpi := &meta.RetentionPolicyInfo{
+		Name:               "rp0",
+		ShardGroupDuration: 24 * time.Hour,
+		ShardGroups: []meta.ShardGroupInfo{
+			// The first day already has a group of the full duration.
+			group(1, day, 24*time.Hour),
+			// The second day has a group crossing into the third day.
+			group(2, day.Add(24*time.Hour), time.Hour),
+			group(3, day.Add(47*time.Hour), 2*time.Hour),
+			// The fourth day can be merged.
+			group(4, day.Add(72*time.Hour), time.Hour),
+			group(5, day.Add(73*time.Hour), time.Hour),
+			// The fifth day has only one group.
+			group(6, day.Add(96*time.Hour), time.Hour),
+		},
+	}
+	local := map[uint64]struct{}{10: {}, 20: {}, 30: {}, 40: {}, 50: {}, 60: {}}
+
+	j := planJob("db0", rpi, local, day.Add(30*24*time.Hour))
+	if j == nil {
+		t.Fatal("expected job")
+	}
+
+	exp := &job{
+		Database:        "db0",
+		RetentionPolicy: "rp0",
+		StartTime:       day.Add(72 * time.Hour),
+		EndTime:         day.Add(96 * time.Hour),
+		ShardGroupIDs:   []uint64{4, 5},
+		ShardIDs:        []uint64{40, 50},
+		Phase:           phaseMerge,
+	}
+	if !reflect.DeepEqual(j, exp) {
+		t.Fatalf("unexpected job:\ngot  %#v\nexp  %#v", j, exp)
+	}
+
+	// Windows that have not ended are not merged.
+	if j := planJob("db0", rpi, local, day.Add(95*time.Hour)); j != nil {
+		t.Fatalf("unexpected job: %#v", j)
+	}
+
+	// Windows with shards stored elsewhere are not merged.
+	delete(local, 50)
+	if j := planJob("db0", rpi, local, day.Add(30*24*time.Hour)); j != nil {
+		t.Fatalf("unexpected job: %#v", j)
+	}
+	local[50] = struct{}{}
+
+	// Deleted groups are ignored.
+	rpi.ShardGroups[4].DeletedAt = day
+	if j := planJob("db0", rpi, local, day.Add(30*24*time.Hour)); j != nil {
+		t.Fatalf("unexpected job: %#v", j)
+	}
+}
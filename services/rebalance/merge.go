@@ -0,0 +1,164 @@
+package rebalance
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/influxdata/influxdb/tsdb/engine/tsm1"
+)
+
+// maxTSMFileSize is the size at which a merged TSM file is closed and a new one
+// started.  It matches the limit used by compactions.
+const maxTSMFileSize = uint32(2048 * 1024 * 1024) // 2GB
+
+// openTSMFiles opens a reader for each TSM file in dir.  It returns the names of
+// the files along with the readers.
+func openTSMFiles(dir string) ([]string, []*tsm1.TSMReader, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*."+tsm1.TSMFileExtension))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names := make([]string, 0, len(paths))
+	readers := make([]*tsm1.TSMReader, 0, len(paths))
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			closeTSMReaders(readers)
+			return nil, nil, err
+		}
+
+		r, err := tsm1.NewTSMReader(f)
+		if err != nil {
+			f.Close()
+			closeTSMReaders(readers)
+			return nil, nil, fmt.Errorf("open %s: %s", path, err)
+		}
+		names = append(names, filepath.Base(path))
+		readers = append(readers, r)
+	}
+	return names, readers, nil
+}
+
+func closeTSMReaders(readers []*tsm1.TSMReader) {
+	for _, r := range readers {
+		r.Close()
+	}
+}
+
+// writeTSMFiles writes the blocks read from iter to new TSM files in dir, starting a
+// new file whenever the current one reaches its maximum number of blocks for a key or
+// its maximum size.  fn is called with the size of every block written.  It returns
+// the paths of the files written.
+func writeTSMFiles(dir string, iter tsm1.KeyIterator, fn func(n int)) ([]string, error) {
+	var files []string
+	for seq := 1; ; seq++ {
+		path := filepath.Join(dir, fmt.Sprintf("%09d.%s", seq, tsm1.TSMFileExtension))
+		n, more, err := writeTSMFile(path, iter, fn)
+		if err != nil {
+			return nil, err
+		} else if n > 0 {
+			files = append(files, path)
+		}
+
+		if !more {
+			return files, nil
+		}
+	}
+}
+
+// writeTSMFile writes blocks read from iter to a new TSM file at path until the file is
+// full or the iterator is exhausted.  It returns the number of blocks written and
+// whether the iterator has blocks remaining.  No file is left at path if no blocks
+// were written.
+func writeTSMFile(path string, iter tsm1.KeyIterator, fn func(n int)) (n int, more bool, err error) {
+	fd, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_EXCL, 0666)
+	if err != nil {
+		return 0, false, err
+	}
+
+	w, err := tsm1.NewTSMWriter(fd)
+	if err != nil {
+		fd.Close()
+		return 0, false, err
+	}
+	defer func() {
+		if err != nil || n == 0 {
+			w.Remove()
+			return
+		}
+		err = w.Close()
+	}()
+
+	for iter.Next() {
+		key, minTime, maxTime, block, err := iter.Read()
+		if err != nil {
+			return n, false, err
+		}
+
+		err = w.WriteBlock(key, minTime, maxTime, block)
+		if err != nil && err != tsm1.ErrMaxBlocksExceeded {
+			return n, false, err
+		}
+		n++
+		fn(len(block))
+
+		// The block has been written even if the key has reached its maximum
+		// number of blocks, so close out the file and continue in the next one.
+		if err == tsm1.ErrMaxBlocksExceeded || w.Size() > maxTSMFileSize {
+			return n, true, w.WriteIndex()
+		}
+	}
+
+	if err := iter.Err(); err != nil {
+		return n, false, err
+	}
+
+	if n == 0 {
+		return 0, false, nil
+	}
+	return n, false, w.WriteIndex()
+}
+
+// writeTar writes the files at paths to w as a tar archive that can be imported into
+// the shard at the relative path dir.
+func writeTar(w io.Writer, dir string, paths []string) error {
+	tw := tar.NewWriter(w)
+	for i, path := range paths {
+		// Files are renamed when imported, so only their order matters.
+		name := fmt.Sprintf("%09d.%s", i+1, tsm1.TSMFileExtension)
+		if err := writeTarFile(tw, filepath.ToSlash(filepath.Join(dir, name)), path); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+func writeTarFile(tw *tar.Writer, name, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0666,
+		Size:    fi.Size(),
+		ModTime: fi.ModTime(),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+
+	_, err = io.CopyN(tw, f, fi.Size())
+	return err
+}
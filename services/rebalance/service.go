@@ -0,0 +1,591 @@
+// Package rebalance provides a service that merges shard groups created before the
+// shard group duration of a retention policy was increased.
+package rebalance // import "github.com/influxdata/influxdb/services/rebalance"
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/monitor/diagnostics"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/engine/tsm1"
+	"github.com/influxdata/influxql"
+	"go.uber.org/zap"
+)
+
+const (
+	// jobFileName is the name of the file in the service directory recording the
+	// job in progress.
+	jobFileName = "job.json"
+
+	// stagingDirName is the name of the directory in the service directory that
+	// the merged TSM files are written to.
+	stagingDirName = "staging"
+)
+
+// Service represents the shard rebalance service.  It merges the shard groups of a
+// retention policy that are shorter than its shard group duration, one window of
+// the duration at a time, so that changing the duration of a retention policy also
+// applies to its existing data.
+type Service struct {
+	MetaClient interface {
+		Databases() []meta.DatabaseInfo
+		ReserveShardGroup(database, policy string, timestamp time.Time) (*meta.ShardGroupInfo, error)
+		ReplaceShardGroups(database, policy string, ids []uint64, id uint64) error
+	}
+	TSDBStore interface {
+		ShardIDs() []uint64
+		CreateShard(database, policy string, shardID uint64, enabled bool) error
+		CreateShardSnapshot(id uint64) (string, error)
+		RemoveShardSnapshot(id uint64, path string) error
+		ShardRelativePath(id uint64) (string, error)
+		ImportShard(id uint64, r io.Reader) error
+		DeleteShard(shardID uint64) error
+		DeleteShardSeries(database string, shardIDs []uint64, sources []influxql.Source, condition influxql.Expr) error
+		OnDeleteSeries(fn func(database string, shardIDs []uint64, sources []influxql.Source, condition influxql.Expr) error)
+	}
+	Monitor interface {
+		RegisterDiagnosticsClient(name string, client diagnostics.Client)
+		DeregisterDiagnosticsClient(name string)
+	}
+
+	config Config
+	wg     sync.WaitGroup
+	done   chan struct{}
+
+	mu  sync.Mutex
+	job *job // the job in progress, if any
+
+	logger *zap.Logger
+}
+
+// NewService returns a configured shard rebalance service.
+func NewService(c Config) *Service {
+	return &Service{
+		config: c,
+		logger: zap.NewNop(),
+	}
+}
+
+// Open loads any interrupted job and starts merging shard groups.
+func (s *Service) Open() error {
+	if !s.config.Enabled || s.done != nil {
+		return nil
+	}
+
+	s.logger.Info(fmt.Sprint("Starting shard rebalance service with check interval of ", s.config.CheckInterval))
+
+	if err := os.MkdirAll(s.config.Dir, 0777); err != nil {
+		return err
+	}
+
+	j, err := readJobFile(s.jobPath())
+	if err != nil {
+		return err
+	}
+	s.job = j
+
+	// Deletes from the source shards of a job are recorded so they can be replayed
+	// on the target shard.
+	s.TSDBStore.OnDeleteSeries(s.recordDelete)
+
+	if s.Monitor != nil {
+		s.Monitor.RegisterDiagnosticsClient("shard-rebalance", s)
+	}
+
+	s.done = make(chan struct{})
+
+	s.wg.Add(1)
+	go func() { defer s.wg.Done(); s.run() }()
+	return nil
+}
+
+// Close stops merging shard groups.  A job in progress is resumed when the
+// service is next opened.
+func (s *Service) Close() error {
+	if !s.config.Enabled || s.done == nil {
+		return nil
+	}
+
+	s.logger.Info("Shard rebalance service closing.")
+	close(s.done)
+
+	s.wg.Wait()
+	s.done = nil
+
+	s.TSDBStore.OnDeleteSeries(nil)
+
+	if s.Monitor != nil {
+		s.Monitor.DeregisterDiagnosticsClient("shard-rebalance")
+	}
+	return nil
+}
+
+// WithLogger sets the logger on the service.
+func (s *Service) WithLogger(log *zap.Logger) {
+	s.logger = log.With(zap.String("service", "shard-rebalance"))
+}
+
+// Diagnostics returns the progress of the job in progress.
+func (s *Service) Diagnostics() (*diagnostics.Diagnostics, error) {
+	d := diagnostics.NewDiagnostics([]string{"database", "retention_policy", "start_time", "end_time", "source_shards", "target_shard", "phase", "progress"})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if j := s.job; j != nil {
+		ids := make([]string, len(j.ShardIDs))
+		for i, id := range j.ShardIDs {
+			ids[i] = fmt.Sprint(id)
+		}
+		d.AddRow([]interface{}{j.Database, j.RetentionPolicy, j.StartTime, j.EndTime, strings.Join(ids, ","), j.ShardID, j.Phase, j.progress()})
+	}
+	return d, nil
+}
+
+func (s *Service) run() {
+	ticker := time.NewTicker(time.Duration(s.config.CheckInterval))
+	defer ticker.Stop()
+	for {
+		// Resume any interrupted job straight away.
+		s.rebalance()
+
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// rebalance runs the job in progress, if any, followed by a job for every window
+// that needs to be merged until there are none left.
+func (s *Service) rebalance() {
+	for {
+		select {
+		case <-s.done:
+			return
+		default:
+		}
+
+		s.mu.Lock()
+		j := s.job
+		s.mu.Unlock()
+
+		if j == nil {
+			if j = s.nextJob(); j == nil {
+				return
+			}
+			if err := s.startJob(j); err != nil {
+				s.logger.Info(fmt.Sprintf("Failed to start merging shard groups of database %s, retention policy %s: %v. Will retry in %v", j.Database, j.RetentionPolicy, err, s.config.CheckInterval))
+				return
+			}
+			s.logger.Info(fmt.Sprintf("Merging shard groups %v of database %s, retention policy %s from %s to %s.", j.ShardGroupIDs, j.Database, j.RetentionPolicy, j.StartTime, j.EndTime))
+		}
+
+		if err := s.runJob(j); err != nil {
+			s.logger.Info(fmt.Sprintf("Failed to merge shard groups %v of database %s, retention policy %s during %s phase: %v. Will retry in %v", j.ShardGroupIDs, j.Database, j.RetentionPolicy, j.Phase, err, s.config.CheckInterval))
+			return
+		}
+		s.logger.Info(fmt.Sprintf("Merged shard groups %v of database %s, retention policy %s into shard %d.", j.ShardGroupIDs, j.Database, j.RetentionPolicy, j.ShardID))
+	}
+}
+
+// nextJob returns the next job to run, or nil if no shard groups need to be merged.
+func (s *Service) nextJob() *job {
+	localShardIDs := make(map[uint64]struct{})
+	for _, id := range s.TSDBStore.ShardIDs() {
+		localShardIDs[id] = struct{}{}
+	}
+
+	now := time.Now().UTC()
+	for _, d := range s.MetaClient.Databases() {
+		for i := range d.RetentionPolicies {
+			if j := planJob(d.Name, &d.RetentionPolicies[i], localShardIDs, now); j != nil {
+				return j
+			}
+		}
+	}
+	return nil
+}
+
+// startJob records j as the job in progress.
+func (s *Service) startJob(j *job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := writeJobFile(s.jobPath(), j); err != nil {
+		return err
+	}
+	s.job = j
+	return nil
+}
+
+// runJob runs each of the remaining phases of j.
+func (s *Service) runJob(j *job) error {
+	for {
+		var err error
+		switch j.Phase {
+		case phaseMerge:
+			err = s.merge(j)
+		case phaseReserve:
+			err = s.reserveShardGroup(j)
+		case phaseImport:
+			err = s.importShard(j)
+		case phaseSwitch:
+			err = s.switchShardGroups(j)
+		case phaseVerify:
+			err = s.verify(j)
+		case phaseCleanup:
+			return s.cleanup(j)
+		default:
+			return fmt.Errorf("unknown rebalance phase: %q", j.Phase)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// advance records that j has moved on to phase.
+func (s *Service) advance(j *job, phase string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.setPhase(j, phase)
+}
+
+// setPhase records that j has moved on to phase.  s.mu must be held.
+func (s *Service) setPhase(j *job, phase string) error {
+	prev := j.Phase
+	j.Phase = phase
+	if err := writeJobFile(s.jobPath(), j); err != nil {
+		j.Phase = prev
+		return err
+	}
+	return nil
+}
+
+// merge merges a snapshot of the TSM files of every source shard into new TSM
+// files in the staging directory.
+func (s *Service) merge(j *job) error {
+	dir := s.stagingPath()
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return err
+	}
+
+	var total int64
+	files := make(map[uint64][]string, len(j.ShardIDs))
+	var readers []*tsm1.TSMReader
+	for _, id := range j.ShardIDs {
+		path, err := s.TSDBStore.CreateShardSnapshot(id)
+		if err != nil {
+			closeTSMReaders(readers)
+			return err
+		}
+		defer s.TSDBStore.RemoveShardSnapshot(id, path)
+
+		names, rs, err := openTSMFiles(path)
+		if err != nil {
+			closeTSMReaders(readers)
+			return err
+		}
+
+		for _, r := range rs {
+			total += int64(r.Size())
+		}
+		files[id] = names
+		readers = append(readers, rs...)
+	}
+
+	s.mu.Lock()
+	j.Files, j.BytesMerged, j.BytesTotal = files, 0, total
+	s.mu.Unlock()
+
+	// The iterator closes the readers.
+	iter, err := tsm1.NewTSMKeyIterator(tsdb.DefaultMaxPointsPerBlock, false, s.done, readers...)
+	if err != nil {
+		closeTSMReaders(readers)
+		return err
+	}
+	defer iter.Close()
+
+	if _, err := writeTSMFiles(dir, iter, func(n int) {
+		s.mu.Lock()
+		j.BytesMerged += int64(n)
+		s.mu.Unlock()
+	}); err != nil {
+		return err
+	}
+	return s.advance(j, phaseReserve)
+}
+
+// reserveShardGroup reserves the target shard group.  The source shard groups keep
+// receiving writes and being queried until the switch.
+func (s *Service) reserveShardGroup(j *job) error {
+	sgi, err := s.MetaClient.ReserveShardGroup(j.Database, j.RetentionPolicy, j.StartTime)
+	if err != nil {
+		return err
+	} else if len(sgi.Shards) == 0 || sgi.StartTime.After(j.StartTime) || sgi.EndTime.Before(j.EndTime) {
+		return fmt.Errorf("shard group %d does not cover %s to %s", sgi.ID, j.StartTime, j.EndTime)
+	}
+
+	s.mu.Lock()
+	j.ShardGroupID, j.ShardID = sgi.ID, sgi.Shards[0].ID
+	s.mu.Unlock()
+
+	return s.advance(j, phaseImport)
+}
+
+// importShard imports the merged files into the target shard, along with any TSM
+// files of the source shards that were not merged, which contain data written
+// while the merge was running.
+func (s *Service) importShard(j *job) error {
+	if err := s.TSDBStore.CreateShard(j.Database, j.RetentionPolicy, j.ShardID, true); err != nil {
+		return err
+	}
+
+	paths, err := filepath.Glob(filepath.Join(s.stagingPath(), "*."+tsm1.TSMFileExtension))
+	if err != nil {
+		return err
+	}
+	if _, err := s.importSourceFiles(j, paths); err != nil {
+		return err
+	}
+	return s.advance(j, phaseSwitch)
+}
+
+// switchShardGroups replaces the source shard groups with the target shard group,
+// moving writes and queries to the target shard at once.
+func (s *Service) switchShardGroups(j *job) error {
+	if err := s.MetaClient.ReplaceShardGroups(j.Database, j.RetentionPolicy, j.ShardGroupIDs, j.ShardGroupID); err != nil {
+		return err
+	}
+	return s.advance(j, phaseVerify)
+}
+
+// verify imports the files of the source shards holding data written before the
+// switch that haven't been imported, and replays the deletes from the source shards
+// on the target shard.  The source shards are only deleted once a snapshot of every
+// source shard finds nothing left to import, every delete has been replayed, and the
+// target shard exists.
+func (s *Service) verify(j *job) error {
+	n, err := s.importSourceFiles(j, nil)
+	if err != nil {
+		return err
+	} else if n > 0 {
+		// Check the source shards again, since more writes may have completed.
+		return nil
+	}
+
+	var found bool
+	for _, id := range s.TSDBStore.ShardIDs() {
+		if id == j.ShardID {
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("target shard %d not found", j.ShardID)
+	}
+
+	if err := s.replayDeletes(j); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if j.DeletesReplayed < len(j.Deletes) {
+		// Replay the deletes recorded during the replay.
+		return nil
+	}
+	return s.setPhase(j, phaseCleanup)
+}
+
+// recordDelete records a delete of series from the shards of a database in the job
+// in progress, if the delete includes any of its source shards.  The delete fails if
+// it can't be recorded, so it is never lost from the target shard.
+func (s *Service) recordDelete(database string, shardIDs []uint64, sources []influxql.Source, condition influxql.Expr) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j := s.job
+	if j == nil || j.Database != database || j.Phase == phaseCleanup || !j.hasSourceShard(shardIDs) {
+		return nil
+	}
+
+	var d deleteRecord
+	for _, src := range sources {
+		if m, ok := src.(*influxql.Measurement); ok {
+			d.Measurements = append(d.Measurements, m.Name)
+		}
+	}
+	if condition != nil {
+		d.Condition = condition.String()
+	}
+
+	prev := j.Deletes
+	j.Deletes = append(j.Deletes[:len(j.Deletes):len(j.Deletes)], d)
+	if err := writeJobFile(s.jobPath(), j); err != nil {
+		j.Deletes = prev
+		return err
+	}
+	return nil
+}
+
+// replayDeletes deletes the series deleted from the source shards of j from the target
+// shard, since the files imported from the source shards may still hold their values.
+func (s *Service) replayDeletes(j *job) error {
+	s.mu.Lock()
+	deletes := j.Deletes[j.DeletesReplayed:]
+	s.mu.Unlock()
+
+	if len(deletes) == 0 {
+		return nil
+	}
+
+	for _, d := range deletes {
+		var sources []influxql.Source
+		for _, name := range d.Measurements {
+			sources = append(sources, &influxql.Measurement{Name: name})
+		}
+
+		var condition influxql.Expr
+		if d.Condition != "" {
+			expr, err := influxql.ParseExpr(d.Condition)
+			if err != nil {
+				return err
+			}
+			condition = expr
+		}
+
+		if err := s.TSDBStore.DeleteShardSeries(j.Database, []uint64{j.ShardID}, sources, condition); err != nil {
+			return err
+		}
+	}
+
+	// Record the deletes replayed, so a resumed job doesn't replay them again.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev := j.DeletesReplayed
+	j.DeletesReplayed += len(deletes)
+	if err := writeJobFile(s.jobPath(), j); err != nil {
+		j.DeletesReplayed = prev
+		return err
+	}
+	return nil
+}
+
+// importSourceFiles imports paths and the TSM files of the source shards that are
+// not yet in the target shard into it, and records the files imported.  It returns
+// the number of files of the source shards imported.
+func (s *Service) importSourceFiles(j *job, paths []string) (int, error) {
+	s.mu.Lock()
+	files := make(map[uint64][]string, len(j.ShardIDs))
+	for id, names := range j.Files {
+		files[id] = append([]string(nil), names...)
+	}
+	s.mu.Unlock()
+
+	var n int
+	for _, id := range j.ShardIDs {
+		path, err := s.TSDBStore.CreateShardSnapshot(id)
+		if err == tsdb.ErrShardNotFound {
+			continue
+		} else if err != nil {
+			return 0, err
+		}
+		defer s.TSDBStore.RemoveShardSnapshot(id, path)
+
+		imported := make(map[string]struct{}, len(files[id]))
+		for _, name := range files[id] {
+			imported[name] = struct{}{}
+		}
+
+		snapshot, err := filepath.Glob(filepath.Join(path, "*."+tsm1.TSMFileExtension))
+		if err != nil {
+			return 0, err
+		}
+		for _, p := range snapshot {
+			if _, ok := imported[filepath.Base(p)]; !ok {
+				paths = append(paths, p)
+				files[id] = append(files[id], filepath.Base(p))
+				n++
+			}
+		}
+	}
+
+	if len(paths) == 0 {
+		return 0, nil
+	}
+
+	rel, err := s.TSDBStore.ShardRelativePath(j.ShardID)
+	if err != nil {
+		return 0, err
+	}
+
+	pr, pw := io.Pipe()
+	errC := make(chan error, 1)
+	go func() {
+		err := writeTar(pw, rel, paths)
+		pw.CloseWithError(err)
+		errC <- err
+	}()
+
+	err = s.TSDBStore.ImportShard(j.ShardID, pr)
+	pr.CloseWithError(io.ErrClosedPipe)
+	if werr := <-errC; err == nil && werr != nil {
+		err = werr
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// Record the files imported, so a resumed job doesn't import them again.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev := j.Files
+	j.Files = files
+	if err := writeJobFile(s.jobPath(), j); err != nil {
+		j.Files = prev
+		return 0, err
+	}
+	return n, nil
+}
+
+// cleanup deletes the source shards and the staging directory, completing j.
+func (s *Service) cleanup(j *job) error {
+	for _, id := range j.ShardIDs {
+		if err := s.TSDBStore.DeleteShard(id); err != nil {
+			return err
+		}
+	}
+
+	if err := os.RemoveAll(s.stagingPath()); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.jobPath()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	s.job = nil
+	return nil
+}
+
+func (s *Service) jobPath() string { return filepath.Join(s.config.Dir, jobFileName) }
+
+func (s *Service) stagingPath() string { return filepath.Join(s.config.Dir, stagingDirName) }
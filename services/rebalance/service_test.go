@@ -0,0 +1,425 @@
+package rebalance_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/internal"
+	"github.com/influxdata/influxdb/logger"
+	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/services/rebalance"
+	"github.com/influxdata/influxdb/toml"
+	"github.com/influxdata/influxdb/tsdb/engine/tsm1"
+	"github.com/influxdata/influxql"
+)
+
+func TestService_OpenDisabled(t *testing.T) {
+	// Opening a disabled service should be a no-op.
+	s := NewService(rebalance.NewConfig())
+
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	if s.LogBuf.String() != "" {
+		t.Fatalf("service logged %q, didn't expect any logging", s.LogBuf.String())
+	}
+}
+
+func TestService_Rebalance(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	c := rebalance.NewConfig()
+	c.Enabled = true
+	c.Dir = filepath.Join(dir, "rebalance")
+	c.CheckInterval = toml.Duration(time.Millisecond)
+	s := NewService(c)
+
+	start := time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+	key := []byte("cpu,host=A#!~#value")
+
+	var mu sync.Mutex
+	rpi := meta.RetentionPolicyInfo{
+		Name:               "rp0",
+		ShardGroupDuration: 24 * time.Hour,
+		ShardGroups: []meta.ShardGroupInfo{
+			{ID: 1, StartTime: start, EndTime: start.Add(time.Hour), Shards: []meta.ShardInfo{{ID: 1}}},
+			{ID: 2, StartTime: start.Add(time.Hour), EndTime: start.Add(2 * time.Hour), Shards: []meta.ShardInfo{{ID: 2}}},
+		},
+	}
+	local := map[uint64]bool{1: true, 2: true}
+
+	s.MetaClient.DatabasesFn = func() []meta.DatabaseInfo {
+		mu.Lock()
+		defer mu.Unlock()
+		r := rpi
+		r.ShardGroups = append([]meta.ShardGroupInfo(nil), rpi.ShardGroups...)
+		return []meta.DatabaseInfo{{Name: "db0", RetentionPolicies: []meta.RetentionPolicyInfo{r}}}
+	}
+	s.MetaClient.ReserveShardGroupFn = func(database, policy string, timestamp time.Time) (*meta.ShardGroupInfo, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !timestamp.Equal(start) {
+			return nil, fmt.Errorf("unexpected timestamp: %s", timestamp)
+		}
+		sgi := meta.ShardGroupInfo{ID: 3, StartTime: start, EndTime: start.Add(24 * time.Hour), DeletedAt: time.Now(), Shards: []meta.ShardInfo{{ID: 3}}}
+		rpi.ShardGroups = append(rpi.ShardGroups, sgi)
+		return &sgi, nil
+	}
+	var switched bool
+	s.MetaClient.ReplaceShardGroupsFn = func(database, policy string, ids []uint64, id uint64) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if !reflect.DeepEqual(ids, []uint64{1, 2}) || id != 3 {
+			return fmt.Errorf("unexpected shard groups: %v, %d", ids, id)
+		}
+		for i := range rpi.ShardGroups {
+			if rpi.ShardGroups[i].ID == id {
+				rpi.ShardGroups[i].DeletedAt = time.Time{}
+			} else {
+				rpi.ShardGroups[i].DeletedAt = time.Now()
+			}
+		}
+		switched = true
+		return nil
+	}
+
+	s.TSDBStore.ShardIDsFn = func() []uint64 {
+		mu.Lock()
+		defer mu.Unlock()
+		var ids []uint64
+		for id := range local {
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	// The second snapshot of shard 2 contains a point written after the merge.
+	snapshots := make(map[uint64]int)
+	s.TSDBStore.CreateShardSnapshotFn = func(id uint64) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		snapshots[id]++
+
+		path := filepath.Join(dir, fmt.Sprintf("snapshot-%d-%d", id, snapshots[id]))
+		if err := os.MkdirAll(path, 0777); err != nil {
+			return "", err
+		}
+
+		ts := start.Add(time.Duration(id-1) * time.Hour)
+		if err := WriteTSM(filepath.Join(path, "000000001-000000001.tsm"), key, tsm1.Values{
+			tsm1.NewValue(ts.UnixNano(), float64(id)),
+			tsm1.NewValue(ts.Add(time.Minute).UnixNano(), float64(id)),
+		}); err != nil {
+			return "", err
+		}
+		if id == 2 && snapshots[id] > 1 {
+			if err := WriteTSM(filepath.Join(path, "000000002-000000001.tsm"), key, tsm1.Values{
+				tsm1.NewValue(ts.Add(2*time.Minute).UnixNano(), float64(id)),
+			}); err != nil {
+				return "", err
+			}
+		}
+		return path, nil
+	}
+	removed := make(map[string]bool)
+	s.TSDBStore.RemoveShardSnapshotFn = func(id uint64, path string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		removed[path] = true
+		return os.RemoveAll(path)
+	}
+	// A delete from a source shard while the job runs is replayed on the target shard.
+	cond := `host = 'A' AND time < '1980-01-01T01:30:00Z'`
+	s.TSDBStore.CreateShardFn = func(database, policy string, shardID uint64, enabled bool) error {
+		if database != "db0" || policy != "rp0" || shardID != 3 {
+			return fmt.Errorf("unexpected shard: %s.%s %d", database, policy, shardID)
+		}
+		if err := s.DeleteSeries("db0", []uint64{2}, "cpu", cond); err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		local[shardID] = true
+		return nil
+	}
+	var replayed []string
+	s.TSDBStore.DeleteShardSeriesFn = func(database string, shardIDs []uint64, sources []influxql.Source, condition influxql.Expr) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if !switched {
+			return fmt.Errorf("delete replayed before switch")
+		}
+		replayed = append(replayed, fmt.Sprintf("%s %v %s %s", database, shardIDs, influxql.Sources(sources), condition))
+		return nil
+	}
+	s.TSDBStore.ShardRelativePathFn = func(id uint64) (string, error) {
+		return filepath.Join("db0", "rp0", fmt.Sprint(id)), nil
+	}
+
+	var imported tsm1.Values
+	s.TSDBStore.ImportShardFn = func(id uint64, r io.Reader) error {
+		// The source shard groups stay live until the import completes.
+		mu.Lock()
+		sw := switched
+		mu.Unlock()
+		if sw {
+			return fmt.Errorf("shard groups switched before import")
+		}
+
+		tr := tar.NewReader(r)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+
+			path := filepath.Join(dir, "import.tsm")
+			buf, err := ioutil.ReadAll(tr)
+			if err != nil {
+				return err
+			} else if err := ioutil.WriteFile(path, buf, 0666); err != nil {
+				return err
+			}
+
+			values, err := ReadTSM(path, key)
+			if err != nil {
+				return fmt.Errorf("%s: %s", hdr.Name, err)
+			}
+			imported = append(imported, values...)
+		}
+	}
+
+	deleted := make(chan uint64, 2)
+	s.TSDBStore.DeleteShardFn = func(id uint64) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if local[id] {
+			delete(local, id)
+			deleted <- id
+		}
+		return nil
+	}
+
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-deleted:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for rebalance")
+		}
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := tsm1.Values{
+		tsm1.NewValue(start.UnixNano(), float64(1)),
+		tsm1.NewValue(start.Add(time.Minute).UnixNano(), float64(1)),
+		tsm1.NewValue(start.Add(time.Hour).UnixNano(), float64(2)),
+		tsm1.NewValue(start.Add(time.Hour+time.Minute).UnixNano(), float64(2)),
+		tsm1.NewValue(start.Add(time.Hour+2*time.Minute).UnixNano(), float64(2)),
+	}
+	if !reflect.DeepEqual(imported, exp) {
+		t.Fatalf("unexpected values imported:\ngot  %v\nexp  %v", imported, exp)
+	}
+
+	if exp := []string{"db0 [3] cpu " + cond}; !reflect.DeepEqual(replayed, exp) {
+		t.Fatalf("unexpected deletes replayed:\ngot  %v\nexp  %v", replayed, exp)
+	}
+
+	for _, id := range []uint64{1, 2} {
+		if !rpi.ShardGroups[id-1].Deleted() {
+			t.Fatalf("shard group %d not deleted", id)
+		}
+	}
+	if rpi.ShardGroups[2].Deleted() {
+		t.Fatal("target shard group not live")
+	}
+
+	if _, err := os.Stat(filepath.Join(c.Dir, "job.json")); !os.IsNotExist(err) {
+		t.Fatalf("expected job file to be removed: %v", err)
+	}
+
+	// Snapshots are removed through the store so their cold tier links are removed too.
+	for id, n := range snapshots {
+		for i := 1; i <= n; i++ {
+			if path := filepath.Join(dir, fmt.Sprintf("snapshot-%d-%d", id, i)); !removed[path] {
+				t.Fatalf("snapshot not removed: %s", path)
+			}
+		}
+	}
+}
+
+func TestService_Rebalance_Resume(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	c := rebalance.NewConfig()
+	c.Enabled = true
+	c.Dir = dir
+	c.CheckInterval = toml.Duration(time.Hour)
+	s := NewService(c)
+
+	// A job that was interrupted after its shard groups were switched only
+	// needs to clean up the source shards.
+	if err := ioutil.WriteFile(filepath.Join(dir, "job.json"), []byte(`{"database":"db0","retention_policy":"rp0","shard_group_ids":[1,2],"shard_ids":[1,2],"shard_id":3,"phase":"cleanup"}`), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	deleted := make(chan uint64, 2)
+	s.TSDBStore.DeleteShardFn = func(id uint64) error {
+		deleted <- id
+		return nil
+	}
+	s.TSDBStore.ShardIDsFn = func() []uint64 { return nil }
+	s.MetaClient.DatabasesFn = func() []meta.DatabaseInfo { return nil }
+
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	for _, exp := range []uint64{1, 2} {
+		select {
+		case id := <-deleted:
+			if id != exp {
+				t.Fatalf("unexpected shard deleted: %d", id)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for rebalance")
+		}
+	}
+}
+
+func TestService_Diagnostics(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	c := rebalance.NewConfig()
+	c.Dir = dir
+	s := NewService(c)
+
+	d, err := s.Diagnostics()
+	if err != nil {
+		t.Fatal(err)
+	} else if len(d.Rows) != 0 {
+		t.Fatalf("unexpected rows: %v", d.Rows)
+	}
+
+	exp := []string{"database", "retention_policy", "start_time", "end_time", "source_shards", "target_shard", "phase", "progress"}
+	if !reflect.DeepEqual(d.Columns, exp) {
+		t.Fatalf("unexpected columns: %v", d.Columns)
+	}
+}
+
+type Service struct {
+	MetaClient *internal.MetaClientMock
+	TSDBStore  *internal.TSDBStoreMock
+
+	mu             sync.Mutex
+	onDeleteSeries func(database string, shardIDs []uint64, sources []influxql.Source, condition influxql.Expr) error
+
+	LogBuf bytes.Buffer
+	*rebalance.Service
+}
+
+func NewService(c rebalance.Config) *Service {
+	s := &Service{
+		MetaClient: &internal.MetaClientMock{},
+		TSDBStore:  &internal.TSDBStoreMock{},
+		Service:    rebalance.NewService(c),
+	}
+
+	l := logger.New(&s.LogBuf)
+	s.WithLogger(l)
+
+	s.TSDBStore.OnDeleteSeriesFn = func(fn func(database string, shardIDs []uint64, sources []influxql.Source, condition influxql.Expr) error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.onDeleteSeries = fn
+	}
+
+	s.Service.MetaClient = s.MetaClient
+	s.Service.TSDBStore = s.TSDBStore
+	return s
+}
+
+// DeleteSeries notifies the service of a delete of the series of measurement matching
+// condition from the given shards, as the store does.
+func (s *Service) DeleteSeries(database string, shardIDs []uint64, measurement, condition string) error {
+	expr, err := influxql.ParseExpr(condition)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	fn := s.onDeleteSeries
+	s.mu.Unlock()
+
+	if fn == nil {
+		return fmt.Errorf("service not notified of deletes")
+	}
+	return fn(database, shardIDs, []influxql.Source{&influxql.Measurement{Name: measurement}}, expr)
+}
+
+// MustTempDir returns a new temporary directory.
+func MustTempDir() string {
+	dir, err := ioutil.TempDir("", "rebalance-test")
+	if err != nil {
+		panic(err)
+	}
+	return dir
+}
+
+// WriteTSM writes values for key to a new TSM file at path.
+func WriteTSM(path string, key []byte, values tsm1.Values) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	w, err := tsm1.NewTSMWriter(f)
+	if err != nil {
+		return err
+	}
+	if err := w.Write(key, values); err != nil {
+		return err
+	}
+	if err := w.WriteIndex(); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// ReadTSM returns all of the values of key in the TSM file at path.
+func ReadTSM(path string, key []byte) (tsm1.Values, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := tsm1.NewTSMReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return r.ReadAll(key)
+}
@@ -2,16 +2,97 @@ package retention
 
 import (
 	"errors"
+	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/influxdata/influxdb/monitor/diagnostics"
 	"github.com/influxdata/influxdb/toml"
+	"github.com/influxdata/influxql"
 )
 
 // Config represents the configuration for the retention service.
 type Config struct {
 	Enabled       bool          `toml:"enabled"`
 	CheckInterval toml.Duration `toml:"check-interval"`
+
+	// Rules expire the data of matching series before the retention policy
+	// of their shards does.
+	Rules []Rule `toml:"rule"`
+}
+
+// Rule is a retention rule that deletes the data of matching series once it is
+// older than MaxAge.
+type Rule struct {
+	Database string `toml:"database"`
+
+	// Measurement is the name of the measurement, or a regular expression between
+	// slashes such as "/^debug_/".  An empty value matches every measurement.
+	Measurement string `toml:"measurement"`
+
+	// Where is an InfluxQL condition on the tags of the series, such as
+	// "level = 'debug'".  An empty value matches every series of the measurement.
+	Where string `toml:"where"`
+
+	MaxAge toml.Duration `toml:"max-age"`
+}
+
+// Source returns the measurement source matched by the rule, or nil if it matches
+// every measurement.
+func (r Rule) Source() (influxql.Source, error) {
+	if r.Measurement == "" {
+		return nil, nil
+	}
+
+	if len(r.Measurement) > 1 && strings.HasPrefix(r.Measurement, "/") && strings.HasSuffix(r.Measurement, "/") {
+		re, err := regexp.Compile(r.Measurement[1 : len(r.Measurement)-1])
+		if err != nil {
+			return nil, err
+		}
+		return &influxql.Measurement{Regex: &influxql.RegexLiteral{Val: re}}, nil
+	}
+	return &influxql.Measurement{Name: r.Measurement}, nil
+}
+
+// Condition returns the tag condition of the rule, or nil if it matches every series.
+func (r Rule) Condition() (influxql.Expr, error) {
+	if r.Where == "" {
+		return nil, nil
+	}
+
+	expr, err := influxql.ParseExpr(r.Where)
+	if err != nil {
+		return nil, err
+	}
+
+	var hasTime bool
+	influxql.WalkFunc(expr, func(n influxql.Node) {
+		if ref, ok := n.(*influxql.VarRef); ok && strings.ToLower(ref.Val) == "time" {
+			hasTime = true
+		}
+	})
+	if hasTime {
+		return nil, errors.New("time is not allowed in the where condition")
+	}
+	return expr, nil
+}
+
+// Validate returns an error if the rule is invalid.
+func (r Rule) Validate() error {
+	if r.Database == "" {
+		return errors.New("database must be set")
+	} else if r.MaxAge <= 0 {
+		return errors.New("max-age must be positive")
+	}
+
+	if _, err := r.Source(); err != nil {
+		return fmt.Errorf("invalid measurement: %s", err)
+	}
+	if _, err := r.Condition(); err != nil {
+		return fmt.Errorf("invalid where condition: %s", err)
+	}
+	return nil
 }
 
 // NewConfig returns an instance of Config with defaults.
@@ -31,6 +112,12 @@ func (c Config) Validate() error {
 		return errors.New("check-interval must be positive")
 	}
 
+	for i, r := range c.Rules {
+		if err := r.Validate(); err != nil {
+			return fmt.Errorf("rule %d: %s", i, err)
+		}
+	}
+
 	return nil
 }
 
@@ -45,5 +132,6 @@ func (c Config) Diagnostics() (*diagnostics.Diagnostics, error) {
 	return diagnostics.RowFromMap(map[string]interface{}{
 		"enabled":        true,
 		"check-interval": c.CheckInterval,
+		"rules":          len(c.Rules),
 	}), nil
 }
@@ -26,6 +26,36 @@ check-interval = "1s"
 	}
 }
 
+func TestConfig_Parse_Rules(t *testing.T) {
+	var c retention.Config
+	if _, err := toml.Decode(`
+enabled = true
+check-interval = "1s"
+
+[[rule]]
+database = "db0"
+measurement = "/^debug_/"
+where = "level = 'debug'"
+max-age = "24h"
+`, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Validate(); err != nil {
+		t.Fatal(err)
+	} else if len(c.Rules) != 1 {
+		t.Fatalf("unexpected rules: %#v", c.Rules)
+	} else if r := c.Rules[0]; r.Database != "db0" || time.Duration(r.MaxAge) != 24*time.Hour {
+		t.Fatalf("unexpected rule: %#v", r)
+	}
+
+	// Rules may not delete by time themselves.
+	c.Rules[0].Where = "time > now() - 1h"
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected error for time in where condition, got nil")
+	}
+}
+
 func TestConfig_Validate(t *testing.T) {
 	c := retention.NewConfig()
 	if err := c.Validate(); err != nil {
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/influxdata/influxdb/services/meta"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxql"
 	"go.uber.org/zap"
 )
 
@@ -20,12 +22,19 @@ type Service struct {
 	TSDBStore interface {
 		ShardIDs() []uint64
 		DeleteShard(shardID uint64) error
+		SetShardRollupRule(shardID uint64, rule *tsdb.RollupRule) error
+		DeleteShardSeries(database string, shardIDs []uint64, sources []influxql.Source, condition influxql.Expr) error
+		ShardLastModified(id uint64) time.Time
 	}
 
 	config Config
 	wg     sync.WaitGroup
 	done   chan struct{}
 
+	// expired holds the last expiry of each rule, by the index of the rule.  It is only
+	// used by the enforcement goroutine.
+	expired map[int]ruleExpiry
+
 	logger *zap.Logger
 }
 
@@ -117,9 +126,134 @@ func (s *Service) run() {
 				}
 			}
 
+			s.rollupShards(dbs)
+			s.expireSeries(dbs)
+
 			if err := s.MetaClient.PruneShardGroups(); err != nil {
 				s.logger.Info(fmt.Sprintf("Problem pruning shard groups: %s. Will retry in %v", err, s.config.CheckInterval))
 			}
 		}
 	}
 }
+
+// rollupShards sets the rollup rule of every local shard whose shard group ended
+// longer ago than the rollup age of its retention policy.
+func (s *Service) rollupShards(dbs []meta.DatabaseInfo) {
+	localShardIDs := make(map[uint64]struct{})
+	for _, id := range s.TSDBStore.ShardIDs() {
+		localShardIDs[id] = struct{}{}
+	}
+
+	now := time.Now().UTC()
+	for _, d := range dbs {
+		for _, r := range d.RetentionPolicies {
+			if r.Rollup == nil {
+				continue
+			}
+
+			rule := &tsdb.RollupRule{
+				After:     r.Rollup.After,
+				Interval:  r.Rollup.Interval,
+				Functions: r.Rollup.Functions,
+			}
+			for _, g := range r.ShardGroups {
+				if g.Deleted() || g.EndTime.Add(rule.After).After(now) {
+					continue
+				}
+
+				for _, sh := range g.Shards {
+					if _, ok := localShardIDs[sh.ID]; !ok {
+						continue
+					}
+					if err := s.TSDBStore.SetShardRollupRule(sh.ID, rule); err != nil {
+						s.logger.Info(fmt.Sprintf("Failed to set rollup of shard ID %d from database %s, retention policy %s: %v. Will retry in %v", sh.ID, d.Name, r.Name, err, s.config.CheckInterval))
+					}
+				}
+			}
+		}
+	}
+}
+
+// ruleExpiry records when a retention rule last expired series and the time its data
+// was expired before.
+type ruleExpiry struct {
+	at     time.Time
+	cutoff time.Time
+}
+
+// expireSeries deletes the data of the series matching each retention rule that is
+// older than the max age of the rule.  The shards tombstone the data, which is
+// dropped from their TSM files when they are next compacted.
+func (s *Service) expireSeries(dbs []meta.DatabaseInfo) {
+	if s.expired == nil {
+		s.expired = make(map[int]ruleExpiry)
+	}
+
+	localShardIDs := make(map[uint64]struct{})
+	for _, id := range s.TSDBStore.ShardIDs() {
+		localShardIDs[id] = struct{}{}
+	}
+
+	now := time.Now().UTC()
+	for i, r := range s.config.Rules {
+		cutoff := now.Add(-time.Duration(r.MaxAge))
+		shardIDs := s.expiringShardIDs(dbs, localShardIDs, r.Database, cutoff, s.expired[i])
+		if len(shardIDs) == 0 {
+			continue
+		}
+
+		// The rules have already been validated with the config.
+		source, _ := r.Source()
+		cond, _ := r.Condition()
+
+		var sources []influxql.Source
+		if source != nil {
+			sources = []influxql.Source{source}
+		}
+
+		expr := influxql.Expr(&influxql.BinaryExpr{
+			Op:  influxql.LT,
+			LHS: &influxql.VarRef{Val: "time"},
+			RHS: &influxql.TimeLiteral{Val: cutoff},
+		})
+		if cond != nil {
+			expr = &influxql.BinaryExpr{Op: influxql.AND, LHS: &influxql.ParenExpr{Expr: cond}, RHS: expr}
+		}
+
+		if err := s.TSDBStore.DeleteShardSeries(r.Database, shardIDs, sources, expr); err != nil {
+			s.logger.Info(fmt.Sprintf("Failed to expire series of database %s matching %s: %v. Will retry in %v", r.Database, expr, err, s.config.CheckInterval))
+			continue
+		}
+		s.expired[i] = ruleExpiry{at: time.Now().UTC(), cutoff: cutoff}
+	}
+}
+
+// expiringShardIDs returns the local shards of database holding data older than cutoff
+// that may have data to expire since the last expiry of a rule: shards whose time range
+// the cutoff has moved into, and shards written to since.
+func (s *Service) expiringShardIDs(dbs []meta.DatabaseInfo, localShardIDs map[uint64]struct{}, database string, cutoff time.Time, last ruleExpiry) []uint64 {
+	var shardIDs []uint64
+	for _, d := range dbs {
+		if d.Name != database {
+			continue
+		}
+
+		for _, r := range d.RetentionPolicies {
+			for _, g := range r.ShardGroups {
+				if g.Deleted() || !g.StartTime.Before(cutoff) {
+					continue
+				}
+
+				for _, sh := range g.Shards {
+					if _, ok := localShardIDs[sh.ID]; !ok {
+						continue
+					}
+					if g.EndTime.After(last.cutoff) || s.TSDBStore.ShardLastModified(sh.ID).After(last.at) {
+						shardIDs = append(shardIDs, sh.ID)
+					}
+				}
+			}
+		}
+	}
+	return shardIDs
+}
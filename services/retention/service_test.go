@@ -13,6 +13,8 @@ import (
 	"github.com/influxdata/influxdb/services/meta"
 	"github.com/influxdata/influxdb/services/retention"
 	"github.com/influxdata/influxdb/toml"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxql"
 )
 
 func TestService_OpenDisabled(t *testing.T) {
@@ -57,6 +59,181 @@ func TestService_OpenClose(t *testing.T) {
 	}
 }
 
+func TestService_Rollup(t *testing.T) {
+	c := retention.NewConfig()
+	c.CheckInterval = toml.Duration(time.Millisecond)
+	s := NewService(c)
+
+	rollup := &meta.RollupInfo{After: 24 * time.Hour, Interval: 5 * time.Minute, Functions: []string{"mean"}}
+	s.MetaClient.DatabasesFn = func() []meta.DatabaseInfo {
+		return []meta.DatabaseInfo{
+			{
+				Name: "db0",
+				RetentionPolicies: []meta.RetentionPolicyInfo{
+					{
+						Name:               "autogen",
+						ShardGroupDuration: time.Hour,
+						Rollup:             rollup,
+						ShardGroups: []meta.ShardGroupInfo{
+							{
+								ID:        1,
+								StartTime: time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC),
+								EndTime:   time.Date(1980, 1, 1, 1, 0, 0, 0, time.UTC),
+								Shards:    []meta.ShardInfo{{ID: 3}, {ID: 4}},
+							},
+							{
+								ID:        2,
+								StartTime: time.Now().UTC(),
+								EndTime:   time.Now().UTC().Add(time.Hour),
+								Shards:    []meta.ShardInfo{{ID: 5}},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	s.MetaClient.PruneShardGroupsFn = func() error { return nil }
+	s.TSDBStore.ShardIDsFn = func() []uint64 { return []uint64{3, 5} }
+
+	// Only the local shard of the old shard group should be rolled up.
+	shardIDs := make(chan uint64, 10)
+	s.TSDBStore.SetShardRollupRuleFn = func(id uint64, rule *tsdb.RollupRule) error {
+		if rule.Interval != rollup.Interval || !reflect.DeepEqual(rule.Functions, rollup.Functions) {
+			t.Errorf("unexpected rollup rule: %#v", rule)
+		}
+		select {
+		case shardIDs <- id:
+		default:
+		}
+		return nil
+	}
+
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	for i := 0; i < 3; i++ {
+		select {
+		case id := <-shardIDs:
+			if id != 3 {
+				t.Fatalf("unexpected shard rolled up: %d", id)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for rollup")
+		}
+	}
+}
+
+func TestService_ExpireSeries(t *testing.T) {
+	c := retention.NewConfig()
+	c.CheckInterval = toml.Duration(time.Millisecond)
+	c.Rules = []retention.Rule{{
+		Database:    "db0",
+		Measurement: "debug",
+		Where:       "level = 'debug'",
+		MaxAge:      toml.Duration(24 * time.Hour),
+	}}
+	s := NewService(c)
+
+	s.MetaClient.DatabasesFn = func() []meta.DatabaseInfo {
+		return []meta.DatabaseInfo{
+			{
+				Name: "db0",
+				RetentionPolicies: []meta.RetentionPolicyInfo{
+					{
+						Name:               "autogen",
+						ShardGroupDuration: time.Hour,
+						ShardGroups: []meta.ShardGroupInfo{
+							{
+								ID:        1,
+								StartTime: time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC),
+								EndTime:   time.Date(1980, 1, 1, 1, 0, 0, 0, time.UTC),
+								Shards:    []meta.ShardInfo{{ID: 3}, {ID: 4}},
+							},
+							{
+								ID:        2,
+								StartTime: time.Now().UTC(),
+								EndTime:   time.Now().UTC().Add(time.Hour),
+								Shards:    []meta.ShardInfo{{ID: 5}},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	s.MetaClient.PruneShardGroupsFn = func() error { return nil }
+	s.TSDBStore.ShardIDsFn = func() []uint64 { return []uint64{3, 5} }
+
+	var mu sync.Mutex
+	lastModified := time.Date(1980, 1, 1, 1, 0, 0, 0, time.UTC)
+	s.TSDBStore.ShardLastModifiedFn = func(id uint64) time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return lastModified
+	}
+
+	deletes := make(chan string, 10)
+	s.TSDBStore.DeleteShardSeriesFn = func(database string, shardIDs []uint64, sources []influxql.Source, condition influxql.Expr) error {
+		if database != "db0" || len(sources) != 1 || sources[0].String() != "debug" {
+			t.Errorf("unexpected delete from %s: %v", database, sources)
+		}
+
+		// Only the local shard of the old shard group holds expired data.
+		if !reflect.DeepEqual(shardIDs, []uint64{3}) {
+			t.Errorf("unexpected shards: %v", shardIDs)
+		}
+
+		// The expired data is everything of the matching series older than the max age.
+		cond, timeRange, err := influxql.ConditionExpr(condition, nil)
+		if err != nil {
+			t.Error(err)
+		} else if !timeRange.Min.IsZero() || time.Since(timeRange.Max) < 24*time.Hour {
+			t.Errorf("unexpected time range: %v", timeRange)
+		}
+
+		select {
+		case deletes <- cond.String():
+		default:
+		}
+		return nil
+	}
+
+	if err := s.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	select {
+	case cond := <-deletes:
+		if cond != "level = 'debug'" {
+			t.Fatalf("unexpected condition: %s", cond)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for series to expire")
+	}
+
+	// The shard isn't written to again, so there's nothing new to expire.
+	select {
+	case cond := <-deletes:
+		t.Fatalf("unexpected delete: %s", cond)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Series are expired again once the shard is written to.
+	mu.Lock()
+	lastModified = time.Now().UTC()
+	mu.Unlock()
+
+	select {
+	case <-deletes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for series to expire")
+	}
+}
+
 // This reproduces https://github.com/influxdata/influxdb/issues/8819
 func TestService_8819_repro(t *testing.T) {
 	for i := 0; i < 1000; i++ {
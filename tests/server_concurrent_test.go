@@ -95,7 +95,7 @@ func TestConcurrentServer_TagValues(t *testing.T) {
 				ids = append(ids, si.ID)
 			}
 		}
-		srv.TSDBStore.TagValues(nil, ids, cond)
+		srv.TSDBStore.TagValues(nil, ids, influxql.TimeRange{}, cond)
 	}
 
 	var f3 = func() { s.DropDatabase("db0") }
@@ -133,7 +133,7 @@ func TestConcurrentServer_ShowMeasurements(t *testing.T) {
 		if !ok {
 			t.Fatal("Not a local server")
 		}
-		srv.TSDBStore.MeasurementNames("db0", nil)
+		srv.TSDBStore.MeasurementNames("db0", nil, influxql.TimeRange{}, nil)
 	}
 
 	runTest(10*time.Second, f1, f2)
@@ -3,6 +3,9 @@ package tsdb
 import (
 	"errors"
 	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/influxdata/influxdb/monitor/diagnostics"
@@ -28,6 +31,10 @@ const (
 	// snapshot the cache and write it to a TSM file, freeing up memory
 	DefaultCacheSnapshotMemorySize = 25 * 1024 * 1024 // 25MB
 
+	// DefaultBlockCacheMaxMemorySize is the maximum size of the decoded blocks kept cached
+	// for queries across all shards.  A value of 0 disables the block cache.
+	DefaultBlockCacheMaxMemorySize = 0
+
 	// DefaultCacheSnapshotWriteColdDuration is the length of time at which
 	// the engine will snapshot the cache and write it to a new TSM file if
 	// the shard hasn't received writes or deletes
@@ -51,6 +58,32 @@ const (
 	// DefaultMaxConcurrentCompactions is the maximum number of concurrent full and level compactions
 	// that can run at one time.  A value of 0 results in 50% of runtime.GOMAXPROCS(0) used at runtime.
 	DefaultMaxConcurrentCompactions = 0
+
+	// DefaultCompactColdTierAge is the age of the newest point in a fully compacted
+	// generation of TSM files after which it is moved to the cold tier, if one is configured.
+	DefaultCompactColdTierAge = time.Duration(7 * 24 * time.Hour)
+
+	// DefaultCompactTombstoneThreshold is the size of the tombstone files of a fully
+	// compacted generation of TSM files after which the deleted values are compacted away.
+	DefaultCompactTombstoneThreshold = 1024 * 1024 // 1MB
+
+	// DefaultStringCompression is the compression used for string blocks in TSM files.
+	DefaultStringCompression = "snappy"
+
+	// DefaultWALCompression is the compression used for WAL entries.
+	DefaultWALCompression = "snappy"
+
+	// DefaultWALFrameSize is the number of bytes of WAL entries batched into a single
+	// compressed frame.  A value of 0 writes each entry on its own.
+	DefaultWALFrameSize = 0
+
+	// DefaultCompactThroughput is the rate in bytes per second that compactions may write
+	// TSM files at.  A value of 0 does not limit compactions.
+	DefaultCompactThroughput = 0
+
+	// DefaultIndexConversionThroughput is the number of series per second indexed when
+	// converting the index of existing shards to tsi1.
+	DefaultIndexConversionThroughput = 100000
 )
 
 // Config holds the configuration for the tsbd package.
@@ -59,6 +92,24 @@ type Config struct {
 	Engine string `toml:"-"`
 	Index  string `toml:"index-version"`
 
+	// IndexConversionEnabled converts the index of existing "inmem" shards to "tsi1" in
+	// the background, one shard at a time, when Index is "tsi1".
+	IndexConversionEnabled bool `toml:"index-conversion-enabled"`
+
+	// IndexConversionThroughput is the number of series per second indexed when converting
+	// the index of a shard.  A value of 0 does not limit conversions.
+	IndexConversionThroughput int `toml:"index-conversion-throughput"`
+
+	// TrigramIndexEnabled indexes the tag values of compacted tsi1 index files by trigram,
+	// so regular expressions matching tag values only scan the values containing the
+	// literals of the expression.  Index files written with trigram indexes can't be read
+	// by earlier versions.
+	TrigramIndexEnabled bool `toml:"trigram-index-enabled"`
+
+	// ColdDir is the directory fully compacted TSM files are moved to once their data is older
+	// than CompactColdTierAge.  An empty value disables the cold tier and keeps all TSM files in Dir.
+	ColdDir string `toml:"cold-dir"`
+
 	// General WAL configuration options
 	WALDir string `toml:"wal-dir"`
 
@@ -67,14 +118,73 @@ type Config struct {
 	// disks or when WAL write contention is seen.  A value of 0 fsyncs every write to the WAL.
 	WALFsyncDelay toml.Duration `toml:"wal-fsync-delay"`
 
+	// WALCompression is the compression used for WAL entries.  Valid values are "none",
+	// "snappy" and "deflate".  Segments written with any compression can always be read.
+	WALCompression string `toml:"wal-compression"`
+
+	// WALFrameSize is the number of bytes of WAL entries batched into a single frame,
+	// which is compressed as a whole.  Entries waiting on the same fsync are written
+	// together, so larger frames compress better on busy shards.  A value of 0 writes
+	// each entry on its own unless WALCompression is other than "snappy".
+	WALFrameSize toml.Size `toml:"wal-frame-size"`
+
 	// Query logging
 	QueryLogEnabled bool `toml:"query-log-enabled"`
 
+	// MetadataTimeCheckEnabled restricts SHOW MEASUREMENTS, SHOW TAG KEYS and SHOW TAG VALUES
+	// queries bounded by time to series with data in the time range, using the time ranges
+	// of the series' TSM blocks.  Otherwise those queries are only restricted to the shards
+	// overlapping the time range.
+	MetadataTimeCheckEnabled bool `toml:"metadata-time-check-enabled"`
+
 	// Compaction options for tsm1 (descriptions above with defaults)
 	CacheMaxMemorySize             toml.Size     `toml:"cache-max-memory-size"`
 	CacheSnapshotMemorySize        toml.Size     `toml:"cache-snapshot-memory-size"`
 	CacheSnapshotWriteColdDuration toml.Duration `toml:"cache-snapshot-write-cold-duration"`
 	CompactFullWriteColdDuration   toml.Duration `toml:"compact-full-write-cold-duration"`
+	CompactColdTierAge             toml.Duration `toml:"compact-cold-tier-age"`
+
+	// CompactTombstoneThreshold is the size of the tombstone files of a fully compacted
+	// generation after which the generation is rewritten without the deleted values.
+	// Only the blocks holding deleted values are rewritten.  A value of 0 leaves the
+	// tombstones until the next full compaction.
+	CompactTombstoneThreshold toml.Size `toml:"compact-tombstone-threshold"`
+
+	// CompactLateWritesEnabled writes the values of a cache snapshot that fall into time
+	// ranges already persisted for their series to separate generations of TSM files.
+	// Those generations are merged into the files they overlap before any other
+	// compaction runs, so reads rarely have to merge overlapping blocks.
+	CompactLateWritesEnabled bool `toml:"compact-late-writes-enabled"`
+
+	// CacheSpillEnabled spills the cache to sorted runs on disk when a write would exceed
+	// CacheMaxMemorySize, instead of rejecting the write.  Spilled runs are merged into the
+	// next snapshot, so writes are slowed by the spill rather than failed.
+	CacheSpillEnabled bool `toml:"cache-spill-enabled"`
+
+	// BlockCacheMaxMemorySize is the maximum size of the decoded TSM blocks kept cached for
+	// all shards, so queries reading the same blocks again don't decode them again.  The least
+	// recently read blocks are evicted first.  A value of 0 disables the block cache.
+	BlockCacheMaxMemorySize toml.Size `toml:"block-cache-max-memory-size"`
+
+	// StringCompression is the compression used for string blocks written to new TSM files.
+	// Valid values are "none", "snappy" and "deflate".  Existing blocks are always readable
+	// regardless of the compression they were written with.  It doesn't apply to float
+	// blocks, which always use their XOR encoding.
+	StringCompression string `toml:"string-compression"`
+
+	// DatabaseStringCompression overrides StringCompression for individual databases.
+	DatabaseStringCompression map[string]string `toml:"database-string-compression"`
+
+	// CompactThroughput is the rate in bytes per second that level and full compactions
+	// write TSM files at, shared by all shards.  Snapshots of the cache are not limited.
+	// A value of 0 does not limit compactions.
+	CompactThroughput toml.Size `toml:"compact-throughput"`
+
+	// CompactThroughputWindow is the daily window of local time, such as "08:00-18:00",
+	// during which CompactThroughput applies.  Compactions are not limited outside the
+	// window and the planner defers full compactions until the window ends.  An empty
+	// value applies CompactThroughput at all times.
+	CompactThroughputWindow string `toml:"compact-throughput-window"`
 
 	// Limits
 
@@ -103,12 +213,22 @@ func NewConfig() Config {
 		Engine: DefaultEngine,
 		Index:  DefaultIndex,
 
+		IndexConversionThroughput: DefaultIndexConversionThroughput,
+
 		QueryLogEnabled: true,
 
+		WALCompression: DefaultWALCompression,
+		WALFrameSize:   toml.Size(DefaultWALFrameSize),
+
 		CacheMaxMemorySize:             toml.Size(DefaultCacheMaxMemorySize),
 		CacheSnapshotMemorySize:        toml.Size(DefaultCacheSnapshotMemorySize),
 		CacheSnapshotWriteColdDuration: toml.Duration(DefaultCacheSnapshotWriteColdDuration),
+		BlockCacheMaxMemorySize:        toml.Size(DefaultBlockCacheMaxMemorySize),
 		CompactFullWriteColdDuration:   toml.Duration(DefaultCompactFullWriteColdDuration),
+		CompactColdTierAge:             toml.Duration(DefaultCompactColdTierAge),
+		CompactTombstoneThreshold:      toml.Size(DefaultCompactTombstoneThreshold),
+		StringCompression:              DefaultStringCompression,
+		CompactThroughput:              toml.Size(DefaultCompactThroughput),
 
 		MaxSeriesPerDatabase:     DefaultMaxSeriesPerDatabase,
 		MaxValuesPerTag:          DefaultMaxValuesPerTag,
@@ -130,6 +250,35 @@ func (c *Config) Validate() error {
 		return errors.New("max-concurrent-compactions must be greater than 0")
 	}
 
+	if c.IndexConversionThroughput < 0 {
+		return errors.New("index-conversion-throughput must not be negative")
+	}
+
+	if c.ColdDir != "" {
+		if c.CompactColdTierAge <= 0 {
+			return errors.New("compact-cold-tier-age must be greater than 0")
+		} else if filepath.Clean(c.ColdDir) == filepath.Clean(c.Dir) {
+			return errors.New("Data.ColdDir must be different from Data.Dir")
+		}
+	}
+
+	if _, err := ParseTimeWindow(c.CompactThroughputWindow); err != nil {
+		return fmt.Errorf("invalid compact-throughput-window: %s", err)
+	}
+
+	if !validStringCompression(c.StringCompression) {
+		return fmt.Errorf("unrecognized string compression %s", c.StringCompression)
+	}
+	for db, compression := range c.DatabaseStringCompression {
+		if !validStringCompression(compression) {
+			return fmt.Errorf("unrecognized string compression %s for database %s", compression, db)
+		}
+	}
+
+	if !validWALCompression(c.WALCompression) {
+		return fmt.Errorf("unrecognized wal compression %s", c.WALCompression)
+	}
+
 	valid := false
 	for _, e := range RegisteredEngines() {
 		if e == c.Engine {
@@ -159,14 +308,137 @@ func (c *Config) Validate() error {
 func (c Config) Diagnostics() (*diagnostics.Diagnostics, error) {
 	return diagnostics.RowFromMap(map[string]interface{}{
 		"dir":                                c.Dir,
+		"cold-dir":                           c.ColdDir,
 		"wal-dir":                            c.WALDir,
+		"index-conversion-enabled":           c.IndexConversionEnabled,
+		"index-conversion-throughput":        c.IndexConversionThroughput,
+		"trigram-index-enabled":              c.TrigramIndexEnabled,
+		"metadata-time-check-enabled":        c.MetadataTimeCheckEnabled,
 		"wal-fsync-delay":                    c.WALFsyncDelay,
+		"wal-compression":                    c.WALCompression,
+		"wal-frame-size":                     c.WALFrameSize,
 		"cache-max-memory-size":              c.CacheMaxMemorySize,
 		"cache-snapshot-memory-size":         c.CacheSnapshotMemorySize,
 		"cache-snapshot-write-cold-duration": c.CacheSnapshotWriteColdDuration,
+		"cache-spill-enabled":                c.CacheSpillEnabled,
+		"block-cache-max-memory-size":        c.BlockCacheMaxMemorySize,
 		"compact-full-write-cold-duration":   c.CompactFullWriteColdDuration,
+		"compact-cold-tier-age":              c.CompactColdTierAge,
+		"compact-tombstone-threshold":        c.CompactTombstoneThreshold,
+		"compact-late-writes-enabled":        c.CompactLateWritesEnabled,
+		"string-compression":                 c.StringCompression,
+		"compact-throughput":                 c.CompactThroughput,
+		"compact-throughput-window":          c.CompactThroughputWindow,
 		"max-series-per-database":            c.MaxSeriesPerDatabase,
 		"max-values-per-tag":                 c.MaxValuesPerTag,
 		"max-concurrent-compactions":         c.MaxConcurrentCompactions,
 	}), nil
 }
+
+// StringCompressionFor returns the compression used for string blocks of the database.
+func (c Config) StringCompressionFor(database string) string {
+	if compression, ok := c.DatabaseStringCompression[database]; ok {
+		return compression
+	}
+	return c.StringCompression
+}
+
+// validStringCompression returns true if compression names a supported string
+// block compression.  An empty value selects the default.
+func validStringCompression(compression string) bool {
+	switch compression {
+	case "", "none", "snappy", "deflate":
+		return true
+	}
+	return false
+}
+
+// validWALCompression returns true if compression names a supported WAL compression.
+// An empty value selects the default.
+func validWALCompression(compression string) bool {
+	switch compression {
+	case "", "none", "snappy", "deflate":
+		return true
+	}
+	return false
+}
+
+// TimeWindow is a daily window of local time.  A window whose end is before its
+// start wraps around midnight.
+type TimeWindow struct {
+	// Start and End are offsets from midnight.
+	Start, End time.Duration
+}
+
+// ParseTimeWindow parses a window in the form "HH:MM-HH:MM".  An empty string
+// returns the zero window, which contains all times.
+func ParseTimeWindow(s string) (TimeWindow, error) {
+	if s == "" {
+		return TimeWindow{}, nil
+	}
+
+	parts := strings.Split(s, "-")
+	if len(parts) != 2 {
+		return TimeWindow{}, fmt.Errorf("expected HH:MM-HH:MM: %q", s)
+	}
+
+	var w TimeWindow
+	for i, part := range parts {
+		t, err := time.Parse("15:04", strings.TrimSpace(part))
+		if err != nil {
+			return TimeWindow{}, fmt.Errorf("expected HH:MM-HH:MM: %q", s)
+		}
+		d := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+		if i == 0 {
+			w.Start = d
+		} else {
+			w.End = d
+		}
+	}
+
+	if w.Start == w.End {
+		return TimeWindow{}, fmt.Errorf("window is empty: %q", s)
+	}
+	return w, nil
+}
+
+// IsZero returns true if w is the zero window.
+func (w TimeWindow) IsZero() bool {
+	return w.Start == 0 && w.End == 0
+}
+
+// Contains returns true if the local time of day of t is within w.  The zero
+// window contains all times.
+func (w TimeWindow) Contains(t time.Time) bool {
+	if w.IsZero() {
+		return true
+	}
+
+	d := timeOfDay(t)
+	if w.Start < w.End {
+		return d >= w.Start && d < w.End
+	}
+	return d >= w.Start || d < w.End
+}
+
+// Remaining returns how long is left in w after t, or 0 if t is not within w.
+// The zero window never ends.
+func (w TimeWindow) Remaining(t time.Time) time.Duration {
+	if w.IsZero() {
+		return time.Duration(math.MaxInt64)
+	} else if !w.Contains(t) {
+		return 0
+	}
+
+	remaining := w.End - timeOfDay(t)
+	if remaining < 0 {
+		remaining += 24 * time.Hour
+	}
+	return remaining
+}
+
+// timeOfDay returns the offset of the local time of t from midnight.
+func timeOfDay(t time.Time) time.Duration {
+	t = t.Local()
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+}
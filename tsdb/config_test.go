@@ -15,6 +15,8 @@ func TestConfig_Parse(t *testing.T) {
 dir = "/var/lib/influxdb/data"
 wal-dir = "/var/lib/influxdb/wal"
 wal-fsync-delay = "10s"
+cold-dir = "/mnt/cold/influxdb/data"
+compact-cold-tier-age = "720h"
 `, &c); err != nil {
 		t.Fatal(err)
 	}
@@ -32,6 +34,12 @@ wal-fsync-delay = "10s"
 	if got, exp := c.WALFsyncDelay, time.Duration(10*time.Second); time.Duration(got).Nanoseconds() != exp.Nanoseconds() {
 		t.Errorf("unexpected wal-fsync-delay:\n\nexp=%v\n\ngot=%v\n\n", exp, got)
 	}
+	if got, exp := c.ColdDir, "/mnt/cold/influxdb/data"; got != exp {
+		t.Errorf("unexpected cold-dir:\n\nexp=%v\n\ngot=%v\n\n", exp, got)
+	}
+	if got, exp := c.CompactColdTierAge, time.Duration(720*time.Hour); time.Duration(got).Nanoseconds() != exp.Nanoseconds() {
+		t.Errorf("unexpected compact-cold-tier-age:\n\nexp=%v\n\ngot=%v\n\n", exp, got)
+	}
 }
 
 func TestConfig_Validate_Error(t *testing.T) {
@@ -66,6 +74,133 @@ func TestConfig_Validate_Error(t *testing.T) {
 	if err := c.Validate(); err != nil {
 		t.Error(err)
 	}
+
+	c.ColdDir = "/var/lib/influxdb/data/"
+	if err := c.Validate(); err == nil || err.Error() != "Data.ColdDir must be different from Data.Dir" {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	c.ColdDir = "/mnt/cold/influxdb/data"
+	c.CompactColdTierAge = 0
+	if err := c.Validate(); err == nil || err.Error() != "compact-cold-tier-age must be greater than 0" {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	c.CompactColdTierAge = tsdb.NewConfig().CompactColdTierAge
+	if err := c.Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestConfig_StringCompression(t *testing.T) {
+	c := tsdb.NewConfig()
+	if _, err := toml.Decode(`
+dir = "/var/lib/influxdb/data"
+wal-dir = "/var/lib/influxdb/wal"
+string-compression = "none"
+
+[database-string-compression]
+logs = "deflate"
+`, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Validate(); err != nil {
+		t.Errorf("unexpected validate error: %s", err)
+	}
+
+	if got, exp := c.StringCompressionFor("logs"), "deflate"; got != exp {
+		t.Errorf("unexpected string compression for logs:\n\nexp=%v\n\ngot=%v\n\n", exp, got)
+	}
+	if got, exp := c.StringCompressionFor("metrics"), "none"; got != exp {
+		t.Errorf("unexpected string compression for metrics:\n\nexp=%v\n\ngot=%v\n\n", exp, got)
+	}
+
+	c.DatabaseStringCompression["logs"] = "zip"
+	if err := c.Validate(); err == nil || err.Error() != "unrecognized string compression zip for database logs" {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestConfig_WALCompression(t *testing.T) {
+	c := tsdb.NewConfig()
+	if _, err := toml.Decode(`
+dir = "/var/lib/influxdb/data"
+wal-dir = "/var/lib/influxdb/wal"
+wal-compression = "deflate"
+wal-frame-size = "64k"
+`, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Validate(); err != nil {
+		t.Errorf("unexpected validate error: %s", err)
+	}
+
+	if got, exp := c.WALFrameSize, 64*1024; int(got) != exp {
+		t.Errorf("unexpected wal frame size:\n\nexp=%v\n\ngot=%v\n\n", exp, got)
+	}
+
+	c.WALCompression = "zip"
+	if err := c.Validate(); err == nil || err.Error() != "unrecognized wal compression zip" {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
+
+func TestConfig_CompactThroughput(t *testing.T) {
+	c := tsdb.NewConfig()
+	if _, err := toml.Decode(`
+dir = "/var/lib/influxdb/data"
+wal-dir = "/var/lib/influxdb/wal"
+compact-throughput = "48m"
+compact-throughput-window = "22:00-06:00"
+`, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Validate(); err != nil {
+		t.Errorf("unexpected validate error: %s", err)
+	}
+
+	if got, exp := c.CompactThroughput, 48*1024*1024; int(got) != exp {
+		t.Errorf("unexpected compact-throughput:\n\nexp=%v\n\ngot=%v\n\n", exp, got)
+	}
+
+	w, err := tsdb.ParseTimeWindow(c.CompactThroughputWindow)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	day := time.Date(2000, 1, 1, 0, 0, 0, 0, time.Local)
+	for _, tt := range []struct {
+		d   time.Duration
+		exp bool
+	}{
+		{d: 21*time.Hour + 59*time.Minute, exp: false},
+		{d: 22 * time.Hour, exp: true},
+		{d: 2 * time.Hour, exp: true},
+		{d: 6 * time.Hour, exp: false},
+		{d: 12 * time.Hour, exp: false},
+	} {
+		if got := w.Contains(day.Add(tt.d)); got != tt.exp {
+			t.Errorf("unexpected contains for %v: exp=%v got=%v", tt.d, tt.exp, got)
+		}
+	}
+
+	if got, exp := w.Remaining(day.Add(23*time.Hour)), 7*time.Hour; got != exp {
+		t.Errorf("unexpected remaining: exp=%v got=%v", exp, got)
+	} else if got := w.Remaining(day.Add(12 * time.Hour)); got != 0 {
+		t.Errorf("unexpected remaining outside window: %v", got)
+	}
+
+	if !(tsdb.TimeWindow{}).Contains(day) {
+		t.Error("expected zero window to contain all times")
+	}
+
+	c.CompactThroughputWindow = "08:00"
+	if err := c.Validate(); err == nil || err.Error() != `invalid compact-throughput-window: expected HH:MM-HH:MM: "08:00"` {
+		t.Errorf("unexpected error: %s", err)
+	}
 }
 
 func TestConfig_ByteSizes(t *testing.T) {
@@ -35,18 +35,28 @@ type Engine interface {
 	SetEnabled(enabled bool)
 	SetCompactionsEnabled(enabled bool)
 	ScheduleFullCompaction() error
+	SetRollupRule(rule *RollupRule) error
+	Rollup() *RollupRule
+	SetReadOnly(readOnly bool) error
+	ReadOnly() bool
 
 	WithLogger(*zap.Logger)
 
 	LoadMetadataIndex(shardID uint64, index Index) error
+	SetIndex(index Index)
+	ForEachSeriesKey(fn func(key []byte) error) error
+	SeriesHasData(key []byte, min, max int64) bool
 
 	CreateSnapshot() (string, error)
+	RemoveSnapshot(path string) error
 	Backup(w io.Writer, basePath string, since time.Time) error
 	Restore(r io.Reader, basePath string) error
 	Import(r io.Reader, basePath string) error
+	IngestTSMFiles(paths []string) error
 
 	CreateIterator(ctx context.Context, measurement string, opt query.IteratorOptions) (query.Iterator, error)
 	CreateCursor(ctx context.Context, r *CursorRequest) (Cursor, error)
+	ReadSnapshot() (ReadSnapshot, error)
 	IteratorCost(measurement string, opt query.IteratorOptions) (query.IteratorCost, error)
 	WritePoints(points []models.Point) error
 
@@ -153,8 +163,20 @@ type EngineOptions struct {
 	ShardID       uint64
 	InmemIndex    interface{} // shared in-memory index
 
+	// ColdPath is the shard's directory on the cold storage tier.  It is empty
+	// when the cold tier is not configured.
+	ColdPath string
+
 	CompactionLimiter limiter.Fixed
 
+	// CompactionThroughputLimiter limits the rate at which compactions write TSM
+	// files across all shards.  It is nil when compactions are not limited.
+	CompactionThroughputLimiter *limiter.Rate
+
+	// BlockCache caches the decoded blocks of the shards' TSM files.  It is shared by
+	// all shards and is nil when blocks are not cached.
+	BlockCache BlockCache
+
 	Config Config
 }
 
@@ -169,3 +191,12 @@ func NewEngineOptions() EngineOptions {
 
 // NewInmemIndex returns a new "inmem" index type.
 var NewInmemIndex func(name string) (interface{}, error)
+
+// BlockCache is a cache of decoded blocks shared by the engines of a store.
+type BlockCache interface {
+	// Statistics returns statistics for periodic monitoring.
+	Statistics(tags map[string]string) []models.Statistic
+}
+
+// NewBlockCache returns a new block cache holding up to maxSize bytes of decoded blocks.
+var NewBlockCache func(maxSize int) BlockCache
@@ -15,83 +15,113 @@ import (
 )
 
 // buildFloatCursor creates a cursor for a float field.
-func (e *Engine) buildFloatCursor(ctx context.Context, measurement, seriesKey, field string, opt query.IteratorOptions) floatCursor {
+func (e *Engine) buildFloatCursor(ctx context.Context, measurement, seriesKey, field string, opt query.IteratorOptions) (floatCursor, error) {
 	key := SeriesFieldKeyBytes(seriesKey, field)
-	cacheValues := e.Cache.Values(key)
+	cacheValues, err := e.cacheValues(ctx, key)
+	if err != nil {
+		return nil, err
+	}
 	keyCursor := e.KeyCursor(ctx, key, opt.SeekTime(), opt.Ascending)
-	return newFloatCursor(opt.SeekTime(), opt.Ascending, cacheValues, keyCursor)
+	return newFloatCursor(opt.SeekTime(), opt.Ascending, cacheValues, keyCursor), nil
 }
 
 // buildFloatBatchCursor creates a batch cursor for a float field.
-func (e *Engine) buildFloatBatchCursor(ctx context.Context, measurement, seriesKey, field string, opt query.IteratorOptions) tsdb.FloatBatchCursor {
+func (e *Engine) buildFloatBatchCursor(ctx context.Context, measurement, seriesKey, field string, opt query.IteratorOptions) (tsdb.FloatBatchCursor, error) {
 	key := SeriesFieldKeyBytes(seriesKey, field)
-	cacheValues := e.Cache.Values(key)
+	cacheValues, err := e.cacheValues(ctx, key)
+	if err != nil {
+		return nil, err
+	}
 	keyCursor := e.KeyCursor(ctx, key, opt.SeekTime(), opt.Ascending)
-	return newFloatBatchCursor(seriesKey, opt.SeekTime(), opt.Ascending, cacheValues, keyCursor)
+	return newFloatBatchCursor(seriesKey, opt.SeekTime(), opt.Ascending, cacheValues, keyCursor), nil
 }
 
 // buildIntegerCursor creates a cursor for a integer field.
-func (e *Engine) buildIntegerCursor(ctx context.Context, measurement, seriesKey, field string, opt query.IteratorOptions) integerCursor {
+func (e *Engine) buildIntegerCursor(ctx context.Context, measurement, seriesKey, field string, opt query.IteratorOptions) (integerCursor, error) {
 	key := SeriesFieldKeyBytes(seriesKey, field)
-	cacheValues := e.Cache.Values(key)
+	cacheValues, err := e.cacheValues(ctx, key)
+	if err != nil {
+		return nil, err
+	}
 	keyCursor := e.KeyCursor(ctx, key, opt.SeekTime(), opt.Ascending)
-	return newIntegerCursor(opt.SeekTime(), opt.Ascending, cacheValues, keyCursor)
+	return newIntegerCursor(opt.SeekTime(), opt.Ascending, cacheValues, keyCursor), nil
 }
 
 // buildIntegerBatchCursor creates a batch cursor for a integer field.
-func (e *Engine) buildIntegerBatchCursor(ctx context.Context, measurement, seriesKey, field string, opt query.IteratorOptions) tsdb.IntegerBatchCursor {
+func (e *Engine) buildIntegerBatchCursor(ctx context.Context, measurement, seriesKey, field string, opt query.IteratorOptions) (tsdb.IntegerBatchCursor, error) {
 	key := SeriesFieldKeyBytes(seriesKey, field)
-	cacheValues := e.Cache.Values(key)
+	cacheValues, err := e.cacheValues(ctx, key)
+	if err != nil {
+		return nil, err
+	}
 	keyCursor := e.KeyCursor(ctx, key, opt.SeekTime(), opt.Ascending)
-	return newIntegerBatchCursor(seriesKey, opt.SeekTime(), opt.Ascending, cacheValues, keyCursor)
+	return newIntegerBatchCursor(seriesKey, opt.SeekTime(), opt.Ascending, cacheValues, keyCursor), nil
 }
 
 // buildUnsignedCursor creates a cursor for a unsigned field.
-func (e *Engine) buildUnsignedCursor(ctx context.Context, measurement, seriesKey, field string, opt query.IteratorOptions) unsignedCursor {
+func (e *Engine) buildUnsignedCursor(ctx context.Context, measurement, seriesKey, field string, opt query.IteratorOptions) (unsignedCursor, error) {
 	key := SeriesFieldKeyBytes(seriesKey, field)
-	cacheValues := e.Cache.Values(key)
+	cacheValues, err := e.cacheValues(ctx, key)
+	if err != nil {
+		return nil, err
+	}
 	keyCursor := e.KeyCursor(ctx, key, opt.SeekTime(), opt.Ascending)
-	return newUnsignedCursor(opt.SeekTime(), opt.Ascending, cacheValues, keyCursor)
+	return newUnsignedCursor(opt.SeekTime(), opt.Ascending, cacheValues, keyCursor), nil
 }
 
 // buildUnsignedBatchCursor creates a batch cursor for a unsigned field.
-func (e *Engine) buildUnsignedBatchCursor(ctx context.Context, measurement, seriesKey, field string, opt query.IteratorOptions) tsdb.UnsignedBatchCursor {
+func (e *Engine) buildUnsignedBatchCursor(ctx context.Context, measurement, seriesKey, field string, opt query.IteratorOptions) (tsdb.UnsignedBatchCursor, error) {
 	key := SeriesFieldKeyBytes(seriesKey, field)
-	cacheValues := e.Cache.Values(key)
+	cacheValues, err := e.cacheValues(ctx, key)
+	if err != nil {
+		return nil, err
+	}
 	keyCursor := e.KeyCursor(ctx, key, opt.SeekTime(), opt.Ascending)
-	return newUnsignedBatchCursor(seriesKey, opt.SeekTime(), opt.Ascending, cacheValues, keyCursor)
+	return newUnsignedBatchCursor(seriesKey, opt.SeekTime(), opt.Ascending, cacheValues, keyCursor), nil
 }
 
 // buildStringCursor creates a cursor for a string field.
-func (e *Engine) buildStringCursor(ctx context.Context, measurement, seriesKey, field string, opt query.IteratorOptions) stringCursor {
+func (e *Engine) buildStringCursor(ctx context.Context, measurement, seriesKey, field string, opt query.IteratorOptions) (stringCursor, error) {
 	key := SeriesFieldKeyBytes(seriesKey, field)
-	cacheValues := e.Cache.Values(key)
+	cacheValues, err := e.cacheValues(ctx, key)
+	if err != nil {
+		return nil, err
+	}
 	keyCursor := e.KeyCursor(ctx, key, opt.SeekTime(), opt.Ascending)
-	return newStringCursor(opt.SeekTime(), opt.Ascending, cacheValues, keyCursor)
+	return newStringCursor(opt.SeekTime(), opt.Ascending, cacheValues, keyCursor), nil
 }
 
 // buildStringBatchCursor creates a batch cursor for a string field.
-func (e *Engine) buildStringBatchCursor(ctx context.Context, measurement, seriesKey, field string, opt query.IteratorOptions) tsdb.StringBatchCursor {
+func (e *Engine) buildStringBatchCursor(ctx context.Context, measurement, seriesKey, field string, opt query.IteratorOptions) (tsdb.StringBatchCursor, error) {
 	key := SeriesFieldKeyBytes(seriesKey, field)
-	cacheValues := e.Cache.Values(key)
+	cacheValues, err := e.cacheValues(ctx, key)
+	if err != nil {
+		return nil, err
+	}
 	keyCursor := e.KeyCursor(ctx, key, opt.SeekTime(), opt.Ascending)
-	return newStringBatchCursor(seriesKey, opt.SeekTime(), opt.Ascending, cacheValues, keyCursor)
+	return newStringBatchCursor(seriesKey, opt.SeekTime(), opt.Ascending, cacheValues, keyCursor), nil
 }
 
 // buildBooleanCursor creates a cursor for a boolean field.
-func (e *Engine) buildBooleanCursor(ctx context.Context, measurement, seriesKey, field string, opt query.IteratorOptions) booleanCursor {
+func (e *Engine) buildBooleanCursor(ctx context.Context, measurement, seriesKey, field string, opt query.IteratorOptions) (booleanCursor, error) {
 	key := SeriesFieldKeyBytes(seriesKey, field)
-	cacheValues := e.Cache.Values(key)
+	cacheValues, err := e.cacheValues(ctx, key)
+	if err != nil {
+		return nil, err
+	}
 	keyCursor := e.KeyCursor(ctx, key, opt.SeekTime(), opt.Ascending)
-	return newBooleanCursor(opt.SeekTime(), opt.Ascending, cacheValues, keyCursor)
+	return newBooleanCursor(opt.SeekTime(), opt.Ascending, cacheValues, keyCursor), nil
 }
 
 // buildBooleanBatchCursor creates a batch cursor for a boolean field.
-func (e *Engine) buildBooleanBatchCursor(ctx context.Context, measurement, seriesKey, field string, opt query.IteratorOptions) tsdb.BooleanBatchCursor {
+func (e *Engine) buildBooleanBatchCursor(ctx context.Context, measurement, seriesKey, field string, opt query.IteratorOptions) (tsdb.BooleanBatchCursor, error) {
 	key := SeriesFieldKeyBytes(seriesKey, field)
-	cacheValues := e.Cache.Values(key)
+	cacheValues, err := e.cacheValues(ctx, key)
+	if err != nil {
+		return nil, err
+	}
 	keyCursor := e.KeyCursor(ctx, key, opt.SeekTime(), opt.Ascending)
-	return newBooleanBatchCursor(seriesKey, opt.SeekTime(), opt.Ascending, cacheValues, keyCursor)
+	return newBooleanBatchCursor(seriesKey, opt.SeekTime(), opt.Ascending, cacheValues, keyCursor), nil
 }
 
 // Cursors
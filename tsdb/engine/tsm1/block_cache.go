@@ -0,0 +1,192 @@
+package tsm1
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+func init() {
+	tsdb.NewBlockCache = func(maxSize int) tsdb.BlockCache { return newBlockCache(maxSize) }
+}
+
+// Statistics gathered by the block cache.
+const (
+	statBlockCacheHits      = "blockCacheHits"
+	statBlockCacheMisses    = "blockCacheMisses"
+	statBlockCacheEvictions = "blockCacheEvictions"
+	statBlockCacheBytes     = "blockCacheBytes"
+)
+
+// blockCache is a size-bounded cache of decoded blocks shared by the cursors of all file
+// stores, so queries reading the same blocks again don't decode them again.  Blocks are
+// grouped by their file and keyed by their offset within it, and the least recently
+// read blocks are evicted once the cached values exceed the max size.  A nil cache
+// holds no blocks.
+type blockCache struct {
+	mu      sync.Mutex
+	maxSize int
+	size    int
+	files   map[*TSMReader]map[int64]*list.Element
+	lru     *list.List // most recently read blocks first
+
+	hits, misses, evictions int64
+}
+
+// blockKey identifies a block by its file and its offset within the file.
+type blockKey struct {
+	r      TSMFile
+	offset int64
+}
+
+// blockCacheEntry holds the decoded values of a block, such as []FloatValue.
+type blockCacheEntry struct {
+	r      *TSMReader
+	offset int64
+	values interface{}
+	size   int
+}
+
+// newBlockCache returns a cache holding up to maxSize bytes of decoded values.
+func newBlockCache(maxSize int) *blockCache {
+	return &blockCache{
+		maxSize: maxSize,
+		files:   make(map[*TSMReader]map[int64]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// blockFile returns the reader the blocks of f are cached under.  Read views share the
+// blocks of the file they were taken of, so their blocks are cached under its reader.
+func blockFile(f TSMFile) *TSMReader {
+	r, ok := f.(*TSMReader)
+	if !ok {
+		return nil
+	} else if r.parent != nil {
+		return r.parent
+	}
+	return r
+}
+
+// get returns the decoded values of the block, or nil if the block isn't cached.  The
+// values are shared, so they must be copied before being modified.
+func (c *blockCache) get(key blockKey) interface{} {
+	r := blockFile(key.r)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.files[r][key.offset]
+	if !ok {
+		c.misses++
+		return nil
+	}
+	c.hits++
+	c.lru.MoveToFront(e)
+	return e.Value.(*blockCacheEntry).values
+}
+
+// put adds the decoded values of a block taking size bytes, evicting the least recently
+// read blocks to make room.  Blocks larger than the cache and blocks of files already
+// evicted, which cursors of read snapshots still read, aren't cached.  The values must
+// not be modified once added.
+func (c *blockCache) put(key blockKey, values interface{}, size int) {
+	r := blockFile(key.r)
+	if r == nil || size > c.maxSize {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if r.blocksEvicted {
+		return
+	}
+
+	blocks := c.files[r]
+	if blocks == nil {
+		blocks = make(map[int64]*list.Element)
+		c.files[r] = blocks
+	}
+
+	// Another cursor may have decoded the same block concurrently.
+	if e, ok := blocks[key.offset]; ok {
+		c.lru.MoveToFront(e)
+		return
+	}
+
+	blocks[key.offset] = c.lru.PushFront(&blockCacheEntry{r: r, offset: key.offset, values: values, size: size})
+	c.size += size
+	for c.size > c.maxSize {
+		c.remove(c.lru.Back())
+		c.evictions++
+	}
+}
+
+// evictFile removes the blocks of f from the cache and stops any more from being added.
+// It must be called once f is removed from the file store, so the cache doesn't keep the
+// file or its values.
+func (c *blockCache) evictFile(f TSMFile) {
+	r := blockFile(f)
+	if c == nil || r == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	r.blocksEvicted = true
+	for _, e := range c.files[r] {
+		c.remove(e)
+	}
+}
+
+// remove removes the block of e from the cache.
+func (c *blockCache) remove(e *list.Element) {
+	entry := c.lru.Remove(e).(*blockCacheEntry)
+	blocks := c.files[entry.r]
+	delete(blocks, entry.offset)
+	if len(blocks) == 0 {
+		delete(c.files, entry.r)
+	}
+	c.size -= entry.size
+}
+
+// blockCacheStatistics holds the counts of a block cache.
+type blockCacheStatistics struct {
+	Hits, Misses, Evictions int64
+	Size                    int64
+}
+
+// statistics returns the reads, evictions and size of the cache.
+func (c *blockCache) statistics() blockCacheStatistics {
+	if c == nil {
+		return blockCacheStatistics{}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return blockCacheStatistics{
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+		Size:      int64(c.size),
+	}
+}
+
+// Statistics returns statistics for periodic monitoring.
+func (c *blockCache) Statistics(tags map[string]string) []models.Statistic {
+	stats := c.statistics()
+	return []models.Statistic{{
+		Name: "tsm1_blockcache",
+		Tags: tags,
+		Values: map[string]interface{}{
+			statBlockCacheHits:      stats.Hits,
+			statBlockCacheMisses:    stats.Misses,
+			statBlockCacheEvictions: stats.Evictions,
+			statBlockCacheBytes:     stats.Size,
+		},
+	}}
+}
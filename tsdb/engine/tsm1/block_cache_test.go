@@ -0,0 +1,121 @@
+package tsm1
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestBlockCache_Evict(t *testing.T) {
+	r1, r2 := &TSMReader{}, &TSMReader{}
+	c := newBlockCache(25)
+
+	c.put(blockKey{r: r1, offset: 5}, []FloatValue{{unixnano: 1, value: 1}}, 10)
+	c.put(blockKey{r: r1, offset: 100}, []FloatValue{{unixnano: 2, value: 2}}, 10)
+
+	// Reading the first block makes the second the least recently read.
+	if v, ok := c.get(blockKey{r: r1, offset: 5}).([]FloatValue); !ok || v[0].value != 1 {
+		t.Fatalf("unexpected values: %v", v)
+	}
+	c.put(blockKey{r: r2, offset: 5}, []FloatValue{{unixnano: 3, value: 3}}, 10)
+
+	if v := c.get(blockKey{r: r1, offset: 100}); v != nil {
+		t.Fatalf("expected block to be evicted, got %v", v)
+	}
+	if v := c.get(blockKey{r: r2, offset: 5}); v == nil {
+		t.Fatal("expected block to be cached")
+	}
+
+	// Blocks larger than the cache aren't cached.
+	c.put(blockKey{r: r2, offset: 100}, []FloatValue{}, 30)
+	if v := c.get(blockKey{r: r2, offset: 100}); v != nil {
+		t.Fatalf("expected block not to be cached, got %v", v)
+	}
+
+	c.evictFile(r1)
+	if v := c.get(blockKey{r: r1, offset: 5}); v != nil {
+		t.Fatalf("expected block of removed file to be evicted, got %v", v)
+	}
+
+	// Blocks read from views of a removed file aren't cached again.
+	c.put(blockKey{r: &TSMReader{parent: r1}, offset: 5}, []FloatValue{{unixnano: 1, value: 1}}, 10)
+	if v := c.get(blockKey{r: r1, offset: 5}); v != nil {
+		t.Fatalf("expected block of removed file not to be cached, got %v", v)
+	}
+
+	// Views share the blocks of the file they were taken of.
+	if v := c.get(blockKey{r: &TSMReader{parent: r2}, offset: 5}); v == nil {
+		t.Fatal("expected block of view to be cached")
+	}
+
+	if got, exp := c.statistics(), (blockCacheStatistics{Hits: 3, Misses: 4, Evictions: 1, Size: 10}); got != exp {
+		t.Fatalf("unexpected statistics: got %+v, exp %+v", got, exp)
+	}
+}
+
+func TestBlockCache_Nil(t *testing.T) {
+	var c *blockCache
+	c.evictFile(&TSMReader{})
+	if got := c.statistics(); got != (blockCacheStatistics{}) {
+		t.Fatalf("unexpected statistics: %+v", got)
+	}
+}
+
+// Ensure cursors read blocks from the cache of the file store and that replacing a
+// file evicts its blocks.
+func TestKeyCursor_BlockCache(t *testing.T) {
+	dir := mustTempDir()
+	defer os.RemoveAll(dir)
+
+	f := mustTempFile(dir)
+	w, err := NewTSMWriter(f)
+	if err != nil {
+		t.Fatalf("unexpected error creating writer: %v", err)
+	}
+	values := []Value{NewValue(0, 1.0), NewValue(1, 2.0), NewValue(2, 3.0)}
+	if err := w.Write([]byte("cpu"), values); err != nil {
+		t.Fatalf("unexpected error writing: %v", err)
+	}
+	if err := w.WriteIndex(); err != nil {
+		t.Fatalf("unexpected error writing index: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected error closing: %v", err)
+	}
+
+	fs := NewFileStore(dir)
+	fs.blocks = newBlockCache(1024)
+	defer fs.Close()
+	if err := fs.Replace(nil, []string{f.Name()}); err != nil {
+		t.Fatalf("unexpected error replacing files: %v", err)
+	}
+
+	exp := []FloatValue{{unixnano: 1, value: 2}, {unixnano: 2, value: 3}}
+	for i := 0; i < 2; i++ {
+		// Seeking past the first value removes it from the values read, which must
+		// not change the values cached.
+		c := fs.KeyCursor(context.Background(), []byte("cpu"), 1, true)
+		var buf []FloatValue
+		got, err := c.ReadFloatBlock(&buf)
+		if err != nil {
+			t.Fatalf("unexpected error reading values: %v", err)
+		}
+		c.Close()
+
+		if !reflect.DeepEqual(got, exp) {
+			t.Fatalf("unexpected values(%d): got %v, exp %v", i, got, exp)
+		}
+	}
+
+	if stats := fs.blocks.statistics(); stats.Hits != 1 || stats.Misses != 1 || stats.Size != 48 {
+		t.Fatalf("unexpected statistics: %+v", stats)
+	}
+
+	if err := fs.Replace([]string{f.Name()}, nil); err != nil {
+		t.Fatalf("unexpected error replacing files: %v", err)
+	}
+	if stats := fs.blocks.statistics(); stats.Size != 0 {
+		t.Fatalf("expected blocks of replaced file to be evicted: %+v", stats)
+	}
+}
@@ -0,0 +1,230 @@
+package tsm1
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// BlockStats holds aggregate statistics of the values in a numeric block.  Sum, Min,
+// Max, First and Last hold the bits of values of the block type: math.Float64bits
+// for floats and a conversion to uint64 for integers and unsigned integers.
+type BlockStats struct {
+	// Count is the number of values in the block.  Blocks without statistics
+	// have a count of zero.
+	Count uint32
+
+	Sum, Min, Max, First, Last uint64
+
+	// MinTime and MaxTime are the times of the first occurrence of the min
+	// and max values.
+	MinTime, MaxTime int64
+}
+
+// UnmarshalBinary decodes BlockStats from a byte slice.
+func (s *BlockStats) UnmarshalBinary(b []byte) error {
+	if len(b) < indexStatsSize {
+		return fmt.Errorf("unmarshalBinary: short buf: %v < %v", len(b), indexStatsSize)
+	}
+	s.Count = binary.BigEndian.Uint32(b[:4])
+	s.Sum = binary.BigEndian.Uint64(b[4:12])
+	s.Min = binary.BigEndian.Uint64(b[12:20])
+	s.MinTime = int64(binary.BigEndian.Uint64(b[20:28]))
+	s.Max = binary.BigEndian.Uint64(b[28:36])
+	s.MaxTime = int64(binary.BigEndian.Uint64(b[36:44]))
+	s.First = binary.BigEndian.Uint64(b[44:52])
+	s.Last = binary.BigEndian.Uint64(b[52:60])
+	return nil
+}
+
+// AppendTo writes a binary-encoded version of BlockStats to b, allocating
+// and returning a new slice, if necessary.
+func (s *BlockStats) AppendTo(b []byte) []byte {
+	if len(b) < indexStatsSize {
+		if cap(b) < indexStatsSize {
+			b = make([]byte, indexStatsSize)
+		} else {
+			b = b[:indexStatsSize]
+		}
+	}
+
+	binary.BigEndian.PutUint32(b[:4], s.Count)
+	binary.BigEndian.PutUint64(b[4:12], s.Sum)
+	binary.BigEndian.PutUint64(b[12:20], s.Min)
+	binary.BigEndian.PutUint64(b[20:28], uint64(s.MinTime))
+	binary.BigEndian.PutUint64(b[28:36], s.Max)
+	binary.BigEndian.PutUint64(b[36:44], uint64(s.MaxTime))
+	binary.BigEndian.PutUint64(b[44:52], s.First)
+	binary.BigEndian.PutUint64(b[52:60], s.Last)
+
+	return b
+}
+
+// addFloat adds a float value to the statistics.  Values must be added in time order.
+func (s *BlockStats) addFloat(t int64, v float64) {
+	bits := math.Float64bits(v)
+	if s.Count == 0 {
+		s.Sum, s.First = bits, bits
+		s.Min, s.MinTime = bits, t
+		s.Max, s.MaxTime = bits, t
+	} else {
+		s.Sum = math.Float64bits(math.Float64frombits(s.Sum) + v)
+		if v < math.Float64frombits(s.Min) {
+			s.Min, s.MinTime = bits, t
+		}
+		if v > math.Float64frombits(s.Max) {
+			s.Max, s.MaxTime = bits, t
+		}
+	}
+	s.Last = bits
+	s.Count++
+}
+
+// addInteger adds an integer value to the statistics.  Values must be added in time order.
+func (s *BlockStats) addInteger(t int64, v int64) {
+	bits := uint64(v)
+	if s.Count == 0 {
+		s.Sum, s.First = bits, bits
+		s.Min, s.MinTime = bits, t
+		s.Max, s.MaxTime = bits, t
+	} else {
+		s.Sum = uint64(int64(s.Sum) + v)
+		if v < int64(s.Min) {
+			s.Min, s.MinTime = bits, t
+		}
+		if v > int64(s.Max) {
+			s.Max, s.MaxTime = bits, t
+		}
+	}
+	s.Last = bits
+	s.Count++
+}
+
+// addUnsigned adds an unsigned value to the statistics.  Values must be added in time order.
+func (s *BlockStats) addUnsigned(t int64, v uint64) {
+	if s.Count == 0 {
+		s.Sum, s.First = v, v
+		s.Min, s.MinTime = v, t
+		s.Max, s.MaxTime = v, t
+	} else {
+		s.Sum += v
+		if v < s.Min {
+			s.Min, s.MinTime = v, t
+		}
+		if v > s.Max {
+			s.Max, s.MaxTime = v, t
+		}
+	}
+	s.Last = v
+	s.Count++
+}
+
+// newBlockStats returns the statistics of values.  Values that are not numeric have no
+// statistics.
+func newBlockStats(values Values) BlockStats {
+	var s BlockStats
+	for _, v := range values {
+		switch v := v.(type) {
+		case FloatValue:
+			s.addFloat(v.unixnano, v.value)
+		case IntegerValue:
+			s.addInteger(v.unixnano, v.value)
+		case UnsignedValue:
+			s.addUnsigned(v.unixnano, v.value)
+		default:
+			return BlockStats{}
+		}
+	}
+	return s
+}
+
+// stats returns the statistics of the values.
+func (a FloatValues) stats() BlockStats {
+	var s BlockStats
+	for _, v := range a {
+		s.addFloat(v.unixnano, v.value)
+	}
+	return s
+}
+
+// stats returns the statistics of the values.
+func (a IntegerValues) stats() BlockStats {
+	var s BlockStats
+	for _, v := range a {
+		s.addInteger(v.unixnano, v.value)
+	}
+	return s
+}
+
+// stats returns the statistics of the values.
+func (a UnsignedValues) stats() BlockStats {
+	var s BlockStats
+	for _, v := range a {
+		s.addUnsigned(v.unixnano, v.value)
+	}
+	return s
+}
+
+// stats returns no statistics, as boolean values are not numeric.
+func (a BooleanValues) stats() BlockStats { return BlockStats{} }
+
+// stats returns no statistics, as string values are not numeric.
+func (a StringValues) stats() BlockStats { return BlockStats{} }
+
+// blockStatsReader is implemented by key iterators that know the statistics of the
+// blocks they read, so the blocks are written without being decoded again.
+type blockStatsReader interface {
+	// readStats returns the statistics of the block returned by Read.  Blocks whose
+	// statistics are not known have a count of zero.
+	readStats() BlockStats
+}
+
+// blockStatsDecoder computes the statistics of encoded blocks, reusing the buffers
+// it decodes the blocks into.
+type blockStatsDecoder struct {
+	floats    []FloatValue
+	integers  []IntegerValue
+	unsigneds []UnsignedValue
+}
+
+// blockStats returns the statistics of the values in block.  Blocks that are not
+// numeric have no statistics.
+func (d *blockStatsDecoder) blockStats(block []byte) (BlockStats, error) {
+	var s BlockStats
+	switch block[0] {
+	case BlockFloat64:
+		values, err := DecodeFloatBlock(block, &d.floats)
+		if err != nil {
+			return s, err
+		}
+		for _, v := range values {
+			s.addFloat(v.unixnano, v.value)
+		}
+	case BlockInteger:
+		values, err := DecodeIntegerBlock(block, &d.integers)
+		if err != nil {
+			return s, err
+		}
+		for _, v := range values {
+			s.addInteger(v.unixnano, v.value)
+		}
+	case BlockUnsigned:
+		values, err := DecodeUnsignedBlock(block, &d.unsigneds)
+		if err != nil {
+			return s, err
+		}
+		for _, v := range values {
+			s.addUnsigned(v.unixnano, v.value)
+		}
+	}
+	return s, nil
+}
+
+// floatFromBits returns the float value stored in the bits of a BlockStats field.
+func floatFromBits(b uint64) float64 { return math.Float64frombits(b) }
+
+// integerFromBits returns the integer value stored in the bits of a BlockStats field.
+func integerFromBits(b uint64) int64 { return int64(b) }
+
+// unsignedFromBits returns the unsigned value stored in the bits of a BlockStats field.
+func unsignedFromBits(b uint64) uint64 { return b }
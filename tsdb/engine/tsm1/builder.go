@@ -0,0 +1,213 @@
+package tsm1
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxql"
+)
+
+// DefaultBuilderMaxValues is the default number of values a Builder buffers before
+// writing them to a new TSM file.
+const DefaultBuilderMaxValues = 10 * 1000 * 1000
+
+// Builder builds TSM files offline, outside of a running engine.  Values are buffered
+// in memory and written to a new TSM file in sorted order each time MaxValues is
+// reached.  The files it writes can be attached to a shard with Engine.IngestTSMFiles.
+type Builder struct {
+	// MaxValues is the number of values buffered before they are written to a new file.
+	MaxValues int
+
+	dir        string
+	generation int
+
+	values map[string][]Value
+	n      int
+
+	// types holds the type of each field written, keyed by measurement and field.
+	types map[string]influxql.DataType
+
+	files []string
+}
+
+// NewBuilder returns a new Builder writing TSM files to dir.  Generations of the new
+// files follow those of any TSM files already in dir.
+func NewBuilder(dir string) (*Builder, error) {
+	files, err := filepath.Glob(filepath.Join(dir, fmt.Sprintf("*.%s", TSMFileExtension)))
+	if err != nil {
+		return nil, err
+	}
+
+	var generation int
+	for _, f := range files {
+		gen, _, err := ParseTSMFileName(f)
+		if err != nil {
+			return nil, err
+		}
+		if gen > generation {
+			generation = gen
+		}
+	}
+
+	return &Builder{
+		MaxValues:  DefaultBuilderMaxValues,
+		dir:        dir,
+		generation: generation,
+		values:     make(map[string][]Value),
+		types:      make(map[string]influxql.DataType),
+	}, nil
+}
+
+// WritePoints adds the field values of points to the builder.
+func (b *Builder) WritePoints(points []models.Point) error {
+	values := make(map[string][]Value, len(points))
+	if err := appendPointValues(values, points); err != nil {
+		return err
+	}
+
+	for k, v := range values {
+		if err := b.Write([]byte(k), v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write adds values for the composite series and field key to the builder.  It returns
+// an error if a value has a different type than the values previously written to the
+// same field of the measurement.
+func (b *Builder) Write(key []byte, values Values) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	seriesKey, field := SeriesAndFieldFromCompositeKey(key)
+	if len(field) == 0 {
+		return fmt.Errorf("missing field key: %q", key)
+	}
+	name := tsdb.MeasurementFromSeriesKey(seriesKey)
+	fieldKey := string(name) + keyFieldSeparator + string(field)
+
+	for _, v := range values {
+		typ := valueDataType(v)
+		if typ == influxql.Unknown {
+			return fmt.Errorf("unsupported value type %T", v)
+		}
+
+		if existing, ok := b.types[fieldKey]; !ok {
+			b.types[fieldKey] = typ
+		} else if existing != typ {
+			return fmt.Errorf("%s: field %q on measurement %q is type %s, already written as type %s",
+				tsdb.ErrFieldTypeConflict, field, name, typ, existing)
+		}
+	}
+
+	b.values[string(key)] = append(b.values[string(key)], values...)
+	b.n += len(values)
+
+	if b.MaxValues > 0 && b.n >= b.MaxValues {
+		return b.Flush()
+	}
+	return nil
+}
+
+// Flush writes the buffered values to a new TSM file.
+func (b *Builder) Flush() error {
+	if b.n == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(b.values))
+	for k := range b.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	b.generation++
+	path := filepath.Join(b.dir, fmt.Sprintf("%09d-%09d.%s", b.generation, 1, TSMFileExtension))
+	tmp := path + "." + CompactionTempExtension
+
+	if err := b.writeFile(tmp, keys); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	if err := syncDir(b.dir); err != nil {
+		return err
+	}
+
+	b.files = append(b.files, path)
+	b.values = make(map[string][]Value)
+	b.n = 0
+	return nil
+}
+
+// writeFile writes the buffered values of keys to a new TSM file at path.
+func (b *Builder) writeFile(path string, keys []string) error {
+	fd, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_EXCL, 0666)
+	if err != nil {
+		return err
+	}
+
+	w, err := NewTSMWriter(fd)
+	if err != nil {
+		fd.Close()
+		return err
+	}
+
+	for _, k := range keys {
+		values := Values(b.values[k]).Deduplicate()
+		for len(values) > 0 {
+			n := tsdb.DefaultMaxPointsPerBlock
+			if n > len(values) {
+				n = len(values)
+			}
+
+			if err := w.Write([]byte(k), values[:n]); err != nil {
+				w.Remove()
+				return err
+			}
+			values = values[n:]
+		}
+	}
+
+	if err := w.WriteIndex(); err != nil {
+		w.Remove()
+		return err
+	}
+	return w.Close()
+}
+
+// Close writes any buffered values to a new TSM file.
+func (b *Builder) Close() error {
+	return b.Flush()
+}
+
+// Files returns the paths of the TSM files written by the builder.
+func (b *Builder) Files() []string {
+	return b.files
+}
+
+// valueDataType returns the influxql.DataType of v.
+func valueDataType(v Value) influxql.DataType {
+	switch v.(type) {
+	case FloatValue:
+		return influxql.Float
+	case IntegerValue:
+		return influxql.Integer
+	case UnsignedValue:
+		return influxql.Unsigned
+	case BooleanValue:
+		return influxql.Boolean
+	case StringValue:
+		return influxql.String
+	}
+	return influxql.Unknown
+}
@@ -0,0 +1,82 @@
+package tsm1_test
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxdb/tsdb/engine/tsm1"
+)
+
+func TestBuilder_WritePoints(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	b, err := tsm1.NewBuilder(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.MaxValues = 2
+
+	// The second batch reaches MaxValues and is written to its own file.
+	if err := b.WritePoints(MustParsePointsString(`cpu,host=A value=1.1 2000000000`)); err != nil {
+		t.Fatal(err)
+	} else if err := b.WritePoints(MustParsePointsString(`cpu,host=B value=1.2 1000000000`)); err != nil {
+		t.Fatal(err)
+	} else if err := b.WritePoints(MustParsePointsString(`cpu,host=A value=1.3 1000000000`)); err != nil {
+		t.Fatal(err)
+	}
+
+	// A field keeps its type across series and files.
+	if err := b.WritePoints(MustParsePointsString(`cpu,host=C value="x" 1000000000`)); err == nil || !strings.Contains(err.Error(), tsdb.ErrFieldTypeConflict.Error()) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	files := b.Files()
+	if len(files) != 2 {
+		t.Fatalf("unexpected files: %v", files)
+	}
+
+	r := MustOpenTSMReader(files[0])
+	defer r.Close()
+
+	if n := r.KeyCount(); n != 2 {
+		t.Fatalf("unexpected key count: %d", n)
+	}
+	if key, _ := r.KeyAt(0); string(key) != "cpu,host=A#!~#value" {
+		t.Fatalf("unexpected first key: %s", key)
+	}
+
+	// New builders continue after the generations already in the directory.
+	b, err = tsm1.NewBuilder(dir)
+	if err != nil {
+		t.Fatal(err)
+	} else if err := b.Write([]byte("cpu,host=A#!~#value"), tsm1.Values{tsm1.NewValue(0, 1.0)}); err != nil {
+		t.Fatal(err)
+	} else if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	gen, _, err := tsm1.ParseTSMFileName(b.Files()[0])
+	if err != nil {
+		t.Fatal(err)
+	} else if gen != 3 {
+		t.Fatalf("unexpected generation: %d", gen)
+	}
+
+	r2 := MustOpenTSMReader(files[1])
+	defer r2.Close()
+
+	values, err := r2.ReadAll([]byte("cpu,host=A#!~#value"))
+	if err != nil {
+		t.Fatal(err)
+	} else if exp := (tsm1.Values{tsm1.NewValue(1000000000, 1.3)}); !reflect.DeepEqual(values, exp) {
+		t.Fatalf("unexpected values: %v", values)
+	}
+}
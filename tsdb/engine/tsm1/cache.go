@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/bytesutil"
 	"github.com/influxdata/influxdb/tsdb"
 	"github.com/influxdata/influxql"
 	"go.uber.org/zap"
@@ -172,6 +173,9 @@ const (
 	statCacheWriteOK      = "writeOk"
 	statCacheWriteErr     = "writeErr"
 	statCacheWriteDropped = "writeDropped"
+
+	statCacheSpilledBytes   = "spilledBytes"   // counter: Total number of bytes spilled to disk to make room for writes
+	statCacheSpillDiskBytes = "spillDiskBytes" // level: Size of spilled runs on disk in bytes
 )
 
 // storer is the interface that descibes a cache's store.
@@ -209,6 +213,24 @@ type Cache struct {
 	// This number is the number of pending or failed WriteSnaphot attempts since the last successful one.
 	snapshotAttempts int
 
+	// spillDir is the directory that the store is spilled to when a write would exceed
+	// maxSize.  Writes exceeding maxSize are rejected if it is empty.
+	spillDir string
+
+	// spillMu serializes spills with snapshots and deletes.  It is acquired before mu.
+	spillMu sync.Mutex
+
+	// spills are the sorted runs spilled to disk since the last snapshot, oldest first.
+	// They are moved to the snapshot along with the store and merged into its TSM files.
+	spills []*spillRun
+
+	// spilling is the store being written to a new run, which is still read by queries.
+	spilling storer
+
+	// views is the number of read snapshots of the cache that have not been released.
+	// The snapshot's store is not reused while any are open.
+	views int
+
 	stats        *CacheStatistics
 	lastSnapshot time.Time
 
@@ -247,6 +269,8 @@ type CacheStatistics struct {
 	WriteOK             int64
 	WriteErr            int64
 	WriteDropped        int64
+	SpilledBytes        int64
+	SpillDiskBytes      int64
 }
 
 // Statistics returns statistics for periodic monitoring.
@@ -264,6 +288,8 @@ func (c *Cache) Statistics(tags map[string]string) []models.Statistic {
 			statCacheWriteOK:        atomic.LoadInt64(&c.stats.WriteOK),
 			statCacheWriteErr:       atomic.LoadInt64(&c.stats.WriteErr),
 			statCacheWriteDropped:   atomic.LoadInt64(&c.stats.WriteDropped),
+			statCacheSpilledBytes:   atomic.LoadInt64(&c.stats.SpilledBytes),
+			statCacheSpillDiskBytes: atomic.LoadInt64(&c.stats.SpillDiskBytes),
 		},
 	}}
 }
@@ -292,22 +318,23 @@ func (c *Cache) Free() {
 }
 
 // Write writes the set of values for the key to the cache. This function is goroutine-safe.
-// It returns an error if the cache will exceed its max size by adding the new values.
+// It returns an error if the cache will exceed its max size by adding the new values,
+// unless the cache spills to disk.
 func (c *Cache) Write(key []byte, values []Value) error {
 	c.init()
 	addedSize := uint64(Values(values).Size())
 
 	// Enough room in the cache?
-	limit := c.maxSize
-	n := c.Size() + addedSize
-
-	if limit > 0 && n > limit {
+	if err := c.reserve(addedSize); err != nil {
 		atomic.AddInt64(&c.stats.WriteErr, 1)
-		return ErrCacheMemorySizeLimitExceeded(n, limit)
+		return err
 	}
 
+	// The store cannot be spilled while it is being written to.
+	c.mu.RLock()
 	newKey, err := c.store.write(key, values)
 	if err != nil {
+		c.mu.RUnlock()
 		atomic.AddInt64(&c.stats.WriteErr, 1)
 		return err
 	}
@@ -317,6 +344,7 @@ func (c *Cache) Write(key []byte, values []Value) error {
 	}
 	// Update the cache size and the memory size stat.
 	c.increaseSize(addedSize)
+	c.mu.RUnlock()
 	c.updateMemSize(int64(addedSize))
 	atomic.AddInt64(&c.stats.WriteOK, 1)
 
@@ -325,9 +353,9 @@ func (c *Cache) Write(key []byte, values []Value) error {
 
 // WriteMulti writes the map of keys and associated values to the cache. This
 // function is goroutine-safe. It returns an error if the cache will exceeded
-// its max size by adding the new values.  The write attempts to write as many
-// values as possible.  If one key fails, the others can still succeed and an
-// error will be returned.
+// its max size by adding the new values, unless the cache spills to disk.  The
+// write attempts to write as many values as possible.  If one key fails, the
+// others can still succeed and an error will be returned.
 func (c *Cache) WriteMulti(values map[string][]Value) error {
 	c.init()
 	var addedSize uint64
@@ -336,17 +364,16 @@ func (c *Cache) WriteMulti(values map[string][]Value) error {
 	}
 
 	// Enough room in the cache?
-	limit := c.maxSize // maxSize is safe for reading without a lock.
-	n := c.Size() + addedSize
-	if limit > 0 && n > limit {
+	if err := c.reserve(addedSize); err != nil {
 		atomic.AddInt64(&c.stats.WriteErr, 1)
-		return ErrCacheMemorySizeLimitExceeded(n, limit)
+		return err
 	}
 
 	var werr error
+
+	// The store cannot be spilled while it is being written to.
 	c.mu.RLock()
 	store := c.store
-	c.mu.RUnlock()
 
 	// We'll optimistially set size here, and then decrement it for write errors.
 	c.increaseSize(addedSize)
@@ -363,6 +390,7 @@ func (c *Cache) WriteMulti(values map[string][]Value) error {
 			c.increaseSize(uint64(len(k)))
 		}
 	}
+	c.mu.RUnlock()
 
 	// Some points in the batch were dropped.  An error is returned so
 	// error stat is incremented as well.
@@ -378,11 +406,30 @@ func (c *Cache) WriteMulti(values map[string][]Value) error {
 	return werr
 }
 
+// reserve makes room in the cache for n more bytes.  If the cache would exceed its max
+// size, it spills the store to disk when a spill directory is set and otherwise returns
+// an error.
+func (c *Cache) reserve(n uint64) error {
+	limit := c.maxSize // maxSize is safe for reading without a lock.
+	size := c.Size() + n
+	if limit == 0 || size <= limit {
+		return nil
+	}
+
+	if c.spillDir == "" {
+		return ErrCacheMemorySizeLimitExceeded(size, limit)
+	}
+	return c.spill(n)
+}
+
 // Snapshot takes a snapshot of the current cache, adds it to the slice of caches that
 // are being flushed, and resets the current cache with new values.
 func (c *Cache) Snapshot() (*Cache, error) {
 	c.init()
 
+	c.spillMu.Lock()
+	defer c.spillMu.Unlock()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -407,11 +454,12 @@ func (c *Cache) Snapshot() (*Cache, error) {
 
 	// Did a prior snapshot exist that failed?  If so, return the existing
 	// snapshot to retry.
-	if c.snapshot.Size() > 0 {
+	if c.snapshot.Size() > 0 || len(c.snapshot.spills) > 0 {
 		return c.snapshot, nil
 	}
 
 	c.snapshot.store, c.store = c.store, c.snapshot.store
+	c.snapshot.spills, c.spills = c.spills, nil
 	snapshotSize := c.Size()
 
 	// Save the size of the snapshot on the snapshot cache
@@ -449,10 +497,12 @@ func (c *Cache) ClearSnapshot(success bool) {
 
 	c.mu.RLock()
 	snapStore := c.snapshot.store
+	viewed := c.views > 0
 	c.mu.RUnlock()
 
-	// reset the snapshot store outside of the write lock
-	if success {
+	// reset the snapshot store outside of the write lock, unless a read snapshot may
+	// still read it.
+	if success && !viewed {
 		snapStore.reset()
 	}
 
@@ -465,13 +515,24 @@ func (c *Cache) ClearSnapshot(success bool) {
 		c.snapshotAttempts = 0
 		c.updateMemSize(-int64(atomic.LoadUint64(&c.snapshotSize))) // decrement the number of bytes in cache
 
-		// Reset the snapshot to a fresh Cache.
+		// The spilled runs have been merged into the snapshot's TSM files.  Runs that
+		// cannot be removed are removed when the engine is next opened.
+		for _, run := range c.snapshot.spills {
+			run.remove()
+		}
+
+		// Reset the snapshot to a fresh Cache.  The store is replaced rather than reused
+		// if a read snapshot may still read it.
+		if viewed {
+			snapStore, _ = newring(ringShards)
+		}
 		c.snapshot = &Cache{
-			store: c.snapshot.store,
+			store: snapStore,
 		}
 
 		atomic.StoreUint64(&c.snapshotSize, 0)
 		c.updateSnapshots()
+		c.updateSpills()
 	}
 }
 
@@ -526,6 +587,185 @@ func (c *Cache) Split(n int) []*Cache {
 	return caches
 }
 
+// keysWithSnapshot returns a sorted slice of all keys in the cache, including the
+// keys of a snapshot that is being written.
+func (c *Cache) keysWithSnapshot() [][]byte {
+	c.spillMu.Lock()
+	defer c.spillMu.Unlock()
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	keys := c.store.keys(false)
+	seen := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		seen[string(k)] = struct{}{}
+	}
+	add := func(other [][]byte) {
+		for _, k := range other {
+			if _, ok := seen[string(k)]; !ok {
+				seen[string(k)] = struct{}{}
+				keys = append(keys, k)
+			}
+		}
+	}
+
+	for _, run := range c.spills {
+		add(run.keys())
+	}
+	if c.snapshot != nil {
+		add(c.snapshot.store.keys(false))
+		for _, run := range c.snapshot.spills {
+			add(run.keys())
+		}
+	}
+	bytesutil.Sort(keys)
+	return keys
+}
+
+// cacheView is a read snapshot of the values of a cache.  It reads the stores and runs
+// the cache held when it was taken, none of which are written to again.
+type cacheView struct {
+	cache  *Cache
+	runs   []*spillRun // oldest first
+	pinned []*spillRun // runs on disk that are not removed until the view is released
+}
+
+// view returns a read snapshot of the values in the cache, including the values of a
+// snapshot being written and of spilled runs.  The store is sealed as a run in memory
+// rather than copied, so later writes go to a new store.  The view must be released.
+func (c *Cache) view() (*cacheView, error) {
+	c.init()
+
+	store, err := newring(ringShards)
+	if err != nil {
+		return nil, err
+	}
+
+	c.spillMu.Lock()
+	defer c.spillMu.Unlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.store.count() > 0 {
+		// The size of the store is what remains of the cache's size once the runs
+		// already sealed in memory are accounted for.
+		size := atomic.LoadUint64(&c.size)
+		for _, run := range c.spills {
+			if run.store != nil {
+				size -= run.size
+			}
+		}
+		c.spills = append(c.spills, &spillRun{store: c.store, size: size})
+		c.store = store
+	}
+
+	v := &cacheView{cache: c}
+	add := func(runs []*spillRun) {
+		for _, run := range runs {
+			if run.store != nil {
+				v.runs = append(v.runs, run)
+				continue
+			}
+
+			// Deletes tombstone the run's file, so the view reads it through an index
+			// that they do not change.
+			run.ref()
+			v.pinned = append(v.pinned, run)
+			v.runs = append(v.runs, &spillRun{r: run.r.readView()})
+		}
+	}
+	if c.snapshot != nil {
+		add(c.snapshot.spills)
+		if c.snapshot.store.count() > 0 {
+			v.runs = append(v.runs, &spillRun{store: c.snapshot.store})
+		}
+	}
+	add(c.spills)
+
+	c.views++
+	return v, nil
+}
+
+// values returns the values of key in the view, deduped and sorted.
+func (v *cacheView) values(key []byte) (Values, error) {
+	var values Values
+	for _, run := range v.runs {
+		a, err := run.values(key)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, a...)
+	}
+	return values.Deduplicate(), nil
+}
+
+// release releases the stores and runs read by the view.
+func (v *cacheView) release() error {
+	var err error
+	for _, run := range v.pinned {
+		if e := run.unref(); e != nil && err == nil {
+			err = e
+		}
+	}
+	v.pinned = nil
+
+	v.cache.mu.Lock()
+	v.cache.views--
+	v.cache.mu.Unlock()
+	return err
+}
+
+// mergeSealedSnapshot merges the runs of a snapshot that were sealed in memory by read
+// snapshots into the snapshot's store, so a snapshot that spilled nothing to disk is
+// written like any other.  The sealed stores are copied, since views may still read them.
+func (c *Cache) mergeSealedSnapshot() error {
+	c.mu.RLock()
+	runs, sealed := c.snapshot.spills, c.snapshot.store
+	c.mu.RUnlock()
+
+	if len(runs) == 0 {
+		return nil
+	}
+	for _, run := range runs {
+		if run.store == nil {
+			return nil
+		}
+	}
+
+	store, err := newring(ringShards)
+	if err != nil {
+		return err
+	}
+
+	copyStore := func(src storer) error {
+		return src.applySerial(func(k []byte, e *entry) error {
+			e.mu.RLock()
+			values := append(Values(nil), e.values...)
+			e.mu.RUnlock()
+
+			_, err := store.write(k, values)
+			return err
+		})
+	}
+	for _, run := range runs {
+		if err := copyStore(run.store); err != nil {
+			return err
+		}
+	}
+	if err := copyStore(sealed); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.snapshot.mu.Lock()
+	c.snapshot.store, c.snapshot.spills = store, nil
+	c.snapshot.mu.Unlock()
+	c.mu.Unlock()
+	return nil
+}
+
 // unsortedKeys returns a slice of all keys under management by the cache. The
 // keys are not sorted.
 func (c *Cache) unsortedKeys() [][]byte {
@@ -535,37 +775,75 @@ func (c *Cache) unsortedKeys() [][]byte {
 	return store.keys(false)
 }
 
-// Values returns a copy of all values, deduped and sorted, for the given key.
+// Values returns a copy of all values, deduped and sorted, for the given key.  Values
+// in spilled runs that can't be read are left out; use ReadValues to get the error.
 func (c *Cache) Values(key []byte) Values {
-	var snapshotEntries *entry
+	values, _ := c.ReadValues(key)
+	return values
+}
+
+// ReadValues returns a copy of all values, deduped and sorted, for the given key.  An
+// error is returned if a run spilled to disk can't be read.
+func (c *Cache) ReadValues(key []byte) (Values, error) {
+	var snapshotEntries, spillingEntries *entry
+	var snapshotSpilled, spilled []*entry
+	var err error
 
 	c.mu.RLock()
 	e := c.store.entry(key)
 	if c.snapshot != nil {
 		snapshotEntries = c.snapshot.store.entry(key)
+		snapshotSpilled, err = spilledEntries(c.snapshot.spills, key)
+	}
+	if err == nil {
+		spilled, err = spilledEntries(c.spills, key)
+	}
+	if c.spilling != nil {
+		spillingEntries = c.spilling.entry(key)
 	}
 	c.mu.RUnlock()
 
+	if err != nil {
+		return nil, err
+	}
+
 	if e == nil {
-		if snapshotEntries == nil {
+		if snapshotEntries == nil && spillingEntries == nil && len(snapshotSpilled) == 0 && len(spilled) == 0 {
 			// No values in hot cache or snapshots.
-			return nil
+			return nil, nil
 		}
 	} else {
 		e.deduplicate()
 	}
 
 	// Build the sequence of entries that will be returned, in the correct order.
-	// Calculate the required size of the destination buffer.
+	// Spilled runs are older than the store they were spilled from.  Calculate the
+	// required size of the destination buffer.
 	var entries []*entry
 	sz := 0
 
+	for _, se := range snapshotSpilled {
+		entries = append(entries, se)
+		sz += se.count()
+	}
+
 	if snapshotEntries != nil {
 		snapshotEntries.deduplicate() // guarantee we are deduplicated
 		entries = append(entries, snapshotEntries)
 		sz += snapshotEntries.count()
 	}
 
+	for _, se := range spilled {
+		entries = append(entries, se)
+		sz += se.count()
+	}
+
+	if spillingEntries != nil {
+		spillingEntries.deduplicate()
+		entries = append(entries, spillingEntries)
+		sz += spillingEntries.count()
+	}
+
 	if e != nil {
 		entries = append(entries, e)
 		sz += e.count()
@@ -573,7 +851,7 @@ func (c *Cache) Values(key []byte) Values {
 
 	// Any entries? If not, return.
 	if sz == 0 {
-		return nil
+		return nil, nil
 	}
 
 	// Create the buffer, and copy all hot values and snapshots. Individual
@@ -589,7 +867,22 @@ func (c *Cache) Values(key []byte) Values {
 	values = values[:n]
 	values = values.Deduplicate()
 
-	return values
+	return values, nil
+}
+
+// spilledEntries returns an entry for the values of key in each of runs that holds
+// the key.  The caller must hold the cache's read lock while the runs are read.
+func spilledEntries(runs []*spillRun, key []byte) ([]*entry, error) {
+	var entries []*entry
+	for _, run := range runs {
+		values, err := run.values(key)
+		if err != nil {
+			return nil, err
+		} else if len(values) > 0 {
+			entries = append(entries, &entry{values: values})
+		}
+	}
+	return entries, nil
 }
 
 // Delete removes all values for the given keys from the cache.
@@ -604,6 +897,15 @@ func (c *Cache) Delete(keys [][]byte) {
 func (c *Cache) DeleteRange(keys [][]byte, min, max int64) {
 	c.init()
 
+	c.spillMu.Lock()
+	defer c.spillMu.Unlock()
+
+	c.deleteRange(keys, min, max)
+}
+
+// deleteRange removes the values of keys with timestamps between min and max from the
+// cache's store.  The caller must hold spillMu.
+func (c *Cache) deleteRange(keys [][]byte, min, max int64) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -731,10 +1033,16 @@ func (cl *CacheLoader) Load(cache *Cache) error {
 					if err := cache.WriteMulti(t.Values); err != nil {
 						return err
 					}
+				// Values loaded earlier may have been spilled to make room for
+				// later writes.
 				case *DeleteRangeWALEntry:
-					cache.DeleteRange(t.Keys, t.Min, t.Max)
+					if err := cache.deleteRangeWithSpills(t.Keys, t.Min, t.Max); err != nil {
+						return err
+					}
 				case *DeleteWALEntry:
-					cache.Delete(t.Keys)
+					if err := cache.deleteRangeWithSpills(t.Keys, math.MinInt64, math.MaxInt64); err != nil {
+						return err
+					}
 				}
 			}
 
@@ -0,0 +1,49 @@
+package tsm1
+
+import "sort"
+
+// splitLate splits the values of the cache into the values newer than those persisted
+// for their key and the late values falling into time ranges already persisted, which
+// are returned as separate caches to be written to their own generations.  maxTime
+// returns the max time persisted for a key.  The cache must be deduplicated first.  It
+// is not modified, so its values remain readable while the returned caches are written.
+// If none of the values are late, the cache itself is returned with a nil late cache.
+func (c *Cache) splitLate(maxTime func(key []byte) (int64, bool)) (onTime, late *Cache, n int, err error) {
+	onTimeStore, err := newring(ringShards)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	lateStore, err := newring(ringShards)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	if err := c.ApplyEntryFn(func(key []byte, e *entry) error {
+		e.mu.RLock()
+		values := e.values
+		e.mu.RUnlock()
+
+		// The values are sorted, so the late values are the ones up to the first value
+		// after the persisted max time.
+		var i int
+		if max, ok := maxTime(key); ok {
+			i = sort.Search(len(values), func(i int) bool { return values[i].UnixNano() > max })
+		}
+
+		if i > 0 {
+			lateStore.add(key, &entry{values: values[:i:i], vtype: e.vtype})
+			n += i
+		}
+		if i < len(values) {
+			onTimeStore.add(key, &entry{values: values[i:], vtype: e.vtype})
+		}
+		return nil
+	}); err != nil {
+		return nil, nil, 0, err
+	}
+
+	if n == 0 {
+		return c, nil, 0, nil
+	}
+	return &Cache{store: onTimeStore}, &Cache{store: lateStore}, n, nil
+}
@@ -0,0 +1,520 @@
+package tsm1
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// cacheSpillDir is the directory of the shard holding the runs spilled by the cache.
+// The WAL holds every spilled value until it is snapshotted, so the directory is
+// removed when the engine is opened.
+const cacheSpillDir = "spill"
+
+// spillRun is a sorted run of cache values spilled to a TSM file, or a store sealed in
+// memory by a read snapshot.  Sealed stores are not written to again, so both kinds of
+// runs only change through deletes.
+type spillRun struct {
+	r     *TSMReader
+	store storer // the sealed store, if the run is in memory
+	size  uint64 // size of the values in the cache before they were spilled
+
+	mu      sync.Mutex
+	refs    int  // number of read snapshots reading the run
+	removed bool // the run is removed once no read snapshot reads it
+}
+
+// values returns the values of key in the run.  An error is returned if the run's file
+// can't be read, so values are never left out of reads silently.
+func (s *spillRun) values(key []byte) (Values, error) {
+	if s.store != nil {
+		e := s.store.entry(key)
+		if e == nil {
+			return nil, nil
+		}
+
+		// Once sorted, the values of a sealed store never change.
+		e.deduplicate()
+		e.mu.RLock()
+		v := e.values
+		e.mu.RUnlock()
+		return v, nil
+	}
+
+	v, err := s.r.ReadAll(key)
+	if err != nil {
+		return nil, fmt.Errorf("read spilled cache run %s: %s", s.r.Path(), err)
+	}
+	return v, nil
+}
+
+// keys returns a copy of the keys in the run.
+func (s *spillRun) keys() [][]byte {
+	if s.store != nil {
+		return s.store.keys(false)
+	}
+
+	n := s.r.KeyCount()
+	keys := make([][]byte, 0, n)
+	for i := 0; i < n; i++ {
+		key, _ := s.r.KeyAt(i)
+		keys = append(keys, append([]byte(nil), key...))
+	}
+	return keys
+}
+
+// remove closes the run and removes its file and tombstones, once no read snapshot reads
+// the run.
+func (s *spillRun) remove() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.r == nil || s.removed {
+		return nil
+	}
+	s.removed = true
+
+	if s.refs > 0 {
+		return nil
+	}
+	return s.removeFile()
+}
+
+// ref records that a read snapshot reads the run.
+func (s *spillRun) ref() {
+	s.mu.Lock()
+	s.refs++
+	s.mu.Unlock()
+}
+
+// unref records that a read snapshot no longer reads the run, and removes the run if it
+// was removed while it was read.
+func (s *spillRun) unref() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.refs--
+	if s.refs > 0 || !s.removed {
+		return nil
+	}
+	return s.removeFile()
+}
+
+// removeFile closes the run and removes its file and tombstones.  The caller must hold mu.
+func (s *spillRun) removeFile() error {
+	if err := s.r.Remove(); err != nil {
+		return err
+	}
+	return s.r.Close()
+}
+
+// without returns a copy of a run in memory without the values of keys with timestamps
+// between min and max, along with the size of the values removed.  The sealed store
+// may be read by read snapshots, so it is not changed.
+func (s *spillRun) without(keys [][]byte, min, max int64) (*spillRun, uint64, error) {
+	deleted := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		deleted[string(k)] = struct{}{}
+	}
+
+	store, err := newring(ringShards)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var removed uint64
+	if err := s.store.applySerial(func(k []byte, e *entry) error {
+		if _, ok := deleted[string(k)]; !ok {
+			store.add(k, e)
+			return nil
+		}
+
+		e.mu.RLock()
+		values := append(Values(nil), e.values...)
+		e.mu.RUnlock()
+
+		n := uint64(values.Size())
+		values = values.Exclude(min, max)
+		removed += n - uint64(values.Size())
+		if len(values) == 0 {
+			removed += uint64(len(k))
+			return nil
+		}
+		store.add(k, &entry{values: values, vtype: e.vtype})
+		return nil
+	}); err != nil {
+		return nil, 0, err
+	}
+
+	if removed == 0 {
+		return s, 0, nil
+	}
+	return &spillRun{store: store, size: s.size - removed}, removed, nil
+}
+
+// writeSpillRun writes the values of store to a new TSM file in dir, sorted by key.
+// It returns a nil run if the store has no values.
+func writeSpillRun(dir string, store storer) (*spillRun, error) {
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return nil, err
+	}
+
+	f, err := ioutil.TempFile(dir, "run-")
+	if err != nil {
+		return nil, err
+	}
+	path := f.Name()
+
+	w, err := NewTSMWriter(f)
+	if err != nil {
+		f.Close()
+		os.Remove(path)
+		return nil, err
+	}
+
+	if err := func() error {
+		for _, key := range store.keys(true) {
+			e := store.entry(key)
+			if e == nil {
+				continue
+			}
+			e.deduplicate()
+
+			e.mu.RLock()
+			values := e.values
+			e.mu.RUnlock()
+
+			for len(values) > 0 {
+				n := tsdb.DefaultMaxPointsPerBlock
+				if n > len(values) {
+					n = len(values)
+				}
+				if err := w.Write(key, values[:n]); err != nil {
+					return err
+				}
+				values = values[n:]
+			}
+		}
+		return w.WriteIndex()
+	}(); err == ErrNoValues {
+		w.Remove()
+		os.Remove(path)
+		return nil, nil
+	} else if err != nil {
+		w.Remove()
+		os.Remove(path)
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+
+	fd, err := os.Open(path)
+	if err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+
+	r, err := NewTSMReader(fd)
+	if err != nil {
+		fd.Close()
+		os.Remove(path)
+		return nil, err
+	}
+	return &spillRun{r: r}, nil
+}
+
+// spill writes the values in the cache's store to a new sorted run on disk, making
+// room for n more bytes.  Writers exceeding the max size wait for the spill in progress,
+// so writes are slowed down rather than rejected while the cache is full.  The write
+// goes ahead once the store is spilled, even if a snapshot still holds more than the
+// max size.
+func (c *Cache) spill(n uint64) error {
+	c.spillMu.Lock()
+	defer c.spillMu.Unlock()
+
+	// Another writer may have made room while this one waited.
+	if c.Size()+n <= c.maxSize {
+		return nil
+	}
+
+	if err := c.spillSealed(); err != nil {
+		return err
+	} else if c.Size()+n <= c.maxSize {
+		return nil
+	}
+
+	store, err := newring(ringShards)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	sealed := c.store
+	if sealed.count() == 0 {
+		// Only a snapshot being written holds values, so there is nothing to spill.
+		c.mu.Unlock()
+		return nil
+	}
+	c.store, c.spilling = store, sealed
+	size := atomic.LoadUint64(&c.size)
+	c.mu.Unlock()
+
+	run, err := writeSpillRun(c.spillDir, sealed)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.spilling = nil
+	if err != nil {
+		c.unspill(sealed)
+		return err
+	}
+
+	if run != nil {
+		run.size = size
+		c.spills = append(c.spills, run)
+		atomic.AddInt64(&c.stats.SpilledBytes, int64(size))
+	}
+	c.decreaseSize(size)
+	c.updateMemSize(-int64(size))
+	c.updateSpills()
+	return nil
+}
+
+// spillSealed writes the stores sealed in memory by read snapshots to runs on disk, which
+// replace them.  The read snapshots keep reading the stores they sealed.  The caller must
+// hold spillMu.
+func (c *Cache) spillSealed() error {
+	c.mu.RLock()
+	runs := append([]*spillRun(nil), c.spills...)
+	c.mu.RUnlock()
+
+	spills := make([]*spillRun, 0, len(runs))
+	var written []*spillRun
+	var size uint64
+	for _, run := range runs {
+		if run.store == nil {
+			spills = append(spills, run)
+			continue
+		}
+
+		spilled, err := writeSpillRun(c.spillDir, run.store)
+		if err != nil {
+			for _, run := range written {
+				run.remove()
+			}
+			return err
+		}
+
+		size += run.size
+		if spilled != nil {
+			spilled.size = run.size
+			spills = append(spills, spilled)
+			written = append(written, spilled)
+		}
+	}
+
+	if size == 0 && len(spills) == len(runs) {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.spills = spills
+	atomic.AddInt64(&c.stats.SpilledBytes, int64(size))
+	c.decreaseSize(size)
+	c.updateMemSize(-int64(size))
+	c.updateSpills()
+	return nil
+}
+
+// unspill returns the values of a store that could not be spilled to the cache's store.
+// The values of the sealed store are older than those written since it was sealed.
+// The caller must hold the write lock.
+func (c *Cache) unspill(sealed storer) {
+	_ = sealed.applySerial(func(k []byte, e *entry) error {
+		cur := c.store.entry(k)
+		if cur == nil {
+			c.store.add(k, e)
+			return nil
+		}
+
+		cur.mu.Lock()
+		cur.values = append(e.values, cur.values...)
+		cur.mu.Unlock()
+		return nil
+	})
+}
+
+// hasSpills returns true if values have been spilled to disk since the last snapshot.
+func (c *Cache) hasSpills() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, run := range c.spills {
+		if run.r != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// spilledKeys returns the keys spilled since the last snapshot.  The keys are not
+// sorted and may contain duplicates.  It waits for a spill in progress to complete.
+func (c *Cache) spilledKeys() [][]byte {
+	c.spillMu.Lock()
+	defer c.spillMu.Unlock()
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var keys [][]byte
+	for _, run := range c.spills {
+		keys = append(keys, run.keys()...)
+	}
+	return keys
+}
+
+// deleteSeriesRange removes the values with timestamps between min and max of every
+// key matched by fn from the cache and from the runs it spilled since the last
+// snapshot.  It returns the keys matched, which the caller removes from the WAL.
+// Keys are matched and deleted under a single hold of spillMu, so a spill cannot
+// move values from the store to a run in between.
+func (c *Cache) deleteSeriesRange(fn func(key []byte) bool, min, max int64) ([][]byte, error) {
+	c.init()
+
+	c.spillMu.Lock()
+	defer c.spillMu.Unlock()
+
+	var keys [][]byte
+	seen := make(map[string]struct{})
+
+	// ApplyEntryFn cannot return an error in this invocation.
+	_ = c.ApplyEntryFn(func(k []byte, _ *entry) error {
+		if fn(k) {
+			seen[string(k)] = struct{}{}
+			keys = append(keys, k)
+		}
+		return nil
+	})
+
+	var spilled [][]byte
+	c.mu.RLock()
+	for _, run := range c.spills {
+		for _, k := range run.keys() {
+			if fn(k) {
+				spilled = append(spilled, k)
+			}
+		}
+	}
+	c.mu.RUnlock()
+
+	c.deleteRange(keys, min, max)
+	if err := c.deleteSpilledRange(spilled, min, max); err != nil {
+		return nil, err
+	}
+
+	for _, k := range spilled {
+		if _, ok := seen[string(k)]; !ok {
+			seen[string(k)] = struct{}{}
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+
+// deleteRangeWithSpills removes the values of keys with timestamps between min and
+// max from the cache and from the runs it spilled since the last snapshot.
+func (c *Cache) deleteRangeWithSpills(keys [][]byte, min, max int64) error {
+	c.init()
+
+	c.spillMu.Lock()
+	defer c.spillMu.Unlock()
+
+	c.deleteRange(keys, min, max)
+	return c.deleteSpilledRange(keys, min, max)
+}
+
+// deleteSpilledRange removes the values of keys with timestamps between min and max
+// from the runs spilled or sealed since the last snapshot.  The caller must hold spillMu.
+func (c *Cache) deleteSpilledRange(keys [][]byte, min, max int64) error {
+	c.mu.RLock()
+	runs := append([]*spillRun(nil), c.spills...)
+	c.mu.RUnlock()
+
+	for i, run := range runs {
+		if run.store == nil {
+			if err := run.r.DeleteRange(keys, min, max); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// A sealed store is replaced by a copy without the deleted values.
+		filtered, n, err := run.without(keys, min, max)
+		if err != nil {
+			return err
+		} else if n == 0 {
+			continue
+		}
+
+		c.mu.Lock()
+		c.spills[i] = filtered
+		c.decreaseSize(n)
+		c.mu.Unlock()
+	}
+	atomic.StoreInt64(&c.stats.MemSizeBytes, int64(c.Size()))
+	return nil
+}
+
+// removeSpills removes the runs spilled by the cache and its snapshot.  The values
+// are still in the WAL and are loaded back into the cache when the engine is opened.
+func (c *Cache) removeSpills() error {
+	c.spillMu.Lock()
+	defer c.spillMu.Unlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	runs := c.spills
+	c.spills = nil
+	if c.snapshot != nil {
+		runs = append(runs, c.snapshot.spills...)
+		c.snapshot.spills = nil
+	}
+
+	var err error
+	for _, run := range runs {
+		if e := run.remove(); e != nil && err == nil {
+			err = e
+		}
+	}
+	c.updateSpills()
+	return err
+}
+
+// updateSpills updates the spillDiskBytes level.  The caller must hold the lock.
+func (c *Cache) updateSpills() {
+	var n int64
+	for _, run := range c.spills {
+		if run.r != nil {
+			n += int64(run.r.Size())
+		}
+	}
+	if c.snapshot != nil {
+		for _, run := range c.snapshot.spills {
+			if run.r != nil {
+				n += int64(run.r.Size())
+			}
+		}
+	}
+	atomic.StoreInt64(&c.stats.SpillDiskBytes, n)
+}
@@ -8,6 +8,7 @@ import (
 	"math"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"reflect"
 	"runtime"
 	"strings"
@@ -528,6 +529,247 @@ func TestCache_CacheWriteMemoryExceeded(t *testing.T) {
 	}
 }
 
+func TestCache_CacheWriteMemoryExceeded_Spill(t *testing.T) {
+	dir := mustTempDir()
+	defer os.RemoveAll(dir)
+
+	v0 := NewValue(1, 1.0)
+	v1 := NewValue(2, 2.0)
+	v2 := NewValue(1, 3.0)
+
+	c := NewCache(uint64(v1.Size()), dir)
+	c.spillDir = filepath.Join(dir, cacheSpillDir)
+
+	if err := c.Write([]byte("foo"), Values{v0}); err != nil {
+		t.Fatalf("failed to write key foo to cache: %s", err.Error())
+	}
+
+	// The writes exceed the max size, so the cache is spilled to make room for them.
+	if err := c.Write([]byte("bar"), Values{v1}); err != nil {
+		t.Fatalf("failed to write key bar to cache: %s", err.Error())
+	}
+	if err := c.Write([]byte("foo"), Values{v2}); err != nil {
+		t.Fatalf("failed to write key foo to cache: %s", err.Error())
+	}
+	if n := atomic.LoadInt64(&c.stats.SpilledBytes); n == 0 {
+		t.Fatalf("expected spilled bytes")
+	}
+
+	// The value written after the spill replaces the spilled value.
+	if exp, got := (Values{v2}), c.Values([]byte("foo")); !reflect.DeepEqual(exp, got) {
+		t.Fatalf("values for foo incorrect, exp: %v, got %v", exp, got)
+	}
+	if exp, got := (Values{v1}), c.Values([]byte("bar")); !reflect.DeepEqual(exp, got) {
+		t.Fatalf("values for bar incorrect, exp: %v, got %v", exp, got)
+	}
+
+	// The snapshot merges the spilled runs with the values in memory.
+	snapshot, err := c.Snapshot()
+	if err != nil {
+		t.Fatalf("failed to snapshot cache: %v", err)
+	}
+
+	compactor := &Compactor{Dir: dir, FileStore: NewFileStore(dir)}
+	compactor.Open()
+	files, err := compactor.WriteSnapshot(snapshot)
+	if err != nil {
+		t.Fatalf("failed to write snapshot: %v", err)
+	} else if len(files) != 1 {
+		t.Fatalf("unexpected number of files: %d", len(files))
+	}
+	c.ClearSnapshot(true)
+
+	f, err := os.Open(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewTSMReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	for key, exp := range map[string]Values{"foo": {v2}, "bar": {v1}} {
+		if got, err := r.ReadAll([]byte(key)); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(exp, Values(got)) {
+			t.Fatalf("snapshot values for %s incorrect, exp: %v, got %v", key, exp, got)
+		}
+	}
+
+	// The spilled runs are removed once the snapshot is written.
+	if fis, err := ioutil.ReadDir(c.spillDir); err != nil {
+		t.Fatal(err)
+	} else if len(fis) != 0 {
+		t.Fatalf("unexpected spilled runs: %d", len(fis))
+	}
+}
+
+// Ensure values in a spilled run that can't be read fail the read rather than being
+// left out of it.
+func TestCache_ReadValues_SpillCorrupt(t *testing.T) {
+	dir := mustTempDir()
+	defer os.RemoveAll(dir)
+
+	v0 := NewValue(1, 1.0)
+	v1 := NewValue(2, 2.0)
+
+	c := NewCache(uint64(v1.Size()), dir)
+	c.spillDir = filepath.Join(dir, cacheSpillDir)
+
+	if err := c.Write([]byte("foo"), Values{v0}); err != nil {
+		t.Fatalf("failed to write key foo to cache: %s", err.Error())
+	}
+	if err := c.Write([]byte("bar"), Values{v1}); err != nil {
+		t.Fatalf("failed to write key bar to cache: %s", err.Error())
+	}
+	if len(c.spills) != 1 {
+		t.Fatalf("expected spilled run")
+	}
+
+	// Overwrite the type of the run's only block, after the header and block checksum.
+	f, err := os.OpenFile(c.spills[0].r.Path(), os.O_RDWR, 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteAt([]byte{0xff}, 9); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.ReadValues([]byte("foo")); err == nil {
+		t.Fatalf("expected error reading corrupt spilled run")
+	}
+	if _, err := c.ReadValues([]byte("bar")); err != nil {
+		t.Fatalf("unexpected error reading bar: %v", err)
+	}
+}
+
+// Ensure series deleted while the cache is being spilled are removed whether their
+// values were still in memory or already spilled.
+func TestCache_DeleteSeriesRange_Spill(t *testing.T) {
+	dir := mustTempDir()
+	defer os.RemoveAll(dir)
+
+	for i := 0; i < 50; i++ {
+		c := NewCache(uint64(4*NewValue(0, 0.0).Size()), dir)
+		c.spillDir = filepath.Join(dir, cacheSpillDir, fmt.Sprint(i))
+
+		for j := 0; j < 4; j++ {
+			if err := c.Write([]byte("foo"), Values{NewValue(int64(j), float64(j))}); err != nil {
+				t.Fatalf("failed to write key foo to cache: %s", err.Error())
+			}
+		}
+
+		// Writes to bar spill the values of foo while it is deleted.
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if err := c.Write([]byte("bar"), Values{NewValue(int64(j), float64(j))}); err != nil {
+					t.Errorf("failed to write key bar to cache: %s", err.Error())
+				}
+			}
+		}()
+
+		keys, err := c.deleteSeriesRange(func(k []byte) bool {
+			return bytes.Equal(k, []byte("foo"))
+		}, math.MinInt64, math.MaxInt64)
+		wg.Wait()
+
+		if err != nil {
+			t.Fatal(err)
+		} else if exp := [][]byte{[]byte("foo")}; !reflect.DeepEqual(keys, exp) {
+			t.Fatalf("unexpected deleted keys: got %q, exp %q", keys, exp)
+		} else if values := c.Values([]byte("foo")); len(values) != 0 {
+			t.Fatalf("unexpected values for foo after delete: %v", values)
+		} else if values := c.Values([]byte("bar")); len(values) != 20 {
+			t.Fatalf("unexpected number of values for bar: %d", len(values))
+		}
+
+		if err := c.removeSpills(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// Ensure a view of the cache is not changed by later writes, deletes, spills and
+// snapshots.
+func TestCache_View(t *testing.T) {
+	dir := mustTempDir()
+	defer os.RemoveAll(dir)
+
+	v0 := NewValue(1, 1.0)
+	v1 := NewValue(2, 2.0)
+	v2 := NewValue(3, 3.0)
+
+	c := NewCache(0, dir)
+	c.spillDir = filepath.Join(dir, cacheSpillDir)
+
+	if err := c.Write([]byte("foo"), Values{v0}); err != nil {
+		t.Fatalf("failed to write key foo to cache: %s", err.Error())
+	} else if err := c.Write([]byte("bar"), Values{v0}); err != nil {
+		t.Fatalf("failed to write key bar to cache: %s", err.Error())
+	}
+	if _, err := c.Snapshot(); err != nil {
+		t.Fatalf("failed to snapshot cache: %v", err)
+	}
+	if err := c.Write([]byte("foo"), Values{v1}); err != nil {
+		t.Fatalf("failed to write key foo to cache: %s", err.Error())
+	}
+
+	v, err := c.view()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Write([]byte("foo"), Values{v2}); err != nil {
+		t.Fatalf("failed to write key foo to cache: %s", err.Error())
+	}
+	if _, err := c.deleteSeriesRange(func(k []byte) bool {
+		return bytes.Equal(k, []byte("foo"))
+	}, math.MinInt64, math.MaxInt64); err != nil {
+		t.Fatal(err)
+	}
+
+	// The next write spills the store sealed by the view.
+	c.SetMaxSize(1)
+	if err := c.Write([]byte("bar"), Values{v2}); err != nil {
+		t.Fatalf("failed to write key bar to cache: %s", err.Error())
+	} else if !c.hasSpills() {
+		t.Fatalf("expected spilled runs")
+	}
+	c.ClearSnapshot(true)
+
+	if values := c.Values([]byte("foo")); len(values) != 0 {
+		t.Fatalf("unexpected values for foo after delete: %v", values)
+	}
+	if got, err := v.values([]byte("foo")); err != nil {
+		t.Fatal(err)
+	} else if exp := (Values{v0, v1}); !reflect.DeepEqual(exp, got) {
+		t.Fatalf("view values for foo incorrect, exp: %v, got %v", exp, got)
+	}
+	if got, err := v.values([]byte("bar")); err != nil {
+		t.Fatal(err)
+	} else if exp := (Values{v0}); !reflect.DeepEqual(exp, got) {
+		t.Fatalf("view values for bar incorrect, exp: %v, got %v", exp, got)
+	}
+
+	if err := v.release(); err != nil {
+		t.Fatal(err)
+	} else if err := c.removeSpills(); err != nil {
+		t.Fatal(err)
+	}
+	if fis, err := ioutil.ReadDir(c.spillDir); err != nil {
+		t.Fatal(err)
+	} else if len(fis) != 0 {
+		t.Fatalf("unexpected spilled runs: %d", len(fis))
+	}
+}
+
 func TestCache_Deduplicate_Concurrent(t *testing.T) {
 	if testing.Short() || os.Getenv("GORACE") != "" || os.Getenv("APPVEYOR") != "" {
 		t.Skip("Skipping test in short, race, appveyor mode.")
@@ -635,6 +877,126 @@ func TestCacheLoader_LoadSingle(t *testing.T) {
 	}
 }
 
+// Ensure the CacheLoader can load segments with and without frames.
+// Ensure the cache spills while it is loaded rather than exceeding its max size, and
+// that deletes in the WAL remove values that were already spilled.
+func TestCacheLoader_LoadSpill(t *testing.T) {
+	dir := mustTempDir()
+	defer os.RemoveAll(dir)
+	f := mustTempFile(dir)
+	w := NewWALSegmentWriter(f)
+
+	p1 := NewValue(1, 1.1)
+	p2 := NewValue(2, 1.2)
+	p3 := NewValue(3, 1.3)
+
+	for _, entry := range []WALEntry{
+		&WriteWALEntry{Values: map[string][]Value{"foo": {p1}}},
+		&WriteWALEntry{Values: map[string][]Value{"bar": {p2}}},
+		&WriteWALEntry{Values: map[string][]Value{"baz": {p3}}},
+		&DeleteRangeWALEntry{Keys: [][]byte{[]byte("foo")}, Min: 0, Max: 10},
+	} {
+		if err := w.Write(mustMarshalEntry(entry)); err != nil {
+			t.Fatal("write points", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("flush error: %v", err)
+	}
+
+	cache := NewCache(uint64(p1.Size()), dir)
+	cache.spillDir = filepath.Join(dir, cacheSpillDir)
+	defer cache.removeSpills()
+
+	loader := NewCacheLoader([]string{f.Name()})
+	if err := loader.Load(cache); err != nil {
+		t.Fatalf("failed to load cache: %s", err.Error())
+	}
+
+	if n := atomic.LoadInt64(&cache.stats.SpilledBytes); n == 0 {
+		t.Fatalf("expected spilled bytes")
+	} else if !cache.hasSpills() {
+		t.Fatalf("expected spilled runs")
+	}
+
+	// Check the cache.
+	if values := cache.Values([]byte("foo")); len(values) != 0 {
+		t.Fatalf("cache key foo not as expected, got %v, exp none", values)
+	}
+	if values := cache.Values([]byte("bar")); !reflect.DeepEqual(values, Values{p2}) {
+		t.Fatalf("cache key bar not as expected, got %v, exp %v", values, Values{p2})
+	}
+	if values := cache.Values([]byte("baz")); !reflect.DeepEqual(values, Values{p3}) {
+		t.Fatalf("cache key baz not as expected, got %v, exp %v", values, Values{p3})
+	}
+}
+
+func TestCacheLoader_LoadFramed(t *testing.T) {
+	dir := mustTempDir()
+	defer os.RemoveAll(dir)
+
+	p1 := NewValue(1, 1.1)
+	p2 := NewValue(2, 1.2)
+	p3 := NewValue(3, 1.3)
+
+	// The first segment has entries written on their own.
+	f1 := mustTempFile(dir)
+	w1 := NewWALSegmentWriter(f1)
+	if err := w1.Write(mustMarshalEntry(&WriteWALEntry{Values: map[string][]Value{"foo": {p1}}})); err != nil {
+		t.Fatal("write points", err)
+	} else if err := w1.Flush(); err != nil {
+		t.Fatalf("flush error: %v", err)
+	}
+
+	// The second segment batches entries into deflate compressed frames.
+	f2 := mustTempFile(dir)
+	w2 := newFramedWALSegmentWriter(f2, walCompressionDeflate, 1024)
+	for _, entry := range []WALEntry{
+		&WriteWALEntry{Values: map[string][]Value{"foo": {p2}, "bar": {p2}}},
+		&DeleteRangeWALEntry{Keys: [][]byte{[]byte("bar")}, Min: 0, Max: 10},
+		&WriteWALEntry{Values: map[string][]Value{"foo": {p3}}},
+	} {
+		b, err := entry.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		} else if err := w2.WriteEntry(entry.Type(), b); err != nil {
+			t.Fatal("write entry", err)
+		}
+	}
+	if err := w2.Flush(); err != nil {
+		t.Fatalf("flush error: %v", err)
+	}
+	fi, err := f2.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	size := fi.Size()
+
+	// Corrupt the framed segment with a truncated frame.
+	if _, err := f2.Write([]byte{byte(FrameWALEntryType), 0, 0, 0, 8, walCompressionNone, 1}); err != nil {
+		t.Fatalf("corrupt WAL segment: %s", err.Error())
+	}
+
+	cache := NewCache(1024, "")
+	loader := NewCacheLoader([]string{f1.Name(), f2.Name()})
+	if err := loader.Load(cache); err != nil {
+		t.Fatalf("failed to load cache: %s", err.Error())
+	}
+
+	if values := cache.Values([]byte("foo")); !reflect.DeepEqual(values, Values{p1, p2, p3}) {
+		t.Fatalf("cache key foo not as expected, got %v, exp %v", values, Values{p1, p2, p3})
+	}
+	if values := cache.Values([]byte("bar")); len(values) != 0 {
+		t.Fatalf("cache key bar not as expected, got %v", values)
+	}
+
+	if fi, err := os.Stat(f2.Name()); err != nil {
+		t.Fatal(err)
+	} else if fi.Size() != size {
+		t.Fatalf("framed segment not truncated: got %d, exp %d", fi.Size(), size)
+	}
+}
+
 // Ensure the CacheLoader can correctly load from two segments, even if one is corrupted.
 func TestCacheLoader_LoadDouble(t *testing.T) {
 	// Create a WAL segment.
@@ -806,6 +1168,54 @@ func TestCache_Split(t *testing.T) {
 	}
 }
 
+func TestCache_SplitLate(t *testing.T) {
+	v0 := NewValue(1, 1.0)
+	v1 := NewValue(2, 2.0)
+	v2 := NewValue(3, 3.0)
+
+	c := NewCache(0, "")
+	for _, key := range []string{"foo", "bar", "baz"} {
+		if err := c.Write([]byte(key), Values{v0, v1, v2}); err != nil {
+			t.Fatalf("failed to write key %s to cache: %s", key, err.Error())
+		}
+	}
+
+	// foo has values persisted up to 2 and bar up to 3, baz has never been persisted.
+	persisted := map[string]int64{"foo": 2, "bar": 3}
+	onTime, late, n, err := c.splitLate(func(key []byte) (int64, bool) {
+		max, ok := persisted[string(key)]
+		return max, ok
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if n != 5 {
+		t.Fatalf("unexpected late value count: got %d, exp 5", n)
+	}
+
+	for _, tt := range []struct {
+		c    *Cache
+		key  string
+		exp  Values
+		desc string
+	}{
+		{onTime, "foo", Values{v2}, "on time"},
+		{onTime, "bar", nil, "on time"},
+		{onTime, "baz", Values{v0, v1, v2}, "on time"},
+		{late, "foo", Values{v0, v1}, "late"},
+		{late, "bar", Values{v0, v1, v2}, "late"},
+		{late, "baz", nil, "late"},
+	} {
+		if got := tt.c.values([]byte(tt.key)); !reflect.DeepEqual(got, tt.exp) {
+			t.Fatalf("unexpected %s values for %s: got %v, exp %v", tt.desc, tt.key, got, tt.exp)
+		}
+	}
+
+	// The split cache keeps all of its values.
+	if got := c.Values([]byte("bar")); len(got) != 3 {
+		t.Fatalf("unexpected values in split cache: %v", got)
+	}
+}
+
 func mustTempDir() string {
 	dir, err := ioutil.TempDir("", "tsm1-test")
 	if err != nil {
@@ -179,6 +179,7 @@ func (k *tsmKeyIterator) chunkFloat(dst blocks) blocks {
 			maxTime: values[len(values)-1].UnixNano(),
 			key:     k.key,
 			b:       cb,
+			stats:   FloatValues(values).stats(),
 		})
 		k.mergedFloatValues = k.mergedFloatValues[k.size:]
 		return dst
@@ -197,6 +198,7 @@ func (k *tsmKeyIterator) chunkFloat(dst blocks) blocks {
 			maxTime: k.mergedFloatValues[len(k.mergedFloatValues)-1].UnixNano(),
 			key:     k.key,
 			b:       cb,
+			stats:   FloatValues(k.mergedFloatValues).stats(),
 		})
 		k.mergedFloatValues = k.mergedFloatValues[:0]
 	}
@@ -376,6 +378,7 @@ func (k *tsmKeyIterator) chunkInteger(dst blocks) blocks {
 			maxTime: values[len(values)-1].UnixNano(),
 			key:     k.key,
 			b:       cb,
+			stats:   IntegerValues(values).stats(),
 		})
 		k.mergedIntegerValues = k.mergedIntegerValues[k.size:]
 		return dst
@@ -394,6 +397,7 @@ func (k *tsmKeyIterator) chunkInteger(dst blocks) blocks {
 			maxTime: k.mergedIntegerValues[len(k.mergedIntegerValues)-1].UnixNano(),
 			key:     k.key,
 			b:       cb,
+			stats:   IntegerValues(k.mergedIntegerValues).stats(),
 		})
 		k.mergedIntegerValues = k.mergedIntegerValues[:0]
 	}
@@ -573,6 +577,7 @@ func (k *tsmKeyIterator) chunkUnsigned(dst blocks) blocks {
 			maxTime: values[len(values)-1].UnixNano(),
 			key:     k.key,
 			b:       cb,
+			stats:   UnsignedValues(values).stats(),
 		})
 		k.mergedUnsignedValues = k.mergedUnsignedValues[k.size:]
 		return dst
@@ -591,6 +596,7 @@ func (k *tsmKeyIterator) chunkUnsigned(dst blocks) blocks {
 			maxTime: k.mergedUnsignedValues[len(k.mergedUnsignedValues)-1].UnixNano(),
 			key:     k.key,
 			b:       cb,
+			stats:   UnsignedValues(k.mergedUnsignedValues).stats(),
 		})
 		k.mergedUnsignedValues = k.mergedUnsignedValues[:0]
 	}
@@ -759,7 +765,7 @@ func (k *tsmKeyIterator) combineString(dedup bool) blocks {
 func (k *tsmKeyIterator) chunkString(dst blocks) blocks {
 	if len(k.mergedStringValues) > k.size {
 		values := k.mergedStringValues[:k.size]
-		cb, err := StringValues(values).Encode(nil)
+		cb, err := encodeCompressedStringValuesBlock(nil, values, k.stringCompression)
 		if err != nil {
 			k.err = err
 			return nil
@@ -770,6 +776,7 @@ func (k *tsmKeyIterator) chunkString(dst blocks) blocks {
 			maxTime: values[len(values)-1].UnixNano(),
 			key:     k.key,
 			b:       cb,
+			stats:   StringValues(values).stats(),
 		})
 		k.mergedStringValues = k.mergedStringValues[k.size:]
 		return dst
@@ -777,7 +784,7 @@ func (k *tsmKeyIterator) chunkString(dst blocks) blocks {
 
 	// Re-encode the remaining values into the last block
 	if len(k.mergedStringValues) > 0 {
-		cb, err := StringValues(k.mergedStringValues).Encode(nil)
+		cb, err := encodeCompressedStringValuesBlock(nil, k.mergedStringValues, k.stringCompression)
 		if err != nil {
 			k.err = err
 			return nil
@@ -788,6 +795,7 @@ func (k *tsmKeyIterator) chunkString(dst blocks) blocks {
 			maxTime: k.mergedStringValues[len(k.mergedStringValues)-1].UnixNano(),
 			key:     k.key,
 			b:       cb,
+			stats:   StringValues(k.mergedStringValues).stats(),
 		})
 		k.mergedStringValues = k.mergedStringValues[:0]
 	}
@@ -967,6 +975,7 @@ func (k *tsmKeyIterator) chunkBoolean(dst blocks) blocks {
 			maxTime: values[len(values)-1].UnixNano(),
 			key:     k.key,
 			b:       cb,
+			stats:   BooleanValues(values).stats(),
 		})
 		k.mergedBooleanValues = k.mergedBooleanValues[k.size:]
 		return dst
@@ -985,6 +994,7 @@ func (k *tsmKeyIterator) chunkBoolean(dst blocks) blocks {
 			maxTime: k.mergedBooleanValues[len(k.mergedBooleanValues)-1].UnixNano(),
 			key:     k.key,
 			b:       cb,
+			stats:   BooleanValues(k.mergedBooleanValues).stats(),
 		})
 		k.mergedBooleanValues = k.mergedBooleanValues[:0]
 	}
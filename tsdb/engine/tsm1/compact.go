@@ -15,6 +15,7 @@ package tsm1
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"math"
 	"os"
 	"path/filepath"
@@ -24,6 +25,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/influxdata/influxdb/pkg/limiter"
 	"github.com/influxdata/influxdb/tsdb"
 )
 
@@ -41,6 +43,7 @@ var (
 	errMaxFileExceeded     = fmt.Errorf("max file exceeded")
 	errSnapshotsDisabled   = fmt.Errorf("snapshots disabled")
 	errCompactionsDisabled = fmt.Errorf("compactions disabled")
+	errColdTierDisabled    = fmt.Errorf("cold tier disabled")
 )
 
 type errCompactionInProgress struct {
@@ -75,6 +78,23 @@ type CompactionPlanner interface {
 	Plan(lastWrite time.Time) []CompactionGroup
 	PlanLevel(level int) []CompactionGroup
 	PlanOptimize() []CompactionGroup
+
+	// PlanColdTier returns the groups of TSM files that should be moved to the
+	// cold storage tier.
+	PlanColdTier() []CompactionGroup
+
+	// PlanRollup returns a single group of all TSM files to be rewritten by a
+	// rollup compaction.
+	PlanRollup() []CompactionGroup
+
+	// PlanTombstones returns the generations of TSM files whose tombstones should
+	// be compacted away.
+	PlanTombstones() []CompactionGroup
+
+	// PlanLate returns a group of a late generation of TSM files and the older
+	// generations it overlaps, to be merged by a late compaction.
+	PlanLate() []CompactionGroup
+
 	Release(group []CompactionGroup)
 	FullyCompacted() bool
 
@@ -114,6 +134,19 @@ type DefaultPlanner struct {
 	// filesInUse is the set of files that have been returned as part of a plan and might
 	// be being compacted.  Two plans should not return the same file at any given time.
 	filesInUse map[string]struct{}
+
+	// ColdDir is the directory of the cold storage tier.  If empty, PlanColdTier
+	// never returns any plans.
+	ColdDir string
+
+	// ColdTierAge is how old the newest point in a fully compacted generation must
+	// be before the generation is moved to the cold tier.
+	ColdTierAge time.Duration
+
+	// TombstoneThreshold is the size of the tombstone files of a fully compacted
+	// generation after which PlanTombstones returns it.  A value of 0 disables
+	// tombstone compactions.
+	TombstoneThreshold uint64
 }
 
 type fileStore interface {
@@ -138,6 +171,43 @@ type tsmGeneration struct {
 	files []FileStat
 }
 
+// timeRange returns the min and max time of the files in the generation.
+func (t *tsmGeneration) timeRange() (min, max int64) {
+	for i, f := range t.files {
+		if i == 0 || f.MinTime < min {
+			min = f.MinTime
+		}
+		if i == 0 || f.MaxTime > max {
+			max = f.MaxTime
+		}
+	}
+	return min, max
+}
+
+// keyRange returns the min and max key of the files in the generation.
+func (t *tsmGeneration) keyRange() (min, max []byte) {
+	for i, f := range t.files {
+		if i == 0 || bytes.Compare(f.MinKey, min) < 0 {
+			min = f.MinKey
+		}
+		if i == 0 || bytes.Compare(f.MaxKey, max) > 0 {
+			max = f.MaxKey
+		}
+	}
+	return min, max
+}
+
+// overlaps returns true if any file of the generation holds keys between minKey and
+// maxKey and values between minTime and maxTime.
+func (t *tsmGeneration) overlaps(minKey, maxKey []byte, minTime, maxTime int64) bool {
+	for _, f := range t.files {
+		if f.OverlapsTimeRange(minTime, maxTime) && f.OverlapsKeyRange(minKey, maxKey) {
+			return true
+		}
+	}
+	return false
+}
+
 // size returns the total size of the files in the generation.
 func (t *tsmGeneration) size() uint64 {
 	var n uint64
@@ -166,6 +236,15 @@ func (t *tsmGeneration) count() int {
 	return len(t.files)
 }
 
+// tombstoneSize returns the total size of the tombstone files of the generation.
+func (t *tsmGeneration) tombstoneSize() uint64 {
+	var n uint64
+	for _, f := range t.files {
+		n += uint64(f.TombstoneSize)
+	}
+	return n
+}
+
 // hasTombstones returns true if there are keys removed for any of the files.
 func (t *tsmGeneration) hasTombstones() bool {
 	for _, f := range t.files {
@@ -434,7 +513,7 @@ func (c *DefaultPlanner) Plan(lastWrite time.Time) []CompactionGroup {
 			}
 			genCount += 1
 		}
-		sort.Strings(tsmFiles)
+		sort.Sort(tsmFileNames(tsmFiles))
 
 		// Make sure we have more than 1 file and more than 1 generation
 		if len(tsmFiles) <= 1 || genCount <= 1 {
@@ -574,7 +653,7 @@ func (c *DefaultPlanner) Plan(lastWrite time.Time) []CompactionGroup {
 				cGroup = append(cGroup, f.Path)
 			}
 		}
-		sort.Strings(cGroup)
+		sort.Sort(tsmFileNames(cGroup))
 		tsmFiles = append(tsmFiles, cGroup)
 	}
 
@@ -584,6 +663,169 @@ func (c *DefaultPlanner) Plan(lastWrite time.Time) []CompactionGroup {
 	return tsmFiles
 }
 
+// PlanColdTier returns a group for each fully compacted generation whose data is
+// older than ColdTierAge and that is still stored outside of the cold tier.
+// Generations with tombstones are skipped until the tombstones are compacted away.
+func (c *DefaultPlanner) PlanColdTier() []CompactionGroup {
+	if c.ColdDir == "" || c.ColdTierAge <= 0 {
+		return nil
+	}
+
+	// If a full plan has been requested, don't plan anything which would prevent
+	// the full plan from acquiring the files.
+	c.mu.RLock()
+	if c.forceFull {
+		c.mu.RUnlock()
+		return nil
+	}
+	c.mu.RUnlock()
+
+	coldDir := filepath.Clean(c.ColdDir)
+	cutoff := time.Now().Add(-c.ColdTierAge).UnixNano()
+
+	var groups []CompactionGroup
+	for _, gen := range c.findGenerations(true) {
+		if gen.level() < 4 || gen.hasTombstones() {
+			continue
+		}
+
+		var group CompactionGroup
+		for _, f := range gen.files {
+			if filepath.Dir(f.Path) == coldDir || f.MaxTime >= cutoff {
+				group = nil
+				break
+			}
+			group = append(group, f.Path)
+		}
+
+		if len(group) > 0 {
+			groups = append(groups, group)
+		}
+	}
+
+	if len(groups) == 0 || !c.acquire(groups) {
+		return nil
+	}
+	return groups
+}
+
+// PlanRollup returns a single group containing every TSM file in the shard, since a
+// rollup must see all of the values of a series to aggregate them.  No plan is
+// returned while any of the files are part of another plan.
+func (c *DefaultPlanner) PlanRollup() []CompactionGroup {
+	// If a full plan has been requested, don't plan anything which would prevent
+	// the full plan from acquiring the files.
+	c.mu.RLock()
+	if c.forceFull {
+		c.mu.RUnlock()
+		return nil
+	}
+	c.mu.RUnlock()
+
+	var tsmFiles []string
+	for _, gen := range c.findGenerations(false) {
+		for _, f := range gen.files {
+			tsmFiles = append(tsmFiles, f.Path)
+		}
+	}
+	sort.Sort(tsmFileNames(tsmFiles))
+
+	group := []CompactionGroup{tsmFiles}
+	if len(tsmFiles) == 0 || !c.acquire(group) {
+		return nil
+	}
+	return group
+}
+
+// PlanTombstones returns a group for each fully compacted generation whose tombstone
+// files are larger than TombstoneThreshold.  Lower levels are compacted often enough
+// that their tombstones are removed by the level compactions.
+func (c *DefaultPlanner) PlanTombstones() []CompactionGroup {
+	if c.TombstoneThreshold == 0 {
+		return nil
+	}
+
+	// If a full plan has been requested, don't plan anything which would prevent
+	// the full plan from acquiring the files.
+	c.mu.RLock()
+	if c.forceFull {
+		c.mu.RUnlock()
+		return nil
+	}
+	c.mu.RUnlock()
+
+	var groups []CompactionGroup
+	for _, gen := range c.findGenerations(true) {
+		if gen.level() < 4 || gen.tombstoneSize() < c.TombstoneThreshold {
+			continue
+		}
+
+		var group CompactionGroup
+		for _, f := range gen.files {
+			group = append(group, f.Path)
+		}
+		groups = append(groups, group)
+	}
+
+	if len(groups) == 0 || !c.acquire(groups) {
+		return nil
+	}
+	return groups
+}
+
+// PlanLate returns a group holding the oldest late generation along with every older
+// generation back to the oldest one it overlaps.  A generation is late if none of its
+// values are newer than the values of the generations before it, which is the case for
+// the generations written from the late values of a cache snapshot.  The group is
+// contiguous so that newer values still replace older ones when it is merged.
+func (c *DefaultPlanner) PlanLate() []CompactionGroup {
+	// If a full plan has been requested, don't plan anything which would prevent
+	// the full plan from acquiring the files.
+	c.mu.RLock()
+	if c.forceFull {
+		c.mu.RUnlock()
+		return nil
+	}
+	c.mu.RUnlock()
+
+	generations := c.findGenerations(false)
+
+	var prevMax int64
+	for i, gen := range generations {
+		minTime, maxTime := gen.timeRange()
+		if i == 0 || maxTime > prevMax {
+			prevMax = maxTime
+			continue
+		}
+
+		minKey, maxKey := gen.keyRange()
+		start := -1
+		for j := 0; j < i; j++ {
+			if generations[j].overlaps(minKey, maxKey, minTime, maxTime) {
+				start = j
+				break
+			}
+		}
+		if start < 0 {
+			continue
+		}
+
+		var group CompactionGroup
+		for _, g := range generations[start : i+1] {
+			for _, f := range g.files {
+				group = append(group, f.Path)
+			}
+		}
+
+		groups := []CompactionGroup{group}
+		if !c.acquire(groups) {
+			return nil
+		}
+		return groups
+	}
+	return nil
+}
+
 // findGenerations groups all the TSM files by generation based
 // on their filename, then returns the generations in descending order (newest first).
 // If skipInUse is true, tsm files that are part of an existing compaction plan
@@ -668,12 +910,148 @@ func (c *DefaultPlanner) Release(groups []CompactionGroup) {
 	}
 }
 
+// BudgetPlanner is a CompactionPlanner for shards whose compactions write to disk
+// within an I/O throughput budget.  It plans the same groups as DefaultPlanner, but
+// while the budget applies it orders level compactions by the number of series they
+// rewrite per byte written.  Groups with many series in few bytes are made of many
+// small blocks and improve queries the most for the I/O they use.  Full and optimize
+// compactions that cannot finish within the budget before the window ends are
+// deferred until compactions are no longer limited.
+type BudgetPlanner struct {
+	*DefaultPlanner
+
+	// Throughput is the budget in bytes per second that compactions write at.
+	Throughput int
+
+	// Window is the time of day during which the budget applies.  The zero
+	// window applies it at all times.
+	Window tsdb.TimeWindow
+
+	now func() time.Time
+}
+
+// NewBudgetPlanner returns a BudgetPlanner limiting compactions to throughput bytes
+// per second during window.
+func NewBudgetPlanner(fs fileStore, writeColdDuration time.Duration, throughput int, window tsdb.TimeWindow) *BudgetPlanner {
+	return &BudgetPlanner{
+		DefaultPlanner: NewDefaultPlanner(fs, writeColdDuration),
+		Throughput:     throughput,
+		Window:         window,
+		now:            time.Now,
+	}
+}
+
+// PlanLevel returns the groups of the level ordered by the series they rewrite
+// per byte while the budget applies.
+func (c *BudgetPlanner) PlanLevel(level int) []CompactionGroup {
+	groups := c.DefaultPlanner.PlanLevel(level)
+	if len(groups) < 2 || !c.Window.Contains(c.now()) {
+		return groups
+	}
+
+	costs := c.costs(groups)
+	sort.SliceStable(groups, func(i, j int) bool {
+		return costs[i].seriesPerByte() > costs[j].seriesPerByte()
+	})
+	return groups
+}
+
+// Plan returns the full compaction groups that can finish within the budget.
+func (c *BudgetPlanner) Plan(lastWrite time.Time) []CompactionGroup {
+	return c.withinBudget(c.DefaultPlanner.Plan(lastWrite))
+}
+
+// PlanOptimize returns the optimize compaction groups that can finish within the budget.
+func (c *BudgetPlanner) PlanOptimize() []CompactionGroup {
+	return c.withinBudget(c.DefaultPlanner.PlanOptimize())
+}
+
+// withinBudget releases and removes the groups that would not finish writing at the
+// budget before the window ends.
+func (c *BudgetPlanner) withinBudget(groups []CompactionGroup) []CompactionGroup {
+	if len(groups) == 0 || c.Throughput <= 0 {
+		return groups
+	}
+
+	remaining := c.Window.Remaining(c.now())
+	if remaining == 0 {
+		return groups
+	}
+
+	costs := c.costs(groups)
+	var deferred []CompactionGroup
+	planned := groups[:0]
+	for i, group := range groups {
+		if time.Duration(costs[i].size/int64(c.Throughput))*time.Second > remaining {
+			deferred = append(deferred, group)
+			continue
+		}
+		planned = append(planned, group)
+	}
+	c.Release(deferred)
+	return planned
+}
+
+// costs returns the cost of compacting each of the groups.
+func (c *BudgetPlanner) costs(groups []CompactionGroup) []compactionCost {
+	stats := make(map[string]FileStat)
+	for _, f := range c.FileStore.Stats() {
+		stats[f.Path] = f
+	}
+
+	costs := make([]compactionCost, len(groups))
+	for i, group := range groups {
+		for _, path := range group {
+			f := stats[path]
+			costs[i].size += int64(f.Size)
+			costs[i].series += int64(f.KeyCount)
+		}
+	}
+	return costs
+}
+
+// compactionCost estimates the I/O of compacting a group of TSM files.
+type compactionCost struct {
+	size   int64 // bytes read and rewritten
+	series int64 // series keys summed over the files
+}
+
+// seriesPerByte returns the number of series rewritten per byte written.
+func (c compactionCost) seriesPerByte() float64 {
+	if c.size == 0 {
+		return 0
+	}
+	return float64(c.series) / float64(c.size)
+}
+
 // Compactor merges multiple TSM files into new files or
 // writes a Cache into 1 or more TSM files.
 type Compactor struct {
+	// throttled is the number of nanoseconds compactions have been delayed by
+	// Throughput.  It is the first field to keep it aligned for atomic access.
+	throttled int64
+
 	Dir  string
 	Size int
 
+	// ColdDir is the directory of the cold storage tier.  Compactions of files
+	// that are all stored in the cold tier write their output to it as well.
+	ColdDir string
+
+	// StringCompression is the name of the compression used for string blocks
+	// that are encoded by the Compactor: "none", "snappy" or "deflate".  An empty
+	// value uses snappy.  Blocks copied unchanged keep their existing compression.
+	StringCompression string
+
+	// Throughput limits the rate at which level, full and rollup compactions write
+	// TSM files, and at which files are copied to the cold tier.  Snapshots are not
+	// limited.  A nil Throughput does not limit compactions.
+	Throughput *limiter.Rate
+
+	// ThroughputWindow is the time of day during which Throughput applies.  The
+	// zero window applies it at all times.
+	ThroughputWindow tsdb.TimeWindow
+
 	FileStore interface {
 		NextGeneration() int
 		TSMReader(path string) *TSMReader
@@ -777,6 +1155,15 @@ func (c *Compactor) WriteSnapshot(cache *Cache) ([]string, error) {
 		return nil, errSnapshotsDisabled
 	}
 
+	compression, err := parseStringCompression(c.StringCompression)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cache.spills) > 0 {
+		return c.writeSpilledSnapshot(cache, compression, intC)
+	}
+
 	card := cache.Count()
 
 	concurrency, maxConcurrency := 1, runtime.GOMAXPROCS(0)/2
@@ -808,14 +1195,13 @@ func (c *Compactor) WriteSnapshot(cache *Cache) ([]string, error) {
 	resC := make(chan res, concurrency)
 	for i := 0; i < concurrency; i++ {
 		go func(sp *Cache) {
-			iter := NewCacheKeyIterator(sp, tsdb.DefaultMaxPointsPerBlock, intC)
-			files, err := c.writeNewFiles(c.FileStore.NextGeneration(), 0, iter)
+			iter := newCacheKeyIterator(sp, tsdb.DefaultMaxPointsPerBlock, compression, intC)
+			files, err := c.writeNewFiles(c.Dir, c.FileStore.NextGeneration(), 0, iter, false)
 			resC <- res{files: files, err: err}
 
 		}(splits[i])
 	}
 
-	var err error
 	files := make([]string, 0, concurrency)
 	for i := 0; i < concurrency; i++ {
 		result := <-resC
@@ -837,8 +1223,88 @@ func (c *Compactor) WriteSnapshot(cache *Cache) ([]string, error) {
 	return files, err
 }
 
+// writeSpilledSnapshot writes a Cache snapshot with spilled runs to one or more new
+// TSM files by merging the runs with the values still in memory.
+func (c *Compactor) writeSpilledSnapshot(cache *Cache, compression byte, intC chan struct{}) ([]string, error) {
+	dir := c.Dir
+	for _, run := range cache.spills {
+		if run.r != nil {
+			dir = filepath.Dir(run.r.Path())
+			break
+		}
+	}
+
+	// Runs sealed in memory by read snapshots are written out to be merged in order
+	// with the runs on disk.
+	paths := make([]string, 0, len(cache.spills)+1)
+	for _, run := range cache.spills {
+		if run.r != nil {
+			paths = append(paths, run.r.Path())
+			continue
+		}
+
+		sealed, err := writeSpillRun(dir, run.store)
+		if err != nil {
+			return nil, err
+		}
+		if sealed != nil {
+			defer sealed.remove()
+			paths = append(paths, sealed.r.Path())
+		}
+	}
+
+	// The values still in memory are newer than the spilled runs, so they are merged
+	// as the last run.
+	if cache.Count() > 0 {
+		run, err := writeSpillRun(dir, cache.store)
+		if err != nil {
+			return nil, err
+		}
+		if run != nil {
+			defer run.remove()
+			paths = append(paths, run.r.Path())
+		}
+	}
+
+	// The key iterator closes its readers, so the runs are opened again.
+	readers := make([]*TSMReader, 0, len(paths))
+	for _, path := range paths {
+		r, err := func() (*TSMReader, error) {
+			f, err := os.Open(path)
+			if err != nil {
+				return nil, err
+			}
+
+			r, err := NewTSMReader(f)
+			if err != nil {
+				f.Close()
+				return nil, err
+			}
+			return r, nil
+		}()
+		if err != nil {
+			for _, r := range readers {
+				r.Close()
+			}
+			return nil, err
+		}
+		readers = append(readers, r)
+	}
+
+	iter, err := newTSMKeyIterator(tsdb.DefaultMaxPointsPerBlock, false, compression, intC, readers...)
+	if err != nil {
+		for _, r := range readers {
+			r.Close()
+		}
+		return nil, err
+	}
+	defer iter.Close()
+
+	return c.writeNewFiles(c.Dir, c.FileStore.NextGeneration(), 0, iter, false)
+}
+
 // compact writes multiple smaller TSM files into 1 or more larger files.
-func (c *Compactor) compact(fast bool, tsmFiles []string) ([]string, error) {
+func (c *Compactor) compact(fast bool, tsmFiles []string, rollup *tsdb.RollupRule, keepLevel bool) ([]string, error) {
 	size := c.Size
 	if size <= 0 {
 		size = tsdb.DefaultMaxPointsPerBlock
@@ -848,14 +1314,63 @@ func (c *Compactor) compact(fast bool, tsmFiles []string) ([]string, error) {
 	intC := c.compactionsInterrupt
 	c.mu.RUnlock()
 
+	compression, err := parseStringCompression(c.StringCompression)
+	if err != nil {
+		return nil, err
+	}
+
 	// The new compacted files need to added to the max generation in the
 	// set.  We need to find that max generation as well as the max sequence
 	// number to ensure we write to the next unique location.
-	var maxGeneration, maxSequence int
+	maxGeneration, maxSequence, err := maxGenerationSequence(tsmFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	// The new files are numbered after every file of the group when they must keep
+	// the highest level of the files they replace.
+	if keepLevel {
+		for _, f := range tsmFiles {
+			if _, seq, err := ParseTSMFileName(f); err != nil {
+				return nil, err
+			} else if seq > maxSequence {
+				maxSequence = seq
+			}
+		}
+	}
+
+	trs, err := c.readers(tsmFiles, intC)
+	if err != nil {
+		return nil, err
+	} else if len(trs) == 0 {
+		return nil, nil
+	}
+
+	tsm, err := newTSMKeyIterator(size, fast, compression, intC, trs...)
+	if err != nil {
+		return nil, err
+	}
+
+	if rollup != nil {
+		tsm = newRollupKeyIterator(tsm, rollup, size, compression)
+	}
+
+	// Keep data that has already been moved to the cold tier there.
+	dir := c.Dir
+	if c.isCold(tsmFiles) {
+		dir = c.ColdDir
+	}
+
+	return c.writeNewFiles(dir, maxGeneration, maxSequence, tsm, true)
+}
+
+// maxGenerationSequence returns the max generation of tsmFiles and the max sequence
+// within that generation.
+func maxGenerationSequence(tsmFiles []string) (maxGeneration, maxSequence int, err error) {
 	for _, f := range tsmFiles {
 		gen, seq, err := ParseTSMFileName(f)
 		if err != nil {
-			return nil, err
+			return 0, 0, err
 		}
 
 		if gen > maxGeneration {
@@ -867,8 +1382,11 @@ func (c *Compactor) compact(fast bool, tsmFiles []string) ([]string, error) {
 			maxSequence = seq
 		}
 	}
+	return maxGeneration, maxSequence, nil
+}
 
-	// For each TSM file, create a TSM reader
+// readers returns the readers of tsmFiles from the file store.
+func (c *Compactor) readers(tsmFiles []string, intC chan struct{}) ([]*TSMReader, error) {
 	var trs []*TSMReader
 	for _, file := range tsmFiles {
 		select {
@@ -886,17 +1404,22 @@ func (c *Compactor) compact(fast bool, tsmFiles []string) ([]string, error) {
 		}
 		trs = append(trs, tr)
 	}
+	return trs, nil
+}
 
-	if len(trs) == 0 {
-		return nil, nil
+// isCold returns true if all of the files are stored in the cold tier.
+func (c *Compactor) isCold(tsmFiles []string) bool {
+	if c.ColdDir == "" {
+		return false
 	}
 
-	tsm, err := NewTSMKeyIterator(size, fast, intC, trs...)
-	if err != nil {
-		return nil, err
+	coldDir := filepath.Clean(c.ColdDir)
+	for _, f := range tsmFiles {
+		if filepath.Dir(f) != coldDir {
+			return false
+		}
 	}
-
-	return c.writeNewFiles(maxGeneration, maxSequence, tsm)
+	return true
 }
 
 // CompactFull writes multiple smaller TSM files into 1 or more larger files.
@@ -914,7 +1437,7 @@ func (c *Compactor) CompactFull(tsmFiles []string) ([]string, error) {
 	}
 	defer c.remove(tsmFiles)
 
-	files, err := c.compact(false, tsmFiles)
+	files, err := c.compact(false, tsmFiles, nil, false)
 
 	// See if we were disabled while writing a snapshot
 	c.mu.RLock()
@@ -946,7 +1469,7 @@ func (c *Compactor) CompactFast(tsmFiles []string) ([]string, error) {
 	}
 	defer c.remove(tsmFiles)
 
-	files, err := c.compact(true, tsmFiles)
+	files, err := c.compact(true, tsmFiles, nil, false)
 
 	// See if we were disabled while writing a snapshot
 	c.mu.RLock()
@@ -964,6 +1487,233 @@ func (c *Compactor) CompactFast(tsmFiles []string) ([]string, error) {
 
 }
 
+// CompactRollup writes all of the TSM files of a shard into 1 or more new files,
+// replacing the raw values with the aggregates of the rollup rule.
+func (c *Compactor) CompactRollup(tsmFiles []string, rule *tsdb.RollupRule) ([]string, error) {
+	c.mu.RLock()
+	enabled := c.compactionsEnabled
+	c.mu.RUnlock()
+
+	if !enabled {
+		return nil, errCompactionsDisabled
+	}
+
+	if !c.add(tsmFiles) {
+		return nil, errCompactionInProgress{}
+	}
+	defer c.remove(tsmFiles)
+
+	files, err := c.compact(false, tsmFiles, rule, false)
+
+	// See if we were disabled while writing a snapshot
+	c.mu.RLock()
+	enabled = c.compactionsEnabled
+	c.mu.RUnlock()
+
+	if !enabled {
+		if err := c.removeTmpFiles(files); err != nil {
+			return nil, err
+		}
+		return nil, errCompactionsDisabled
+	}
+
+	return files, err
+}
+
+// CompactLate merges a late generation of TSM files into the older generations it
+// overlaps.  The new files are written to the late generation, which is the max
+// generation of the group, and keep the highest level of the files they replace.
+func (c *Compactor) CompactLate(tsmFiles []string) ([]string, error) {
+	c.mu.RLock()
+	enabled := c.compactionsEnabled
+	c.mu.RUnlock()
+
+	if !enabled {
+		return nil, errCompactionsDisabled
+	}
+
+	if !c.add(tsmFiles) {
+		return nil, errCompactionInProgress{}
+	}
+	defer c.remove(tsmFiles)
+
+	files, err := c.compact(false, tsmFiles, nil, true)
+
+	// See if we were disabled while writing the files
+	c.mu.RLock()
+	enabled = c.compactionsEnabled
+	c.mu.RUnlock()
+
+	if !enabled {
+		if err := c.removeTmpFiles(files); err != nil {
+			return nil, err
+		}
+		return nil, errCompactionsDisabled
+	}
+
+	return files, err
+}
+
+// CompactTombstones writes the files of a generation into 1 or more new files of the
+// same generation without the values deleted by their tombstones.  Only the blocks
+// overlapping a tombstone are decoded and rewritten, every other block is copied as is.
+func (c *Compactor) CompactTombstones(tsmFiles []string) ([]string, error) {
+	c.mu.RLock()
+	enabled := c.compactionsEnabled
+	intC := c.compactionsInterrupt
+	c.mu.RUnlock()
+
+	if !enabled {
+		return nil, errCompactionsDisabled
+	}
+
+	if !c.add(tsmFiles) {
+		return nil, errCompactionInProgress{}
+	}
+	defer c.remove(tsmFiles)
+
+	files, err := func() ([]string, error) {
+		compression, err := parseStringCompression(c.StringCompression)
+		if err != nil {
+			return nil, err
+		}
+
+		generation, sequence, err := maxGenerationSequence(tsmFiles)
+		if err != nil {
+			return nil, err
+		}
+
+		trs, err := c.readers(tsmFiles, intC)
+		if err != nil {
+			return nil, err
+		} else if len(trs) == 0 {
+			return nil, nil
+		}
+
+		dir := c.Dir
+		if c.isCold(tsmFiles) {
+			dir = c.ColdDir
+		}
+
+		iter := newTombstoneKeyIterator(compression, intC, trs...)
+		return c.writeNewFiles(dir, generation, sequence, iter, true)
+	}()
+
+	// See if we were disabled while writing the files
+	c.mu.RLock()
+	enabled = c.compactionsEnabled
+	c.mu.RUnlock()
+
+	if !enabled {
+		if err := c.removeTmpFiles(files); err != nil {
+			return nil, err
+		}
+		return nil, errCompactionsDisabled
+	}
+
+	return files, err
+}
+
+// MoveToColdTier copies the TSM files into the cold tier.  The copies are
+// written with a tmp extension and become live when passed to FileStore.Replace
+// along with the original files, which are removed at that point.
+func (c *Compactor) MoveToColdTier(tsmFiles []string) ([]string, error) {
+	if c.ColdDir == "" {
+		return nil, errColdTierDisabled
+	}
+
+	c.mu.RLock()
+	enabled := c.compactionsEnabled
+	intC := c.compactionsInterrupt
+	c.mu.RUnlock()
+
+	if !enabled {
+		return nil, errCompactionsDisabled
+	}
+
+	if !c.add(tsmFiles) {
+		return nil, errCompactionInProgress{}
+	}
+	defer c.remove(tsmFiles)
+
+	files := make([]string, 0, len(tsmFiles))
+	for _, f := range tsmFiles {
+		select {
+		case <-intC:
+			if err := c.removeTmpFiles(files); err != nil {
+				return nil, err
+			}
+			return nil, errCompactionAborted{}
+		default:
+		}
+
+		// Tombstones are not moved with the file, so skip any file that had keys
+		// deleted since it was planned.  It will be moved once it is compacted.
+		if tr := c.FileStore.TSMReader(f); tr == nil || tr.HasTombstones() {
+			if err := c.removeTmpFiles(files); err != nil {
+				return nil, err
+			}
+			return nil, errCompactionAborted{fmt.Errorf("bad plan: %s", f)}
+		}
+
+		fileName := filepath.Join(c.ColdDir, fmt.Sprintf("%s.%s", filepath.Base(f), CompactionTempExtension))
+		if err := c.copyFileSync(f, fileName); err != nil {
+			os.RemoveAll(fileName)
+			if err := c.removeTmpFiles(files); err != nil {
+				return nil, err
+			}
+			return nil, err
+		}
+		files = append(files, fileName)
+	}
+
+	// See if we were disabled while copying the files
+	c.mu.RLock()
+	enabled = c.compactionsEnabled
+	c.mu.RUnlock()
+
+	if !enabled {
+		if err := c.removeTmpFiles(files); err != nil {
+			return nil, err
+		}
+		return nil, errCompactionsDisabled
+	}
+
+	return files, nil
+}
+
+// copyFileSync copies the file at src to a new file at dst and syncs it to disk.
+// The copy is written at the rate allowed by Throughput.
+func (c *Compactor) copyFileSync(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_RDWR|os.O_EXCL, 0666)
+	if err != nil {
+		return errCompactionInProgress{err: err}
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(throttledWriter{c: c, w: out}, in); err != nil {
+		return err
+	}
+	return out.Sync()
+}
+
+// throttledWriter writes to w at the rate allowed by the Throughput of c.
+type throttledWriter struct {
+	c *Compactor
+	w io.Writer
+}
+
+func (w throttledWriter) Write(p []byte) (int, error) {
+	w.c.throttle(len(p))
+	return w.w.Write(p)
+}
+
 // removeTmpFiles is responsible for cleaning up a compaction that
 // was started, but then abandoned before the temporary files were dealt with.
 func (c *Compactor) removeTmpFiles(files []string) error {
@@ -976,18 +1726,19 @@ func (c *Compactor) removeTmpFiles(files []string) error {
 }
 
 // writeNewFiles writes from the iterator into new TSM files, rotating
-// to a new file once it has reached the max TSM file size.
-func (c *Compactor) writeNewFiles(generation, sequence int, iter KeyIterator) ([]string, error) {
+// to a new file once it has reached the max TSM file size.  If throttle is true,
+// writes are limited by Throughput.
+func (c *Compactor) writeNewFiles(dir string, generation, sequence int, iter KeyIterator, throttle bool) ([]string, error) {
 	// These are the new TSM files written
 	var files []string
 
 	for {
 		sequence++
 		// New TSM files are written to a temp file and renamed when fully completed.
-		fileName := filepath.Join(c.Dir, fmt.Sprintf("%09d-%09d.%s.tmp", generation, sequence, TSMFileExtension))
+		fileName := filepath.Join(dir, fmt.Sprintf("%09d-%09d.%s.tmp", generation, sequence, TSMFileExtension))
 
 		// Write as much as possible to this file
-		err := c.write(fileName, iter)
+		err := c.write(fileName, iter, throttle)
 
 		// We've hit the max file limit and there is more to write.  Create a new file
 		// and continue.
@@ -1026,7 +1777,7 @@ func (c *Compactor) writeNewFiles(generation, sequence int, iter KeyIterator) ([
 	return files, nil
 }
 
-func (c *Compactor) write(path string, iter KeyIterator) (err error) {
+func (c *Compactor) write(path string, iter KeyIterator, throttle bool) (err error) {
 	fd, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_EXCL|os.O_SYNC, 0666)
 	if err != nil {
 		return errCompactionInProgress{err: err}
@@ -1072,8 +1823,24 @@ func (c *Compactor) write(path string, iter KeyIterator) (err error) {
 			return err
 		}
 
-		// Write the key and value
-		if err := w.WriteBlock(key, minTime, maxTime, block); err == ErrMaxBlocksExceeded {
+		if throttle {
+			c.throttle(len(key) + len(block))
+		}
+
+		// Write the key and value, along with the statistics of the block if the
+		// iterator knows them.
+		var stats *BlockStats
+		if r, ok := iter.(blockStatsReader); ok {
+			if s := r.readStats(); s.Count > 0 {
+				stats = &s
+			}
+		}
+		if tw, ok := w.(*tsmWriter); ok {
+			err = tw.writeBlockStats(key, minTime, maxTime, block, stats)
+		} else {
+			err = w.WriteBlock(key, minTime, maxTime, block)
+		}
+		if err == ErrMaxBlocksExceeded {
 			if err := w.WriteIndex(); err != nil {
 				return err
 			}
@@ -1105,6 +1872,23 @@ func (c *Compactor) write(path string, iter KeyIterator) (err error) {
 	return nil
 }
 
+// throttle delays the compaction writing n bytes to stay within Throughput.
+func (c *Compactor) throttle(n int) {
+	if c.Throughput == nil || !c.ThroughputWindow.Contains(time.Now()) {
+		return
+	}
+
+	if d := c.Throughput.WaitN(n); d > 0 {
+		atomic.AddInt64(&c.throttled, int64(d))
+	}
+}
+
+// Throttled returns the total time compactions have been delayed to stay within
+// Throughput.
+func (c *Compactor) Throttled() time.Duration {
+	return time.Duration(atomic.LoadInt64(&c.throttled))
+}
+
 func (c *Compactor) add(files []string) bool {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -1175,6 +1959,9 @@ type tsmKeyIterator struct {
 	// size is the maximum number of values to encode in a single block
 	size int
 
+	// stringCompression is the encoding type used to compress string blocks
+	stringCompression byte
+
 	// key is the current key lowest key across all readers that has not be fully exhausted
 	// of values.
 	key []byte
@@ -1204,6 +1991,7 @@ type block struct {
 	typ              byte
 	b                []byte
 	tombstones       []TimeRange
+	stats            BlockStats
 
 	// readMin, readMax are the timestamps range of values have been
 	// read and encoded from this block.
@@ -1253,20 +2041,27 @@ func (a blocks) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
 // NewTSMKeyIterator returns a new TSM key iterator from readers.
 // size indicates the maximum number of values to encode in a single block.
 func NewTSMKeyIterator(size int, fast bool, interrupt chan struct{}, readers ...*TSMReader) (KeyIterator, error) {
+	return newTSMKeyIterator(size, fast, stringCompressedSnappy, interrupt, readers...)
+}
+
+// newTSMKeyIterator returns a new TSM key iterator from readers that compresses
+// re-encoded string blocks using the stringCompression encoding type.
+func newTSMKeyIterator(size int, fast bool, stringCompression byte, interrupt chan struct{}, readers ...*TSMReader) (KeyIterator, error) {
 	var iter []*BlockIterator
 	for _, r := range readers {
 		iter = append(iter, r.BlockIterator())
 	}
 
 	return &tsmKeyIterator{
-		readers:   readers,
-		values:    map[string][]Value{},
-		pos:       make([]int, len(readers)),
-		size:      size,
-		iterators: iter,
-		fast:      fast,
-		buf:       make([]blocks, len(iter)),
-		interrupt: interrupt,
+		readers:           readers,
+		values:            map[string][]Value{},
+		pos:               make([]int, len(readers)),
+		size:              size,
+		stringCompression: stringCompression,
+		iterators:         iter,
+		fast:              fast,
+		buf:               make([]blocks, len(iter)),
+		interrupt:         interrupt,
 	}, nil
 }
 
@@ -1336,6 +2131,7 @@ func (k *tsmKeyIterator) Next() bool {
 				blk.typ = typ
 				blk.b = b
 				blk.tombstones = tombstones
+				blk.stats = iter.stats()
 				blk.readMin = math.MaxInt64
 				blk.readMax = math.MinInt64
 
@@ -1368,6 +2164,7 @@ func (k *tsmKeyIterator) Next() bool {
 					blk.typ = typ
 					blk.b = b
 					blk.tombstones = tombstones
+					blk.stats = iter.stats()
 					blk.readMin = math.MaxInt64
 					blk.readMax = math.MinInt64
 				}
@@ -1451,6 +2248,15 @@ func (k *tsmKeyIterator) Read() ([]byte, int64, int64, []byte, error) {
 	return block.key, block.minTime, block.maxTime, block.b, k.err
 }
 
+// readStats returns the statistics of the block returned by Read.  Blocks copied from
+// the readers keep the statistics of their index entries.
+func (k *tsmKeyIterator) readStats() BlockStats {
+	if len(k.merged) == 0 {
+		return BlockStats{}
+	}
+	return k.merged[0].stats
+}
+
 func (k *tsmKeyIterator) Close() error {
 	k.values = nil
 	k.pos = nil
@@ -1473,6 +2279,9 @@ type cacheKeyIterator struct {
 	size  int
 	order [][]byte
 
+	// stringCompression is the encoding type used to compress string blocks
+	stringCompression byte
+
 	i         int
 	blocks    [][]cacheBlock
 	ready     []chan struct{}
@@ -1484,11 +2293,18 @@ type cacheBlock struct {
 	k                []byte
 	minTime, maxTime int64
 	b                []byte
+	stats            BlockStats
 	err              error
 }
 
 // NewCacheKeyIterator returns a new KeyIterator from a Cache.
 func NewCacheKeyIterator(cache *Cache, size int, interrupt chan struct{}) KeyIterator {
+	return newCacheKeyIterator(cache, size, stringCompressedSnappy, interrupt)
+}
+
+// newCacheKeyIterator returns a new KeyIterator from a Cache that compresses string
+// blocks using the stringCompression encoding type.
+func newCacheKeyIterator(cache *Cache, size int, stringCompression byte, interrupt chan struct{}) KeyIterator {
 	keys := cache.Keys()
 
 	chans := make([]chan struct{}, len(keys))
@@ -1497,13 +2313,14 @@ func NewCacheKeyIterator(cache *Cache, size int, interrupt chan struct{}) KeyIte
 	}
 
 	cki := &cacheKeyIterator{
-		i:         -1,
-		size:      size,
-		cache:     cache,
-		order:     keys,
-		ready:     chans,
-		blocks:    make([][]cacheBlock, len(keys)),
-		interrupt: interrupt,
+		i:                 -1,
+		size:              size,
+		stringCompression: stringCompression,
+		cache:             cache,
+		order:             keys,
+		ready:             chans,
+		blocks:            make([][]cacheBlock, len(keys)),
+		interrupt:         interrupt,
 	}
 	go cki.encode()
 	return cki
@@ -1525,6 +2342,7 @@ func (c *cacheKeyIterator) encode() {
 			benc := getBooleanEncoder(tsdb.DefaultMaxPointsPerBlock)
 			uenc := getUnsignedEncoder(tsdb.DefaultMaxPointsPerBlock)
 			senc := getStringEncoder(tsdb.DefaultMaxPointsPerBlock)
+			senc.compression = c.stringCompression
 			ienc := getIntegerEncoder(tsdb.DefaultMaxPointsPerBlock)
 
 			defer putTimeEncoder(tenc)
@@ -1570,6 +2388,7 @@ func (c *cacheKeyIterator) encode() {
 						b, err = Values(values[:end]).Encode(nil)
 					}
 
+					stats := newBlockStats(values[:end])
 					values = values[end:]
 
 					c.blocks[i] = append(c.blocks[i], cacheBlock{
@@ -1577,6 +2396,7 @@ func (c *cacheKeyIterator) encode() {
 						minTime: minTime,
 						maxTime: maxTime,
 						b:       b,
+						stats:   stats,
 						err:     err,
 					})
 
@@ -1621,6 +2441,11 @@ func (c *cacheKeyIterator) Read() ([]byte, int64, int64, []byte, error) {
 	return blk.k, blk.minTime, blk.maxTime, blk.b, blk.err
 }
 
+// readStats returns the statistics of the block returned by Read.
+func (c *cacheKeyIterator) readStats() BlockStats {
+	return c.blocks[c.i][0].stats
+}
+
 func (c *cacheKeyIterator) Close() error {
 	return nil
 }
@@ -1680,3 +2505,11 @@ func (a tsmGenerations) IsSorted() bool {
 	}
 	return true
 }
+
+// tsmFileNames sorts TSM file paths by file name so that files are ordered by
+// generation and sequence regardless of which storage tier they are in.
+type tsmFileNames []string
+
+func (a tsmFileNames) Len() int           { return len(a) }
+func (a tsmFileNames) Less(i, j int) bool { return filepath.Base(a[i]) < filepath.Base(a[j]) }
+func (a tsmFileNames) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/influxdata/influxdb/pkg/limiter"
 	"github.com/influxdata/influxdb/tsdb"
 	"github.com/influxdata/influxdb/tsdb/engine/tsm1"
 )
@@ -847,6 +848,293 @@ func TestCompactor_CompactFull_MaxKeys(t *testing.T) {
 	}
 }
 
+// Ensures that string blocks re-encoded by a compaction use the configured compression.
+func TestCompactor_CompactFull_StringCompression(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	a1 := tsm1.NewValue(1, "GET /api/v2/query HTTP/1.1 200")
+	writes := map[string][]tsm1.Value{
+		"log,host=A#!~#message": []tsm1.Value{a1},
+	}
+	f1 := MustWriteTSM(dir, 1, writes)
+
+	a2 := tsm1.NewValue(2, "GET /api/v2/write HTTP/1.1 204")
+	writes = map[string][]tsm1.Value{
+		"log,host=A#!~#message": []tsm1.Value{a2},
+	}
+	f2 := MustWriteTSM(dir, 2, writes)
+
+	fs := &fakeFileStore{}
+	defer fs.Close()
+	compactor := &tsm1.Compactor{
+		Dir:               dir,
+		FileStore:         fs,
+		StringCompression: "zip",
+	}
+	compactor.Open()
+
+	if _, err := compactor.CompactFull([]string{f1, f2}); err == nil {
+		t.Fatalf("expected error compacting with an unknown string compression")
+	}
+
+	compactor.StringCompression = "deflate"
+	files, err := compactor.CompactFull([]string{f1, f2})
+	if err != nil {
+		t.Fatalf("unexpected error compacting: %v", err)
+	}
+
+	if got, exp := len(files), 1; got != exp {
+		t.Fatalf("files length mismatch: got %v, exp %v", got, exp)
+	}
+
+	r := MustOpenTSMReader(files[0])
+	defer r.Close()
+
+	values, err := r.ReadAll([]byte("log,host=A#!~#message"))
+	if err != nil {
+		t.Fatalf("unexpected error reading: %v", err)
+	}
+
+	exp := []tsm1.Value{a1, a2}
+	if got, exp := len(values), len(exp); got != exp {
+		t.Fatalf("values length mismatch: got %v, exp %v", got, exp)
+	}
+
+	for i, v := range exp {
+		assertValueEqual(t, values[i], v)
+	}
+}
+
+// Ensures that a rollup compaction replaces raw values with their aggregates.
+func TestCompactor_CompactRollup(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	writes := map[string][]tsm1.Value{
+		"cpu,host=A#!~#value": []tsm1.Value{
+			tsm1.NewValue(1, 1.0), tsm1.NewValue(5, 3.0), tsm1.NewValue(12, 4.0),
+		},
+		"cpu,host=A#!~#status": []tsm1.Value{
+			tsm1.NewValue(1, "ok"), tsm1.NewValue(5, "warn"),
+		},
+	}
+	f1 := MustWriteTSM(dir, 1, writes)
+
+	writes = map[string][]tsm1.Value{
+		"cpu,host=A#!~#value": []tsm1.Value{tsm1.NewValue(15, 8.0)},
+		"cpu,host=B#!~#value": []tsm1.Value{tsm1.NewValue(2, int64(2)), tsm1.NewValue(4, int64(6))},
+	}
+	f2 := MustWriteTSM(dir, 2, writes)
+
+	fs := &fakeFileStore{}
+	defer fs.Close()
+	compactor := &tsm1.Compactor{
+		Dir:       dir,
+		FileStore: fs,
+	}
+	compactor.Open()
+
+	rule := &tsdb.RollupRule{Interval: 10, Functions: []string{"mean", "max", "first"}}
+	files, err := compactor.CompactRollup([]string{f1, f2}, rule)
+	if err != nil {
+		t.Fatalf("unexpected error compacting: %v", err)
+	}
+
+	if got, exp := len(files), 1; got != exp {
+		t.Fatalf("files length mismatch: got %v, exp %v", got, exp)
+	}
+
+	r := MustOpenTSMReader(files[0])
+	defer r.Close()
+
+	var data = []struct {
+		key    string
+		points []tsm1.Value
+	}{
+		{"cpu,host=A#!~#first_status", []tsm1.Value{tsm1.NewValue(0, "ok")}},
+		{"cpu,host=A#!~#first_value", []tsm1.Value{tsm1.NewValue(0, 1.0), tsm1.NewValue(10, 4.0)}},
+		{"cpu,host=A#!~#max_value", []tsm1.Value{tsm1.NewValue(0, 3.0), tsm1.NewValue(10, 8.0)}},
+		{"cpu,host=A#!~#mean_value", []tsm1.Value{tsm1.NewValue(0, 2.0), tsm1.NewValue(10, 6.0)}},
+		{"cpu,host=B#!~#first_value", []tsm1.Value{tsm1.NewValue(0, int64(2))}},
+		{"cpu,host=B#!~#max_value", []tsm1.Value{tsm1.NewValue(0, int64(6))}},
+		{"cpu,host=B#!~#mean_value", []tsm1.Value{tsm1.NewValue(0, 4.0)}},
+	}
+
+	if got, exp := r.KeyCount(), len(data); got != exp {
+		t.Fatalf("keys length mismatch: got %v, exp %v", got, exp)
+	}
+
+	for _, p := range data {
+		values, err := r.ReadAll([]byte(p.key))
+		if err != nil {
+			t.Fatalf("unexpected error reading: %v", err)
+		}
+
+		if got, exp := len(values), len(p.points); got != exp {
+			t.Fatalf("values length mismatch %s: got %v, exp %v", p.key, got, exp)
+		}
+
+		for i, point := range p.points {
+			assertValueEqual(t, values[i], point)
+		}
+	}
+}
+
+// Ensures that files moved to the cold tier are copied unchanged.
+func TestCompactor_MoveToColdTier(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+	coldDir := MustTempDir()
+	defer os.RemoveAll(coldDir)
+
+	a1 := tsm1.NewValue(1, 1.1)
+	writes := map[string][]tsm1.Value{
+		"cpu,host=A#!~#value": []tsm1.Value{a1},
+	}
+	f1 := MustWriteTSM(dir, 1, writes)
+
+	fs := &fakeFileStore{}
+	defer fs.Close()
+	compactor := &tsm1.Compactor{
+		Dir:       dir,
+		FileStore: fs,
+	}
+	compactor.Open()
+
+	if _, err := compactor.MoveToColdTier([]string{f1}); err == nil {
+		t.Fatalf("expected error moving files without a cold tier")
+	}
+
+	// Copies are limited by the compaction throughput, which has no tokens left.
+	compactor.Throughput = limiter.NewRate(10000, 10000)
+	compactor.Throughput.WaitN(10000)
+
+	compactor.ColdDir = coldDir
+	files, err := compactor.MoveToColdTier([]string{f1})
+	if err != nil {
+		t.Fatalf("unexpected error moving files: %v", err)
+	} else if compactor.Throttled() == 0 {
+		t.Fatal("expected copy to be throttled")
+	}
+
+	if got, exp := len(files), 1; got != exp {
+		t.Fatalf("files length mismatch: got %v, exp %v", got, exp)
+	}
+
+	if got, exp := files[0], filepath.Join(coldDir, filepath.Base(f1)+".tmp"); got != exp {
+		t.Fatalf("file name mismatch: got %v, exp %v", got, exp)
+	}
+
+	if _, err := os.Stat(f1); err != nil {
+		t.Fatalf("expected original file to exist: %v", err)
+	}
+
+	r := MustOpenTSMReader(files[0])
+	defer r.Close()
+
+	values, err := r.ReadAll([]byte("cpu,host=A#!~#value"))
+	if err != nil {
+		t.Fatalf("unexpected error reading: %v", err)
+	}
+
+	if got, exp := len(values), 1; got != exp {
+		t.Fatalf("values length mismatch: got %v, exp %v", got, exp)
+	}
+	assertValueEqual(t, values[0], a1)
+}
+
+// Ensures that a tombstone compaction only rewrites the blocks overlapping a tombstone.
+func TestCompactor_CompactTombstones(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	a1, a2 := tsm1.NewValue(1, 1.1), tsm1.NewValue(2, 1.2)
+	a3, a4 := tsm1.NewValue(3, 1.3), tsm1.NewValue(4, 1.4)
+	b1 := tsm1.NewValue(1, 2.1)
+
+	w, f1 := MustTSMWriter(dir, 1)
+	if err := w.Write([]byte("cpu,host=A#!~#value"), []tsm1.Value{a1, a2}); err != nil {
+		t.Fatal(err)
+	} else if err := w.Write([]byte("cpu,host=A#!~#value"), []tsm1.Value{a3, a4}); err != nil {
+		t.Fatal(err)
+	} else if err := w.Write([]byte("cpu,host=B#!~#value"), []tsm1.Value{b1}); err != nil {
+		t.Fatal(err)
+	} else if err := w.WriteIndex(); err != nil {
+		t.Fatal(err)
+	} else if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := tsm1.Tombstoner{
+		Path: f1,
+	}
+	ts.AddRange([][]byte{[]byte("cpu,host=A#!~#value")}, 3, 3)
+	if err := ts.Flush(); err != nil {
+		t.Fatalf("unexpected error flushing tombstone: %v", err)
+	}
+
+	fs := &fakeFileStore{}
+	defer fs.Close()
+	compactor := &tsm1.Compactor{
+		Dir:       dir,
+		FileStore: fs,
+	}
+	compactor.Open()
+
+	files, err := compactor.CompactTombstones([]string{f1})
+	if err != nil {
+		t.Fatalf("unexpected error compacting tombstones: %v", err)
+	}
+
+	if got, exp := len(files), 1; got != exp {
+		t.Fatalf("files length mismatch: got %v, exp %v", got, exp)
+	}
+
+	if gen, seq, err := tsm1.ParseTSMFileName(files[0]); err != nil {
+		t.Fatalf("unexpected error parsing file name: %v", err)
+	} else if gen != 1 || seq != 2 {
+		t.Fatalf("wrong generation and sequence for new file: got %d-%d, exp 1-2", gen, seq)
+	}
+
+	r := MustOpenTSMReader(files[0])
+	defer r.Close()
+
+	var data = []struct {
+		key    string
+		points []tsm1.Value
+	}{
+		{"cpu,host=A#!~#value", []tsm1.Value{a1, a2, a4}},
+		{"cpu,host=B#!~#value", []tsm1.Value{b1}},
+	}
+
+	for _, p := range data {
+		values, err := r.ReadAll([]byte(p.key))
+		if err != nil {
+			t.Fatalf("unexpected error reading: %v", err)
+		}
+
+		if got, exp := len(values), len(p.points); got != exp {
+			t.Fatalf("values length mismatch %s: got %v, exp %v", p.key, got, exp)
+		}
+
+		for i, point := range p.points {
+			assertValueEqual(t, values[i], point)
+		}
+	}
+
+	// The untouched block is copied as is and the deleted value is removed from
+	// the other one.
+	entries := r.Entries([]byte("cpu,host=A#!~#value"))
+	if got, exp := len(entries), 2; got != exp {
+		t.Fatalf("block count mismatch: got %v, exp %v", got, exp)
+	} else if entries[0].MinTime != 1 || entries[0].MaxTime != 2 {
+		t.Fatalf("unexpected first block: %d-%d", entries[0].MinTime, entries[0].MaxTime)
+	} else if entries[1].MinTime != 4 || entries[1].MaxTime != 4 {
+		t.Fatalf("unexpected second block: %d-%d", entries[1].MinTime, entries[1].MaxTime)
+	}
+}
+
 // Tests that a single TSM file can be read and iterated over
 func TestTSMKeyIterator_Single(t *testing.T) {
 	dir := MustTempDir()
@@ -2560,6 +2848,357 @@ func TestDefaultPlanner_Plan_ForceFull(t *testing.T) {
 
 }
 
+func TestDefaultPlanner_PlanColdTier(t *testing.T) {
+	dir, coldDir := filepath.Join("data", "1"), filepath.Join("cold", "1")
+	old := time.Now().Add(-48 * time.Hour).UnixNano()
+	data := []tsm1.FileStat{
+		tsm1.FileStat{
+			Path:    filepath.Join(coldDir, "000000001-000000004.tsm"),
+			MaxTime: old,
+		},
+		tsm1.FileStat{
+			Path:    filepath.Join(dir, "000000002-000000004.tsm"),
+			MaxTime: old,
+		},
+		tsm1.FileStat{
+			Path:    filepath.Join(dir, "000000002-000000005.tsm"),
+			MaxTime: old,
+		},
+		tsm1.FileStat{
+			Path:         filepath.Join(dir, "000000003-000000004.tsm"),
+			MaxTime:      old,
+			HasTombstone: true,
+		},
+		tsm1.FileStat{
+			Path:    filepath.Join(dir, "000000004-000000002.tsm"),
+			MaxTime: old,
+		},
+		tsm1.FileStat{
+			Path:    filepath.Join(dir, "000000005-000000004.tsm"),
+			MaxTime: time.Now().UnixNano(),
+		},
+	}
+
+	cp := tsm1.NewDefaultPlanner(
+		&fakeFileStore{
+			PathsFn: func() []tsm1.FileStat {
+				return data
+			},
+		}, tsdb.DefaultCompactFullWriteColdDuration,
+	)
+
+	// No plans are returned without a cold tier.
+	if tsm := cp.PlanColdTier(); len(tsm) != 0 {
+		t.Fatalf("tsm file length mismatch: got %v, exp %v", len(tsm), 0)
+	}
+
+	cp.ColdDir = coldDir
+	cp.ColdTierAge = 24 * time.Hour
+
+	expFiles := []tsm1.FileStat{data[1], data[2]}
+	tsm := cp.PlanColdTier()
+	if exp, got := 1, len(tsm); got != exp {
+		t.Fatalf("group length mismatch: got %v, exp %v", got, exp)
+	}
+
+	if exp, got := len(expFiles), len(tsm[0]); got != exp {
+		t.Fatalf("tsm file length mismatch: got %v, exp %v", got, exp)
+	}
+
+	for i, p := range expFiles {
+		if got, exp := tsm[0][i], p.Path; got != exp {
+			t.Fatalf("tsm file mismatch: got %v, exp %v", got, exp)
+		}
+	}
+
+	// The files are in use until released.
+	if tsm := cp.PlanColdTier(); len(tsm) != 0 {
+		t.Fatalf("tsm file length mismatch: got %v, exp %v", len(tsm), 0)
+	}
+	cp.Release(tsm)
+
+	if tsm := cp.PlanColdTier(); len(tsm) != 1 {
+		t.Fatalf("group length mismatch: got %v, exp %v", len(tsm), 1)
+	}
+}
+
+func TestDefaultPlanner_PlanTombstones(t *testing.T) {
+	data := []tsm1.FileStat{
+		tsm1.FileStat{
+			Path:          "000000001-000000004.tsm",
+			TombstoneSize: 2048,
+		},
+		tsm1.FileStat{
+			Path:          "000000002-000000004.tsm",
+			TombstoneSize: 512,
+		},
+		tsm1.FileStat{
+			Path:          "000000002-000000005.tsm",
+			TombstoneSize: 512,
+		},
+		tsm1.FileStat{
+			Path:          "000000003-000000004.tsm",
+			TombstoneSize: 512,
+		},
+		tsm1.FileStat{
+			Path:          "000000004-000000002.tsm",
+			TombstoneSize: 2048,
+		},
+	}
+
+	cp := tsm1.NewDefaultPlanner(
+		&fakeFileStore{
+			PathsFn: func() []tsm1.FileStat {
+				return data
+			},
+		}, tsdb.DefaultCompactFullWriteColdDuration,
+	)
+
+	// No plans are returned without a threshold.
+	if tsm := cp.PlanTombstones(); len(tsm) != 0 {
+		t.Fatalf("group length mismatch: got %v, exp %v", len(tsm), 0)
+	}
+
+	// Only fully compacted generations with enough tombstones are planned.
+	cp.TombstoneThreshold = 1024
+	tsm := cp.PlanTombstones()
+	if exp, got := 2, len(tsm); got != exp {
+		t.Fatalf("group length mismatch: got %v, exp %v", got, exp)
+	}
+
+	expGroups := [][]tsm1.FileStat{{data[0]}, {data[1], data[2]}}
+	for i, exp := range expGroups {
+		if got, exp := len(tsm[i]), len(exp); got != exp {
+			t.Fatalf("tsm file length mismatch: got %v, exp %v", got, exp)
+		}
+		for j, p := range exp {
+			if got, exp := tsm[i][j], p.Path; got != exp {
+				t.Fatalf("tsm file mismatch: got %v, exp %v", got, exp)
+			}
+		}
+	}
+
+	// The files are in use until released.
+	if tsm := cp.PlanTombstones(); len(tsm) != 0 {
+		t.Fatalf("group length mismatch: got %v, exp %v", len(tsm), 0)
+	}
+	cp.Release(tsm)
+
+	if tsm := cp.PlanTombstones(); len(tsm) != 2 {
+		t.Fatalf("group length mismatch: got %v, exp %v", len(tsm), 2)
+	}
+}
+
+func TestDefaultPlanner_PlanLate(t *testing.T) {
+	data := []tsm1.FileStat{
+		tsm1.FileStat{
+			Path:    "000000001-000000004.tsm",
+			MinTime: 0, MaxTime: 100,
+			MinKey: []byte("cpu,host=A"), MaxKey: []byte("cpu,host=Z"),
+		},
+		tsm1.FileStat{
+			Path:    "000000002-000000004.tsm",
+			MinTime: 100, MaxTime: 200,
+			MinKey: []byte("cpu,host=A"), MaxKey: []byte("cpu,host=Z"),
+		},
+		tsm1.FileStat{
+			Path:    "000000003-000000001.tsm",
+			MinTime: 200, MaxTime: 300,
+			MinKey: []byte("cpu,host=A"), MaxKey: []byte("cpu,host=Z"),
+		},
+	}
+
+	cp := tsm1.NewDefaultPlanner(
+		&fakeFileStore{
+			PathsFn: func() []tsm1.FileStat {
+				return data
+			},
+		}, tsdb.DefaultCompactFullWriteColdDuration,
+	)
+
+	// No plans are returned while every generation is newer than the ones before it.
+	if tsm := cp.PlanLate(); len(tsm) != 0 {
+		t.Fatalf("group length mismatch: got %v, exp %v", len(tsm), 0)
+	}
+
+	// A late generation is merged with every generation back to the oldest it overlaps.
+	data = append(data, tsm1.FileStat{
+		Path:    "000000004-000000001.tsm",
+		MinTime: 150, MaxTime: 160,
+		MinKey: []byte("cpu,host=B"), MaxKey: []byte("cpu,host=B"),
+	})
+	cp = tsm1.NewDefaultPlanner(
+		&fakeFileStore{
+			PathsFn: func() []tsm1.FileStat {
+				return data
+			},
+		}, tsdb.DefaultCompactFullWriteColdDuration,
+	)
+
+	expFiles := []tsm1.FileStat{data[1], data[2], data[3]}
+	tsm := cp.PlanLate()
+	if exp, got := 1, len(tsm); got != exp {
+		t.Fatalf("group length mismatch: got %v, exp %v", got, exp)
+	}
+
+	if exp, got := len(expFiles), len(tsm[0]); got != exp {
+		t.Fatalf("tsm file length mismatch: got %v, exp %v", got, exp)
+	}
+
+	for i, p := range expFiles {
+		if got, exp := tsm[0][i], p.Path; got != exp {
+			t.Fatalf("tsm file mismatch: got %v, exp %v", got, exp)
+		}
+	}
+
+	// The files are in use until released.
+	if tsm := cp.PlanLate(); len(tsm) != 0 {
+		t.Fatalf("group length mismatch: got %v, exp %v", len(tsm), 0)
+	}
+}
+
+func TestDefaultPlanner_PlanRollup(t *testing.T) {
+	data := []tsm1.FileStat{
+		tsm1.FileStat{
+			Path: "03-04.tsm1",
+			Size: 251 * 1024 * 1024,
+		},
+		tsm1.FileStat{
+			Path: "01-04.tsm1",
+			Size: 251 * 1024 * 1024,
+		},
+		tsm1.FileStat{
+			Path: "02-02.tsm1",
+			Size: 1 * 1024 * 1024,
+		},
+	}
+
+	cp := tsm1.NewDefaultPlanner(
+		&fakeFileStore{
+			PathsFn: func() []tsm1.FileStat {
+				return data
+			},
+		}, tsdb.DefaultCompactFullWriteColdDuration,
+	)
+
+	tsm := cp.PlanRollup()
+	if got, exp := len(tsm), 1; got != exp {
+		t.Fatalf("group length mismatch: got %v, exp %v", got, exp)
+	}
+
+	expFiles := []string{data[1].Path, data[2].Path, data[0].Path}
+	if got, exp := len(tsm[0]), len(expFiles); got != exp {
+		t.Fatalf("tsm file length mismatch: got %v, exp %v", got, exp)
+	}
+
+	for i, p := range expFiles {
+		if got, exp := tsm[0][i], p; got != exp {
+			t.Fatalf("tsm file mismatch: got %v, exp %v", got, exp)
+		}
+	}
+
+	// The files are in use until released.
+	if tsm := cp.PlanRollup(); len(tsm) != 0 {
+		t.Fatalf("group length mismatch: got %v, exp %v", len(tsm), 0)
+	}
+	cp.Release(tsm)
+
+	if tsm := cp.PlanRollup(); len(tsm) != 1 {
+		t.Fatalf("group length mismatch: got %v, exp %v", len(tsm), 1)
+	}
+}
+
+// Tests that the budget planner runs the level groups with the most series per
+// byte first while the budget applies.
+func TestBudgetPlanner_PlanLevel_SeriesPerByte(t *testing.T) {
+	var data []tsm1.FileStat
+	for i := 1; i <= 16; i++ {
+		keys := 1
+		if i > 8 {
+			keys = 1000
+		}
+		data = append(data, tsm1.FileStat{
+			Path:     fmt.Sprintf("%02d-01.tsm1", i),
+			Size:     1 * 1024 * 1024,
+			KeyCount: keys,
+		})
+	}
+
+	fs := &fakeFileStore{
+		PathsFn: func() []tsm1.FileStat {
+			return data
+		},
+	}
+
+	cp := tsm1.NewDefaultPlanner(fs, tsdb.DefaultCompactFullWriteColdDuration)
+	tsm := cp.PlanLevel(1)
+	if exp, got := 2, len(tsm); got != exp {
+		t.Fatalf("tsm group length mismatch: got %v, exp %v", got, exp)
+	} else if got, exp := tsm[0][0], data[0].Path; got != exp {
+		t.Fatalf("tsm file mismatch: got %v, exp %v", got, exp)
+	}
+
+	bp := tsm1.NewBudgetPlanner(fs, tsdb.DefaultCompactFullWriteColdDuration, 1024*1024, tsdb.TimeWindow{})
+	tsm = bp.PlanLevel(1)
+	if exp, got := 2, len(tsm); got != exp {
+		t.Fatalf("tsm group length mismatch: got %v, exp %v", got, exp)
+	}
+	for i, p := range data[8:] {
+		if got, exp := tsm[0][i], p.Path; got != exp {
+			t.Fatalf("tsm file mismatch: got %v, exp %v", got, exp)
+		}
+	}
+}
+
+// Tests that the budget planner defers full compactions that cannot finish before
+// the window ends.
+func TestBudgetPlanner_Plan_DeferFull(t *testing.T) {
+	data := []tsm1.FileStat{
+		tsm1.FileStat{
+			Path: "01-04.tsm1",
+			Size: 100 * 1024 * 1024,
+		},
+		tsm1.FileStat{
+			Path: "02-04.tsm1",
+			Size: 100 * 1024 * 1024,
+		},
+	}
+
+	// A window that ends a minute from now.
+	now := time.Now()
+	tod := now.Sub(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()))
+	window := tsdb.TimeWindow{
+		Start: (tod + 23*time.Hour) % (24 * time.Hour),
+		End:   (tod + time.Minute) % (24 * time.Hour),
+	}
+
+	cp := tsm1.NewBudgetPlanner(
+		&fakeFileStore{
+			PathsFn: func() []tsm1.FileStat {
+				return data
+			},
+		}, tsdb.DefaultCompactFullWriteColdDuration, 1024*1024, window,
+	)
+
+	cp.ForceFull()
+	if tsm := cp.Plan(time.Now()); len(tsm) != 0 {
+		t.Fatalf("expected full compaction to be deferred: %v", tsm)
+	}
+
+	// The deferred files are released to be planned again.
+	cp.Throughput = 1024 * 1024 * 1024
+	cp.ForceFull()
+	tsm := cp.Plan(time.Now())
+	if exp, got := 1, len(tsm); got != exp {
+		t.Fatalf("tsm group length mismatch: got %v, exp %v", got, exp)
+	}
+	for i, p := range data {
+		if got, exp := tsm[0][i], p.Path; got != exp {
+			t.Fatalf("tsm file mismatch: got %v, exp %v", got, exp)
+		}
+	}
+}
+
 func assertValueEqual(t *testing.T, a, b tsm1.Value) {
 	if got, exp := a.UnixNano(), b.UnixNano(); got != exp {
 		t.Fatalf("time mismatch: got %v, exp %v", got, exp)
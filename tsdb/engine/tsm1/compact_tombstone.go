@@ -0,0 +1,130 @@
+package tsm1
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// tombstoneKeyIterator iterates over the blocks of a generation of TSM files, removing
+// the values deleted by the tombstones of each file.  Only the blocks overlapping a
+// tombstone are decoded and encoded again, every other block is returned as is.  The
+// files of a generation hold sorted, non-overlapping ranges of keys, so they are read
+// one after another rather than merged.
+type tombstoneKeyIterator struct {
+	readers []*TSMReader
+	iter    *BlockIterator
+
+	// stringCompression is the encoding type used to compress rewritten string blocks
+	stringCompression byte
+
+	// the current block
+	key              []byte
+	minTime, maxTime int64
+	data             []byte
+
+	interrupt chan struct{}
+	err       error
+}
+
+// newTombstoneKeyIterator returns a KeyIterator over the blocks of readers, which must
+// be the files of a single generation in sequence order.
+func newTombstoneKeyIterator(stringCompression byte, interrupt chan struct{}, readers ...*TSMReader) *tombstoneKeyIterator {
+	return &tombstoneKeyIterator{
+		readers:           readers,
+		stringCompression: stringCompression,
+		interrupt:         interrupt,
+	}
+}
+
+// Next returns true if there are any blocks remaining in the iterator.
+func (k *tombstoneKeyIterator) Next() bool {
+	for k.err == nil {
+		select {
+		case <-k.interrupt:
+			k.err = errCompactionAborted{}
+			return false
+		default:
+		}
+
+		if k.iter == nil || !k.iter.Next() {
+			if k.iter != nil && k.iter.Err() != nil {
+				k.err = k.iter.Err()
+				return false
+			}
+			if len(k.readers) == 0 {
+				return false
+			}
+			k.iter, k.readers = k.readers[0].BlockIterator(), k.readers[1:]
+			continue
+		}
+
+		key, minTime, maxTime, _, _, data, err := k.iter.Read()
+		if err != nil {
+			k.err = err
+			return false
+		}
+
+		if bytes.Compare(key, k.key) < 0 {
+			k.err = fmt.Errorf("tombstone compaction: key %q out of order in %s", key, k.iter.r.Path())
+			return false
+		}
+
+		tombstones := k.iter.r.TombstoneRange(key)
+		if !overlapsTombstones(tombstones, minTime, maxTime) {
+			k.key, k.minTime, k.maxTime, k.data = key, minTime, maxTime, data
+			return true
+		}
+
+		values, err := DecodeBlock(data, nil)
+		if err != nil {
+			k.err = err
+			return false
+		}
+
+		for _, ts := range tombstones {
+			values = Values(values).Exclude(ts.Min, ts.Max)
+		}
+
+		// Drop blocks that were entirely deleted.
+		if len(values) == 0 {
+			continue
+		}
+
+		if data, err = encodeValuesBlock(values, k.stringCompression); err != nil {
+			k.err = err
+			return false
+		}
+		k.key, k.minTime, k.maxTime, k.data = key, values[0].UnixNano(), values[len(values)-1].UnixNano(), data
+		return true
+	}
+	return false
+}
+
+// Read returns the key, time range and encoded data of the current block.
+func (k *tombstoneKeyIterator) Read() ([]byte, int64, int64, []byte, error) {
+	if k.err != nil {
+		return nil, 0, 0, nil, k.err
+	}
+	return k.key, k.minTime, k.maxTime, k.data, nil
+}
+
+// Close releases the iterator.  The readers belong to the file store and are left open.
+func (k *tombstoneKeyIterator) Close() error {
+	k.readers, k.iter = nil, nil
+	return nil
+}
+
+// Err returns any errors encountered during iteration.
+func (k *tombstoneKeyIterator) Err() error {
+	return k.err
+}
+
+// overlapsTombstones returns true if any of the tombstones intersect min and max.
+func overlapsTombstones(tombstones []TimeRange, min, max int64) bool {
+	for _, ts := range tombstones {
+		if ts.Overlaps(min, max) {
+			return true
+		}
+	}
+	return false
+}
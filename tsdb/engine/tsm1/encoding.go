@@ -897,6 +897,39 @@ func encodeStringBlockUsing(buf []byte, values []Value, tenc TimeEncoder, venc S
 	return packBlock(buf, BlockString, tb, vb), nil
 }
 
+// encodeCompressedStringValuesBlock encodes values into a string block, compressing
+// the strings using the compression encoding type.
+func encodeCompressedStringValuesBlock(buf []byte, values []StringValue, compression byte) ([]byte, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	tenc := getTimeEncoder(len(values))
+	venc := getStringEncoder(len(values))
+	venc.compression = compression
+
+	defer putTimeEncoder(tenc)
+	defer putStringEncoder(venc)
+
+	for _, v := range values {
+		tenc.Write(v.unixnano)
+		venc.Write(v.value)
+	}
+
+	// Encoded timestamp values
+	tb, err := tenc.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	// Encoded string values
+	vb, err := venc.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	return packBlock(buf, BlockString, tb, vb), nil
+}
+
 // DecodeStringBlock decodes the string block from the byte slice
 // and appends the string values to a.
 func DecodeStringBlock(block []byte, a *[]StringValue) ([]StringValue, error) {
@@ -960,6 +993,87 @@ func DecodeStringBlock(block []byte, a *[]StringValue) ([]StringValue, error) {
 	return (*a)[:i], err
 }
 
+// DecodeStringBlockMatching decodes the string block from the byte slice and appends
+// the string values for which fn returns true to a.  The values of a dictionary encoded
+// block are matched by their code, so fn is only called once for each distinct value.
+func DecodeStringBlockMatching(block []byte, a *[]StringValue, fn func(string) bool) ([]StringValue, error) {
+	blockType := block[0]
+	if blockType != BlockString {
+		return nil, fmt.Errorf("invalid block type: exp %d, got %d", BlockString, blockType)
+	}
+
+	block = block[1:]
+
+	// The first 8 bytes is the minimum timestamp of the block
+	tb, vb, err := unpackBlock(block)
+	if err != nil {
+		return nil, err
+	}
+
+	sz := CountTimestamps(tb)
+
+	if cap(*a) < sz {
+		*a = make([]StringValue, sz)
+	} else {
+		*a = (*a)[:sz]
+	}
+
+	tdec := timeDecoderPool.Get(0).(*TimeDecoder)
+	vdec := stringDecoderPool.Get(0).(*StringDecoder)
+
+	var i int
+	err = func(a []StringValue) error {
+		// Setup our timestamp and value decoders
+		tdec.Init(tb)
+		err = vdec.SetBytes(vb)
+		if err != nil {
+			return err
+		}
+
+		// Match each distinct value of a dictionary encoded block once.
+		dict := vdec.Dictionary()
+		var matches []bool
+		if dict != nil {
+			matches = make([]bool, len(dict))
+			for k, v := range dict {
+				matches[k] = fn(v)
+			}
+		}
+
+		// Decode both a timestamp and value, keeping the matching values
+		j := 0
+		for j < len(a) && tdec.Next() && vdec.Next() {
+			if dict != nil {
+				if code := vdec.Code(); matches[code] {
+					a[j] = StringValue{unixnano: tdec.Read(), value: dict[code]}
+					j++
+				}
+			} else if v := vdec.Read(); fn(v) {
+				a[j] = StringValue{unixnano: tdec.Read(), value: v}
+				j++
+			}
+		}
+		i = j
+
+		// Did timestamp decoding have an error?
+		err = tdec.Error()
+		if err != nil {
+			return err
+		}
+		// Did string decoding have an error?
+		err = vdec.Error()
+		if err != nil {
+			return err
+		}
+		return nil
+	}(*a)
+
+	timeDecoderPool.Put(tdec)
+	stringDecoderPool.Put(vdec)
+
+	return (*a)[:i], err
+}
+
 func packBlock(buf []byte, typ byte, ts []byte, values []byte) []byte {
 	// We encode the length of the timestamp block using a variable byte encoding.
 	// This allows small byte slices to take up 1 byte while larger ones use 2 or more.
@@ -1042,6 +1156,7 @@ func putFloatEncoder(enc *FloatEncoder) { floatEncoderPool.Put(enc) }
 func getStringEncoder(sz int) StringEncoder {
 	x := stringEncoderPool.Get(sz).(StringEncoder)
 	x.Reset()
+	x.compression = stringCompressedSnappy
 	return x
 }
 func putStringEncoder(enc StringEncoder) { stringEncoderPool.Put(enc) }
@@ -3,6 +3,7 @@ package tsm1_test
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -254,6 +255,53 @@ func TestEncoding_StringBlock_Basic(t *testing.T) {
 	}
 }
 
+func TestEncoding_StringBlock_Matching(t *testing.T) {
+	valueCount := 1000
+	times := getTimes(valueCount, 60, time.Second)
+	states := []string{"OK", "WARN", "CRIT"}
+
+	for _, distinct := range []bool{false, true} {
+		values := make([]tsm1.Value, len(times))
+		var exp []tsm1.StringValue
+		for i, t := range times {
+			v := states[i%len(states)]
+			if distinct {
+				v = fmt.Sprintf("%s %d", v, i)
+			}
+			values[i] = tsm1.NewValue(t, v)
+			if strings.HasPrefix(v, "CRIT") {
+				exp = append(exp, values[i].(tsm1.StringValue))
+			}
+		}
+
+		b, err := tsm1.Values(values).Encode(nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		var calls int
+		var buf []tsm1.StringValue
+		got, err := tsm1.DecodeStringBlockMatching(b, &buf, func(v string) bool {
+			calls++
+			return strings.HasPrefix(v, "CRIT")
+		})
+		if err != nil {
+			t.Fatalf("unexpected error decoding block: %v", err)
+		}
+
+		if !reflect.DeepEqual(got, exp) {
+			t.Fatalf("unexpected results:\n\tgot: %v\n\texp: %v\n", got, exp)
+		}
+
+		// Values of a dictionary encoded block are matched once per distinct value.
+		if exp := len(states); !distinct && calls != exp {
+			t.Fatalf("unexpected number of matches: got %v, exp %v", calls, exp)
+		} else if exp := valueCount; distinct && calls != exp {
+			t.Fatalf("unexpected number of matches: got %v, exp %v", calls, exp)
+		}
+	}
+}
+
 func TestEncoding_BlockType(t *testing.T) {
 	tests := []struct {
 		value     interface{}
@@ -106,6 +106,33 @@ const (
 	statTSMFullCompactionError    = "tsmFullCompactionErr"
 	statTSMFullCompactionDuration = "tsmFullCompactionDuration"
 	statTSMFullCompactionQueue    = "tsmFullCompactionQueue"
+
+	statTSMColdTierMoves        = "tsmColdTierMoves"
+	statTSMColdTierMovesActive  = "tsmColdTierMovesActive"
+	statTSMColdTierMoveError    = "tsmColdTierMoveErr"
+	statTSMColdTierMoveDuration = "tsmColdTierMoveDuration"
+	statTSMColdTierMoveQueue    = "tsmColdTierMoveQueue"
+
+	statTSMRollupCompactions        = "tsmRollupCompactions"
+	statTSMRollupCompactionsActive  = "tsmRollupCompactionsActive"
+	statTSMRollupCompactionError    = "tsmRollupCompactionErr"
+	statTSMRollupCompactionDuration = "tsmRollupCompactionDuration"
+
+	statTSMTombstoneCompactions        = "tsmTombstoneCompactions"
+	statTSMTombstoneCompactionsActive  = "tsmTombstoneCompactionsActive"
+	statTSMTombstoneCompactionError    = "tsmTombstoneCompactionErr"
+	statTSMTombstoneCompactionDuration = "tsmTombstoneCompactionDuration"
+	statTSMTombstoneCompactionQueue    = "tsmTombstoneCompactionQueue"
+
+	statTSMLateCompactions        = "tsmLateCompactions"
+	statTSMLateCompactionsActive  = "tsmLateCompactionsActive"
+	statTSMLateCompactionError    = "tsmLateCompactionErr"
+	statTSMLateCompactionDuration = "tsmLateCompactionDuration"
+	statTSMLateCompactionQueue    = "tsmLateCompactionQueue"
+	statTSMLateValues             = "tsmLateValues"
+
+	statTSMCompactionQueueDepth    = "tsmCompactionQueueDepth"
+	statTSMCompactionThrottledTime = "tsmCompactionThrottledTime"
 )
 
 // Engine represents a storage engine with compressed blocks.
@@ -130,13 +157,23 @@ type Engine struct {
 	id           uint64
 	database     string
 	path         string
+	coldPath     string
 	logger       *zap.Logger // Logger to be used for important messages
 	traceLogger  *zap.Logger // Logger to be used when trace-logging is on.
 	traceLogging bool
 
+	// indexMu guards index, which SetIndex may replace.  It is held only to swap or
+	// load the index, so index reads don't wait on long holds of mu.
+	indexMu  sync.RWMutex
 	index    tsdb.Index
 	fieldset *tsdb.MeasurementFieldSet
 
+	rollup   *tsdb.RollupRule // rollup rule waiting to be applied to the shard
+	rolledUp *tsdb.RollupRule // rollup rule that has been applied to the shard
+
+	readOnly bool           // shard is sealed and rejects writes and deletes
+	deleteWG sync.WaitGroup // deletes that started before the shard was sealed
+
 	WAL            *WAL
 	Cache          *Cache
 	Compactor      *Compactor
@@ -154,6 +191,10 @@ type Engine struct {
 	// a snapshot of the cache to a TSM file
 	CacheFlushWriteColdDuration time.Duration
 
+	// LateWritesEnabled writes the values of a cache snapshot that fall into time
+	// ranges already persisted for their series to separate generations.
+	LateWritesEnabled bool
+
 	// Controls whether to enabled compactions when the engine is open
 	enableCompactionsOnOpen bool
 
@@ -163,19 +204,57 @@ type Engine struct {
 	compactionLimiter limiter.Fixed
 
 	scheduler *scheduler
+
+	// readSnapshotMu is held for writing by deletes and for reading while a ReadSnapshot
+	// is taken.
+	readSnapshotMu sync.RWMutex
 }
 
 // NewEngine returns a new instance of Engine.
 func NewEngine(id uint64, idx tsdb.Index, database, path string, walPath string, opt tsdb.EngineOptions) tsdb.Engine {
 	w := NewWAL(walPath)
 	w.syncDelay = time.Duration(opt.Config.WALFsyncDelay)
+	w.frameSize = int(opt.Config.WALFrameSize)
 
-	fs := NewFileStore(path)
+	// The compression has already been validated with the config.
+	if compression, err := parseWALCompression(opt.Config.WALCompression); err == nil {
+		w.compression = compression
+	}
+
+	fs := NewTieredFileStore(path, opt.ColdPath)
+	if blocks, ok := opt.BlockCache.(*blockCache); ok {
+		fs.blocks = blocks
+	}
 	cache := NewCache(uint64(opt.Config.CacheMaxMemorySize), path)
+	if opt.Config.CacheSpillEnabled {
+		cache.spillDir = filepath.Join(path, cacheSpillDir)
+	}
+
+	// The window has already been validated with the config.
+	window, _ := tsdb.ParseTimeWindow(opt.Config.CompactThroughputWindow)
 
 	c := &Compactor{
-		Dir:       path,
-		FileStore: fs,
+		Dir:               path,
+		ColdDir:           opt.ColdPath,
+		StringCompression: opt.Config.StringCompressionFor(database),
+		FileStore:         fs,
+		Throughput:        opt.CompactionThroughputLimiter,
+		ThroughputWindow:  window,
+	}
+
+	var planner CompactionPlanner
+	if opt.CompactionThroughputLimiter != nil {
+		p := NewBudgetPlanner(fs, time.Duration(opt.Config.CompactFullWriteColdDuration), opt.CompactionThroughputLimiter.Limit(), window)
+		p.ColdDir = opt.ColdPath
+		p.ColdTierAge = time.Duration(opt.Config.CompactColdTierAge)
+		p.TombstoneThreshold = uint64(opt.Config.CompactTombstoneThreshold)
+		planner = p
+	} else {
+		p := NewDefaultPlanner(fs, time.Duration(opt.Config.CompactFullWriteColdDuration))
+		p.ColdDir = opt.ColdPath
+		p.ColdTierAge = time.Duration(opt.Config.CompactColdTierAge)
+		p.TombstoneThreshold = uint64(opt.Config.CompactTombstoneThreshold)
+		planner = p
 	}
 
 	logger := zap.NewNop()
@@ -184,6 +263,7 @@ func NewEngine(id uint64, idx tsdb.Index, database, path string, walPath string,
 		id:           id,
 		database:     database,
 		path:         path,
+		coldPath:     opt.ColdPath,
 		index:        idx,
 		logger:       logger,
 		traceLogger:  logger,
@@ -196,14 +276,15 @@ func NewEngine(id uint64, idx tsdb.Index, database, path string, walPath string,
 
 		FileStore:      fs,
 		Compactor:      c,
-		CompactionPlan: NewDefaultPlanner(fs, time.Duration(opt.Config.CompactFullWriteColdDuration)),
+		CompactionPlan: planner,
 
 		CacheFlushMemorySizeThreshold: uint64(opt.Config.CacheSnapshotMemorySize),
 		CacheFlushWriteColdDuration:   time.Duration(opt.Config.CacheSnapshotWriteColdDuration),
+		LateWritesEnabled:             opt.Config.CompactLateWritesEnabled,
 		enableCompactionsOnOpen:       true,
-		stats:             stats,
-		compactionLimiter: opt.CompactionLimiter,
-		scheduler:         newScheduler(stats, opt.CompactionLimiter.Capacity()),
+		stats:                         stats,
+		compactionLimiter:             opt.CompactionLimiter,
+		scheduler:                     newScheduler(stats, opt.CompactionLimiter.Capacity()),
 	}
 
 	// Attach fieldset to index.
@@ -225,7 +306,12 @@ func (e *Engine) SetEnabled(enabled bool) {
 
 // SetCompactionsEnabled enables compactions on the engine.  When disabled
 // all running compactions are aborted and new compactions stop running.
+// Compactions are never enabled while the engine is read-only.
 func (e *Engine) SetCompactionsEnabled(enabled bool) {
+	if enabled && e.ReadOnly() {
+		return
+	}
+
 	if enabled {
 		e.enableSnapshotCompactions()
 		e.enableLevelCompactions(false)
@@ -235,6 +321,185 @@ func (e *Engine) SetCompactionsEnabled(enabled bool) {
 	}
 }
 
+// SetRollupRule sets the rule used to roll up the shard once its data is old enough.
+// The rule is ignored if the shard has already been rolled up.
+func (e *Engine) SetRollupRule(rule *tsdb.RollupRule) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.rolledUp == nil {
+		e.rollup = rule
+	}
+	return nil
+}
+
+// Rollup returns the rollup rule that has been applied to the shard, or nil if the
+// shard has not been rolled up.
+func (e *Engine) Rollup() *tsdb.RollupRule {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.rolledUp
+}
+
+// SetReadOnly seals or unseals the shard.  Sealing writes the cache to TSM files,
+// stops compactions, records the checksums of the TSM and tombstone files in a
+// manifest, makes the files read-only and removes the WAL.  While sealed, writes
+// and deletes return tsdb.ErrShardReadOnly.  Unsealing removes the manifest and
+// reopens the WAL.
+func (e *Engine) SetReadOnly(readOnly bool) error {
+	if readOnly {
+		return e.seal()
+	}
+	return e.unseal()
+}
+
+// ReadOnly returns true if the shard is sealed.
+func (e *Engine) ReadOnly() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.readOnly
+}
+
+// seal makes the shard read-only.  If sealing fails before the manifest is written
+// the shard is writable again.
+func (e *Engine) seal() error {
+	e.mu.Lock()
+	if e.readOnly {
+		e.mu.Unlock()
+		return nil
+	}
+	e.readOnly = true
+	e.mu.Unlock()
+
+	// Deletes that started before the shard was sealed may still write tombstones, so
+	// wait for them before building the manifest.
+	e.deleteWG.Wait()
+
+	m, err := func() (*SealManifest, error) {
+		// Stop compactions from replacing files and write out any values that
+		// were accepted before writes were rejected.
+		e.disableLevelCompactions(false)
+		if err := e.WriteSnapshot(); err != nil {
+			return nil, err
+		}
+		e.disableSnapshotCompactions()
+
+		m := &SealManifest{SealedAt: time.Now().UTC()}
+		for _, f := range e.FileStore.Files() {
+			paths := []string{f.Path()}
+			for _, ts := range f.TombstoneFiles() {
+				paths = append(paths, ts.Path)
+			}
+
+			for _, path := range paths {
+				cold := e.coldPath != "" && filepath.Dir(path) == filepath.Clean(e.coldPath)
+				sf, err := newSealedFile(path, cold)
+				if err != nil {
+					return nil, err
+				}
+				m.Files = append(m.Files, sf)
+			}
+		}
+		return m, writeSealManifest(e.path, m)
+	}()
+	if err != nil {
+		e.mu.Lock()
+		e.readOnly = false
+		e.mu.Unlock()
+		e.SetCompactionsEnabled(e.enableCompactionsOnOpen)
+		return err
+	}
+
+	for _, f := range m.Files {
+		if err := os.Chmod(e.sealedPath(f), 0444); err != nil {
+			return err
+		}
+	}
+
+	e.Cache.Free()
+	if err := e.WAL.Close(); err != nil {
+		return err
+	}
+	return os.RemoveAll(e.WAL.Path())
+}
+
+// unseal makes a sealed shard writable again.
+func (e *Engine) unseal() error {
+	e.mu.Lock()
+	if !e.readOnly {
+		e.mu.Unlock()
+		return nil
+	}
+
+	if err := func() error {
+		m, err := ReadSealManifest(e.path)
+		if err != nil {
+			return err
+		} else if m != nil {
+			for _, f := range m.Files {
+				if err := os.Chmod(e.sealedPath(f), f.Mode); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := os.RemoveAll(filepath.Join(e.path, SealFileName)); err != nil {
+			return err
+		}
+
+		w := NewWAL(e.WAL.Path())
+		w.syncDelay = e.WAL.syncDelay
+		w.compression = e.WAL.compression
+		w.frameSize = e.WAL.frameSize
+		w.enableTraceLogging(e.traceLogging)
+		w.WithLogger(e.logger)
+		if err := w.Open(); err != nil {
+			return err
+		}
+		e.WAL = w
+		return nil
+	}(); err != nil {
+		e.mu.Unlock()
+		return err
+	}
+
+	e.readOnly = false
+	e.mu.Unlock()
+
+	e.SetCompactionsEnabled(e.enableCompactionsOnOpen)
+	return nil
+}
+
+// sealedPath returns the path of a file of the sealed shard.
+func (e *Engine) sealedPath(f SealedFile) string {
+	if f.Cold {
+		return filepath.Join(e.coldPath, f.Name)
+	}
+	return filepath.Join(e.path, f.Name)
+}
+
+// pendingRollup returns the rollup rule waiting to be applied to the shard.
+func (e *Engine) pendingRollup() *tsdb.RollupRule {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.rollup
+}
+
+// rollupApplied records that rule has been applied to the shard and adds the fields
+// written by the rollup to the index.
+func (e *Engine) rollupApplied(rule *tsdb.RollupRule) error {
+	e.mu.Lock()
+	e.rollup, e.rolledUp = nil, rule
+	e.mu.Unlock()
+
+	return e.FileStore.WalkKeys(nil, func(key []byte, typ byte) error {
+		fieldType, err := tsmFieldTypeToInfluxQLDataType(typ)
+		if err != nil {
+			return err
+		}
+		return e.addToIndexFromKey(key, fieldType)
+	})
+}
+
 // enableLevelCompactions will request that level compactions start back up again
 //
 // 'wait' signifies that a corresponding call to disableLevelCompactions(true) was made at some
@@ -343,6 +608,10 @@ func (e *Engine) disableSnapshotCompactions() {
 // This will cancel and running compactions and snapshot any data in the cache to
 // TSM files.  This is an expensive operation.
 func (e *Engine) ScheduleFullCompaction() error {
+	if e.ReadOnly() {
+		return tsdb.ErrShardReadOnly
+	}
+
 	// Snapshot any data in the cache
 	if err := e.WriteSnapshot(); err != nil {
 		return err
@@ -363,19 +632,19 @@ func (e *Engine) ScheduleFullCompaction() error {
 func (e *Engine) Path() string { return e.path }
 
 func (e *Engine) SetFieldName(measurement []byte, name string) {
-	e.index.SetFieldName(measurement, name)
+	e.currentIndex().SetFieldName(measurement, name)
 }
 
 func (e *Engine) MeasurementExists(name []byte) (bool, error) {
-	return e.index.MeasurementExists(name)
+	return e.currentIndex().MeasurementExists(name)
 }
 
 func (e *Engine) MeasurementNamesByExpr(expr influxql.Expr) ([][]byte, error) {
-	return e.index.MeasurementNamesByExpr(expr)
+	return e.currentIndex().MeasurementNamesByExpr(expr)
 }
 
 func (e *Engine) MeasurementNamesByRegex(re *regexp.Regexp) ([][]byte, error) {
-	return e.index.MeasurementNamesByRegex(re)
+	return e.currentIndex().MeasurementNamesByRegex(re)
 }
 
 // MeasurementFields returns the measurement fields for a measurement.
@@ -384,11 +653,11 @@ func (e *Engine) MeasurementFields(measurement []byte) *tsdb.MeasurementFields {
 }
 
 func (e *Engine) HasTagKey(name, key []byte) (bool, error) {
-	return e.index.HasTagKey(name, key)
+	return e.currentIndex().HasTagKey(name, key)
 }
 
 func (e *Engine) MeasurementTagKeysByExpr(name []byte, expr influxql.Expr) (map[string]struct{}, error) {
-	return e.index.MeasurementTagKeysByExpr(name, expr)
+	return e.currentIndex().MeasurementTagKeysByExpr(name, expr)
 }
 
 // MeasurementTagKeyValuesByExpr returns a set of tag values filtered by an expression.
@@ -399,30 +668,29 @@ func (e *Engine) MeasurementTagKeysByExpr(name []byte, expr influxql.Expr) (map[
 // is indexible according to the sorted order of the tag keys, e.g., the values
 // for the earliest tag k will be available in index 0 of the returned values
 // slice.
-//
 func (e *Engine) MeasurementTagKeyValuesByExpr(auth query.Authorizer, name []byte, keys []string, expr influxql.Expr, keysSorted bool) ([][]string, error) {
-	return e.index.MeasurementTagKeyValuesByExpr(auth, name, keys, expr, keysSorted)
+	return e.currentIndex().MeasurementTagKeyValuesByExpr(auth, name, keys, expr, keysSorted)
 }
 
 func (e *Engine) ForEachMeasurementTagKey(name []byte, fn func(key []byte) error) error {
-	return e.index.ForEachMeasurementTagKey(name, fn)
+	return e.currentIndex().ForEachMeasurementTagKey(name, fn)
 }
 
 func (e *Engine) TagKeyCardinality(name, key []byte) int {
-	return e.index.TagKeyCardinality(name, key)
+	return e.currentIndex().TagKeyCardinality(name, key)
 }
 
 // SeriesN returns the unique number of series in the index.
 func (e *Engine) SeriesN() int64 {
-	return e.index.SeriesN()
+	return e.currentIndex().SeriesN()
 }
 
 func (e *Engine) SeriesSketches() (estimator.Sketch, estimator.Sketch, error) {
-	return e.index.SeriesSketches()
+	return e.currentIndex().SeriesSketches()
 }
 
 func (e *Engine) MeasurementsSketches() (estimator.Sketch, estimator.Sketch, error) {
-	return e.index.MeasurementsSketches()
+	return e.currentIndex().MeasurementsSketches()
 }
 
 // LastModified returns the time when this shard was last modified.
@@ -461,6 +729,32 @@ type EngineStatistics struct {
 	TSMFullCompactionErrors   int64 // Counter of full compactions that have failed due to error.
 	TSMFullCompactionDuration int64 // Counter of number of wall nanoseconds spent in full compactions.
 	TSMFullCompactionsQueue   int64 // Gauge of full compactions queue.
+
+	TSMColdTierMoves        int64 // Counter of moves to the cold tier that have ever run.
+	TSMColdTierMovesActive  int64 // Gauge of moves to the cold tier currently running.
+	TSMColdTierMoveErrors   int64 // Counter of moves to the cold tier that have failed due to error.
+	TSMColdTierMoveDuration int64 // Counter of number of wall nanoseconds spent moving files to the cold tier.
+	TSMColdTierMovesQueue   int64 // Gauge of moves to the cold tier queue.
+
+	TSMRollupCompactions        int64 // Counter of rollup compactions that have ever run.
+	TSMRollupCompactionsActive  int64 // Gauge of rollup compactions currently running.
+	TSMRollupCompactionErrors   int64 // Counter of rollup compactions that have failed due to error.
+	TSMRollupCompactionDuration int64 // Counter of number of wall nanoseconds spent in rollup compactions.
+
+	TSMTombstoneCompactions        int64 // Counter of tombstone compactions that have ever run.
+	TSMTombstoneCompactionsActive  int64 // Gauge of tombstone compactions currently running.
+	TSMTombstoneCompactionErrors   int64 // Counter of tombstone compactions that have failed due to error.
+	TSMTombstoneCompactionDuration int64 // Counter of number of wall nanoseconds spent in tombstone compactions.
+	TSMTombstoneCompactionsQueue   int64 // Gauge of tombstone compactions queue.
+
+	TSMLateCompactions        int64 // Counter of late compactions that have ever run.
+	TSMLateCompactionsActive  int64 // Gauge of late compactions currently running.
+	TSMLateCompactionErrors   int64 // Counter of late compactions that have failed due to error.
+	TSMLateCompactionDuration int64 // Counter of number of wall nanoseconds spent in late compactions.
+	TSMLateCompactionsQueue   int64 // Gauge of late compactions queue.
+	TSMLateValues             int64 // Counter of cache values written to late generations.
+
+	TSMCompactionsQueueDepth int64 // Gauge of planned compactions of all kinds waiting to run.
 }
 
 // Statistics returns statistics for periodic monitoring.
@@ -504,6 +798,33 @@ func (e *Engine) Statistics(tags map[string]string) []models.Statistic {
 			statTSMFullCompactionError:    atomic.LoadInt64(&e.stats.TSMFullCompactionErrors),
 			statTSMFullCompactionDuration: atomic.LoadInt64(&e.stats.TSMFullCompactionDuration),
 			statTSMFullCompactionQueue:    atomic.LoadInt64(&e.stats.TSMFullCompactionsQueue),
+
+			statTSMColdTierMoves:        atomic.LoadInt64(&e.stats.TSMColdTierMoves),
+			statTSMColdTierMovesActive:  atomic.LoadInt64(&e.stats.TSMColdTierMovesActive),
+			statTSMColdTierMoveError:    atomic.LoadInt64(&e.stats.TSMColdTierMoveErrors),
+			statTSMColdTierMoveDuration: atomic.LoadInt64(&e.stats.TSMColdTierMoveDuration),
+			statTSMColdTierMoveQueue:    atomic.LoadInt64(&e.stats.TSMColdTierMovesQueue),
+
+			statTSMRollupCompactions:        atomic.LoadInt64(&e.stats.TSMRollupCompactions),
+			statTSMRollupCompactionsActive:  atomic.LoadInt64(&e.stats.TSMRollupCompactionsActive),
+			statTSMRollupCompactionError:    atomic.LoadInt64(&e.stats.TSMRollupCompactionErrors),
+			statTSMRollupCompactionDuration: atomic.LoadInt64(&e.stats.TSMRollupCompactionDuration),
+
+			statTSMTombstoneCompactions:        atomic.LoadInt64(&e.stats.TSMTombstoneCompactions),
+			statTSMTombstoneCompactionsActive:  atomic.LoadInt64(&e.stats.TSMTombstoneCompactionsActive),
+			statTSMTombstoneCompactionError:    atomic.LoadInt64(&e.stats.TSMTombstoneCompactionErrors),
+			statTSMTombstoneCompactionDuration: atomic.LoadInt64(&e.stats.TSMTombstoneCompactionDuration),
+			statTSMTombstoneCompactionQueue:    atomic.LoadInt64(&e.stats.TSMTombstoneCompactionsQueue),
+
+			statTSMLateCompactions:        atomic.LoadInt64(&e.stats.TSMLateCompactions),
+			statTSMLateCompactionsActive:  atomic.LoadInt64(&e.stats.TSMLateCompactionsActive),
+			statTSMLateCompactionError:    atomic.LoadInt64(&e.stats.TSMLateCompactionErrors),
+			statTSMLateCompactionDuration: atomic.LoadInt64(&e.stats.TSMLateCompactionDuration),
+			statTSMLateCompactionQueue:    atomic.LoadInt64(&e.stats.TSMLateCompactionsQueue),
+			statTSMLateValues:             atomic.LoadInt64(&e.stats.TSMLateValues),
+
+			statTSMCompactionQueueDepth:    atomic.LoadInt64(&e.stats.TSMCompactionsQueueDepth),
+			statTSMCompactionThrottledTime: e.Compactor.Throttled().Nanoseconds(),
 		},
 	})
 
@@ -524,11 +845,32 @@ func (e *Engine) Open() error {
 		return err
 	}
 
+	if e.coldPath != "" {
+		if err := os.MkdirAll(e.coldPath, 0777); err != nil {
+			return err
+		}
+	}
+
 	if err := e.cleanup(); err != nil {
 		return err
 	}
 
-	if err := e.WAL.Open(); err != nil {
+	// A sealed shard has no WAL.
+	sealed, err := ReadSealManifest(e.path)
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	e.readOnly = sealed != nil
+	e.mu.Unlock()
+
+	if sealed == nil {
+		if err := e.WAL.Open(); err != nil {
+			return err
+		}
+	}
+
+	if err := resolvePendingRollup(e.path); err != nil {
 		return err
 	}
 
@@ -536,9 +878,19 @@ func (e *Engine) Open() error {
 		return err
 	}
 
-	if err := e.reloadCache(); err != nil {
+	rolledUp, err := readRollupFile(e.path)
+	if err != nil {
 		return err
 	}
+	e.mu.Lock()
+	e.rolledUp = rolledUp
+	e.mu.Unlock()
+
+	if sealed == nil {
+		if err := e.reloadCache(); err != nil {
+			return err
+		}
+	}
 
 	e.Compactor.Open()
 
@@ -558,6 +910,10 @@ func (e *Engine) Close() error {
 	defer e.mu.Unlock()
 	e.done = nil // Ensures that the channel will not be closed again.
 
+	if err := e.Cache.removeSpills(); err != nil {
+		return err
+	}
+
 	if err := e.FileStore.Close(); err != nil {
 		return err
 	}
@@ -581,7 +937,9 @@ func (e *Engine) LoadMetadataIndex(shardID uint64, index tsdb.Index) error {
 	now := time.Now()
 
 	// Save reference to index for iterator creation.
+	e.indexMu.Lock()
 	e.index = index
+	e.indexMu.Unlock()
 
 	if err := e.FileStore.WalkKeys(nil, func(key []byte, typ byte) error {
 		fieldType, err := tsmFieldTypeToInfluxQLDataType(typ)
@@ -612,14 +970,100 @@ func (e *Engine) LoadMetadataIndex(shardID uint64, index tsdb.Index) error {
 		return err
 	}
 
+	// Rebuild a tsi1 index that was opened without a valid manifest.
+	if idx, ok := index.(*tsi1.Index); ok {
+		idx.SetSeriesKeysFunc(e.ForEachSeriesKey)
+		if idx.RebuildNeeded() {
+			e.logger.Info(fmt.Sprintf("Rebuilding index for shard %d", shardID))
+			if err := idx.Regenerate(); err != nil {
+				return err
+			}
+		}
+	}
+
 	e.traceLogger.Info(fmt.Sprintf("Meta data index for shard %d loaded in %v", shardID, time.Since(now)))
 	return nil
 }
 
+// SetIndex replaces the index of the engine, such as when the index of the shard is
+// converted to another type.  Calls already using the old index complete with it.
+func (e *Engine) SetIndex(index tsdb.Index) {
+	index.SetFieldSet(e.fieldset)
+	if idx, ok := index.(*tsi1.Index); ok {
+		idx.SetSeriesKeysFunc(e.ForEachSeriesKey)
+	}
+
+	e.indexMu.Lock()
+	e.index = index
+	e.indexMu.Unlock()
+}
+
+// currentIndex returns the index of the engine, which SetIndex may replace.
+func (e *Engine) currentIndex() tsdb.Index {
+	e.indexMu.RLock()
+	defer e.indexMu.RUnlock()
+	return e.index
+}
+
+// ForEachSeriesKey calls fn with the series key of each key in the file store and cache.
+func (e *Engine) ForEachSeriesKey(fn func(key []byte) error) error {
+	// Keys of the same series are adjacent in the file store, so each series is
+	// passed once.
+	var prev []byte
+	if err := e.FileStore.WalkKeys(nil, func(key []byte, _ byte) error {
+		seriesKey, _ := SeriesAndFieldFromCompositeKey(key)
+		if bytes.Equal(seriesKey, prev) {
+			return nil
+		}
+		prev = append(prev[:0], seriesKey...)
+		return fn(seriesKey)
+	}); err != nil {
+		return err
+	}
+
+	return e.Cache.ApplyEntryFn(func(key []byte, _ *entry) error {
+		seriesKey, _ := SeriesAndFieldFromCompositeKey(key)
+		return fn(seriesKey)
+	})
+}
+
+// SeriesHasData returns true if any field of the series has values between min and max,
+// inclusive, in the cache or the file store.  Only the time ranges of TSM blocks are
+// checked, so a block with some of its values deleted is still counted.
+func (e *Engine) SeriesHasData(key []byte, min, max int64) bool {
+	name, err := models.ParseName(key)
+	if err != nil {
+		return false
+	}
+	mf := e.fieldset.Fields(string(name))
+	if mf == nil {
+		return false
+	}
+
+	for _, field := range mf.FieldKeys() {
+		fieldKey := SeriesFieldKeyBytes(string(key), field)
+		if e.FileStore.keyOverlapsTimeRange(fieldKey, min, max) {
+			return true
+		}
+		// Values that can't be read are counted, so a series isn't hidden because
+		// of a read error.
+		values, err := e.Cache.ReadValues(fieldKey)
+		if err != nil {
+			return true
+		}
+		for _, v := range values {
+			if t := v.UnixNano(); t >= min && t <= max {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // IsIdle returns true if the cache is empty, there are no running compactions and the
 // shard is fully compacted.
 func (e *Engine) IsIdle() bool {
-	cacheEmpty := e.Cache.Size() == 0
+	cacheEmpty := e.Cache.Size() == 0 && !e.Cache.hasSpills()
 
 	runningCompactions := atomic.LoadInt64(&e.stats.CacheCompactionsActive)
 	runningCompactions += atomic.LoadInt64(&e.stats.TSMCompactionsActive[0])
@@ -627,6 +1071,10 @@ func (e *Engine) IsIdle() bool {
 	runningCompactions += atomic.LoadInt64(&e.stats.TSMCompactionsActive[2])
 	runningCompactions += atomic.LoadInt64(&e.stats.TSMFullCompactionsActive)
 	runningCompactions += atomic.LoadInt64(&e.stats.TSMOptimizeCompactionsActive)
+	runningCompactions += atomic.LoadInt64(&e.stats.TSMColdTierMovesActive)
+	runningCompactions += atomic.LoadInt64(&e.stats.TSMRollupCompactionsActive)
+	runningCompactions += atomic.LoadInt64(&e.stats.TSMTombstoneCompactionsActive)
+	runningCompactions += atomic.LoadInt64(&e.stats.TSMLateCompactionsActive)
 
 	return cacheEmpty && runningCompactions == 0 && e.CompactionPlan.FullyCompacted()
 }
@@ -650,7 +1098,7 @@ func (e *Engine) Backup(w io.Writer, basePath string, since time.Time) error {
 		return err
 	}
 
-	if err := e.index.SnapshotTo(path); err != nil {
+	if err := e.currentIndex().SnapshotTo(path); err != nil {
 		return err
 	}
 
@@ -658,7 +1106,7 @@ func (e *Engine) Backup(w io.Writer, basePath string, since time.Time) error {
 	defer tw.Close()
 
 	// Remove the temporary snapshot dir
-	defer os.RemoveAll(path)
+	defer e.FileStore.RemoveSnapshot(path)
 
 	// Recursively read all files from path.
 	files, err := readDir(path, "")
@@ -723,6 +1171,9 @@ func (e *Engine) writeFileToBackup(name string, shardRelativePath, fullPath stri
 // Only files that match basePath will be copied into the directory. This obtains
 // a write lock so no operations can be performed while restoring.
 func (e *Engine) Restore(r io.Reader, basePath string) error {
+	if e.ReadOnly() {
+		return tsdb.ErrShardReadOnly
+	}
 	return e.overlay(r, basePath, false)
 }
 
@@ -730,6 +1181,9 @@ func (e *Engine) Restore(r io.Reader, basePath string) error {
 // file matching basePath as a new TSM file.  This obtains
 // a write lock so no operations can be performed while Importing.
 func (e *Engine) Import(r io.Reader, basePath string) error {
+	if e.ReadOnly() {
+		return tsdb.ErrShardReadOnly
+	}
 	return e.overlay(r, basePath, true)
 }
 
@@ -877,9 +1331,9 @@ func (e *Engine) addToIndexFromKey(key []byte, fieldType influxql.DataType) erro
 	}
 
 	// Build in-memory index, if necessary.
-	if e.index.Type() == inmem.IndexName {
+	if index := e.currentIndex(); index.Type() == inmem.IndexName {
 		tags, _ := models.ParseTags(seriesKey)
-		if err := e.index.InitializeSeries(seriesKey, name, tags); err != nil {
+		if err := index.InitializeSeries(seriesKey, name, tags); err != nil {
 			return err
 		}
 	}
@@ -887,10 +1341,9 @@ func (e *Engine) addToIndexFromKey(key []byte, fieldType influxql.DataType) erro
 	return nil
 }
 
-// WritePoints writes metadata and point data into the engine.
-// It returns an error if new points are added to an existing key.
-func (e *Engine) WritePoints(points []models.Point) error {
-	values := make(map[string][]Value, len(points))
+// appendPointValues adds the field values of points to values, keyed by the composite
+// series and field key.
+func appendPointValues(values map[string][]Value, points []models.Point) error {
 	var keyBuf []byte
 	var baseLen int
 	for _, p := range points {
@@ -940,10 +1393,24 @@ func (e *Engine) WritePoints(points []models.Point) error {
 			values[string(keyBuf)] = append(values[string(keyBuf)], v)
 		}
 	}
+	return nil
+}
+
+// WritePoints writes metadata and point data into the engine.
+// It returns an error if new points are added to an existing key.
+func (e *Engine) WritePoints(points []models.Point) error {
+	values := make(map[string][]Value, len(points))
+	if err := appendPointValues(values, points); err != nil {
+		return err
+	}
 
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
+	if e.readOnly {
+		return tsdb.ErrShardReadOnly
+	}
+
 	// first try to write to the cache
 	err := e.Cache.WriteMulti(values)
 	if err != nil {
@@ -954,13 +1421,32 @@ func (e *Engine) WritePoints(points []models.Point) error {
 	return err
 }
 
+// beginDelete registers a delete so that sealing the shard waits for it to finish.
+// It returns tsdb.ErrShardReadOnly if the shard is sealed.  Callers must call
+// e.deleteWG.Done when the delete finishes.
+func (e *Engine) beginDelete() error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.readOnly {
+		return tsdb.ErrShardReadOnly
+	}
+	e.deleteWG.Add(1)
+	return nil
+}
+
 // DeleteSeriesRange removes the values between min and max (inclusive) from all series
 func (e *Engine) DeleteSeriesRange(itr tsdb.SeriesIterator, min, max int64) error {
+	if err := e.beginDelete(); err != nil {
+		return err
+	}
+	defer e.deleteWG.Done()
+
 	var disableOnce bool
 
 	// Ensure that the index does not compact away the measurement or series we're
 	// going to delete before we're done with them.
-	if tsiIndex, ok := e.index.(*tsi1.Index); ok {
+	if tsiIndex, ok := e.currentIndex().(*tsi1.Index); ok {
 		fs := tsiIndex.RetainFileSet()
 		defer fs.Release()
 	}
@@ -1008,7 +1494,11 @@ func (e *Engine) DeleteSeriesRange(itr tsdb.SeriesIterator, min, max int64) erro
 		batch = batch[:0]
 	}
 
-	e.index.Rebuild()
+	// Rebuilding a tsi1 index regenerates all of its files, and its series are
+	// dropped individually instead.
+	if index := e.currentIndex(); index.Type() == inmem.IndexName {
+		index.Rebuild()
+	}
 	return nil
 }
 
@@ -1021,6 +1511,10 @@ func (e *Engine) deleteSeriesRange(seriesKeys [][]byte, min, max int64) error {
 		return nil
 	}
 
+	// A read snapshot must not be taken while the delete is partly applied.
+	e.readSnapshotMu.Lock()
+	defer e.readSnapshotMu.Unlock()
+
 	// Ensure keys are sorted since lower layers require them to be.
 	if !bytesutil.IsSorted(seriesKeys) {
 		bytesutil.Sort(seriesKeys)
@@ -1081,24 +1575,18 @@ func (e *Engine) deleteSeriesRange(seriesKeys [][]byte, min, max int64) error {
 
 	var abort = errors.New("iteration aborted") // sentinel error value
 
-	// find the keys in the cache and remove them
-	deleteKeys := make([][]byte, 0, len(seriesKeys))
-
-	// ApplySerialEntryFn cannot return an error in this invocation.
-	_ = e.Cache.ApplyEntryFn(func(k []byte, _ *entry) error {
-		seriesKey, _ := SeriesAndFieldFromCompositeKey([]byte(k))
+	// find the keys in the cache and the runs it spilled, and remove them
+	deleteKeys, err := e.Cache.deleteSeriesRange(func(k []byte) bool {
+		seriesKey, _ := SeriesAndFieldFromCompositeKey(k)
 
 		// Cache does not walk keys in sorted order, so search the sorted
 		// series we need to delete to see if any of the cache keys match.
 		i := bytesutil.SearchBytes(seriesKeys, seriesKey)
-		if i < len(seriesKeys) && bytes.Equal(seriesKey, seriesKeys[i]) {
-			// k is the measurement + tags + sep + field
-			deleteKeys = append(deleteKeys, k)
-		}
-		return nil
-	})
-
-	e.Cache.DeleteRange(deleteKeys, min, max)
+		return i < len(seriesKeys) && bytes.Equal(seriesKey, seriesKeys[i])
+	}, min, max)
+	if err != nil {
+		return err
+	}
 
 	// delete from the WAL
 	if _, err := e.WAL.DeleteRange(deleteKeys, min, max); err != nil {
@@ -1169,7 +1657,11 @@ func (e *Engine) deleteSeriesRange(seriesKeys [][]byte, min, max int64) error {
 			// If there are multiple fields, they will have the same prefix.  If any field
 			// has values, then we can't delete it from the index.
 			for i < len(deleteKeys) && bytes.HasPrefix(deleteKeys[i], k) {
-				if e.Cache.Values(deleteKeys[i]).Len() > 0 {
+				values, err := e.Cache.ReadValues(deleteKeys[i])
+				if err != nil {
+					return err
+				}
+				if values.Len() > 0 {
 					hasCacheValues = true
 					break
 				}
@@ -1181,7 +1673,7 @@ func (e *Engine) deleteSeriesRange(seriesKeys [][]byte, min, max int64) error {
 				continue
 			}
 
-			if err := e.index.UnassignShard(string(k), e.id, ts); err != nil {
+			if err := e.currentIndex().UnassignShard(string(k), e.id, ts); err != nil {
 				return err
 			}
 		}
@@ -1192,6 +1684,11 @@ func (e *Engine) deleteSeriesRange(seriesKeys [][]byte, min, max int64) error {
 
 // DeleteMeasurement deletes a measurement and all related series.
 func (e *Engine) DeleteMeasurement(name []byte) error {
+	if err := e.beginDelete(); err != nil {
+		return err
+	}
+	defer e.deleteWG.Done()
+
 	// Delete the bulk of data outside of the fields lock.
 	if err := e.deleteMeasurement(name); err != nil {
 		return err
@@ -1215,6 +1712,11 @@ func (e *Engine) DeleteMeasurement(name []byte) error {
 		}); err != nil {
 			return err
 		}
+		for _, k := range e.Cache.spilledKeys() {
+			if bytes.HasPrefix(k, encodedName) {
+				return abortErr
+			}
+		}
 
 		// Check the filestore.
 		return e.FileStore.WalkKeys(name, func(k []byte, typ byte) error {
@@ -1235,7 +1737,7 @@ func (e *Engine) DeleteMeasurement(name []byte) error {
 // DeleteMeasurement deletes a measurement and all related series.
 func (e *Engine) deleteMeasurement(name []byte) error {
 	// Attempt to find the series keys.
-	itr, err := e.index.MeasurementSeriesKeysByExprIterator(name, nil)
+	itr, err := e.currentIndex().MeasurementSeriesKeysByExprIterator(name, nil)
 	if err != nil {
 		return err
 	} else if itr != nil {
@@ -1246,29 +1748,148 @@ func (e *Engine) deleteMeasurement(name []byte) error {
 
 // ForEachMeasurementName iterates over each measurement name in the engine.
 func (e *Engine) ForEachMeasurementName(fn func(name []byte) error) error {
-	return e.index.ForEachMeasurementName(fn)
+	return e.currentIndex().ForEachMeasurementName(fn)
 }
 
 func (e *Engine) MeasurementSeriesKeysByExprIterator(name []byte, expr influxql.Expr) (tsdb.SeriesIterator, error) {
-	return e.index.MeasurementSeriesKeysByExprIterator(name, expr)
+	return e.currentIndex().MeasurementSeriesKeysByExprIterator(name, expr)
+}
+
+// MeasurementSeriesKeysByExpr returns a list of series keys matching expr.
+func (e *Engine) MeasurementSeriesKeysByExpr(name []byte, expr influxql.Expr) ([][]byte, error) {
+	return e.currentIndex().MeasurementSeriesKeysByExpr(name, expr)
+}
+
+func (e *Engine) CreateSeriesListIfNotExists(keys, names [][]byte, tagsSlice []models.Tags) error {
+	return e.currentIndex().CreateSeriesListIfNotExists(keys, names, tagsSlice)
+}
+
+func (e *Engine) CreateSeriesIfNotExists(key, name []byte, tags models.Tags) error {
+	return e.currentIndex().CreateSeriesIfNotExists(key, name, tags)
 }
 
-// MeasurementSeriesKeysByExpr returns a list of series keys matching expr.
-func (e *Engine) MeasurementSeriesKeysByExpr(name []byte, expr influxql.Expr) ([][]byte, error) {
-	return e.index.MeasurementSeriesKeysByExpr(name, expr)
+// WriteTo streams an image of the shard to w as a tar archive of its TSM and tombstone
+// files, plus a TSM file holding the contents of the cache.  Writes are not paused and
+// the cache is not snapshotted.  The image contains every write acknowledged before
+// WriteTo was called, and may contain some that happen while it runs.  The archive can
+// be loaded into another shard with Restore or Import using an empty base path.
+func (e *Engine) WriteTo(w io.Writer) (n int64, err error) {
+	cw := &countingWriter{w: w}
+
+	// The cache is copied before the files are linked.  Values snapshotted out of the
+	// cache in between are then in both the copy and a new TSM file, rather than neither.
+	cachePath, err := e.writeCacheImage()
+	if err != nil {
+		return 0, err
+	}
+	if cachePath != "" {
+		defer os.Remove(cachePath)
+	}
+
+	e.mu.RLock()
+	path, err := e.FileStore.CreateSnapshot()
+	e.mu.RUnlock()
+	if err != nil {
+		return 0, err
+	}
+	defer e.FileStore.RemoveSnapshot(path)
+
+	files, err := readDir(path, "")
+	if err != nil {
+		return 0, err
+	}
+
+	tw := tar.NewWriter(cw)
+	for _, f := range files {
+		if err := e.writeFileToBackup(f, "", filepath.Join(path, f), tw); err != nil {
+			return cw.n, err
+		}
+	}
+
+	// The cache image holds the newest values, so its generation follows the files.
+	if cachePath != "" {
+		name := strings.TrimSuffix(filepath.Base(cachePath), "."+CompactionTempExtension)
+		if err := e.writeFileToBackup(name, "", cachePath, tw); err != nil {
+			return cw.n, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return cw.n, err
+	}
+	return cw.n, nil
+}
+
+// writeCacheImage writes the values in the cache, including any snapshot being
+// written, to a new temporary TSM file in the shard and returns its path.  It returns
+// an empty path if the cache is empty.
+func (e *Engine) writeCacheImage() (string, error) {
+	keys := e.Cache.keysWithSnapshot()
+	if len(keys) == 0 {
+		return "", nil
+	}
+
+	path := filepath.Join(e.path, fmt.Sprintf("%09d-%09d.%s.%s", e.FileStore.NextGeneration(), 1, TSMFileExtension, CompactionTempExtension))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_EXCL, 0666)
+	if err != nil {
+		return "", err
+	}
+
+	w, err := NewTSMWriter(f)
+	if err != nil {
+		f.Close()
+		os.Remove(path)
+		return "", err
+	}
+
+	if err := func() error {
+		for _, key := range keys {
+			values, err := e.Cache.ReadValues(key)
+			if err != nil {
+				return err
+			}
+			for len(values) > 0 {
+				n := tsdb.DefaultMaxPointsPerBlock
+				if n > len(values) {
+					n = len(values)
+				}
+				if err := w.Write(key, values[:n]); err != nil {
+					return err
+				}
+				values = values[n:]
+			}
+		}
+		return w.WriteIndex()
+	}(); err == ErrNoValues {
+		// Every key was deleted while the cache was being copied.
+		w.Remove()
+		os.Remove(path)
+		return "", nil
+	} else if err != nil {
+		w.Remove()
+		os.Remove(path)
+		return "", err
+	}
+
+	if err := w.Close(); err != nil {
+		os.Remove(path)
+		return "", err
+	}
+	return path, nil
 }
 
-func (e *Engine) CreateSeriesListIfNotExists(keys, names [][]byte, tagsSlice []models.Tags) error {
-	return e.index.CreateSeriesListIfNotExists(keys, names, tagsSlice)
+// countingWriter counts the bytes written to the underlying writer.
+type countingWriter struct {
+	w io.Writer
+	n int64
 }
 
-func (e *Engine) CreateSeriesIfNotExists(key, name []byte, tags models.Tags) error {
-	return e.index.CreateSeriesIfNotExists(key, name, tags)
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
 }
 
-// WriteTo is not implemented.
-func (e *Engine) WriteTo(w io.Writer) (n int64, err error) { panic("not implemented") }
-
 // WriteSnapshot will snapshot the cache and write a new TSM file with its contents, releasing the snapshot when done.
 func (e *Engine) WriteSnapshot() error {
 	// Lock and grab the cache snapshot along with all the closed WAL
@@ -1311,7 +1932,7 @@ func (e *Engine) WriteSnapshot() error {
 		return err
 	}
 
-	if snapshot.Size() == 0 {
+	if snapshot.Size() == 0 && len(snapshot.spills) == 0 {
 		e.Cache.ClearSnapshot(true)
 		return nil
 	}
@@ -1320,6 +1941,10 @@ func (e *Engine) WriteSnapshot() error {
 	// it before writing the snapshot.  This can be very expensive so it's done while we are not
 	// holding the engine write lock.
 	dedup := time.Now()
+	if err := e.Cache.mergeSealedSnapshot(); err != nil {
+		e.Cache.ClearSnapshot(false)
+		return err
+	}
 	snapshot.Deduplicate()
 	e.traceLogger.Info(fmt.Sprintf("Snapshot for path %s deduplicated in %v", e.path, time.Since(dedup)))
 
@@ -1329,8 +1954,11 @@ func (e *Engine) WriteSnapshot() error {
 // CreateSnapshot will create a temp directory that holds
 // temporary hardlinks to the underylyng shard files.
 func (e *Engine) CreateSnapshot() (string, error) {
-	if err := e.WriteSnapshot(); err != nil {
-		return "", err
+	// The cache of a sealed shard is always empty and it has no WAL to snapshot.
+	if !e.ReadOnly() {
+		if err := e.WriteSnapshot(); err != nil {
+			return "", err
+		}
 	}
 
 	e.mu.RLock()
@@ -1339,6 +1967,12 @@ func (e *Engine) CreateSnapshot() (string, error) {
 	return e.FileStore.CreateSnapshot()
 }
 
+// RemoveSnapshot removes a snapshot created by CreateSnapshot, including the links
+// to its files in the cold tier.
+func (e *Engine) RemoveSnapshot(path string) error {
+	return e.FileStore.RemoveSnapshot(path)
+}
+
 // writeSnapshotAndCommit will write the passed cache to a new TSM file and remove the closed WAL segments.
 func (e *Engine) writeSnapshotAndCommit(closedFiles []string, snapshot *Cache) (err error) {
 	defer func() {
@@ -1347,13 +1981,35 @@ func (e *Engine) writeSnapshotAndCommit(closedFiles []string, snapshot *Cache) (
 		}
 	}()
 
+	// Late values are written to their own generations after the rest of the snapshot,
+	// so they can be merged into the files they overlap by a late compaction.  Spilled
+	// snapshots are merged with their runs and written as a whole.
+	onTime, late := snapshot, (*Cache)(nil)
+	if e.LateWritesEnabled && len(snapshot.spills) == 0 {
+		var n int
+		if onTime, late, n, err = snapshot.splitLate(e.FileStore.keyMaxTime); err != nil {
+			return err
+		}
+		atomic.AddInt64(&e.stats.TSMLateValues, int64(n))
+	}
+
 	// write the new snapshot files
-	newFiles, err := e.Compactor.WriteSnapshot(snapshot)
+	newFiles, err := e.Compactor.WriteSnapshot(onTime)
 	if err != nil {
 		e.logger.Info(fmt.Sprintf("error writing snapshot from compactor: %v", err))
 		return err
 	}
 
+	if late != nil {
+		lateFiles, err := e.Compactor.WriteSnapshot(late)
+		if err != nil {
+			e.logger.Info(fmt.Sprintf("error writing late values from compactor: %v", err))
+			e.Compactor.removeTmpFiles(newFiles)
+			return err
+		}
+		newFiles = append(newFiles, lateFiles...)
+	}
+
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
@@ -1403,6 +2059,11 @@ func (e *Engine) compactCache(quit <-chan struct{}) {
 // ShouldCompactCache returns true if the Cache is over its flush threshold
 // or if the passed in lastWriteTime is older than the write cold threshold.
 func (e *Engine) ShouldCompactCache(lastWriteTime time.Time) bool {
+	// Spilled values are only written to TSM files by a snapshot.
+	if e.Cache.hasSpills() {
+		return true
+	}
+
 	sz := e.Cache.Size()
 
 	if sz == 0 {
@@ -1424,6 +2085,11 @@ func (e *Engine) compact(quit <-chan struct{}) {
 
 		case <-t.C:
 
+			// Late generations are planned first so level compactions cannot merge them
+			// with newer generations before they are merged into the files they overlap.
+			lateGroups := e.CompactionPlan.PlanLate()
+			atomic.StoreInt64(&e.stats.TSMLateCompactionsQueue, int64(len(lateGroups)))
+
 			// Find our compaction plans
 			level1Groups := e.CompactionPlan.PlanLevel(1)
 			level2Groups := e.CompactionPlan.PlanLevel(2)
@@ -1437,10 +2103,26 @@ func (e *Engine) compact(quit <-chan struct{}) {
 				atomic.StoreInt64(&e.stats.TSMOptimizeCompactionsQueue, int64(len(level4Groups)))
 			}
 
+			// A rollup needs every file in the shard, so plan it before the cold tier
+			// can claim any of them.
+			var rollupGroups []CompactionGroup
+			if e.pendingRollup() != nil {
+				rollupGroups = e.CompactionPlan.PlanRollup()
+			}
+
+			tombstoneGroups := e.CompactionPlan.PlanTombstones()
+			atomic.StoreInt64(&e.stats.TSMTombstoneCompactionsQueue, int64(len(tombstoneGroups)))
+
+			coldGroups := e.CompactionPlan.PlanColdTier()
+			atomic.StoreInt64(&e.stats.TSMColdTierMovesQueue, int64(len(coldGroups)))
+
 			// Update the level plan queue stats
 			atomic.StoreInt64(&e.stats.TSMCompactionsQueue[0], int64(len(level1Groups)))
 			atomic.StoreInt64(&e.stats.TSMCompactionsQueue[1], int64(len(level2Groups)))
 			atomic.StoreInt64(&e.stats.TSMCompactionsQueue[2], int64(len(level3Groups)))
+			atomic.StoreInt64(&e.stats.TSMCompactionsQueueDepth, int64(len(level1Groups)+len(level2Groups)+
+				len(level3Groups)+len(level4Groups)+len(rollupGroups)+len(tombstoneGroups)+len(coldGroups)+
+				len(lateGroups)))
 
 			run1 := atomic.LoadInt64(&e.stats.TSMCompactionsActive[0])
 			run2 := atomic.LoadInt64(&e.stats.TSMCompactionsActive[1])
@@ -1460,8 +2142,11 @@ func (e *Engine) compact(quit <-chan struct{}) {
 			e.scheduler.setDepth(3, len(level3Groups))
 			e.scheduler.setDepth(4, len(level4Groups))
 
-			// Find the next compaction that can run and try to kick it off
-			if level, runnable := e.scheduler.next(); runnable {
+			// Merging late generations takes priority over every other compaction, then
+			// find the next compaction that can run and try to kick it off
+			if len(lateGroups) > 0 && e.compactLate(lateGroups[0]) {
+				lateGroups = lateGroups[1:]
+			} else if level, runnable := e.scheduler.next(); runnable {
 				run1 := atomic.LoadInt64(&e.stats.TSMCompactionsActive[0])
 				run2 := atomic.LoadInt64(&e.stats.TSMCompactionsActive[1])
 				run3 := atomic.LoadInt64(&e.stats.TSMCompactionsActive[2])
@@ -1492,6 +2177,21 @@ func (e *Engine) compact(quit <-chan struct{}) {
 						level4Groups = level4Groups[1:]
 					}
 				}
+			} else if len(tombstoneGroups) > 0 {
+				// Tombstone compactions only use spare compaction capacity.
+				if e.compactTombstones(tombstoneGroups[0]) {
+					tombstoneGroups = tombstoneGroups[1:]
+				}
+			} else if len(rollupGroups) > 0 {
+				// Rollups only use spare compaction capacity.
+				if e.compactRollup(rollupGroups[0]) {
+					rollupGroups = rollupGroups[1:]
+				}
+			} else if len(coldGroups) > 0 {
+				// Moving files to the cold tier only uses spare compaction capacity.
+				if e.moveToColdTier(coldGroups[0]) {
+					coldGroups = coldGroups[1:]
+				}
 			}
 
 			// Release all the plans we didn't start.
@@ -1499,6 +2199,10 @@ func (e *Engine) compact(quit <-chan struct{}) {
 			e.CompactionPlan.Release(level2Groups)
 			e.CompactionPlan.Release(level3Groups)
 			e.CompactionPlan.Release(level4Groups)
+			e.CompactionPlan.Release(rollupGroups)
+			e.CompactionPlan.Release(tombstoneGroups)
+			e.CompactionPlan.Release(lateGroups)
+			e.CompactionPlan.Release(coldGroups)
 		}
 	}
 }
@@ -1582,11 +2286,103 @@ func (e *Engine) compactFull(grp CompactionGroup) bool {
 	return false
 }
 
+// moveToColdTier kicks off moving a generation to the cold tier using the lo priority
+// policy.  It returns true if the move was started.
+func (e *Engine) moveToColdTier(grp CompactionGroup) bool {
+	s := e.coldTierStrategy(grp)
+
+	if e.compactionLimiter.TryTake() {
+		atomic.AddInt64(&e.stats.TSMColdTierMovesActive, 1)
+		e.wg.Add(1)
+		go func() {
+			defer e.wg.Done()
+			defer atomic.AddInt64(&e.stats.TSMColdTierMovesActive, -1)
+			defer e.compactionLimiter.Release()
+			s.Apply()
+			// Release the files in the compaction plan
+			e.CompactionPlan.Release([]CompactionGroup{s.group})
+		}()
+		return true
+	}
+	return false
+}
+
+// compactLate kicks off a late compaction merging a late generation into the files it
+// overlaps. It returns true if the compaction was started.
+func (e *Engine) compactLate(grp CompactionGroup) bool {
+	s := e.lateStrategy(grp)
+
+	if e.compactionLimiter.TryTake() {
+		atomic.AddInt64(&e.stats.TSMLateCompactionsActive, 1)
+		e.wg.Add(1)
+		go func() {
+			defer e.wg.Done()
+			defer atomic.AddInt64(&e.stats.TSMLateCompactionsActive, -1)
+			defer e.compactionLimiter.Release()
+			s.Apply()
+			// Release the files in the compaction plan
+			e.CompactionPlan.Release([]CompactionGroup{s.group})
+		}()
+		return true
+	}
+	return false
+}
+
+// compactTombstones kicks off a tombstone compaction of a generation using the lo
+// priority policy. It returns true if the compaction was started.
+func (e *Engine) compactTombstones(grp CompactionGroup) bool {
+	s := e.tombstoneStrategy(grp)
+
+	if e.compactionLimiter.TryTake() {
+		atomic.AddInt64(&e.stats.TSMTombstoneCompactionsActive, 1)
+		e.wg.Add(1)
+		go func() {
+			defer e.wg.Done()
+			defer atomic.AddInt64(&e.stats.TSMTombstoneCompactionsActive, -1)
+			defer e.compactionLimiter.Release()
+			s.Apply()
+			// Release the files in the compaction plan
+			e.CompactionPlan.Release([]CompactionGroup{s.group})
+		}()
+		return true
+	}
+	return false
+}
+
+// compactRollup kicks off a rollup compaction of every file in the shard using the lo
+// priority policy. It returns true if the compaction was started.
+func (e *Engine) compactRollup(grp CompactionGroup) bool {
+	rule := e.pendingRollup()
+	if rule == nil {
+		return false
+	}
+	s := e.rollupStrategy(grp, rule)
+
+	if e.compactionLimiter.TryTake() {
+		atomic.AddInt64(&e.stats.TSMRollupCompactionsActive, 1)
+		e.wg.Add(1)
+		go func() {
+			defer e.wg.Done()
+			defer atomic.AddInt64(&e.stats.TSMRollupCompactionsActive, -1)
+			defer e.compactionLimiter.Release()
+			s.Apply()
+			// Release the files in the compaction plan
+			e.CompactionPlan.Release([]CompactionGroup{s.group})
+		}()
+		return true
+	}
+	return false
+}
+
 // compactionStrategy holds the details of what to do in a compaction.
 type compactionStrategy struct {
 	group CompactionGroup
 
 	fast        bool
+	cold        bool             // move the group to the cold tier rather than compacting it
+	rollup      *tsdb.RollupRule // roll up the values of the group while compacting it
+	tombstones  bool             // only remove the values deleted by tombstones from the group
+	late        bool             // merge a late generation into the generations it overlaps
 	description string
 	level       int
 
@@ -1631,7 +2427,15 @@ func (s *compactionStrategy) compactGroup() {
 		files []string
 	)
 
-	if s.fast {
+	if s.cold {
+		files, err = s.compactor.MoveToColdTier(group)
+	} else if s.rollup != nil {
+		files, err = s.compactor.CompactRollup(group, s.rollup)
+	} else if s.tombstones {
+		files, err = s.compactor.CompactTombstones(group)
+	} else if s.late {
+		files, err = s.compactor.CompactLate(group)
+	} else if s.fast {
 		files, err = s.compactor.CompactFast(group)
 	} else {
 		files, err = s.compactor.CompactFull(group)
@@ -1654,6 +2458,19 @@ func (s *compactionStrategy) compactGroup() {
 		return
 	}
 
+	// Record the files of the rollup while they are replaced, so that a crash part way
+	// through is completed or undone on open instead of leaving raw data marked as
+	// rolled up, or aggregates that would be rolled up a second time.
+	if s.rollup != nil {
+		if err := writePendingRollup(s.engine.path, s.rollup, group, files); err != nil {
+			s.logger.Info(fmt.Sprintf("error recording rollup: %v", err))
+			s.compactor.removeTmpFiles(files)
+			atomic.AddInt64(s.errorStat, 1)
+			time.Sleep(time.Second)
+			return
+		}
+	}
+
 	if err := s.fileStore.ReplaceWithCallback(group, files, nil); err != nil {
 		s.logger.Info(fmt.Sprintf("error replacing new TSM files: %v", err))
 		atomic.AddInt64(s.errorStat, 1)
@@ -1661,6 +2478,21 @@ func (s *compactionStrategy) compactGroup() {
 		return
 	}
 
+	if s.rollup != nil {
+		// The pending rollup is resolved on open if it can't be recorded now.
+		if err := writeRollupFile(s.engine.path, s.rollup); err != nil {
+			s.logger.Info(fmt.Sprintf("error recording rollup: %v", err))
+			atomic.AddInt64(s.errorStat, 1)
+		} else if err := os.Remove(filepath.Join(s.engine.path, rollupPendingFileName)); err != nil {
+			s.logger.Info(fmt.Sprintf("error removing pending rollup: %v", err))
+		}
+
+		if err := s.engine.rollupApplied(s.rollup); err != nil {
+			s.logger.Info(fmt.Sprintf("error indexing rollup fields: %v", err))
+			atomic.AddInt64(s.errorStat, 1)
+		}
+	}
+
 	for i, f := range files {
 		s.logger.Info(fmt.Sprintf("compacted %s into %s (#%d)", s.description, f, i))
 	}
@@ -1718,6 +2550,86 @@ func (e *Engine) fullCompactionStrategy(group CompactionGroup, optimize bool) *c
 	return s
 }
 
+// coldTierStrategy returns a compactionStrategy that moves a generation of TSM files
+// to the cold tier.
+func (e *Engine) coldTierStrategy(group CompactionGroup) *compactionStrategy {
+	return &compactionStrategy{
+		group:     group,
+		logger:    e.logger,
+		fileStore: e.FileStore,
+		compactor: e.Compactor,
+		cold:      true,
+		engine:    e,
+		level:     4,
+
+		description:  "cold tier",
+		activeStat:   &e.stats.TSMColdTierMovesActive,
+		successStat:  &e.stats.TSMColdTierMoves,
+		errorStat:    &e.stats.TSMColdTierMoveErrors,
+		durationStat: &e.stats.TSMColdTierMoveDuration,
+	}
+}
+
+// rollupStrategy returns a compactionStrategy that rolls up every file in the shard
+// using rule.
+func (e *Engine) rollupStrategy(group CompactionGroup, rule *tsdb.RollupRule) *compactionStrategy {
+	return &compactionStrategy{
+		group:     group,
+		logger:    e.logger,
+		fileStore: e.FileStore,
+		compactor: e.Compactor,
+		rollup:    rule,
+		engine:    e,
+		level:     4,
+
+		description:  "rollup",
+		activeStat:   &e.stats.TSMRollupCompactionsActive,
+		successStat:  &e.stats.TSMRollupCompactions,
+		errorStat:    &e.stats.TSMRollupCompactionErrors,
+		durationStat: &e.stats.TSMRollupCompactionDuration,
+	}
+}
+
+// tombstoneStrategy returns a compactionStrategy that removes the values deleted by
+// tombstones from a generation of TSM files.
+func (e *Engine) tombstoneStrategy(group CompactionGroup) *compactionStrategy {
+	return &compactionStrategy{
+		group:      group,
+		logger:     e.logger,
+		fileStore:  e.FileStore,
+		compactor:  e.Compactor,
+		tombstones: true,
+		engine:     e,
+		level:      4,
+
+		description:  "tombstone",
+		activeStat:   &e.stats.TSMTombstoneCompactionsActive,
+		successStat:  &e.stats.TSMTombstoneCompactions,
+		errorStat:    &e.stats.TSMTombstoneCompactionErrors,
+		durationStat: &e.stats.TSMTombstoneCompactionDuration,
+	}
+}
+
+// lateStrategy returns a compactionStrategy that merges a late generation of TSM files
+// into the generations it overlaps.
+func (e *Engine) lateStrategy(group CompactionGroup) *compactionStrategy {
+	return &compactionStrategy{
+		group:     group,
+		logger:    e.logger,
+		fileStore: e.FileStore,
+		compactor: e.Compactor,
+		late:      true,
+		engine:    e,
+		level:     4,
+
+		description:  "late",
+		activeStat:   &e.stats.TSMLateCompactionsActive,
+		successStat:  &e.stats.TSMLateCompactions,
+		errorStat:    &e.stats.TSMLateCompactionErrors,
+		durationStat: &e.stats.TSMLateCompactionDuration,
+	}
+}
+
 // reloadCache reads the WAL segment files and loads them into the cache.
 func (e *Engine) reloadCache() error {
 	now := time.Now()
@@ -1726,13 +2638,15 @@ func (e *Engine) reloadCache() error {
 		return err
 	}
 
-	limit := e.Cache.MaxSize()
-	defer func() {
-		e.Cache.SetMaxSize(limit)
-	}()
-
-	// Disable the max size during loading
-	e.Cache.SetMaxSize(0)
+	// Disable the max size during loading, unless the cache can spill to disk to
+	// stay within it.
+	if e.Cache.spillDir == "" {
+		limit := e.Cache.MaxSize()
+		defer func() {
+			e.Cache.SetMaxSize(limit)
+		}()
+		e.Cache.SetMaxSize(0)
+	}
 
 	loader := NewCacheLoader(files)
 	loader.WithLogger(e.logger)
@@ -1747,7 +2661,24 @@ func (e *Engine) reloadCache() error {
 // cleanup removes all temp files and dirs that exist on disk.  This is should only be run at startup to avoid
 // removing tmp files that are still in use.
 func (e *Engine) cleanup() error {
-	allfiles, err := ioutil.ReadDir(e.path)
+	// Runs spilled by the cache are loaded back into it from the WAL.
+	if err := os.RemoveAll(filepath.Join(e.path, cacheSpillDir)); err != nil {
+		return err
+	}
+
+	if err := e.cleanupDir(e.path); err != nil {
+		return err
+	}
+
+	if e.coldPath != "" {
+		return e.cleanupDir(e.coldPath)
+	}
+	return nil
+}
+
+// cleanupDir removes the temp files and dirs within a single storage tier.
+func (e *Engine) cleanupDir(path string) error {
+	allfiles, err := ioutil.ReadDir(path)
 	if os.IsNotExist(err) {
 		return nil
 	} else if err != nil {
@@ -1757,17 +2688,17 @@ func (e *Engine) cleanup() error {
 	for _, f := range allfiles {
 		// Check to see if there are any `.tmp` directories that were left over from failed shard snapshots
 		if f.IsDir() && strings.HasSuffix(f.Name(), ".tmp") {
-			if err := os.RemoveAll(filepath.Join(e.path, f.Name())); err != nil {
+			if err := os.RemoveAll(filepath.Join(path, f.Name())); err != nil {
 				return fmt.Errorf("error removing tmp snapshot directory %q: %s", f.Name(), err)
 			}
 		}
 	}
 
-	return e.cleanupTempTSMFiles()
+	return e.cleanupTempTSMFiles(path)
 }
 
-func (e *Engine) cleanupTempTSMFiles() error {
-	files, err := filepath.Glob(filepath.Join(e.path, fmt.Sprintf("*.%s", CompactionTempExtension)))
+func (e *Engine) cleanupTempTSMFiles(path string) error {
+	files, err := filepath.Glob(filepath.Join(path, fmt.Sprintf("*.%s", CompactionTempExtension)))
 	if err != nil {
 		return fmt.Errorf("error getting compaction temp files: %s", err.Error())
 	}
@@ -1782,6 +2713,9 @@ func (e *Engine) cleanupTempTSMFiles() error {
 
 // KeyCursor returns a KeyCursor for the given key starting at time t.
 func (e *Engine) KeyCursor(ctx context.Context, key []byte, t int64, ascending bool) *KeyCursor {
+	if s := e.readSnapshot(ctx); s != nil {
+		return newKeyCursor(ctx, s.files, e.FileStore.blocks, key, t, ascending)
+	}
 	return e.FileStore.KeyCursor(ctx, key, t, ascending)
 }
 
@@ -1840,14 +2774,15 @@ func (e *Engine) CreateIterator(ctx context.Context, measurement string, opt que
 func (e *Engine) createCallIterator(ctx context.Context, measurement string, call *influxql.Call, opt query.IteratorOptions) ([]query.Iterator, error) {
 	ref, _ := call.Args[0].(*influxql.VarRef)
 
-	if exists, err := e.index.MeasurementExists([]byte(measurement)); err != nil {
+	index := e.currentIndex()
+	if exists, err := index.MeasurementExists([]byte(measurement)); err != nil {
 		return nil, err
 	} else if !exists {
 		return nil, nil
 	}
 
 	// Determine tagsets for this measurement based on dimensions and filters.
-	tagSets, err := e.index.TagSets([]byte(measurement), opt)
+	tagSets, err := index.TagSets([]byte(measurement), opt)
 	if err != nil {
 		return nil, err
 	}
@@ -1886,6 +2821,12 @@ func (e *Engine) createCallIterator(ctx context.Context, measurement string, cal
 					input = query.NewInterruptIterator(input, opt.InterruptCh)
 				}
 
+				// Series answered from block statistics are already reduced.
+				if _, ok := inputs[i].(statsIterator); ok {
+					inputs[i] = input
+					continue
+				}
+
 				itr, err := query.NewCallIterator(input, opt)
 				if err != nil {
 					query.Iterators(inputs).Close()
@@ -1910,14 +2851,15 @@ func (e *Engine) createCallIterator(ctx context.Context, measurement string, cal
 func (e *Engine) createVarRefIterator(ctx context.Context, measurement string, opt query.IteratorOptions) ([]query.Iterator, error) {
 	ref, _ := opt.Expr.(*influxql.VarRef)
 
-	if exists, err := e.index.MeasurementExists([]byte(measurement)); err != nil {
+	index := e.currentIndex()
+	if exists, err := index.MeasurementExists([]byte(measurement)); err != nil {
 		return nil, err
 	} else if !exists {
 		return nil, nil
 	}
 
 	// Determine tagsets for this measurement based on dimensions and filters.
-	tagSets, err := e.index.TagSets([]byte(measurement), opt)
+	tagSets, err := index.TagSets([]byte(measurement), opt)
 	if err != nil {
 		return nil, err
 	}
@@ -2102,10 +3044,34 @@ func (e *Engine) createVarRefSeriesIterator(ctx context.Context, ref *influxql.V
 		condCounter = col.GetCounter(numberOfCondCursorsCounter)
 	}
 
-	// Build main cursor.
+	// Count the blocks and cache values read by the cursors of the series into the
+	// stats of its iterator.
+	reads := &query.IteratorStats{}
+	ctx = newContextWithCursorStats(ctx, reads)
+
+	// Answer calls over a numeric field from block statistics when possible.
+	if ref != nil && filter == nil && len(opt.Aux) == 0 {
+		if itr, err := e.createStatsSeriesIterator(ctx, ref, name, seriesKey, tags, opt); err != nil {
+			return nil, err
+		} else if itr != nil {
+			if curCounter != nil {
+				curCounter.Add(1)
+			}
+			return itr, nil
+		}
+	}
+
+	// Build main cursor.  String values that can't satisfy the condition are skipped
+	// as they're read rather than decoded and evaluated one by one.
 	var cur cursor
 	if ref != nil {
-		cur = e.buildCursor(ctx, name, seriesKey, tfs, ref, opt)
+		if c, err := e.buildStringMatchCursor(ctx, name, seriesKey, ref, filter, opt); err != nil {
+			return nil, err
+		} else if c != nil {
+			cur = c
+		} else if cur, err = e.buildCursor(ctx, name, seriesKey, tfs, ref, opt); err != nil {
+			return nil, err
+		}
 		// If the field doesn't exist then don't build an iterator.
 		if cur == nil {
 			return nil, nil
@@ -2123,12 +3089,15 @@ func (e *Engine) createVarRefSeriesIterator(ctx context.Context, ref *influxql.V
 		for i, ref := range opt.Aux {
 			// Create cursor from field if a tag wasn't requested.
 			if ref.Type != influxql.Tag {
-				cur := e.buildCursor(ctx, name, seriesKey, tfs, &ref, opt)
-				if cur != nil {
+				c, err := e.buildCursor(ctx, name, seriesKey, tfs, &ref, opt)
+				if err != nil {
+					closeCursors(cur, aux, nil)
+					return nil, err
+				} else if c != nil {
 					if auxCounter != nil {
 						auxCounter.Add(1)
 					}
-					aux[i] = newBufCursor(cur, opt.Ascending)
+					aux[i] = newBufCursor(c, opt.Ascending)
 					continue
 				}
 
@@ -2190,12 +3159,15 @@ func (e *Engine) createVarRefSeriesIterator(ctx context.Context, ref *influxql.V
 		for i, ref := range conditionFields {
 			// Create cursor from field if a tag wasn't requested.
 			if ref.Type != influxql.Tag {
-				cur := e.buildCursor(ctx, name, seriesKey, tfs, &ref, opt)
-				if cur != nil {
+				c, err := e.buildCursor(ctx, name, seriesKey, tfs, &ref, opt)
+				if err != nil {
+					closeCursors(cur, aux, conds)
+					return nil, err
+				} else if c != nil {
 					if condCounter != nil {
 						condCounter.Add(1)
 					}
-					conds[i] = newBufCursor(cur, opt.Ascending)
+					conds[i] = newBufCursor(c, opt.Ascending)
 					continue
 				}
 
@@ -2239,7 +3211,7 @@ func (e *Engine) createVarRefSeriesIterator(ctx context.Context, ref *influxql.V
 		if opt.StripName {
 			name = ""
 		}
-		return newFloatIterator(name, tags, itrOpt, nil, aux, conds, condNames), nil
+		return newFloatIterator(name, tags, itrOpt, nil, aux, conds, condNames, reads), nil
 	}
 
 	// Remove name if requested.
@@ -2249,49 +3221,119 @@ func (e *Engine) createVarRefSeriesIterator(ctx context.Context, ref *influxql.V
 
 	switch cur := cur.(type) {
 	case floatCursor:
-		return newFloatIterator(name, tags, itrOpt, cur, aux, conds, condNames), nil
+		return newFloatIterator(name, tags, itrOpt, cur, aux, conds, condNames, reads), nil
 	case integerCursor:
-		return newIntegerIterator(name, tags, itrOpt, cur, aux, conds, condNames), nil
+		return newIntegerIterator(name, tags, itrOpt, cur, aux, conds, condNames, reads), nil
 	case unsignedCursor:
-		return newUnsignedIterator(name, tags, itrOpt, cur, aux, conds, condNames), nil
+		return newUnsignedIterator(name, tags, itrOpt, cur, aux, conds, condNames, reads), nil
 	case stringCursor:
-		return newStringIterator(name, tags, itrOpt, cur, aux, conds, condNames), nil
+		return newStringIterator(name, tags, itrOpt, cur, aux, conds, condNames, reads), nil
 	case booleanCursor:
-		return newBooleanIterator(name, tags, itrOpt, cur, aux, conds, condNames), nil
+		return newBooleanIterator(name, tags, itrOpt, cur, aux, conds, condNames, reads), nil
 	default:
 		panic("unreachable")
 	}
 }
 
+// closeCursors closes the cursors built for a series iterator that couldn't be created.
+func closeCursors(cur cursor, aux, conds []cursorAt) {
+	if cur != nil {
+		cur.close()
+	}
+	for _, c := range append(aux, conds...) {
+		if c != nil {
+			c.close()
+		}
+	}
+}
+
+// createStatsSeriesIterator returns an iterator answering a count, sum, min, max, first
+// or last call over a numeric field of a series from the statistics of the blocks lying
+// entirely within an interval.  It returns nil if the call cannot use block statistics.
+func (e *Engine) createStatsSeriesIterator(ctx context.Context, ref *influxql.VarRef, name string, seriesKey string, tags query.Tags, opt query.IteratorOptions) (query.Iterator, error) {
+	call, ok := opt.Expr.(*influxql.Call)
+	if !ok || !opt.Ascending {
+		return nil, nil
+	}
+
+	switch call.Name {
+	case "count", "sum", "min", "max", "first", "last":
+	default:
+		return nil, nil
+	}
+
+	// Look up the field.  Casts are left to the cursors of the field.
+	mf := e.fieldset.Fields(name)
+	if mf == nil {
+		return nil, nil
+	}
+	f := mf.Field(ref.Val)
+	if f == nil {
+		return nil, nil
+	} else if ref.Type != influxql.Unknown && ref.Type != influxql.AnyField && ref.Type != f.Type {
+		return nil, nil
+	}
+
+	// Limit tags to only the dimensions selected.
+	tags = tags.Subset(opt.GetDimensions())
+
+	// Remove name if requested.
+	if opt.StripName {
+		name = ""
+	}
+
+	key := SeriesFieldKeyBytes(seriesKey, ref.Val)
+	switch f.Type {
+	case influxql.Float, influxql.Integer, influxql.Unsigned:
+	default:
+		return nil, nil
+	}
+
+	cacheValues, err := e.cacheValues(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	keyCursor := e.KeyCursor(ctx, key, opt.SeekTime(), true)
+
+	switch f.Type {
+	case influxql.Float:
+		return newFloatStatsIterator(name, tags, opt, cacheValues, keyCursor), nil
+	case influxql.Integer:
+		return newIntegerStatsIterator(name, tags, opt, cacheValues, keyCursor), nil
+	default:
+		return newUnsignedStatsIterator(name, tags, opt, cacheValues, keyCursor), nil
+	}
+}
+
 // buildCursor creates an untyped cursor for a field.
-func (e *Engine) buildCursor(ctx context.Context, measurement, seriesKey string, tags models.Tags, ref *influxql.VarRef, opt query.IteratorOptions) cursor {
+func (e *Engine) buildCursor(ctx context.Context, measurement, seriesKey string, tags models.Tags, ref *influxql.VarRef, opt query.IteratorOptions) (cursor, error) {
 	// Check if this is a system field cursor.
 	switch ref.Val {
 	case "_name":
-		return &stringSliceCursor{values: []string{measurement}}
+		return &stringSliceCursor{values: []string{measurement}}, nil
 	case "_tagKey":
-		return &stringSliceCursor{values: tags.Keys()}
+		return &stringSliceCursor{values: tags.Keys()}, nil
 	case "_tagValue":
-		return &stringSliceCursor{values: matchTagValues(tags, opt.Condition)}
+		return &stringSliceCursor{values: matchTagValues(tags, opt.Condition)}, nil
 	case "_seriesKey":
-		return &stringSliceCursor{values: []string{seriesKey}}
+		return &stringSliceCursor{values: []string{seriesKey}}, nil
 	}
 
 	// Look up fields for measurement.
 	mf := e.fieldset.Fields(measurement)
 	if mf == nil {
-		return nil
+		return nil, nil
 	}
 
 	// Check for system field for field keys.
 	if ref.Val == "_fieldKey" {
-		return &stringSliceCursor{values: mf.FieldKeys()}
+		return &stringSliceCursor{values: mf.FieldKeys()}, nil
 	}
 
 	// Find individual field.
 	f := mf.Field(ref.Val)
 	if f == nil {
-		return nil
+		return nil, nil
 	}
 
 	// Check if we need to perform a cast. Performing a cast in the
@@ -2301,32 +3343,50 @@ func (e *Engine) buildCursor(ctx context.Context, measurement, seriesKey string,
 		case influxql.Float:
 			switch f.Type {
 			case influxql.Integer:
-				cur := e.buildIntegerCursor(ctx, measurement, seriesKey, ref.Val, opt)
-				return &floatCastIntegerCursor{cursor: cur}
+				cur, err := e.buildIntegerCursor(ctx, measurement, seriesKey, ref.Val, opt)
+				if err != nil {
+					return nil, err
+				}
+				return &floatCastIntegerCursor{cursor: cur}, nil
 			case influxql.Unsigned:
-				cur := e.buildUnsignedCursor(ctx, measurement, seriesKey, ref.Val, opt)
-				return &floatCastUnsignedCursor{cursor: cur}
+				cur, err := e.buildUnsignedCursor(ctx, measurement, seriesKey, ref.Val, opt)
+				if err != nil {
+					return nil, err
+				}
+				return &floatCastUnsignedCursor{cursor: cur}, nil
 			}
 		case influxql.Integer:
 			switch f.Type {
 			case influxql.Float:
-				cur := e.buildFloatCursor(ctx, measurement, seriesKey, ref.Val, opt)
-				return &integerCastFloatCursor{cursor: cur}
+				cur, err := e.buildFloatCursor(ctx, measurement, seriesKey, ref.Val, opt)
+				if err != nil {
+					return nil, err
+				}
+				return &integerCastFloatCursor{cursor: cur}, nil
 			case influxql.Unsigned:
-				cur := e.buildUnsignedCursor(ctx, measurement, seriesKey, ref.Val, opt)
-				return &integerCastUnsignedCursor{cursor: cur}
+				cur, err := e.buildUnsignedCursor(ctx, measurement, seriesKey, ref.Val, opt)
+				if err != nil {
+					return nil, err
+				}
+				return &integerCastUnsignedCursor{cursor: cur}, nil
 			}
 		case influxql.Unsigned:
 			switch f.Type {
 			case influxql.Float:
-				cur := e.buildFloatCursor(ctx, measurement, seriesKey, ref.Val, opt)
-				return &unsignedCastFloatCursor{cursor: cur}
+				cur, err := e.buildFloatCursor(ctx, measurement, seriesKey, ref.Val, opt)
+				if err != nil {
+					return nil, err
+				}
+				return &unsignedCastFloatCursor{cursor: cur}, nil
 			case influxql.Integer:
-				cur := e.buildIntegerCursor(ctx, measurement, seriesKey, ref.Val, opt)
-				return &unsignedCastIntegerCursor{cursor: cur}
+				cur, err := e.buildIntegerCursor(ctx, measurement, seriesKey, ref.Val, opt)
+				if err != nil {
+					return nil, err
+				}
+				return &unsignedCastIntegerCursor{cursor: cur}, nil
 			}
 		}
-		return nil
+		return nil, nil
 	}
 
 	// Return appropriate cursor based on type.
@@ -2372,14 +3432,15 @@ func matchTagValues(tags models.Tags, condition influxql.Expr) []string {
 func (e *Engine) IteratorCost(measurement string, opt query.IteratorOptions) (query.IteratorCost, error) {
 	// Determine if this measurement exists. If it does not, then no shards are
 	// accessed to begin with.
-	if exists, err := e.index.MeasurementExists([]byte(measurement)); err != nil {
+	index := e.currentIndex()
+	if exists, err := index.MeasurementExists([]byte(measurement)); err != nil {
 		return query.IteratorCost{}, err
 	} else if !exists {
 		return query.IteratorCost{}, nil
 	}
 
 	// Determine all of the tag sets for this query.
-	tagSets, err := e.index.TagSets([]byte(measurement), opt)
+	tagSets, err := index.TagSets([]byte(measurement), opt)
 	if err != nil {
 		return query.IteratorCost{}, err
 	}
@@ -2403,7 +3464,10 @@ func (e *Engine) IteratorCost(measurement string, opt query.IteratorOptions) (qu
 		for i, key := range t.SeriesKeys {
 			// Retrieve the cost for the main expression (if it exists).
 			if ref != nil {
-				c := e.seriesCost(key, ref.Val, opt.StartTime, opt.EndTime)
+				c, err := e.seriesCost(key, ref.Val, opt.StartTime, opt.EndTime)
+				if err != nil {
+					return query.IteratorCost{}, err
+				}
 				cost = cost.Combine(c)
 			}
 
@@ -2413,7 +3477,10 @@ func (e *Engine) IteratorCost(measurement string, opt query.IteratorOptions) (qu
 			// anywhere close to the full costs of the auxiliary iterators because
 			// many of the selected values are usually skipped.
 			for _, ref := range opt.Aux {
-				c := e.seriesCost(key, ref.Val, opt.StartTime, opt.EndTime)
+				c, err := e.seriesCost(key, ref.Val, opt.StartTime, opt.EndTime)
+				if err != nil {
+					return query.IteratorCost{}, err
+				}
 				cost = cost.Combine(c)
 			}
 
@@ -2422,7 +3489,10 @@ func (e *Engine) IteratorCost(measurement string, opt query.IteratorOptions) (qu
 			if t.Filters[i] != nil {
 				refs := influxql.ExprNames(t.Filters[i])
 				for _, ref := range refs {
-					c := e.seriesCost(key, ref.Val, opt.StartTime, opt.EndTime)
+					c, err := e.seriesCost(key, ref.Val, opt.StartTime, opt.EndTime)
+					if err != nil {
+						return query.IteratorCost{}, err
+					}
 					cost = cost.Combine(c)
 				}
 			}
@@ -2431,18 +3501,21 @@ func (e *Engine) IteratorCost(measurement string, opt query.IteratorOptions) (qu
 	return cost, nil
 }
 
-func (e *Engine) seriesCost(seriesKey, field string, tmin, tmax int64) query.IteratorCost {
+func (e *Engine) seriesCost(seriesKey, field string, tmin, tmax int64) (query.IteratorCost, error) {
 	key := SeriesFieldKeyBytes(seriesKey, field)
 	c := e.FileStore.Cost(key, tmin, tmax)
 
 	// Retrieve the range of values within the cache.
-	cacheValues := e.Cache.Values(key)
+	cacheValues, err := e.Cache.ReadValues(key)
+	if err != nil {
+		return query.IteratorCost{}, err
+	}
 	c.CachedValues = int64(len(cacheValues.Include(tmin, tmax)))
-	return c
+	return c, nil
 }
 
 func (e *Engine) SeriesPointIterator(opt query.IteratorOptions) (query.Iterator, error) {
-	return e.index.SeriesPointIterator(opt)
+	return e.currentIndex().SeriesPointIterator(opt)
 }
 
 // SeriesFieldKey combine a series key and field name for a unique string to be hashed to a numeric ID.
@@ -37,15 +37,35 @@ func (e *Engine) CreateCursor(ctx context.Context, r *tsdb.CursorRequest) (tsdb.
 	// Return appropriate cursor based on type.
 	switch f.Type {
 	case influxql.Float:
-		return newFloatRangeBatchCursor(t, r.Ascending, e.buildFloatBatchCursor(ctx, r.Measurement, r.Series, r.Field, opt)), nil
+		cur, err := e.buildFloatBatchCursor(ctx, r.Measurement, r.Series, r.Field, opt)
+		if err != nil {
+			return nil, err
+		}
+		return newFloatRangeBatchCursor(t, r.Ascending, cur), nil
 	case influxql.Integer:
-		return newIntegerRangeBatchCursor(t, r.Ascending, e.buildIntegerBatchCursor(ctx, r.Measurement, r.Series, r.Field, opt)), nil
+		cur, err := e.buildIntegerBatchCursor(ctx, r.Measurement, r.Series, r.Field, opt)
+		if err != nil {
+			return nil, err
+		}
+		return newIntegerRangeBatchCursor(t, r.Ascending, cur), nil
 	case influxql.Unsigned:
-		return newUnsignedRangeBatchCursor(t, r.Ascending, e.buildUnsignedBatchCursor(ctx, r.Measurement, r.Series, r.Field, opt)), nil
+		cur, err := e.buildUnsignedBatchCursor(ctx, r.Measurement, r.Series, r.Field, opt)
+		if err != nil {
+			return nil, err
+		}
+		return newUnsignedRangeBatchCursor(t, r.Ascending, cur), nil
 	case influxql.String:
-		return newStringRangeBatchCursor(t, r.Ascending, e.buildStringBatchCursor(ctx, r.Measurement, r.Series, r.Field, opt)), nil
+		cur, err := e.buildStringBatchCursor(ctx, r.Measurement, r.Series, r.Field, opt)
+		if err != nil {
+			return nil, err
+		}
+		return newStringRangeBatchCursor(t, r.Ascending, cur), nil
 	case influxql.Boolean:
-		return newBooleanRangeBatchCursor(t, r.Ascending, e.buildBooleanBatchCursor(ctx, r.Measurement, r.Series, r.Field, opt)), nil
+		cur, err := e.buildBooleanBatchCursor(ctx, r.Measurement, r.Series, r.Field, opt)
+		if err != nil {
+			return nil, err
+		}
+		return newBooleanRangeBatchCursor(t, r.Ascending, cur), nil
 	default:
 		panic(fmt.Sprintf("unreachable: %T", f.Type))
 	}
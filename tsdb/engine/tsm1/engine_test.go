@@ -253,6 +253,76 @@ func TestEngine_Backup(t *testing.T) {
 	}
 }
 
+func TestEngine_WriteTo(t *testing.T) {
+	e := MustOpenEngine(tsdb.DefaultIndex)
+	defer e.Close()
+
+	// mock the planner so compactions don't run during the test
+	e.CompactionPlan = &mockPlanner{}
+
+	if err := e.WritePointsString(`cpu,host=A value=1.1 1000000000`); err != nil {
+		t.Fatalf("failed to write points: %s", err.Error())
+	} else if err := e.WriteSnapshot(); err != nil {
+		t.Fatalf("failed to snapshot: %s", err.Error())
+	} else if err := e.WritePointsString(`cpu,host=B value=1.2 2000000000`); err != nil {
+		t.Fatalf("failed to write points: %s", err.Error())
+	}
+
+	var buf bytes.Buffer
+	n, err := e.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	} else if n != int64(buf.Len()) {
+		t.Fatalf("unexpected byte count: got %d, exp %d", n, buf.Len())
+	}
+
+	// The cache is written to the image without being snapshotted.
+	if files := e.FileStore.Files(); len(files) != 1 {
+		t.Fatalf("unexpected number of TSM files: %d", len(files))
+	} else if e.Cache.Size() == 0 {
+		t.Fatal("expected cache to be unchanged")
+	}
+
+	var names []string
+	tr := tar.NewReader(bytes.NewReader(buf.Bytes()))
+	for {
+		th, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, th.Name)
+	}
+	if len(names) != 2 || names[0] != filepath.Base(e.FileStore.Files()[0].Path()) {
+		t.Fatalf("unexpected files in image: %v", names)
+	}
+
+	// The image can be imported into another shard.
+	e2 := MustOpenEngine(tsdb.DefaultIndex)
+	defer e2.Close()
+
+	if err := e2.Import(&buf, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		key string
+		ts  int64
+		v   float64
+	}{
+		{"cpu,host=A#!~#value", 1000000000, 1.1},
+		{"cpu,host=B#!~#value", 2000000000, 1.2},
+	} {
+		values, err := e2.FileStore.Read([]byte(tt.key), tt.ts)
+		if err != nil {
+			t.Fatal(err)
+		} else if exp := []tsm1.Value{tsm1.NewValue(tt.ts, tt.v)}; !reflect.DeepEqual(values, exp) {
+			t.Fatalf("unexpected values for %s: got %v, exp %v", tt.key, values, exp)
+		}
+	}
+}
+
 // Ensure engine can create an ascending iterator for cached values.
 func TestEngine_CreateIterator_Cache_Ascending(t *testing.T) {
 	t.Parallel()
@@ -411,6 +481,73 @@ func TestEngine_CreateIterator_TSM_Ascending(t *testing.T) {
 	}
 }
 
+// Ensure an iterator reading from a read snapshot only sees the values that existed
+// when the snapshot was taken, even once they are deleted.
+func TestEngine_CreateIterator_ReadSnapshot(t *testing.T) {
+	t.Parallel()
+
+	e := MustOpenDefaultEngine()
+	defer e.Close()
+
+	e.MeasurementFields([]byte("cpu")).CreateFieldIfNotExists([]byte("value"), influxql.Float, false)
+	e.CreateSeriesIfNotExists([]byte("cpu,host=A"), []byte("cpu"), models.NewTags(map[string]string{"host": "A"}))
+
+	if err := e.WritePointsString(`cpu,host=A value=1.1 1000000000`); err != nil {
+		t.Fatalf("failed to write points: %s", err.Error())
+	}
+	e.MustWriteSnapshot()
+	if err := e.WritePointsString(`cpu,host=A value=1.2 2000000000`); err != nil {
+		t.Fatalf("failed to write points: %s", err.Error())
+	}
+
+	s, err := e.ReadSnapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Release()
+
+	// Values written and snapshotted after the read snapshot are not seen through it.
+	if err := e.WritePointsString(`cpu,host=A value=1.3 3000000000`); err != nil {
+		t.Fatalf("failed to write points: %s", err.Error())
+	}
+
+	// Deletes do not wait for the read snapshot and are not seen through it.
+	itr := &seriesIterator{keys: [][]byte{[]byte("cpu,host=A")}}
+	if err := e.DeleteSeriesRange(itr, 1000000000, 2000000000); err != nil {
+		t.Fatalf("failed to delete series: %s", err.Error())
+	}
+	e.MustWriteSnapshot()
+
+	qitr, err := e.CreateIterator(s.NewContext(context.Background()), "cpu", query.IteratorOptions{
+		Expr:       influxql.MustParseExpr(`value`),
+		Dimensions: []string{"host"},
+		StartTime:  1000000000,
+		EndTime:    3000000000,
+		Ascending:  true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fitr := qitr.(query.FloatIterator)
+
+	if p, err := fitr.Next(); err != nil {
+		t.Fatalf("unexpected error(0): %v", err)
+	} else if !reflect.DeepEqual(p, &query.FloatPoint{Name: "cpu", Tags: ParseTags("host=A"), Time: 1000000000, Value: 1.1}) {
+		t.Fatalf("unexpected point(0): %v", p)
+	}
+	if p, err := fitr.Next(); err != nil {
+		t.Fatalf("unexpected error(1): %v", err)
+	} else if !reflect.DeepEqual(p, &query.FloatPoint{Name: "cpu", Tags: ParseTags("host=A"), Time: 2000000000, Value: 1.2}) {
+		t.Fatalf("unexpected point(1): %v", p)
+	}
+	if p, err := fitr.Next(); err != nil {
+		t.Fatalf("expected eof, got error: %v", err)
+	} else if p != nil {
+		t.Fatalf("expected eof: %v", p)
+	}
+	qitr.Close()
+}
+
 // Ensure engine can create an descending iterator for cached values.
 func TestEngine_CreateIterator_TSM_Descending(t *testing.T) {
 	t.Parallel()
@@ -578,6 +715,279 @@ func TestEngine_CreateIterator_Condition(t *testing.T) {
 	}
 }
 
+// Ensure string values are matched against the condition as they are read, with values
+// in the cache replacing those in TSM files.
+func TestEngine_CreateIterator_Condition_String(t *testing.T) {
+	t.Parallel()
+
+	e := MustOpenDefaultEngine()
+	defer e.Close()
+
+	e.MeasurementFields([]byte("cpu")).CreateFieldIfNotExists([]byte("status"), influxql.String, false)
+	e.CreateSeriesIfNotExists([]byte("cpu,host=A"), []byte("cpu"), models.NewTags(map[string]string{"host": "A"}))
+
+	if err := e.WritePointsString(
+		`cpu,host=A status="OK" 1000000000`,
+		`cpu,host=A status="CRIT" 2000000000`,
+		`cpu,host=A status="WARN" 3000000000`,
+		`cpu,host=A status="CRIT" 4000000000`,
+		`cpu,host=A status="OK" 5000000000`,
+		`cpu,host=A status="OK" 6000000000`,
+	); err != nil {
+		t.Fatalf("failed to write points: %s", err.Error())
+	}
+	e.MustWriteSnapshot()
+
+	if err := e.WritePointsString(
+		`cpu,host=A status="OK" 4000000000`,
+		`cpu,host=A status="CRIT" 5000000000`,
+	); err != nil {
+		t.Fatalf("failed to write points: %s", err.Error())
+	}
+
+	for _, ascending := range []bool{true, false} {
+		itr, err := e.CreateIterator(context.Background(), "cpu", query.IteratorOptions{
+			Expr:       influxql.MustParseExpr(`status`),
+			Dimensions: []string{"host"},
+			Condition:  influxql.MustParseExpr(`status = 'CRIT'`),
+			StartTime:  influxql.MinTime,
+			EndTime:    influxql.MaxTime,
+			Ascending:  ascending,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sitr := itr.(query.StringIterator)
+
+		exp := []*query.StringPoint{
+			{Name: "cpu", Tags: ParseTags("host=A"), Time: 2000000000, Value: "CRIT"},
+			{Name: "cpu", Tags: ParseTags("host=A"), Time: 5000000000, Value: "CRIT"},
+		}
+		if !ascending {
+			exp[0], exp[1] = exp[1], exp[0]
+		}
+
+		for i := range exp {
+			if p, err := sitr.Next(); err != nil {
+				t.Fatalf("unexpected error(%d): %v", i, err)
+			} else if !reflect.DeepEqual(p, exp[i]) {
+				t.Fatalf("unexpected point(%d): %v", i, p)
+			}
+		}
+		if p, err := sitr.Next(); err != nil {
+			t.Fatalf("expected eof, got error: %v", err)
+		} else if p != nil {
+			t.Fatalf("expected eof: %v", p)
+		}
+		sitr.Close()
+	}
+}
+
+// Ensure the stats of an iterator count the blocks and cache values read by its cursors.
+func TestEngine_CreateIterator_ReadStats(t *testing.T) {
+	t.Parallel()
+
+	e := MustOpenDefaultEngine()
+	defer e.Close()
+	e.SetCompactionsEnabled(false)
+
+	e.MeasurementFields([]byte("cpu")).CreateFieldIfNotExists([]byte("value"), influxql.Float, false)
+	e.CreateSeriesIfNotExists([]byte("cpu,host=A"), []byte("cpu"), models.NewTags(map[string]string{"host": "A"}))
+
+	if err := e.WritePointsString(
+		`cpu,host=A value=1 1000000000`,
+		`cpu,host=A value=2 2000000000`,
+		`cpu,host=A value=3 3000000000`,
+	); err != nil {
+		t.Fatalf("failed to write points: %s", err.Error())
+	}
+	e.MustWriteSnapshot()
+
+	// Overwrite a value in a second file and leave another in the cache.
+	if err := e.WritePointsString(`cpu,host=A value=20 2000000000`); err != nil {
+		t.Fatalf("failed to write points: %s", err.Error())
+	}
+	e.MustWriteSnapshot()
+	if err := e.WritePointsString(`cpu,host=A value=5 5000000000`); err != nil {
+		t.Fatalf("failed to write points: %s", err.Error())
+	}
+
+	itr, err := e.CreateIterator(context.Background(), "cpu", query.IteratorOptions{
+		Expr:       influxql.MustParseExpr(`value`),
+		Dimensions: []string{"host"},
+		StartTime:  influxql.MinTime,
+		EndTime:    influxql.MaxTime,
+		Ascending:  true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer itr.Close()
+
+	fitr := itr.(query.FloatIterator)
+	for {
+		if p, err := fitr.Next(); err != nil {
+			t.Fatal(err)
+		} else if p == nil {
+			break
+		}
+	}
+
+	stats := fitr.Stats()
+	if stats.BlockBytes <= 0 {
+		t.Fatalf("expected block bytes, got %d", stats.BlockBytes)
+	}
+	stats.BlockBytes = 0
+	if exp := (query.IteratorStats{SeriesN: 1, PointN: 4, BlockReadN: 2, BlockDecodeN: 2, CacheHitN: 1, BlockMergeN: 1}); !reflect.DeepEqual(stats, exp) {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+// Ensure engine answers calls from block statistics and decodes blocks crossing an interval.
+func TestEngine_CreateIterator_BlockStats(t *testing.T) {
+	t.Parallel()
+
+	e := MustOpenDefaultEngine()
+	defer e.Close()
+
+	e.MeasurementFields([]byte("cpu")).CreateFieldIfNotExists([]byte("value"), influxql.Float, false)
+	e.CreateSeriesIfNotExists([]byte("cpu,host=A"), []byte("cpu"), models.NewTags(map[string]string{"host": "A"}))
+
+	if err := e.WritePointsString(
+		`cpu,host=A value=1 1000000000`,
+		`cpu,host=A value=5 2000000000`,
+		`cpu,host=A value=3 3000000000`,
+	); err != nil {
+		t.Fatalf("failed to write points: %s", err.Error())
+	}
+	e.MustWriteSnapshot()
+
+	if err := e.WritePointsString(
+		`cpu,host=A value=2 11000000000`,
+		`cpu,host=A value=4 15000000000`,
+	); err != nil {
+		t.Fatalf("failed to write points: %s", err.Error())
+	}
+	e.MustWriteSnapshot()
+
+	// The last point is only in the cache.
+	if err := e.WritePointsString(`cpu,host=A value=7 21000000000`); err != nil {
+		t.Fatalf("failed to write points: %s", err.Error())
+	}
+
+	newOptions := func(call string, interval time.Duration) query.IteratorOptions {
+		return query.IteratorOptions{
+			Expr:       influxql.MustParseExpr(call + `(value)`),
+			Dimensions: []string{"host"},
+			Interval:   query.Interval{Duration: interval},
+			StartTime:  0,
+			EndTime:    30000000000 - 1,
+			Ascending:  true,
+		}
+	}
+
+	for _, tt := range []struct {
+		call string
+		exp  []query.FloatPoint
+	}{
+		{call: "sum", exp: []query.FloatPoint{{Time: 0, Value: 9, Aggregated: 3}, {Time: 10000000000, Value: 6, Aggregated: 2}, {Time: 20000000000, Value: 7, Aggregated: 1}}},
+		{call: "min", exp: []query.FloatPoint{{Time: 1000000000, Value: 1, Aggregated: 3}, {Time: 11000000000, Value: 2, Aggregated: 2}, {Time: 21000000000, Value: 7, Aggregated: 1}}},
+		{call: "max", exp: []query.FloatPoint{{Time: 2000000000, Value: 5, Aggregated: 3}, {Time: 15000000000, Value: 4, Aggregated: 2}, {Time: 21000000000, Value: 7, Aggregated: 1}}},
+		{call: "first", exp: []query.FloatPoint{{Time: 1000000000, Value: 1, Aggregated: 3}, {Time: 11000000000, Value: 2, Aggregated: 2}, {Time: 21000000000, Value: 7, Aggregated: 1}}},
+		{call: "last", exp: []query.FloatPoint{{Time: 3000000000, Value: 3, Aggregated: 3}, {Time: 15000000000, Value: 4, Aggregated: 2}, {Time: 21000000000, Value: 7, Aggregated: 1}}},
+	} {
+		itr, err := e.CreateIterator(context.Background(), "cpu", newOptions(tt.call, 10*time.Second))
+		if err != nil {
+			t.Fatal(err)
+		}
+		fitr := itr.(query.FloatIterator)
+
+		var points []query.FloatPoint
+		for {
+			p, err := fitr.Next()
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", tt.call, err)
+			} else if p == nil {
+				break
+			}
+			points = append(points, *p)
+		}
+		itr.Close()
+
+		for i := range tt.exp {
+			tt.exp[i].Name, tt.exp[i].Tags = "cpu", ParseTags("host=A")
+		}
+		if !reflect.DeepEqual(points, tt.exp) {
+			t.Fatalf("%s: unexpected points:\ngot  %v\nexp  %v", tt.call, points, tt.exp)
+		}
+	}
+
+	// The values of a block before the start of the query are not aggregated.
+	for _, tt := range []struct {
+		call string
+		exp  []query.FloatPoint
+	}{
+		{call: "sum", exp: []query.FloatPoint{{Time: 0, Value: 8, Aggregated: 2}, {Time: 10000000000, Value: 6, Aggregated: 2}, {Time: 20000000000, Value: 7, Aggregated: 1}}},
+		{call: "min", exp: []query.FloatPoint{{Time: 3000000000, Value: 3, Aggregated: 2}, {Time: 11000000000, Value: 2, Aggregated: 2}, {Time: 21000000000, Value: 7, Aggregated: 1}}},
+		{call: "first", exp: []query.FloatPoint{{Time: 2000000000, Value: 5, Aggregated: 2}, {Time: 11000000000, Value: 2, Aggregated: 2}, {Time: 21000000000, Value: 7, Aggregated: 1}}},
+	} {
+		opt := newOptions(tt.call, 10*time.Second)
+		opt.StartTime = 2000000000
+		itr, err := e.CreateIterator(context.Background(), "cpu", opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fitr := itr.(query.FloatIterator)
+
+		var points []query.FloatPoint
+		for {
+			p, err := fitr.Next()
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", tt.call, err)
+			} else if p == nil {
+				break
+			}
+			points = append(points, *p)
+		}
+		itr.Close()
+
+		for i := range tt.exp {
+			tt.exp[i].Name, tt.exp[i].Tags = "cpu", ParseTags("host=A")
+		}
+		if !reflect.DeepEqual(points, tt.exp) {
+			t.Fatalf("%s: unexpected points from start time:\ngot  %v\nexp  %v", tt.call, points, tt.exp)
+		}
+	}
+
+	// Blocks crossing an interval are decoded.
+	itr, err := e.CreateIterator(context.Background(), "cpu", newOptions("count", 2*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer itr.Close()
+	iitr := itr.(query.IntegerIterator)
+
+	for i, exp := range []query.IntegerPoint{
+		{Time: 0, Value: 1, Aggregated: 1},
+		{Time: 2000000000, Value: 2, Aggregated: 2},
+		{Time: 10000000000, Value: 1, Aggregated: 1},
+		{Time: 14000000000, Value: 1, Aggregated: 1},
+		{Time: 20000000000, Value: 1, Aggregated: 1},
+	} {
+		exp.Name, exp.Tags = "cpu", ParseTags("host=A")
+		if p, err := iitr.Next(); err != nil {
+			t.Fatalf("unexpected error(%d): %v", i, err)
+		} else if !reflect.DeepEqual(p, &exp) {
+			t.Fatalf("unexpected point(%d): %v", i, p)
+		}
+	}
+	if p, err := iitr.Next(); err != nil {
+		t.Fatalf("expected eof, got error: %v", err)
+	} else if p != nil {
+		t.Fatalf("expected eof: %v", p)
+	}
+}
+
 // Ensures that deleting series from TSM files with multiple fields removes all the
 /// series
 func TestEngine_DeleteSeries(t *testing.T) {
@@ -718,6 +1128,184 @@ func TestEngine_SnapshotsDisabled(t *testing.T) {
 	}
 }
 
+// Ensure a sealed engine rejects writes, keeps its data and survives a restart.
+func TestEngine_SetReadOnly(t *testing.T) {
+	e := MustOpenEngine(tsdb.DefaultIndex)
+	defer e.Close()
+
+	// mock the planner so compactions don't run during the test
+	e.CompactionPlan = &mockPlanner{}
+
+	if err := e.WritePointsString(`cpu,host=A value=1.1 1000000000`); err != nil {
+		t.Fatalf("failed to write points: %s", err.Error())
+	}
+
+	if err := e.SetReadOnly(true); err != nil {
+		t.Fatal(err)
+	} else if !e.ReadOnly() {
+		t.Fatal("expected engine to be read-only")
+	}
+
+	// The cache was written to a TSM file and the WAL removed.
+	if files := e.FileStore.Files(); len(files) != 1 {
+		t.Fatalf("unexpected number of TSM files: %d", len(files))
+	} else if sz := e.Cache.Size(); sz != 0 {
+		t.Fatalf("unexpected cache size: %d", sz)
+	} else if _, err := os.Stat(filepath.Join(e.root, "wal")); !os.IsNotExist(err) {
+		t.Fatalf("expected WAL to be removed: %v", err)
+	}
+
+	if err := e.WritePointsString(`cpu,host=A value=1.2 2000000000`); err != tsdb.ErrShardReadOnly {
+		t.Fatalf("unexpected write error: %v", err)
+	}
+	itr := &seriesIterator{keys: [][]byte{[]byte("cpu,host=A")}}
+	if err := e.DeleteSeriesRange(itr, math.MinInt64, math.MaxInt64); err != tsdb.ErrShardReadOnly {
+		t.Fatalf("unexpected delete error: %v", err)
+	}
+
+	// The manifest matches the files.
+	m, err := tsm1.ReadSealManifest(filepath.Join(e.root, "data"))
+	if err != nil {
+		t.Fatal(err)
+	} else if m == nil || len(m.Files) != 1 {
+		t.Fatalf("unexpected manifest: %+v", m)
+	} else if err := m.Files[0].Verify(filepath.Join(e.root, "data")); err != nil {
+		t.Fatal(err)
+	}
+
+	// The engine is still read-only after it is reopened.
+	if err := e.Reopen(); err != nil {
+		t.Fatal(err)
+	} else if !e.ReadOnly() {
+		t.Fatal("expected engine to be read-only after reopening")
+	}
+
+	if err := e.SetReadOnly(false); err != nil {
+		t.Fatal(err)
+	} else if e.ReadOnly() {
+		t.Fatal("expected engine to be writable")
+	}
+
+	if err := e.WritePointsString(`cpu,host=A value=1.2 2000000000`); err != nil {
+		t.Fatalf("failed to write points: %s", err.Error())
+	} else if _, err := os.Stat(filepath.Join(e.root, "data", tsm1.SealFileName)); !os.IsNotExist(err) {
+		t.Fatalf("expected manifest to be removed: %v", err)
+	}
+}
+
+// Ensure sealing waits for a delete that started before the shard was sealed, so the
+// delete's tombstones are in the manifest.
+func TestEngine_SetReadOnly_Delete(t *testing.T) {
+	e := MustOpenEngine(tsdb.DefaultIndex)
+	defer e.Close()
+
+	// mock the planner so compactions don't run during the test
+	e.CompactionPlan = &mockPlanner{}
+
+	if err := e.WritePointsString(`cpu,host=A value=1.1 1000000000`, `cpu,host=B value=1.2 1000000000`); err != nil {
+		t.Fatalf("failed to write points: %s", err.Error())
+	} else if err := e.WriteSnapshot(); err != nil {
+		t.Fatalf("failed to snapshot: %s", err.Error())
+	}
+
+	// The delete blocks reading its series until the shard is being sealed.
+	itr := &blockingSeriesIterator{
+		seriesIterator: seriesIterator{keys: [][]byte{[]byte("cpu,host=A")}},
+		started:        make(chan struct{}),
+		release:        make(chan struct{}),
+	}
+	deleted := make(chan error, 1)
+	go func() { deleted <- e.DeleteSeriesRange(itr, math.MinInt64, math.MaxInt64) }()
+	<-itr.started
+
+	sealed := make(chan error, 1)
+	go func() { sealed <- e.SetReadOnly(true) }()
+
+	select {
+	case err := <-sealed:
+		t.Fatalf("shard sealed during delete: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(itr.release)
+	if err := <-deleted; err != nil {
+		t.Fatal(err)
+	} else if err := <-sealed; err != nil {
+		t.Fatal(err)
+	}
+
+	// The manifest records the tombstones written by the delete.
+	m, err := tsm1.ReadSealManifest(filepath.Join(e.root, "data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tombstones int
+	for _, f := range m.Files {
+		if strings.HasSuffix(f.Name, ".tombstone") {
+			tombstones++
+		}
+		if err := f.Verify(filepath.Join(e.root, "data")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if tombstones != 1 {
+		t.Fatalf("unexpected tombstones in manifest: %d", tombstones)
+	}
+}
+
+func TestEngine_IngestTSMFiles(t *testing.T) {
+	e := MustOpenEngine(tsdb.DefaultIndex)
+	defer e.Close()
+
+	// mock the planner so compactions don't run during the test
+	e.CompactionPlan = &mockPlanner{}
+
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	b, err := tsm1.NewBuilder(dir)
+	if err != nil {
+		t.Fatal(err)
+	} else if err := b.WritePoints(MustParsePointsString("cpu,host=B value=1.2 2000000000\nmem,host=B free=10i 2000000000")); err != nil {
+		t.Fatal(err)
+	} else if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.IngestTSMFiles(b.Files()); err != nil {
+		t.Fatal(err)
+	}
+
+	if files := e.FileStore.Files(); len(files) != 1 {
+		t.Fatalf("unexpected number of TSM files: %d", len(files))
+	} else if n := e.SeriesN(); n != 2 {
+		t.Fatalf("unexpected series count: %d", n)
+	} else if f := e.MeasurementFields([]byte("mem")).Field("free"); f == nil || f.Type != influxql.Integer {
+		t.Fatalf("unexpected field: %+v", f)
+	}
+
+	// Files with a field of a different type than the shard are rejected.
+	conflict := MustTempDir()
+	defer os.RemoveAll(conflict)
+
+	b, err = tsm1.NewBuilder(conflict)
+	if err != nil {
+		t.Fatal(err)
+	} else if err := b.WritePoints(MustParsePointsString(`cpu,host=C value="x" 3000000000`)); err != nil {
+		t.Fatal(err)
+	} else if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := e.IngestTSMFiles(b.Files()); err == nil || !strings.Contains(err.Error(), tsdb.ErrFieldTypeConflict.Error()) {
+		t.Fatalf("unexpected error: %v", err)
+	} else if files := e.FileStore.Files(); len(files) != 1 {
+		t.Fatalf("unexpected number of TSM files: %d", len(files))
+	} else if n := e.SeriesN(); n != 2 {
+		t.Fatalf("unexpected series count: %d", n)
+	}
+}
+
 // Ensure engine can create an ascending cursor for cache and tsm values.
 func TestEngine_CreateCursor_Ascending(t *testing.T) {
 	t.Parallel()
@@ -1171,6 +1759,10 @@ type mockPlanner struct{}
 func (m *mockPlanner) Plan(lastWrite time.Time) []tsm1.CompactionGroup { return nil }
 func (m *mockPlanner) PlanLevel(level int) []tsm1.CompactionGroup      { return nil }
 func (m *mockPlanner) PlanOptimize() []tsm1.CompactionGroup            { return nil }
+func (m *mockPlanner) PlanColdTier() []tsm1.CompactionGroup            { return nil }
+func (m *mockPlanner) PlanRollup() []tsm1.CompactionGroup              { return nil }
+func (m *mockPlanner) PlanTombstones() []tsm1.CompactionGroup          { return nil }
+func (m *mockPlanner) PlanLate() []tsm1.CompactionGroup                { return nil }
 func (m *mockPlanner) Release(groups []tsm1.CompactionGroup)           {}
 func (m *mockPlanner) FullyCompacted() bool                            { return false }
 func (m *mockPlanner) ForceFull()                                      {}
@@ -1200,6 +1792,23 @@ func (s series) Tags() models.Tags   { return s.tags }
 func (s series) Deleted() bool       { return s.deleted }
 func (s series) Expr() influxql.Expr { return nil }
 
+// blockingSeriesIterator signals started and waits for release before returning its
+// first series.
+type blockingSeriesIterator struct {
+	seriesIterator
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func (itr *blockingSeriesIterator) Next() tsdb.SeriesElem {
+	itr.once.Do(func() {
+		close(itr.started)
+		<-itr.release
+	})
+	return itr.seriesIterator.Next()
+}
+
 func (itr *seriesIterator) Next() tsdb.SeriesElem {
 	if len(itr.keys) == 0 {
 		return nil
@@ -16,14 +16,10 @@ func (c *KeyCursor) ReadFloatBlock(buf *[]FloatValue) ([]FloatValue, error) {
 	// First block is the oldest block containing the points we're searching for.
 	first := c.current[0]
 	*buf = (*buf)[:0]
-	values, err := first.r.ReadFloatBlockAt(&first.entry, buf)
+	values, err := c.readFloatBlock(first, buf)
 	if err != nil {
 		return nil, err
 	}
-	if c.col != nil {
-		c.col.GetCounter(floatBlocksDecodedCounter).Add(1)
-		c.col.GetCounter(floatBlocksSizeCounter).Add(int64(first.entry.Size))
-	}
 
 	// Remove values we already read
 	values = FloatValues(values).Exclude(first.readMin, first.readMax)
@@ -88,14 +84,10 @@ func (c *KeyCursor) ReadFloatBlock(buf *[]FloatValue) ([]FloatValue, error) {
 
 			tombstones := cur.r.TombstoneRange(c.key)
 			var a []FloatValue
-			v, err := cur.r.ReadFloatBlockAt(&cur.entry, &a)
+			v, err := c.readFloatBlock(cur, &a)
 			if err != nil {
 				return nil, err
 			}
-			if c.col != nil {
-				c.col.GetCounter(floatBlocksDecodedCounter).Add(1)
-				c.col.GetCounter(floatBlocksSizeCounter).Add(int64(cur.entry.Size))
-			}
 
 			// Remove any tombstoned values
 			v = c.filterFloatValues(tombstones, v)
@@ -109,6 +101,7 @@ func (c *KeyCursor) ReadFloatBlock(buf *[]FloatValue) ([]FloatValue, error) {
 
 				// Merge the remaing values with the existing
 				values = FloatValues(values).Merge(v)
+				c.blockMerged()
 			}
 			cur.markRead(minT, maxT)
 		}
@@ -152,14 +145,10 @@ func (c *KeyCursor) ReadFloatBlock(buf *[]FloatValue) ([]FloatValue, error) {
 			tombstones := cur.r.TombstoneRange(c.key)
 
 			var a []FloatValue
-			v, err := cur.r.ReadFloatBlockAt(&cur.entry, &a)
+			v, err := c.readFloatBlock(cur, &a)
 			if err != nil {
 				return nil, err
 			}
-			if c.col != nil {
-				c.col.GetCounter(floatBlocksDecodedCounter).Add(1)
-				c.col.GetCounter(floatBlocksSizeCounter).Add(int64(cur.entry.Size))
-			}
 
 			// Remove any tombstoned values
 			v = c.filterFloatValues(tombstones, v)
@@ -173,6 +162,7 @@ func (c *KeyCursor) ReadFloatBlock(buf *[]FloatValue) ([]FloatValue, error) {
 				v = FloatValues(v).Include(minT, maxT)
 				// Merge the remaing values with the existing
 				values = FloatValues(v).Merge(values)
+				c.blockMerged()
 			}
 			cur.markRead(minT, maxT)
 		}
@@ -183,6 +173,37 @@ func (c *KeyCursor) ReadFloatBlock(buf *[]FloatValue) ([]FloatValue, error) {
 	return values, err
 }
 
+// readFloatBlock decodes the block of l, or copies its values from the block cache
+// if another cursor already decoded it.
+func (c *KeyCursor) readFloatBlock(l *location, buf *[]FloatValue) ([]FloatValue, error) {
+	key := blockKey{r: l.r, offset: l.entry.Offset}
+	if c.blocks != nil {
+		if values, ok := c.blocks.get(key).([]FloatValue); ok {
+			c.blockRead()
+			*buf = append((*buf)[:0], values...)
+			return *buf, nil
+		}
+	}
+
+	values, err := l.r.ReadFloatBlockAt(&l.entry, buf)
+	if err != nil {
+		return nil, err
+	}
+	if c.col != nil {
+		c.col.GetCounter(floatBlocksDecodedCounter).Add(1)
+		c.col.GetCounter(floatBlocksSizeCounter).Add(int64(l.entry.Size))
+	}
+	c.blockDecoded(&l.entry)
+
+	// The cursor modifies the values it reads, so the cache keeps its own copy.
+	if c.blocks != nil {
+		cached := make([]FloatValue, len(values))
+		copy(cached, values)
+		c.blocks.put(key, cached, FloatValues(cached).Size())
+	}
+	return values, nil
+}
+
 // ReadIntegerBlock reads the next block as a set of integer values.
 func (c *KeyCursor) ReadIntegerBlock(buf *[]IntegerValue) ([]IntegerValue, error) {
 	// No matching blocks to decode
@@ -193,14 +214,10 @@ func (c *KeyCursor) ReadIntegerBlock(buf *[]IntegerValue) ([]IntegerValue, error
 	// First block is the oldest block containing the points we're searching for.
 	first := c.current[0]
 	*buf = (*buf)[:0]
-	values, err := first.r.ReadIntegerBlockAt(&first.entry, buf)
+	values, err := c.readIntegerBlock(first, buf)
 	if err != nil {
 		return nil, err
 	}
-	if c.col != nil {
-		c.col.GetCounter(integerBlocksDecodedCounter).Add(1)
-		c.col.GetCounter(integerBlocksSizeCounter).Add(int64(first.entry.Size))
-	}
 
 	// Remove values we already read
 	values = IntegerValues(values).Exclude(first.readMin, first.readMax)
@@ -265,14 +282,10 @@ func (c *KeyCursor) ReadIntegerBlock(buf *[]IntegerValue) ([]IntegerValue, error
 
 			tombstones := cur.r.TombstoneRange(c.key)
 			var a []IntegerValue
-			v, err := cur.r.ReadIntegerBlockAt(&cur.entry, &a)
+			v, err := c.readIntegerBlock(cur, &a)
 			if err != nil {
 				return nil, err
 			}
-			if c.col != nil {
-				c.col.GetCounter(integerBlocksDecodedCounter).Add(1)
-				c.col.GetCounter(integerBlocksSizeCounter).Add(int64(cur.entry.Size))
-			}
 
 			// Remove any tombstoned values
 			v = c.filterIntegerValues(tombstones, v)
@@ -286,6 +299,7 @@ func (c *KeyCursor) ReadIntegerBlock(buf *[]IntegerValue) ([]IntegerValue, error
 
 				// Merge the remaing values with the existing
 				values = IntegerValues(values).Merge(v)
+				c.blockMerged()
 			}
 			cur.markRead(minT, maxT)
 		}
@@ -329,14 +343,10 @@ func (c *KeyCursor) ReadIntegerBlock(buf *[]IntegerValue) ([]IntegerValue, error
 			tombstones := cur.r.TombstoneRange(c.key)
 
 			var a []IntegerValue
-			v, err := cur.r.ReadIntegerBlockAt(&cur.entry, &a)
+			v, err := c.readIntegerBlock(cur, &a)
 			if err != nil {
 				return nil, err
 			}
-			if c.col != nil {
-				c.col.GetCounter(integerBlocksDecodedCounter).Add(1)
-				c.col.GetCounter(integerBlocksSizeCounter).Add(int64(cur.entry.Size))
-			}
 
 			// Remove any tombstoned values
 			v = c.filterIntegerValues(tombstones, v)
@@ -350,6 +360,7 @@ func (c *KeyCursor) ReadIntegerBlock(buf *[]IntegerValue) ([]IntegerValue, error
 				v = IntegerValues(v).Include(minT, maxT)
 				// Merge the remaing values with the existing
 				values = IntegerValues(v).Merge(values)
+				c.blockMerged()
 			}
 			cur.markRead(minT, maxT)
 		}
@@ -360,6 +371,37 @@ func (c *KeyCursor) ReadIntegerBlock(buf *[]IntegerValue) ([]IntegerValue, error
 	return values, err
 }
 
+// readIntegerBlock decodes the block of l, or copies its values from the block cache
+// if another cursor already decoded it.
+func (c *KeyCursor) readIntegerBlock(l *location, buf *[]IntegerValue) ([]IntegerValue, error) {
+	key := blockKey{r: l.r, offset: l.entry.Offset}
+	if c.blocks != nil {
+		if values, ok := c.blocks.get(key).([]IntegerValue); ok {
+			c.blockRead()
+			*buf = append((*buf)[:0], values...)
+			return *buf, nil
+		}
+	}
+
+	values, err := l.r.ReadIntegerBlockAt(&l.entry, buf)
+	if err != nil {
+		return nil, err
+	}
+	if c.col != nil {
+		c.col.GetCounter(integerBlocksDecodedCounter).Add(1)
+		c.col.GetCounter(integerBlocksSizeCounter).Add(int64(l.entry.Size))
+	}
+	c.blockDecoded(&l.entry)
+
+	// The cursor modifies the values it reads, so the cache keeps its own copy.
+	if c.blocks != nil {
+		cached := make([]IntegerValue, len(values))
+		copy(cached, values)
+		c.blocks.put(key, cached, IntegerValues(cached).Size())
+	}
+	return values, nil
+}
+
 // ReadUnsignedBlock reads the next block as a set of unsigned values.
 func (c *KeyCursor) ReadUnsignedBlock(buf *[]UnsignedValue) ([]UnsignedValue, error) {
 	// No matching blocks to decode
@@ -370,14 +412,10 @@ func (c *KeyCursor) ReadUnsignedBlock(buf *[]UnsignedValue) ([]UnsignedValue, er
 	// First block is the oldest block containing the points we're searching for.
 	first := c.current[0]
 	*buf = (*buf)[:0]
-	values, err := first.r.ReadUnsignedBlockAt(&first.entry, buf)
+	values, err := c.readUnsignedBlock(first, buf)
 	if err != nil {
 		return nil, err
 	}
-	if c.col != nil {
-		c.col.GetCounter(unsignedBlocksDecodedCounter).Add(1)
-		c.col.GetCounter(unsignedBlocksSizeCounter).Add(int64(first.entry.Size))
-	}
 
 	// Remove values we already read
 	values = UnsignedValues(values).Exclude(first.readMin, first.readMax)
@@ -442,14 +480,10 @@ func (c *KeyCursor) ReadUnsignedBlock(buf *[]UnsignedValue) ([]UnsignedValue, er
 
 			tombstones := cur.r.TombstoneRange(c.key)
 			var a []UnsignedValue
-			v, err := cur.r.ReadUnsignedBlockAt(&cur.entry, &a)
+			v, err := c.readUnsignedBlock(cur, &a)
 			if err != nil {
 				return nil, err
 			}
-			if c.col != nil {
-				c.col.GetCounter(unsignedBlocksDecodedCounter).Add(1)
-				c.col.GetCounter(unsignedBlocksSizeCounter).Add(int64(cur.entry.Size))
-			}
 
 			// Remove any tombstoned values
 			v = c.filterUnsignedValues(tombstones, v)
@@ -463,6 +497,7 @@ func (c *KeyCursor) ReadUnsignedBlock(buf *[]UnsignedValue) ([]UnsignedValue, er
 
 				// Merge the remaing values with the existing
 				values = UnsignedValues(values).Merge(v)
+				c.blockMerged()
 			}
 			cur.markRead(minT, maxT)
 		}
@@ -506,14 +541,10 @@ func (c *KeyCursor) ReadUnsignedBlock(buf *[]UnsignedValue) ([]UnsignedValue, er
 			tombstones := cur.r.TombstoneRange(c.key)
 
 			var a []UnsignedValue
-			v, err := cur.r.ReadUnsignedBlockAt(&cur.entry, &a)
+			v, err := c.readUnsignedBlock(cur, &a)
 			if err != nil {
 				return nil, err
 			}
-			if c.col != nil {
-				c.col.GetCounter(unsignedBlocksDecodedCounter).Add(1)
-				c.col.GetCounter(unsignedBlocksSizeCounter).Add(int64(cur.entry.Size))
-			}
 
 			// Remove any tombstoned values
 			v = c.filterUnsignedValues(tombstones, v)
@@ -527,6 +558,7 @@ func (c *KeyCursor) ReadUnsignedBlock(buf *[]UnsignedValue) ([]UnsignedValue, er
 				v = UnsignedValues(v).Include(minT, maxT)
 				// Merge the remaing values with the existing
 				values = UnsignedValues(v).Merge(values)
+				c.blockMerged()
 			}
 			cur.markRead(minT, maxT)
 		}
@@ -537,6 +569,37 @@ func (c *KeyCursor) ReadUnsignedBlock(buf *[]UnsignedValue) ([]UnsignedValue, er
 	return values, err
 }
 
+// readUnsignedBlock decodes the block of l, or copies its values from the block cache
+// if another cursor already decoded it.
+func (c *KeyCursor) readUnsignedBlock(l *location, buf *[]UnsignedValue) ([]UnsignedValue, error) {
+	key := blockKey{r: l.r, offset: l.entry.Offset}
+	if c.blocks != nil {
+		if values, ok := c.blocks.get(key).([]UnsignedValue); ok {
+			c.blockRead()
+			*buf = append((*buf)[:0], values...)
+			return *buf, nil
+		}
+	}
+
+	values, err := l.r.ReadUnsignedBlockAt(&l.entry, buf)
+	if err != nil {
+		return nil, err
+	}
+	if c.col != nil {
+		c.col.GetCounter(unsignedBlocksDecodedCounter).Add(1)
+		c.col.GetCounter(unsignedBlocksSizeCounter).Add(int64(l.entry.Size))
+	}
+	c.blockDecoded(&l.entry)
+
+	// The cursor modifies the values it reads, so the cache keeps its own copy.
+	if c.blocks != nil {
+		cached := make([]UnsignedValue, len(values))
+		copy(cached, values)
+		c.blocks.put(key, cached, UnsignedValues(cached).Size())
+	}
+	return values, nil
+}
+
 // ReadStringBlock reads the next block as a set of string values.
 func (c *KeyCursor) ReadStringBlock(buf *[]StringValue) ([]StringValue, error) {
 	// No matching blocks to decode
@@ -547,14 +610,10 @@ func (c *KeyCursor) ReadStringBlock(buf *[]StringValue) ([]StringValue, error) {
 	// First block is the oldest block containing the points we're searching for.
 	first := c.current[0]
 	*buf = (*buf)[:0]
-	values, err := first.r.ReadStringBlockAt(&first.entry, buf)
+	values, err := c.readStringBlock(first, buf)
 	if err != nil {
 		return nil, err
 	}
-	if c.col != nil {
-		c.col.GetCounter(stringBlocksDecodedCounter).Add(1)
-		c.col.GetCounter(stringBlocksSizeCounter).Add(int64(first.entry.Size))
-	}
 
 	// Remove values we already read
 	values = StringValues(values).Exclude(first.readMin, first.readMax)
@@ -619,14 +678,10 @@ func (c *KeyCursor) ReadStringBlock(buf *[]StringValue) ([]StringValue, error) {
 
 			tombstones := cur.r.TombstoneRange(c.key)
 			var a []StringValue
-			v, err := cur.r.ReadStringBlockAt(&cur.entry, &a)
+			v, err := c.readStringBlock(cur, &a)
 			if err != nil {
 				return nil, err
 			}
-			if c.col != nil {
-				c.col.GetCounter(stringBlocksDecodedCounter).Add(1)
-				c.col.GetCounter(stringBlocksSizeCounter).Add(int64(cur.entry.Size))
-			}
 
 			// Remove any tombstoned values
 			v = c.filterStringValues(tombstones, v)
@@ -640,6 +695,7 @@ func (c *KeyCursor) ReadStringBlock(buf *[]StringValue) ([]StringValue, error) {
 
 				// Merge the remaing values with the existing
 				values = StringValues(values).Merge(v)
+				c.blockMerged()
 			}
 			cur.markRead(minT, maxT)
 		}
@@ -683,14 +739,10 @@ func (c *KeyCursor) ReadStringBlock(buf *[]StringValue) ([]StringValue, error) {
 			tombstones := cur.r.TombstoneRange(c.key)
 
 			var a []StringValue
-			v, err := cur.r.ReadStringBlockAt(&cur.entry, &a)
+			v, err := c.readStringBlock(cur, &a)
 			if err != nil {
 				return nil, err
 			}
-			if c.col != nil {
-				c.col.GetCounter(stringBlocksDecodedCounter).Add(1)
-				c.col.GetCounter(stringBlocksSizeCounter).Add(int64(cur.entry.Size))
-			}
 
 			// Remove any tombstoned values
 			v = c.filterStringValues(tombstones, v)
@@ -704,6 +756,7 @@ func (c *KeyCursor) ReadStringBlock(buf *[]StringValue) ([]StringValue, error) {
 				v = StringValues(v).Include(minT, maxT)
 				// Merge the remaing values with the existing
 				values = StringValues(v).Merge(values)
+				c.blockMerged()
 			}
 			cur.markRead(minT, maxT)
 		}
@@ -714,6 +767,37 @@ func (c *KeyCursor) ReadStringBlock(buf *[]StringValue) ([]StringValue, error) {
 	return values, err
 }
 
+// readStringBlock decodes the block of l, or copies its values from the block cache
+// if another cursor already decoded it.
+func (c *KeyCursor) readStringBlock(l *location, buf *[]StringValue) ([]StringValue, error) {
+	key := blockKey{r: l.r, offset: l.entry.Offset}
+	if c.blocks != nil {
+		if values, ok := c.blocks.get(key).([]StringValue); ok {
+			c.blockRead()
+			*buf = append((*buf)[:0], values...)
+			return *buf, nil
+		}
+	}
+
+	values, err := l.r.ReadStringBlockAt(&l.entry, buf)
+	if err != nil {
+		return nil, err
+	}
+	if c.col != nil {
+		c.col.GetCounter(stringBlocksDecodedCounter).Add(1)
+		c.col.GetCounter(stringBlocksSizeCounter).Add(int64(l.entry.Size))
+	}
+	c.blockDecoded(&l.entry)
+
+	// The cursor modifies the values it reads, so the cache keeps its own copy.
+	if c.blocks != nil {
+		cached := make([]StringValue, len(values))
+		copy(cached, values)
+		c.blocks.put(key, cached, StringValues(cached).Size())
+	}
+	return values, nil
+}
+
 // ReadBooleanBlock reads the next block as a set of boolean values.
 func (c *KeyCursor) ReadBooleanBlock(buf *[]BooleanValue) ([]BooleanValue, error) {
 	// No matching blocks to decode
@@ -724,14 +808,10 @@ func (c *KeyCursor) ReadBooleanBlock(buf *[]BooleanValue) ([]BooleanValue, error
 	// First block is the oldest block containing the points we're searching for.
 	first := c.current[0]
 	*buf = (*buf)[:0]
-	values, err := first.r.ReadBooleanBlockAt(&first.entry, buf)
+	values, err := c.readBooleanBlock(first, buf)
 	if err != nil {
 		return nil, err
 	}
-	if c.col != nil {
-		c.col.GetCounter(booleanBlocksDecodedCounter).Add(1)
-		c.col.GetCounter(booleanBlocksSizeCounter).Add(int64(first.entry.Size))
-	}
 
 	// Remove values we already read
 	values = BooleanValues(values).Exclude(first.readMin, first.readMax)
@@ -796,14 +876,10 @@ func (c *KeyCursor) ReadBooleanBlock(buf *[]BooleanValue) ([]BooleanValue, error
 
 			tombstones := cur.r.TombstoneRange(c.key)
 			var a []BooleanValue
-			v, err := cur.r.ReadBooleanBlockAt(&cur.entry, &a)
+			v, err := c.readBooleanBlock(cur, &a)
 			if err != nil {
 				return nil, err
 			}
-			if c.col != nil {
-				c.col.GetCounter(booleanBlocksDecodedCounter).Add(1)
-				c.col.GetCounter(booleanBlocksSizeCounter).Add(int64(cur.entry.Size))
-			}
 
 			// Remove any tombstoned values
 			v = c.filterBooleanValues(tombstones, v)
@@ -817,6 +893,7 @@ func (c *KeyCursor) ReadBooleanBlock(buf *[]BooleanValue) ([]BooleanValue, error
 
 				// Merge the remaing values with the existing
 				values = BooleanValues(values).Merge(v)
+				c.blockMerged()
 			}
 			cur.markRead(minT, maxT)
 		}
@@ -860,14 +937,10 @@ func (c *KeyCursor) ReadBooleanBlock(buf *[]BooleanValue) ([]BooleanValue, error
 			tombstones := cur.r.TombstoneRange(c.key)
 
 			var a []BooleanValue
-			v, err := cur.r.ReadBooleanBlockAt(&cur.entry, &a)
+			v, err := c.readBooleanBlock(cur, &a)
 			if err != nil {
 				return nil, err
 			}
-			if c.col != nil {
-				c.col.GetCounter(booleanBlocksDecodedCounter).Add(1)
-				c.col.GetCounter(booleanBlocksSizeCounter).Add(int64(cur.entry.Size))
-			}
 
 			// Remove any tombstoned values
 			v = c.filterBooleanValues(tombstones, v)
@@ -881,6 +954,7 @@ func (c *KeyCursor) ReadBooleanBlock(buf *[]BooleanValue) ([]BooleanValue, error
 				v = BooleanValues(v).Include(minT, maxT)
 				// Merge the remaing values with the existing
 				values = BooleanValues(v).Merge(values)
+				c.blockMerged()
 			}
 			cur.markRead(minT, maxT)
 		}
@@ -890,3 +964,34 @@ func (c *KeyCursor) ReadBooleanBlock(buf *[]BooleanValue) ([]BooleanValue, error
 
 	return values, err
 }
+
+// readBooleanBlock decodes the block of l, or copies its values from the block cache
+// if another cursor already decoded it.
+func (c *KeyCursor) readBooleanBlock(l *location, buf *[]BooleanValue) ([]BooleanValue, error) {
+	key := blockKey{r: l.r, offset: l.entry.Offset}
+	if c.blocks != nil {
+		if values, ok := c.blocks.get(key).([]BooleanValue); ok {
+			c.blockRead()
+			*buf = append((*buf)[:0], values...)
+			return *buf, nil
+		}
+	}
+
+	values, err := l.r.ReadBooleanBlockAt(&l.entry, buf)
+	if err != nil {
+		return nil, err
+	}
+	if c.col != nil {
+		c.col.GetCounter(booleanBlocksDecodedCounter).Add(1)
+		c.col.GetCounter(booleanBlocksSizeCounter).Add(int64(l.entry.Size))
+	}
+	c.blockDecoded(&l.entry)
+
+	// The cursor modifies the values it reads, so the cache keeps its own copy.
+	if c.blocks != nil {
+		cached := make([]BooleanValue, len(values))
+		copy(cached, values)
+		c.blocks.put(key, cached, BooleanValues(cached).Size())
+	}
+	return values, nil
+}
@@ -40,6 +40,10 @@ type TSMFile interface {
 	ReadStringBlockAt(entry *IndexEntry, values *[]StringValue) ([]StringValue, error)
 	ReadBooleanBlockAt(entry *IndexEntry, values *[]BooleanValue) ([]BooleanValue, error)
 
+	// ReadStringBlockMatchingAt returns the string values in the block identified by
+	// entry for which fn returns true.
+	ReadStringBlockMatchingAt(entry *IndexEntry, values *[]StringValue, fn func(string) bool) ([]StringValue, error)
+
 	// Entries returns the index entries for all blocks for the given key.
 	Entries(key []byte) []IndexEntry
 	ReadEntries(key []byte, entries *[]IndexEntry) []IndexEntry
@@ -52,6 +56,10 @@ type TSMFile interface {
 	// key.
 	Contains(key []byte) bool
 
+	// MayContainKey returns false if the file definitely does not contain the
+	// given key.  It is cheaper than Contains but may return false positives.
+	MayContainKey(key []byte) bool
+
 	// OverlapsTimeRange returns true if the time range of the file intersect min and max.
 	OverlapsTimeRange(min, max int64) bool
 
@@ -135,6 +143,10 @@ type TSMFile interface {
 const (
 	statFileStoreBytes = "diskBytes"
 	statFileStoreCount = "numFiles"
+
+	statFileStoreTombstoneFiles = "numTombstoneFiles"
+	statFileStoreTombstoneBytes = "tombstoneBytes"
+	statFileStoreTombstones     = "numTombstones"
 )
 
 var (
@@ -148,6 +160,7 @@ var (
 	stringBlocksSizeCounter      = metrics.MustRegisterCounter("string_blocks_size_bytes", metrics.WithGroup(tsmGroup))
 	booleanBlocksDecodedCounter  = metrics.MustRegisterCounter("boolean_blocks_decoded", metrics.WithGroup(tsmGroup))
 	booleanBlocksSizeCounter     = metrics.MustRegisterCounter("boolean_blocks_size_bytes", metrics.WithGroup(tsmGroup))
+	blocksSummarizedCounter      = metrics.MustRegisterCounter("blocks_summarized", metrics.WithGroup(tsmGroup))
 )
 
 // FileStore is an abstraction around multiple TSM files.
@@ -161,8 +174,15 @@ type FileStore struct {
 	currentGeneration int
 	dir               string
 
+	// coldDir is the directory of the cold storage tier.  Fully compacted TSM files
+	// are moved here once they age out of dir.  It is empty if there is no cold tier.
+	coldDir string
+
 	files []TSMFile
 
+	// blocks caches the blocks decoded by cursors.  It is nil if blocks aren't cached.
+	blocks *blockCache
+
 	logger       *zap.Logger // Logger to be used for important messages
 	traceLogger  *zap.Logger // Logger to be used when trace-logging is on.
 	traceLogging bool
@@ -181,6 +201,14 @@ type FileStat struct {
 	LastModified     int64
 	MinTime, MaxTime int64
 	MinKey, MaxKey   []byte
+	KeyCount         int
+
+	// TombstoneSize is the size of the tombstone file.  TombstoneCount is the number of
+	// tombstones it holds, which cover the time between TombstoneMinTime and
+	// TombstoneMaxTime.
+	TombstoneSize                      uint32
+	TombstoneCount                     int
+	TombstoneMinTime, TombstoneMaxTime int64
 }
 
 // OverlapsTimeRange returns true if the time range of the file intersect min and max.
@@ -216,6 +244,14 @@ func NewFileStore(dir string) *FileStore {
 	return fs
 }
 
+// NewTieredFileStore returns a new instance of FileStore that keeps new TSM files
+// in dir and files that have been moved to the cold tier in coldDir.
+func NewTieredFileStore(dir, coldDir string) *FileStore {
+	fs := NewFileStore(dir)
+	fs.coldDir = coldDir
+	return fs
+}
+
 // enableTraceLogging must be called before the FileStore is opened.
 func (f *FileStore) enableTraceLogging(enabled bool) {
 	f.traceLogging = enabled
@@ -242,12 +278,26 @@ type FileStoreStatistics struct {
 
 // Statistics returns statistics for periodic monitoring.
 func (f *FileStore) Statistics(tags map[string]string) []models.Statistic {
+	// The tombstone counts are cached by each file, so they are summed from the
+	// file stats rather than tracked as they change.
+	var tombstoneFiles, tombstoneBytes, tombstones int64
+	for _, stat := range f.Stats() {
+		if stat.TombstoneSize > 0 {
+			tombstoneFiles++
+			tombstoneBytes += int64(stat.TombstoneSize)
+		}
+		tombstones += int64(stat.TombstoneCount)
+	}
+
 	return []models.Statistic{{
 		Name: "tsm1_filestore",
 		Tags: tags,
 		Values: map[string]interface{}{
-			statFileStoreBytes: atomic.LoadInt64(&f.stats.DiskBytes),
-			statFileStoreCount: atomic.LoadInt64(&f.stats.FileCount),
+			statFileStoreBytes:          atomic.LoadInt64(&f.stats.DiskBytes),
+			statFileStoreCount:          atomic.LoadInt64(&f.stats.FileCount),
+			statFileStoreTombstoneFiles: tombstoneFiles,
+			statFileStoreTombstoneBytes: tombstoneBytes,
+			statFileStoreTombstones:     tombstones,
 		},
 	}}
 }
@@ -484,6 +534,12 @@ func (f *FileStore) Open() error {
 		return err
 	}
 
+	if f.coldDir != "" {
+		if files, err = f.mergeColdFiles(files); err != nil {
+			return err
+		}
+	}
+
 	// struct to hold the result of opening each reader in a goroutine
 	type res struct {
 		r   *TSMReader
@@ -548,6 +604,40 @@ func (f *FileStore) Open() error {
 	return nil
 }
 
+// mergeColdFiles appends the TSM files stored in the cold tier to the hot
+// files.  If a file exists in both tiers, the process stopped while the file
+// was being moved to the cold tier after the cold copy was made durable, so
+// the copy left in the hot tier is removed.
+func (f *FileStore) mergeColdFiles(hot []string) ([]string, error) {
+	cold, err := filepath.Glob(filepath.Join(f.coldDir, fmt.Sprintf("*.%s", TSMFileExtension)))
+	if err != nil {
+		return nil, err
+	}
+
+	coldNames := make(map[string]struct{}, len(cold))
+	for _, fn := range cold {
+		coldNames[filepath.Base(fn)] = struct{}{}
+	}
+
+	files := make([]string, 0, len(hot)+len(cold))
+	for _, fn := range hot {
+		if _, ok := coldNames[filepath.Base(fn)]; ok {
+			f.logger.Info(fmt.Sprintf("removing %s already moved to the cold tier", fn))
+			if err := os.RemoveAll(fn); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		files = append(files, fn)
+	}
+	return append(files, cold...), nil
+}
+
+// IsCold returns true if path is stored in the cold tier.
+func (f *FileStore) IsCold(path string) bool {
+	return f.coldDir != "" && filepath.Dir(path) == filepath.Clean(f.coldDir)
+}
+
 // Close closes the file store.
 func (f *FileStore) Close() error {
 	f.mu.Lock()
@@ -555,6 +645,7 @@ func (f *FileStore) Close() error {
 
 	for _, file := range f.files {
 		file.Close()
+		f.blocks.evictFile(file)
 	}
 
 	f.lastFileStats = nil
@@ -575,7 +666,7 @@ func (f *FileStore) Read(key []byte, t int64) ([]Value, error) {
 
 	for _, f := range f.files {
 		// Can this file possibly contain this key and timestamp?
-		if !f.Contains(key) {
+		if !f.MayContainKey(key) || !f.Contains(key) {
 			continue
 		}
 
@@ -611,11 +702,86 @@ func (f *FileStore) TSMReader(path string) *TSMReader {
 	return nil
 }
 
+// keyMaxTime returns the max time of the blocks of key in the TSM files, and false if
+// none of the files hold key.
+func (f *FileStore) keyMaxTime(key []byte) (int64, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	var (
+		buf []IndexEntry
+		max int64
+		ok  bool
+	)
+	for _, fd := range f.files {
+		// Skip files that cannot raise the max time without searching their index.
+		if _, fileMax := fd.TimeRange(); ok && fileMax <= max {
+			continue
+		} else if !fd.MayContainKey(key) {
+			continue
+		}
+
+		entries := fd.ReadEntries(key, &buf)
+		if n := len(entries); n > 0 && (!ok || entries[n-1].MaxTime > max) {
+			max, ok = entries[n-1].MaxTime, true
+		}
+	}
+	return max, ok
+}
+
+// keyOverlapsTimeRange returns true if a block of key in the TSM files overlaps min and
+// max, ignoring blocks whose values are all deleted.
+func (f *FileStore) keyOverlapsTimeRange(key []byte, min, max int64) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	var buf []IndexEntry
+	for _, fd := range f.files {
+		if !fd.OverlapsTimeRange(min, max) || !fd.MayContainKey(key) {
+			continue
+		}
+
+		tombstones := fd.TombstoneRange(key)
+	ENTRIES:
+		for _, ie := range fd.ReadEntries(key, &buf) {
+			if !ie.OverlapsTimeRange(min, max) {
+				continue
+			}
+			for _, t := range tombstones {
+				if t.Min <= ie.MinTime && t.Max >= ie.MaxTime {
+					continue ENTRIES
+				}
+			}
+			return true
+		}
+	}
+	return false
+}
+
 // KeyCursor returns a KeyCursor for key and t across the files in the FileStore.
 func (f *FileStore) KeyCursor(ctx context.Context, key []byte, t int64, ascending bool) *KeyCursor {
 	f.mu.RLock()
 	defer f.mu.RUnlock()
-	return newKeyCursor(ctx, f, key, t, ascending)
+	return newKeyCursor(ctx, f.files, f.blocks, key, t, ascending)
+}
+
+// pinFiles returns a view of each current TSM file that later deletes do not change,
+// along with the files themselves.  The files are marked as in-use, which prevents them
+// from being removed until they are unreferenced.
+func (f *FileStore) pinFiles() (views, files []TSMFile) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	views = make([]TSMFile, len(f.files))
+	files = make([]TSMFile, len(f.files))
+	for i, fd := range f.files {
+		fd.Ref()
+		files[i], views[i] = fd, fd
+		if r, ok := fd.(*TSMReader); ok {
+			views[i] = r.readView()
+		}
+	}
+	return views, files
 }
 
 // Stats returns the stats of the underlying files, preferring the cached version if it is still valid.
@@ -720,6 +886,7 @@ func (f *FileStore) replace(oldFiles, newFiles []string, updatedFn func(r []TSMF
 		for _, remove := range oldFiles {
 			if remove == file.Path() {
 				keep = false
+				f.blocks.evictFile(file)
 
 				// If queries are running against this file, then we need to move it out of the
 				// way and let them complete.  We'll then delete the original file to avoid
@@ -771,6 +938,12 @@ func (f *FileStore) replace(oldFiles, newFiles []string, updatedFn func(r []TSMF
 		return err
 	}
 
+	if f.coldDir != "" {
+		if err := syncDir(f.coldDir); err != nil {
+			return err
+		}
+	}
+
 	// Tell the purger about our in-use files we need to remove
 	f.purger.add(inuse)
 
@@ -913,10 +1086,11 @@ func (f *FileStore) cost(key []byte, min, max int64) query.IteratorCost {
 // locations returns the files and index blocks for a key and time.  ascending indicates
 // whether the key will be scan in ascending time order or descenging time order.
 // This function assumes the read-lock has been taken.
-func (f *FileStore) locations(key []byte, t int64, ascending bool) []*location {
+// locations returns the locations of the blocks of key in files.
+func locations(files []TSMFile, key []byte, t int64, ascending bool) []*location {
 	var cache []IndexEntry
-	locations := make([]*location, 0, len(f.files))
-	for _, fd := range f.files {
+	locations := make([]*location, 0, len(files))
+	for _, fd := range files {
 		minTime, maxTime := fd.TimeRange()
 
 		// If we ascending and the max time of the file is before where we want to start
@@ -928,6 +1102,11 @@ func (f *FileStore) locations(key []byte, t int64, ascending bool) []*location {
 		} else if !ascending && minTime > t {
 			continue
 		}
+
+		// Skip files that cannot contain the key without searching their index.
+		if !fd.MayContainKey(key) {
+			continue
+		}
 		tombstones := fd.TombstoneRange(key)
 
 		// This file could potential contain points we are looking for so find the blocks for
@@ -979,6 +1158,11 @@ func (f *FileStore) locations(key []byte, t int64, ascending bool) []*location {
 
 // CreateSnapshot creates hardlinks for all tsm and tombstone files
 // in the path provided.
+//
+// Files stored in the cold tier are hardlinked into a directory with the
+// same name within the cold tier, since hardlinks can not span devices, and
+// symlinked from the returned path.  The returned path therefore always
+// contains every file of the shard.  RemoveSnapshot removes both directories.
 func (f *FileStore) CreateSnapshot() (string, error) {
 	f.traceLogger.Info(fmt.Sprintf("Creating snapshot in %s", f.dir))
 	files := f.Files()
@@ -997,23 +1181,58 @@ func (f *FileStore) CreateSnapshot() (string, error) {
 		return "", err
 	}
 
+	var coldTmpPath string
 	for _, tsmf := range files {
-		newpath := filepath.Join(tmpPath, filepath.Base(tsmf.Path()))
+		dir := tmpPath
+		if f.IsCold(tsmf.Path()) {
+			if coldTmpPath == "" {
+				coldTmpPath = filepath.Join(f.coldDir, filepath.Base(tmpPath))
+				if err := os.Mkdir(coldTmpPath, 0777); err != nil {
+					return "", err
+				}
+			}
+			dir = coldTmpPath
+		}
+
+		newpath := filepath.Join(dir, filepath.Base(tsmf.Path()))
 		if err := os.Link(tsmf.Path(), newpath); err != nil {
 			return "", fmt.Errorf("error creating tsm hard link: %q", err)
 		}
 		// Check for tombstones and link those as well
 		for _, tf := range tsmf.TombstoneFiles() {
-			newpath := filepath.Join(tmpPath, filepath.Base(tf.Path))
+			newpath := filepath.Join(dir, filepath.Base(tf.Path))
 			if err := os.Link(tf.Path, newpath); err != nil {
 				return "", fmt.Errorf("error creating tombstone hard link: %q", err)
 			}
 		}
 	}
 
+	if coldTmpPath != "" {
+		links, err := ioutil.ReadDir(coldTmpPath)
+		if err != nil {
+			return "", err
+		}
+		for _, fi := range links {
+			if err := os.Symlink(filepath.Join(coldTmpPath, fi.Name()), filepath.Join(tmpPath, fi.Name())); err != nil {
+				return "", fmt.Errorf("error creating cold tier symlink: %q", err)
+			}
+		}
+	}
+
 	return tmpPath, nil
 }
 
+// RemoveSnapshot removes a snapshot directory created by CreateSnapshot along
+// with any hardlinks that were created for it within the cold tier.
+func (f *FileStore) RemoveSnapshot(path string) error {
+	if f.coldDir != "" {
+		if err := os.RemoveAll(filepath.Join(f.coldDir, filepath.Base(path))); err != nil {
+			return err
+		}
+	}
+	return os.RemoveAll(path)
+}
+
 // ParseTSMFileName parses the generation and sequence from a TSM file name.
 func ParseTSMFileName(name string) (int, int, error) {
 	base := filepath.Base(name)
@@ -1058,6 +1277,13 @@ type KeyCursor struct {
 	ctx context.Context
 	col *metrics.Group
 
+	// stats counts the blocks read by the cursor when it's created with a context
+	// from newContextWithCursorStats.
+	stats *query.IteratorStats
+
+	// blocks caches decoded blocks.  It is nil if blocks aren't cached.
+	blocks *blockCache
+
 	// pos is the index within seeks.  Based on ascending, it will increment or
 	// decrement through the size of seeks slice.
 	pos       int
@@ -1098,7 +1324,7 @@ func (a descLocations) Len() int      { return len(a) }
 func (a descLocations) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
 func (a descLocations) Less(i, j int) bool {
 	if a[i].entry.OverlapsTimeRange(a[j].entry.MinTime, a[j].entry.MaxTime) {
-		return a[i].r.Path() < a[j].r.Path()
+		return filepath.Base(a[i].r.Path()) < filepath.Base(a[j].r.Path())
 	}
 	return a[i].entry.MaxTime < a[j].entry.MaxTime
 }
@@ -1110,19 +1336,21 @@ func (a ascLocations) Len() int      { return len(a) }
 func (a ascLocations) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
 func (a ascLocations) Less(i, j int) bool {
 	if a[i].entry.OverlapsTimeRange(a[j].entry.MinTime, a[j].entry.MaxTime) {
-		return a[i].r.Path() < a[j].r.Path()
+		return filepath.Base(a[i].r.Path()) < filepath.Base(a[j].r.Path())
 	}
 	return a[i].entry.MinTime < a[j].entry.MinTime
 }
 
 // newKeyCursor returns a new instance of KeyCursor.
 // This function assumes the read-lock has been taken.
-func newKeyCursor(ctx context.Context, fs *FileStore, key []byte, t int64, ascending bool) *KeyCursor {
+func newKeyCursor(ctx context.Context, files []TSMFile, blocks *blockCache, key []byte, t int64, ascending bool) *KeyCursor {
 	c := &KeyCursor{
 		key:       key,
-		seeks:     fs.locations(key, t, ascending),
+		seeks:     locations(files, key, t, ascending),
 		ctx:       ctx,
 		col:       metrics.GroupFromContext(ctx),
+		stats:     cursorStatsFromContext(ctx),
+		blocks:    blocks,
 		ascending: ascending,
 	}
 
@@ -1307,6 +1535,72 @@ func (c *KeyCursor) nextDescending() {
 	}
 }
 
+// peekStats returns the index entry of the next block if the block lies within min and
+// max and can be summarised by its statistics instead of being decoded.  Blocks without
+// statistics, blocks that were partially read or are partially deleted, and blocks that
+// overlap other unread blocks must be decoded.
+func (c *KeyCursor) peekStats(min, max int64) *IndexEntry {
+	if len(c.current) == 0 {
+		return nil
+	}
+
+	first := c.current[0]
+	e := &first.entry
+	if !e.HasStats() || e.MinTime < min || e.MaxTime > max {
+		return nil
+	} else if e.OverlapsTimeRange(first.readMin, first.readMax) {
+		return nil
+	}
+
+	for _, cur := range c.current[1:] {
+		if !cur.read() && cur.entry.OverlapsTimeRange(e.MinTime, e.MaxTime) {
+			return nil
+		}
+	}
+
+	for _, t := range first.r.TombstoneRange(c.key) {
+		if t.Overlaps(e.MinTime, e.MaxTime) {
+			return nil
+		}
+	}
+	return e
+}
+
+// skipBlock marks the block returned by peekStats as read without decoding it and
+// moves the cursor to the next block.
+func (c *KeyCursor) skipBlock() {
+	first := c.current[0]
+	first.markRead(first.entry.MinTime, first.entry.MaxTime)
+	if c.col != nil {
+		c.col.GetCounter(blocksSummarizedCounter).Add(1)
+	}
+	c.blockRead()
+	c.Next()
+}
+
+// blockRead counts a block read by the cursor without decoding it.
+func (c *KeyCursor) blockRead() {
+	if c.stats != nil {
+		c.stats.BlockReadN++
+	}
+}
+
+// blockDecoded counts the block of e as read and decoded by the cursor.
+func (c *KeyCursor) blockDecoded(e *IndexEntry) {
+	if c.stats != nil {
+		c.stats.BlockReadN++
+		c.stats.BlockDecodeN++
+		c.stats.BlockBytes += int64(e.Size)
+	}
+}
+
+// blockMerged counts a block merged with the values of overlapping blocks.
+func (c *KeyCursor) blockMerged() {
+	if c.stats != nil {
+		c.stats.BlockMergeN++
+	}
+}
+
 func (c *KeyCursor) filterFloatValues(tombstones []TimeRange, values FloatValues) FloatValues {
 	for _, t := range tombstones {
 		values = values.Exclude(t.Min, t.Max)
@@ -1401,9 +1695,11 @@ func (p *purger) purge() {
 
 type tsmReaders []TSMFile
 
-func (a tsmReaders) Len() int           { return len(a) }
-func (a tsmReaders) Less(i, j int) bool { return a[i].Path() < a[j].Path() }
-func (a tsmReaders) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a tsmReaders) Len() int { return len(a) }
+func (a tsmReaders) Less(i, j int) bool {
+	return filepath.Base(a[i].Path()) < filepath.Base(a[j].Path())
+}
+func (a tsmReaders) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
 
 type stream struct {
 	c chan seriesKey
@@ -535,6 +535,69 @@ func TestFileStore_SeekToAsc_BeforeStart_OverlapString(t *testing.T) {
 	}
 }
 
+// Tests that matching string values skips blocks without matches and only matches
+// overlapping blocks once they are merged.
+func TestFileStore_ReadStringBlockMatching(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+	fs := tsm1.NewFileStore(dir)
+
+	// Setup 5 files
+	data := []keyValues{
+		keyValues{"cpu", []tsm1.Value{tsm1.NewValue(0, "a"), tsm1.NewValue(1, "b")}},
+		keyValues{"cpu", []tsm1.Value{tsm1.NewValue(2, "b")}},
+		keyValues{"cpu", []tsm1.Value{tsm1.NewValue(1, "a"), tsm1.NewValue(3, "a")}},
+		keyValues{"cpu", []tsm1.Value{tsm1.NewValue(10, "a")}},
+		keyValues{"cpu", []tsm1.Value{tsm1.NewValue(11, "b")}},
+	}
+
+	files, err := newFiles(dir, data...)
+	if err != nil {
+		t.Fatalf("unexpected error creating files: %v", err)
+	}
+
+	fs.Replace(nil, files)
+
+	for _, ascending := range []bool{true, false} {
+		seek := int64(0)
+		exp := []tsm1.Value{data[1].values[0], data[4].values[0]}
+		if !ascending {
+			seek = 11
+			exp = []tsm1.Value{data[4].values[0], data[1].values[0]}
+		}
+
+		buf := make([]tsm1.StringValue, 1000)
+		c := fs.KeyCursor(context.Background(), []byte("cpu"), seek, ascending)
+
+		var got []tsm1.Value
+		for {
+			values, err := c.ReadStringBlockMatching(&buf, func(v string) bool { return v == "b" })
+			if err != nil {
+				t.Fatalf("unexpected error reading values: %v", err)
+			}
+			if len(values) == 0 {
+				break
+			}
+
+			if ascending {
+				for _, v := range values {
+					got = append(got, v)
+				}
+			} else {
+				for i := len(values) - 1; i >= 0; i-- {
+					got = append(got, values[i])
+				}
+			}
+			c.Next()
+		}
+		c.Close()
+
+		if !reflect.DeepEqual(got, exp) {
+			t.Fatalf("unexpected values (ascending=%v):\n\tgot: %v\n\texp: %v\n", ascending, got, exp)
+		}
+	}
+}
+
 // Tests that blocks with a lower min time in later files are not returned
 // more than once causing unsorted results
 func TestFileStore_SeekToAsc_OverlapMinFloat(t *testing.T) {
@@ -2706,6 +2769,137 @@ func TestFileStore_CreateSnapshot(t *testing.T) {
 	}
 }
 
+func TestFileStore_Open_ColdTier(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+	coldDir := MustTempDir()
+	defer os.RemoveAll(coldDir)
+
+	// Create 3 TSM files...
+	data := []keyValues{
+		keyValues{"cpu", []tsm1.Value{tsm1.NewValue(0, 1.0)}},
+		keyValues{"cpu", []tsm1.Value{tsm1.NewValue(1, 2.0)}},
+		keyValues{"mem", []tsm1.Value{tsm1.NewValue(0, 1.0)}},
+	}
+
+	files, err := newFileDir(dir, data...)
+	if err != nil {
+		fatal(t, "creating test files", err)
+	}
+
+	// Move the first file to the cold tier.
+	if err := os.Rename(files[0], filepath.Join(coldDir, filepath.Base(files[0]))); err != nil {
+		fatal(t, "moving file", err)
+	}
+
+	// Copy the second file to the cold tier, as if the process stopped before the
+	// hot copy was removed.
+	b, err := ioutil.ReadFile(files[1])
+	if err != nil {
+		fatal(t, "reading file", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(coldDir, filepath.Base(files[1])), b, 0666); err != nil {
+		fatal(t, "copying file", err)
+	}
+
+	fs := tsm1.NewTieredFileStore(dir, coldDir)
+	if err := fs.Open(); err != nil {
+		fatal(t, "opening file store", err)
+	}
+	defer fs.Close()
+
+	if got, exp := fs.Count(), 3; got != exp {
+		t.Fatalf("file count mismatch: got %v, exp %v", got, exp)
+	}
+
+	if got, exp := fs.CurrentGeneration(), 4; got != exp {
+		t.Fatalf("current ID mismatch: got %v, exp %v", got, exp)
+	}
+
+	if _, err := os.Stat(files[1]); !os.IsNotExist(err) {
+		t.Fatalf("expected hot copy of moved file to be removed: %v", err)
+	}
+
+	exp := []string{
+		filepath.Join(coldDir, filepath.Base(files[0])),
+		filepath.Join(coldDir, filepath.Base(files[1])),
+		files[2],
+	}
+	for i, f := range fs.Files() {
+		if got, exp := f.Path(), exp[i]; got != exp {
+			t.Fatalf("file path mismatch: got %v, exp %v", got, exp)
+		}
+	}
+
+	buf := make([]tsm1.FloatValue, 1000)
+	c := fs.KeyCursor(context.Background(), []byte("cpu"), 0, true)
+	values, err := c.ReadFloatBlock(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error reading values: %v", err)
+	}
+
+	if got, exp := len(values), 1; got != exp {
+		t.Fatalf("value length mismatch: got %v, exp %v", got, exp)
+	}
+}
+
+func TestFileStore_CreateSnapshot_ColdTier(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+	coldDir := MustTempDir()
+	defer os.RemoveAll(coldDir)
+
+	// Setup 3 files
+	data := []keyValues{
+		keyValues{"cpu", []tsm1.Value{tsm1.NewValue(0, 1.0)}},
+		keyValues{"cpu", []tsm1.Value{tsm1.NewValue(1, 2.0)}},
+		keyValues{"cpu", []tsm1.Value{tsm1.NewValue(2, 3.0)}},
+	}
+
+	files, err := newFileDir(dir, data...)
+	if err != nil {
+		t.Fatalf("unexpected error creating files: %v", err)
+	}
+
+	// Move the first file to the cold tier.
+	if err := os.Rename(files[0], filepath.Join(coldDir, filepath.Base(files[0]))); err != nil {
+		fatal(t, "moving file", err)
+	}
+
+	fs := tsm1.NewTieredFileStore(dir, coldDir)
+	if err := fs.Open(); err != nil {
+		fatal(t, "opening file store", err)
+	}
+	defer fs.Close()
+
+	s, e := fs.CreateSnapshot()
+	if e != nil {
+		t.Fatal(e)
+	}
+
+	for _, f := range fs.Files() {
+		p := filepath.Join(s, filepath.Base(f.Path()))
+		if _, err := os.Stat(p); os.IsNotExist(err) {
+			t.Fatalf("unable to find file %q", p)
+		}
+	}
+
+	coldSnapshot := filepath.Join(coldDir, filepath.Base(s))
+	if _, err := os.Stat(filepath.Join(coldSnapshot, filepath.Base(files[0]))); err != nil {
+		t.Fatalf("unable to find cold tier hard link: %v", err)
+	}
+
+	if err := fs.RemoveSnapshot(s); err != nil {
+		t.Fatalf("unexpected error removing snapshot: %v", err)
+	}
+
+	for _, p := range []string{s, coldSnapshot} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Fatalf("expected snapshot dir %q to be removed: %v", p, err)
+		}
+	}
+}
+
 func newFileDir(dir string, values ...keyValues) ([]string, error) {
 	var files []string
 
@@ -0,0 +1,227 @@
+package tsm1
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxql"
+)
+
+// ingestSeriesBatchSize is the number of series added to the index at a time when
+// ingesting TSM files.
+const ingestSeriesBatchSize = 10000
+
+// IngestTSMFiles attaches prebuilt TSM files, such as those written by a Builder, to
+// the engine.  The field types of every file are validated against each other and
+// against the existing fields of the shard, and the fields and series of the files are
+// added to the index, before any file is attached.  The files are then copied into the
+// shard and added to the file store in a single step, and the copies are removed if
+// they can't be attached.  The source files are not modified.
+func (e *Engine) IngestTSMFiles(paths []string) error {
+	if e.ReadOnly() {
+		return tsdb.ErrShardReadOnly
+	}
+
+	fields, err := e.ingestFieldTypes(paths)
+	if err != nil {
+		return err
+	}
+
+	for name, m := range fields {
+		mf := e.fieldset.CreateFieldsIfNotExists([]byte(name))
+		for field, typ := range m {
+			if err := mf.CreateFieldIfNotExists([]byte(field), typ, false); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := e.ingestSeries(paths); err != nil {
+		return err
+	}
+
+	// Copy files while under lock to prevent reopening.
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var newFiles []string
+	for _, path := range paths {
+		tmp, err := e.copyIngestFile(path)
+		if err != nil {
+			removeIngestFiles(newFiles)
+			return err
+		}
+		newFiles = append(newFiles, tmp)
+	}
+
+	if err := syncDir(e.path); err != nil {
+		removeIngestFiles(newFiles)
+		return err
+	}
+
+	if err := e.FileStore.Replace(nil, newFiles); err != nil {
+		removeIngestFiles(newFiles)
+		return err
+	}
+	return nil
+}
+
+// removeIngestFiles removes the copies of ingested TSM files that couldn't be attached,
+// including any already renamed by the file store.
+func removeIngestFiles(files []string) {
+	for _, f := range files {
+		os.Remove(f)
+		os.Remove(f[:len(f)-len(".tmp")])
+	}
+}
+
+// ingestFieldTypes returns the type of each field in the TSM files at paths, keyed by
+// measurement name.  It returns an error if a field has different types in different
+// files, or a different type than the existing field of the shard.
+func (e *Engine) ingestFieldTypes(paths []string) (map[string]map[string]influxql.DataType, error) {
+	fields := make(map[string]map[string]influxql.DataType)
+	for _, path := range paths {
+		if err := func() error {
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+
+			r, err := NewTSMReader(f)
+			if err != nil {
+				return fmt.Errorf("%s: %s", path, err)
+			}
+			defer r.Close()
+
+			for i, n := 0, r.KeyCount(); i < n; i++ {
+				key, blockType := r.KeyAt(i)
+				seriesKey, field := SeriesAndFieldFromCompositeKey(key)
+				if len(field) == 0 {
+					return fmt.Errorf("%s: missing field key: %q", path, key)
+				}
+				name := string(tsdb.MeasurementFromSeriesKey(seriesKey))
+
+				typ, err := tsmFieldTypeToInfluxQLDataType(blockType)
+				if err != nil {
+					return fmt.Errorf("%s: %s", path, err)
+				}
+
+				if fields[name] == nil {
+					fields[name] = make(map[string]influxql.DataType)
+				}
+				if existing, ok := fields[name][string(field)]; ok && existing != typ {
+					return fmt.Errorf("%s: %s: field %q on measurement %q is type %s, already exists as type %s",
+						path, tsdb.ErrFieldTypeConflict, field, name, typ, existing)
+				}
+				fields[name][string(field)] = typ
+
+				if mf := e.fieldset.Fields(name); mf != nil {
+					if f := mf.FieldBytes(field); f != nil && f.Type != typ {
+						return fmt.Errorf("%s: %s: field %q on measurement %q is type %s, already exists as type %s",
+							path, tsdb.ErrFieldTypeConflict, field, name, typ, f.Type)
+					}
+				}
+			}
+			return nil
+		}(); err != nil {
+			return nil, err
+		}
+	}
+	return fields, nil
+}
+
+// copyIngestFile copies the TSM file at path into the shard as a new generation and
+// returns the path of the copy, which has a temp extension until it is added to the
+// file store.
+func (e *Engine) copyIngestFile(path string) (string, error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	filename := fmt.Sprintf("%09d-%09d.%s", e.FileStore.NextGeneration(), 1, TSMFileExtension)
+	tmp := filepath.Join(e.path, filename) + ".tmp"
+
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_RDWR|os.O_EXCL, 0666)
+	if err != nil {
+		return "", err
+	}
+	defer dst.Close()
+
+	if _, err := io.Copy(dst, src); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+
+	// Sync to disk & close.
+	if err := dst.Sync(); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+
+	return tmp, nil
+}
+
+// ingestSeries adds the series of the TSM files to be ingested to the index.
+func (e *Engine) ingestSeries(files []string) error {
+	var (
+		keys, names [][]byte
+		tagsSlice   []models.Tags
+	)
+	flush := func() error {
+		if len(keys) == 0 {
+			return nil
+		}
+		if err := e.currentIndex().CreateSeriesListIfNotExists(keys, names, tagsSlice); err != nil {
+			return err
+		}
+		keys, names, tagsSlice = nil, nil, nil
+		return nil
+	}
+
+	seen := make(map[string]struct{})
+	for _, f := range files {
+		fd, err := os.Open(f)
+		if err != nil {
+			return err
+		}
+
+		r, err := NewTSMReader(fd)
+		if err != nil {
+			return err
+		}
+
+		for i, n := 0, r.KeyCount(); i < n; i++ {
+			key, _ := r.KeyAt(i)
+			seriesKey, _ := SeriesAndFieldFromCompositeKey(key)
+			if _, ok := seen[string(seriesKey)]; ok {
+				continue
+			}
+			seen[string(seriesKey)] = struct{}{}
+
+			// KeyAt returns a slice of the index, so the key must be copied.
+			seriesKey = append([]byte(nil), seriesKey...)
+			keys = append(keys, seriesKey)
+			tags, _ := models.ParseTags(seriesKey)
+			names = append(names, tsdb.MeasurementFromSeriesKey(seriesKey))
+			tagsSlice = append(tagsSlice, tags)
+
+			if len(keys) >= ingestSeriesBatchSize {
+				if err := flush(); err != nil {
+					r.Close()
+					return err
+				}
+			}
+		}
+
+		if err := r.Close(); err != nil {
+			return err
+		}
+	}
+	return flush()
+}
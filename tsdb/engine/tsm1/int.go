@@ -15,15 +15,25 @@ package tsm1
 // or 8 byte uncompressed integers.  The 4 high bits of the first byte indicate the encoding type
 // for the remaining bytes.
 //
-// There are currently two encoding types that can be used with room for 16 total.  These additional
-// encoding slots are reserved for future use.  One improvement to be made is to use a patched
+// Values whose deltas change slowly, such as counters incremented at a near constant rate, may
+// instead be stored as delta-of-deltas.  The first value is stored uncompressed, followed by the
+// number of remaining values as a varint and a bit stream holding the zig zag encoded difference
+// between each delta and the prior delta.  Each difference is prefixed by up to 5 bits selecting
+// its width: 0 for a repeated delta, 10 for 4 bits, 110 for 8 bits, 1110 for 16 bits, 11110 for
+// 32 bits and 11111 for 64 bits.  This encoding is only used when it is smaller than the others.
+//
+// There are currently four encoding types that can be used with room for 16 total.  These additional
+// encoding slots are reserved for future use.  Readers that predate an encoding type reject blocks
+// using it as an unknown encoding.  One improvement to be made is to use a patched
 // encoding such as PFOR if only a small number of values exceed the max compressed value range.  This
 // should improve compression ratios with very large integers near the ends of the int64 range.
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 
+	"github.com/dgryski/go-bitstream"
 	"github.com/jwilder/encoding/simple8b"
 )
 
@@ -34,8 +44,14 @@ const (
 	intCompressedSimple = 1
 	// intCompressedRLE is a run-length encoding format
 	intCompressedRLE = 2
+	// intCompressedDeltaDelta is a bit-packed delta-of-delta format
+	intCompressedDeltaDelta = 3
 )
 
+// intDeltaDeltaWidths holds the bit widths of the delta-of-deltas, indexed by the number
+// of leading one bits of their prefix.
+var intDeltaDeltaWidths = [...]uint{0, 4, 8, 16, 32, 64}
+
 // IntegerEncoder encodes int64s into byte slices.
 type IntegerEncoder struct {
 	prev   int64
@@ -82,14 +98,34 @@ func (e *IntegerEncoder) Bytes() ([]byte, error) {
 		return e.encodeRLE()
 	}
 
+	var b []byte
+	var err error
+	if e.packable() {
+		b, err = e.encodePacked()
+	} else {
+		b, err = e.encodeUncompressed()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Delta-of-deltas are decoded a bit at a time, which is slower than the other formats,
+	// so they are only used when they save at least a word.
+	if len(e.values) > 2 && e.deltaDeltaSize()+8 <= len(b) {
+		return e.encodeDeltaDelta()
+	}
+	return b, nil
+}
+
+// packable returns true if all the values can be encoded using the packed format.
+func (e *IntegerEncoder) packable() bool {
 	for _, v := range e.values {
 		// Value is too large to encode using packed format
 		if v > simple8b.MaxValue {
-			return e.encodeUncompressed()
+			return false
 		}
 	}
-
-	return e.encodePacked()
+	return true
 }
 
 func (e *IntegerEncoder) encodeRLE() ([]byte, error) {
@@ -138,6 +174,64 @@ func (e *IntegerEncoder) encodePacked() ([]byte, error) {
 	return b, nil
 }
 
+// deltaDeltas calls fn with the prefix and width of each zig zag encoded delta-of-delta.
+func (e *IntegerEncoder) deltaDeltas(fn func(v uint64, prefix uint64, prefixBits, width uint)) {
+	var prev int64
+	for _, v := range e.values[1:] {
+		delta := ZigZagDecode(v)
+		dd := ZigZagEncode(delta - prev)
+		prev = delta
+
+		// The prefix holds one bit for each step up in width, terminated by a zero
+		// bit unless it is the widest.
+		var n uint
+		for n < uint(len(intDeltaDeltaWidths)-1) && dd>>intDeltaDeltaWidths[n] != 0 {
+			n++
+		}
+		if n < uint(len(intDeltaDeltaWidths)-1) {
+			fn(dd, 1<<(n+1)-2, n+1, intDeltaDeltaWidths[n])
+		} else {
+			fn(dd, 1<<n-1, n, intDeltaDeltaWidths[n])
+		}
+	}
+}
+
+// deltaDeltaSize returns the number of bytes needed to encode the values as delta-of-deltas.
+func (e *IntegerEncoder) deltaDeltaSize() int {
+	var bits uint
+	e.deltaDeltas(func(_ uint64, _ uint64, prefixBits, width uint) {
+		bits += prefixBits + width
+	})
+
+	var b [binary.MaxVarintLen64]byte
+	return 1 + 8 + binary.PutUvarint(b[:], uint64(len(e.values)-1)) + int(bits+7)/8
+}
+
+func (e *IntegerEncoder) encodeDeltaDelta() ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, 0, e.deltaDeltaSize()))
+
+	var b [9 + binary.MaxVarintLen64]byte
+	// 4 high bits of first byte store the encoding type for the block
+	b[0] = byte(intCompressedDeltaDelta) << 4
+	// The first value
+	binary.BigEndian.PutUint64(b[1:9], e.values[0])
+	// The number of delta-of-deltas that follow
+	i := 9 + binary.PutUvarint(b[9:], uint64(len(e.values)-1))
+	buf.Write(b[:i])
+
+	bw := bitstream.NewWriter(buf)
+	e.deltaDeltas(func(v uint64, prefix uint64, prefixBits, width uint) {
+		bw.WriteBits(prefix, int(prefixBits))
+		if width > 0 {
+			bw.WriteBits(v, int(width))
+		}
+	})
+	if err := bw.Flush(bitstream.Zero); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 func (e *IntegerEncoder) encodeUncompressed() ([]byte, error) {
 	if len(e.values) == 0 {
 		return nil, nil
@@ -168,6 +262,13 @@ type IntegerDecoder struct {
 
 	// The delta value for a run-length encoded byte slice
 	rleDelta uint64
+
+	// The bit stream, count of remaining values and last delta of a delta-of-delta
+	// encoded byte slice
+	br      BitReader
+	ddCount uint64
+	ddDelta int64
+
 	encoding byte
 	err      error
 }
@@ -189,6 +290,8 @@ func (d *IntegerDecoder) SetBytes(b []byte) {
 
 	d.rleFirst = 0
 	d.rleDelta = 0
+	d.ddCount = 0
+	d.ddDelta = 0
 	d.err = nil
 }
 
@@ -208,6 +311,8 @@ func (d *IntegerDecoder) Next() bool {
 			d.decodePacked()
 		case intCompressedRLE:
 			d.decodeRLE()
+		case intCompressedDeltaDelta:
+			d.decodeDeltaDelta()
 		default:
 			d.err = fmt.Errorf("unknown encoding %v", d.encoding)
 		}
@@ -307,6 +412,78 @@ func (d *IntegerDecoder) decodePacked() {
 	d.bytes = d.bytes[8:]
 }
 
+func (d *IntegerDecoder) decodeDeltaDelta() {
+	if len(d.bytes) == 0 {
+		return
+	}
+
+	// The first value is always unencoded, followed by the count of delta-of-deltas
+	if d.first {
+		if len(d.bytes) < 8 {
+			d.err = fmt.Errorf("IntegerDecoder: not enough data to decode delta-of-delta starting value")
+			return
+		}
+
+		count, n := binary.Uvarint(d.bytes[8:])
+		if n <= 0 {
+			d.err = fmt.Errorf("IntegerDecoder: invalid delta-of-delta count")
+			return
+		}
+
+		d.first = false
+		d.values[0] = binary.BigEndian.Uint64(d.bytes[0:8])
+		d.n = 1
+		d.i = 0
+		d.ddCount = count
+		d.br.Reset(d.bytes[8+n:])
+		if count == 0 {
+			d.bytes = nil
+		}
+		return
+	}
+
+	// Decode the next run of deltas into the values buffer, which is read
+	// the same way as packed values.
+	n := len(d.values)
+	if d.ddCount < uint64(n) {
+		n = int(d.ddCount)
+	}
+	for j := 0; j < n; j++ {
+		var prefix int
+		for prefix < len(intDeltaDeltaWidths)-1 {
+			bit, err := d.br.ReadBit()
+			if err != nil {
+				d.err = fmt.Errorf("IntegerDecoder: not enough data to decode delta-of-delta value")
+				return
+			}
+			if !bit {
+				break
+			}
+			prefix++
+		}
+
+		var dd uint64
+		if width := intDeltaDeltaWidths[prefix]; width > 0 {
+			v, err := d.br.ReadBits(width)
+			if err != nil {
+				d.err = fmt.Errorf("IntegerDecoder: not enough data to decode delta-of-delta value")
+				return
+			}
+			dd = v
+		}
+
+		d.ddDelta += ZigZagDecode(dd)
+		d.values[j] = ZigZagEncode(d.ddDelta)
+	}
+
+	d.ddCount -= uint64(n)
+	if d.ddCount == 0 {
+		d.bytes = nil
+	}
+	d.n = n
+	d.i = 0
+}
+
 func (d *IntegerDecoder) decodeUncompressed() {
 	if len(d.bytes) == 0 {
 		return
@@ -383,6 +383,102 @@ func Test_IntegerEncoder_CounterRLE(t *testing.T) {
 	}
 }
 
+func Test_IntegerEncoder_CounterDeltaDelta(t *testing.T) {
+	enc := NewIntegerEncoder(1000)
+	rnd := rand.New(rand.NewSource(0))
+
+	// A counter incremented at a near constant rate with some jitter
+	values := make([]int64, 1000)
+	v := int64(1e15)
+	for i := range values {
+		values[i] = v
+		enc.Write(v)
+		v += 100 + int64(rnd.Intn(7)) - 3
+	}
+
+	b, err := enc.Bytes()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if b[0]>>4 != intCompressedDeltaDelta {
+		t.Fatalf("unexpected encoding format: expected delta-of-delta, got %v", b[0]>>4)
+	}
+
+	packed, err := enc.encodePacked()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(b) >= len(packed) {
+		t.Fatalf("encoded length not reduced: got %v, packed %v", len(b), len(packed))
+	}
+
+	var dec IntegerDecoder
+	dec.SetBytes(b)
+	i := 0
+	for dec.Next() {
+		if i > len(values) {
+			t.Fatalf("read too many values: got %v, exp %v", i, len(values))
+		}
+
+		if values[i] != dec.Read() {
+			t.Fatalf("read value %d mismatch: got %v, exp %v", i, dec.Read(), values[i])
+		}
+		i += 1
+	}
+
+	if err := dec.Error(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if i != len(values) {
+		t.Fatalf("failed to read enough values: got %v, exp %v", i, len(values))
+	}
+}
+
+func Test_IntegerEncoder_DeltaDelta(t *testing.T) {
+	cases := [][]int64{
+		{1, 2},
+		{-2, 0, 1},
+		{0, 1, 1 << 60},
+		{math.MinInt64, math.MaxInt64, math.MinInt64, 0, math.MaxInt64},
+		{1e15, 1e15 + 1, 1e15 + 2, 1e15 + 3, 1e15 + 4, 1e15 + 6},
+		{0, 1 << 3, 1 << 7, 1 << 15, 1 << 31, 1 << 62, 1 << 62, -1},
+	}
+
+	for _, values := range cases {
+		enc := NewIntegerEncoder(len(values))
+		for _, v := range values {
+			enc.Write(v)
+		}
+
+		// Encode directly since the format would not be chosen for most of these
+		b, err := enc.encodeDeltaDelta()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if exp := enc.deltaDeltaSize(); len(b) != exp {
+			t.Fatalf("encoded length mismatch: got %v, exp %v", len(b), exp)
+		}
+
+		var dec IntegerDecoder
+		dec.SetBytes(b)
+		got := make([]int64, 0, len(values))
+		for dec.Next() {
+			got = append(got, dec.Read())
+		}
+
+		if err := dec.Error(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !reflect.DeepEqual(values, got) {
+			t.Fatalf("mismatch:\n\nexp=%#v\n\ngot=%#v\n\n", values, got)
+		}
+	}
+}
+
 func Test_IntegerEncoder_Descending(t *testing.T) {
 	enc := NewIntegerEncoder(16)
 	values := []int64{
@@ -552,6 +648,43 @@ func Test_IntegerEncoder_Quick(t *testing.T) {
 	}, nil)
 }
 
+func Test_IntegerEncoder_CounterQuick(t *testing.T) {
+	quick.Check(func(first int64, deltas []int16) bool {
+		// Write a counter with small, varying deltas to the encoder.
+		enc := NewIntegerEncoder(1024)
+		expected := []int64{first}
+		enc.Write(first)
+		for _, d := range deltas {
+			v := expected[len(expected)-1] + int64(d)
+			expected = append(expected, v)
+			enc.Write(v)
+		}
+
+		buf, err := enc.Bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Read values out of decoder.
+		got := make([]int64, 0, len(expected))
+		var dec IntegerDecoder
+		dec.SetBytes(buf)
+		for dec.Next() {
+			got = append(got, dec.Read())
+		}
+		if err := dec.Error(); err != nil {
+			t.Fatal(err)
+		}
+
+		// Verify that input and output values match.
+		if !reflect.DeepEqual(expected, got) {
+			t.Fatalf("mismatch:\n\nexp=%#v\n\ngot=%#v\n\n", expected, got)
+		}
+
+		return true
+	}, nil)
+}
+
 func Test_IntegerDecoder_Corrupt(t *testing.T) {
 	cases := []string{
 		"",                     // Empty
@@ -560,6 +693,10 @@ func Test_IntegerDecoder_Corrupt(t *testing.T) {
 		"\x20abc",              // RLE: less than 8 bytes
 		"\x2012345678\x90",     // RLE: valid starting value but invalid delta value
 		"\x2012345678\x01\x90", // RLE: valid starting, valid delta value, invalid repeat value
+		"\x30abc",              // Delta-of-delta: less than 8 bytes
+		"\x3012345678",         // Delta-of-delta: valid starting value but missing count
+		"\x3012345678\x90",     // Delta-of-delta: valid starting value but invalid count
+		"\x40abc",              // Unknown encoding
 	}
 
 	for _, c := range cases {
@@ -624,6 +761,26 @@ func BenchmarkIntegerDecoderPackedSimple(b *testing.B) {
 	}
 }
 
+func BenchmarkIntegerDecoderDeltaDelta(b *testing.B) {
+	x := make([]int64, 1024)
+	enc := NewIntegerEncoder(1024)
+	for i := 0; i < len(x); i++ {
+		// A counter with a small amount of jitter in its rate
+		x[i] = int64(i)*100 + int64(rand.Intn(4))
+		enc.Write(x[i])
+	}
+	bytes, _ := enc.Bytes()
+
+	b.ResetTimer()
+
+	var dec IntegerDecoder
+	for i := 0; i < b.N; i++ {
+		dec.SetBytes(bytes)
+		for dec.Next() {
+		}
+	}
+}
+
 func BenchmarkIntegerDecoderRLE(b *testing.B) {
 	x := make([]int64, 1024)
 	enc := NewIntegerEncoder(1024)
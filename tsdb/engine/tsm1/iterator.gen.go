@@ -190,13 +190,18 @@ type floatIterator struct {
 	statsLock sync.Mutex
 	stats     query.IteratorStats
 	statsBuf  query.IteratorStats
+	reads     *query.IteratorStats // blocks and cache values read by the cursors
 }
 
-func newFloatIterator(name string, tags query.Tags, opt query.IteratorOptions, cur floatCursor, aux []cursorAt, conds []cursorAt, condNames []string) *floatIterator {
+// newFloatIterator returns an iterator over the cursors of a series.  reads counts the
+// blocks and cache values read by the cursors, if they were created with a context from
+// newContextWithCursorStats, and is added to the stats of the iterator.
+func newFloatIterator(name string, tags query.Tags, opt query.IteratorOptions, cur floatCursor, aux []cursorAt, conds []cursorAt, condNames []string, reads *query.IteratorStats) *floatIterator {
 	itr := &floatIterator{
-		cur: cur,
-		aux: aux,
-		opt: opt,
+		cur:   cur,
+		aux:   aux,
+		opt:   opt,
+		reads: reads,
 		point: query.FloatPoint{
 			Name: name,
 			Tags: tags,
@@ -280,10 +285,14 @@ func (itr *floatIterator) Next() (*query.FloatPoint, error) {
 	}
 }
 
-// copyStats copies from the itr stats buffer to the stats under lock.
+// copyStats copies from the itr stats buffer and the reads of the cursors to the stats
+// under lock.
 func (itr *floatIterator) copyStats() {
 	itr.statsLock.Lock()
 	itr.stats = itr.statsBuf
+	if itr.reads != nil {
+		itr.stats.Add(*itr.reads)
+	}
 	itr.statsLock.Unlock()
 }
 
@@ -363,6 +372,7 @@ type floatAscendingCursor struct {
 		values Values
 		pos    int
 	}
+	reads *query.IteratorStats
 
 	tsm struct {
 		values    []FloatValue
@@ -372,7 +382,7 @@ type floatAscendingCursor struct {
 }
 
 func newFloatAscendingCursor(seek int64, cacheValues Values, tsmKeyCursor *KeyCursor) *floatAscendingCursor {
-	c := &floatAscendingCursor{}
+	c := &floatAscendingCursor{reads: tsmKeyCursor.stats}
 
 	c.cache.values = cacheValues
 	c.cache.pos = sort.Search(len(c.cache.values), func(i int) bool {
@@ -454,6 +464,9 @@ func (c *floatAscendingCursor) nextCache() {
 		return
 	}
 	c.cache.pos++
+	if c.reads != nil {
+		c.reads.CacheHitN++
+	}
 }
 
 // nextTSM returns the next value from the TSM files.
@@ -474,6 +487,7 @@ type floatDescendingCursor struct {
 		values Values
 		pos    int
 	}
+	reads *query.IteratorStats
 
 	tsm struct {
 		values    []FloatValue
@@ -483,7 +497,7 @@ type floatDescendingCursor struct {
 }
 
 func newFloatDescendingCursor(seek int64, cacheValues Values, tsmKeyCursor *KeyCursor) *floatDescendingCursor {
-	c := &floatDescendingCursor{}
+	c := &floatDescendingCursor{reads: tsmKeyCursor.stats}
 
 	c.cache.values = cacheValues
 	c.cache.pos = sort.Search(len(c.cache.values), func(i int) bool {
@@ -571,6 +585,9 @@ func (c *floatDescendingCursor) nextCache() {
 		return
 	}
 	c.cache.pos--
+	if c.reads != nil {
+		c.reads.CacheHitN++
+	}
 }
 
 // nextTSM returns the next value from the TSM files.
@@ -653,13 +670,18 @@ type integerIterator struct {
 	statsLock sync.Mutex
 	stats     query.IteratorStats
 	statsBuf  query.IteratorStats
+	reads     *query.IteratorStats // blocks and cache values read by the cursors
 }
 
-func newIntegerIterator(name string, tags query.Tags, opt query.IteratorOptions, cur integerCursor, aux []cursorAt, conds []cursorAt, condNames []string) *integerIterator {
+// newIntegerIterator returns an iterator over the cursors of a series.  reads counts the
+// blocks and cache values read by the cursors, if they were created with a context from
+// newContextWithCursorStats, and is added to the stats of the iterator.
+func newIntegerIterator(name string, tags query.Tags, opt query.IteratorOptions, cur integerCursor, aux []cursorAt, conds []cursorAt, condNames []string, reads *query.IteratorStats) *integerIterator {
 	itr := &integerIterator{
-		cur: cur,
-		aux: aux,
-		opt: opt,
+		cur:   cur,
+		aux:   aux,
+		opt:   opt,
+		reads: reads,
 		point: query.IntegerPoint{
 			Name: name,
 			Tags: tags,
@@ -743,10 +765,14 @@ func (itr *integerIterator) Next() (*query.IntegerPoint, error) {
 	}
 }
 
-// copyStats copies from the itr stats buffer to the stats under lock.
+// copyStats copies from the itr stats buffer and the reads of the cursors to the stats
+// under lock.
 func (itr *integerIterator) copyStats() {
 	itr.statsLock.Lock()
 	itr.stats = itr.statsBuf
+	if itr.reads != nil {
+		itr.stats.Add(*itr.reads)
+	}
 	itr.statsLock.Unlock()
 }
 
@@ -826,6 +852,7 @@ type integerAscendingCursor struct {
 		values Values
 		pos    int
 	}
+	reads *query.IteratorStats
 
 	tsm struct {
 		values    []IntegerValue
@@ -835,7 +862,7 @@ type integerAscendingCursor struct {
 }
 
 func newIntegerAscendingCursor(seek int64, cacheValues Values, tsmKeyCursor *KeyCursor) *integerAscendingCursor {
-	c := &integerAscendingCursor{}
+	c := &integerAscendingCursor{reads: tsmKeyCursor.stats}
 
 	c.cache.values = cacheValues
 	c.cache.pos = sort.Search(len(c.cache.values), func(i int) bool {
@@ -917,6 +944,9 @@ func (c *integerAscendingCursor) nextCache() {
 		return
 	}
 	c.cache.pos++
+	if c.reads != nil {
+		c.reads.CacheHitN++
+	}
 }
 
 // nextTSM returns the next value from the TSM files.
@@ -937,6 +967,7 @@ type integerDescendingCursor struct {
 		values Values
 		pos    int
 	}
+	reads *query.IteratorStats
 
 	tsm struct {
 		values    []IntegerValue
@@ -946,7 +977,7 @@ type integerDescendingCursor struct {
 }
 
 func newIntegerDescendingCursor(seek int64, cacheValues Values, tsmKeyCursor *KeyCursor) *integerDescendingCursor {
-	c := &integerDescendingCursor{}
+	c := &integerDescendingCursor{reads: tsmKeyCursor.stats}
 
 	c.cache.values = cacheValues
 	c.cache.pos = sort.Search(len(c.cache.values), func(i int) bool {
@@ -1034,6 +1065,9 @@ func (c *integerDescendingCursor) nextCache() {
 		return
 	}
 	c.cache.pos--
+	if c.reads != nil {
+		c.reads.CacheHitN++
+	}
 }
 
 // nextTSM returns the next value from the TSM files.
@@ -1116,13 +1150,18 @@ type unsignedIterator struct {
 	statsLock sync.Mutex
 	stats     query.IteratorStats
 	statsBuf  query.IteratorStats
+	reads     *query.IteratorStats // blocks and cache values read by the cursors
 }
 
-func newUnsignedIterator(name string, tags query.Tags, opt query.IteratorOptions, cur unsignedCursor, aux []cursorAt, conds []cursorAt, condNames []string) *unsignedIterator {
+// newUnsignedIterator returns an iterator over the cursors of a series.  reads counts the
+// blocks and cache values read by the cursors, if they were created with a context from
+// newContextWithCursorStats, and is added to the stats of the iterator.
+func newUnsignedIterator(name string, tags query.Tags, opt query.IteratorOptions, cur unsignedCursor, aux []cursorAt, conds []cursorAt, condNames []string, reads *query.IteratorStats) *unsignedIterator {
 	itr := &unsignedIterator{
-		cur: cur,
-		aux: aux,
-		opt: opt,
+		cur:   cur,
+		aux:   aux,
+		opt:   opt,
+		reads: reads,
 		point: query.UnsignedPoint{
 			Name: name,
 			Tags: tags,
@@ -1206,10 +1245,14 @@ func (itr *unsignedIterator) Next() (*query.UnsignedPoint, error) {
 	}
 }
 
-// copyStats copies from the itr stats buffer to the stats under lock.
+// copyStats copies from the itr stats buffer and the reads of the cursors to the stats
+// under lock.
 func (itr *unsignedIterator) copyStats() {
 	itr.statsLock.Lock()
 	itr.stats = itr.statsBuf
+	if itr.reads != nil {
+		itr.stats.Add(*itr.reads)
+	}
 	itr.statsLock.Unlock()
 }
 
@@ -1289,6 +1332,7 @@ type unsignedAscendingCursor struct {
 		values Values
 		pos    int
 	}
+	reads *query.IteratorStats
 
 	tsm struct {
 		values    []UnsignedValue
@@ -1298,7 +1342,7 @@ type unsignedAscendingCursor struct {
 }
 
 func newUnsignedAscendingCursor(seek int64, cacheValues Values, tsmKeyCursor *KeyCursor) *unsignedAscendingCursor {
-	c := &unsignedAscendingCursor{}
+	c := &unsignedAscendingCursor{reads: tsmKeyCursor.stats}
 
 	c.cache.values = cacheValues
 	c.cache.pos = sort.Search(len(c.cache.values), func(i int) bool {
@@ -1380,6 +1424,9 @@ func (c *unsignedAscendingCursor) nextCache() {
 		return
 	}
 	c.cache.pos++
+	if c.reads != nil {
+		c.reads.CacheHitN++
+	}
 }
 
 // nextTSM returns the next value from the TSM files.
@@ -1400,6 +1447,7 @@ type unsignedDescendingCursor struct {
 		values Values
 		pos    int
 	}
+	reads *query.IteratorStats
 
 	tsm struct {
 		values    []UnsignedValue
@@ -1409,7 +1457,7 @@ type unsignedDescendingCursor struct {
 }
 
 func newUnsignedDescendingCursor(seek int64, cacheValues Values, tsmKeyCursor *KeyCursor) *unsignedDescendingCursor {
-	c := &unsignedDescendingCursor{}
+	c := &unsignedDescendingCursor{reads: tsmKeyCursor.stats}
 
 	c.cache.values = cacheValues
 	c.cache.pos = sort.Search(len(c.cache.values), func(i int) bool {
@@ -1497,6 +1545,9 @@ func (c *unsignedDescendingCursor) nextCache() {
 		return
 	}
 	c.cache.pos--
+	if c.reads != nil {
+		c.reads.CacheHitN++
+	}
 }
 
 // nextTSM returns the next value from the TSM files.
@@ -1579,13 +1630,18 @@ type stringIterator struct {
 	statsLock sync.Mutex
 	stats     query.IteratorStats
 	statsBuf  query.IteratorStats
+	reads     *query.IteratorStats // blocks and cache values read by the cursors
 }
 
-func newStringIterator(name string, tags query.Tags, opt query.IteratorOptions, cur stringCursor, aux []cursorAt, conds []cursorAt, condNames []string) *stringIterator {
+// newStringIterator returns an iterator over the cursors of a series.  reads counts the
+// blocks and cache values read by the cursors, if they were created with a context from
+// newContextWithCursorStats, and is added to the stats of the iterator.
+func newStringIterator(name string, tags query.Tags, opt query.IteratorOptions, cur stringCursor, aux []cursorAt, conds []cursorAt, condNames []string, reads *query.IteratorStats) *stringIterator {
 	itr := &stringIterator{
-		cur: cur,
-		aux: aux,
-		opt: opt,
+		cur:   cur,
+		aux:   aux,
+		opt:   opt,
+		reads: reads,
 		point: query.StringPoint{
 			Name: name,
 			Tags: tags,
@@ -1669,10 +1725,14 @@ func (itr *stringIterator) Next() (*query.StringPoint, error) {
 	}
 }
 
-// copyStats copies from the itr stats buffer to the stats under lock.
+// copyStats copies from the itr stats buffer and the reads of the cursors to the stats
+// under lock.
 func (itr *stringIterator) copyStats() {
 	itr.statsLock.Lock()
 	itr.stats = itr.statsBuf
+	if itr.reads != nil {
+		itr.stats.Add(*itr.reads)
+	}
 	itr.statsLock.Unlock()
 }
 
@@ -1752,6 +1812,7 @@ type stringAscendingCursor struct {
 		values Values
 		pos    int
 	}
+	reads *query.IteratorStats
 
 	tsm struct {
 		values    []StringValue
@@ -1761,7 +1822,7 @@ type stringAscendingCursor struct {
 }
 
 func newStringAscendingCursor(seek int64, cacheValues Values, tsmKeyCursor *KeyCursor) *stringAscendingCursor {
-	c := &stringAscendingCursor{}
+	c := &stringAscendingCursor{reads: tsmKeyCursor.stats}
 
 	c.cache.values = cacheValues
 	c.cache.pos = sort.Search(len(c.cache.values), func(i int) bool {
@@ -1843,6 +1904,9 @@ func (c *stringAscendingCursor) nextCache() {
 		return
 	}
 	c.cache.pos++
+	if c.reads != nil {
+		c.reads.CacheHitN++
+	}
 }
 
 // nextTSM returns the next value from the TSM files.
@@ -1863,6 +1927,7 @@ type stringDescendingCursor struct {
 		values Values
 		pos    int
 	}
+	reads *query.IteratorStats
 
 	tsm struct {
 		values    []StringValue
@@ -1872,7 +1937,7 @@ type stringDescendingCursor struct {
 }
 
 func newStringDescendingCursor(seek int64, cacheValues Values, tsmKeyCursor *KeyCursor) *stringDescendingCursor {
-	c := &stringDescendingCursor{}
+	c := &stringDescendingCursor{reads: tsmKeyCursor.stats}
 
 	c.cache.values = cacheValues
 	c.cache.pos = sort.Search(len(c.cache.values), func(i int) bool {
@@ -1960,6 +2025,9 @@ func (c *stringDescendingCursor) nextCache() {
 		return
 	}
 	c.cache.pos--
+	if c.reads != nil {
+		c.reads.CacheHitN++
+	}
 }
 
 // nextTSM returns the next value from the TSM files.
@@ -2042,13 +2110,18 @@ type booleanIterator struct {
 	statsLock sync.Mutex
 	stats     query.IteratorStats
 	statsBuf  query.IteratorStats
+	reads     *query.IteratorStats // blocks and cache values read by the cursors
 }
 
-func newBooleanIterator(name string, tags query.Tags, opt query.IteratorOptions, cur booleanCursor, aux []cursorAt, conds []cursorAt, condNames []string) *booleanIterator {
+// newBooleanIterator returns an iterator over the cursors of a series.  reads counts the
+// blocks and cache values read by the cursors, if they were created with a context from
+// newContextWithCursorStats, and is added to the stats of the iterator.
+func newBooleanIterator(name string, tags query.Tags, opt query.IteratorOptions, cur booleanCursor, aux []cursorAt, conds []cursorAt, condNames []string, reads *query.IteratorStats) *booleanIterator {
 	itr := &booleanIterator{
-		cur: cur,
-		aux: aux,
-		opt: opt,
+		cur:   cur,
+		aux:   aux,
+		opt:   opt,
+		reads: reads,
 		point: query.BooleanPoint{
 			Name: name,
 			Tags: tags,
@@ -2132,10 +2205,14 @@ func (itr *booleanIterator) Next() (*query.BooleanPoint, error) {
 	}
 }
 
-// copyStats copies from the itr stats buffer to the stats under lock.
+// copyStats copies from the itr stats buffer and the reads of the cursors to the stats
+// under lock.
 func (itr *booleanIterator) copyStats() {
 	itr.statsLock.Lock()
 	itr.stats = itr.statsBuf
+	if itr.reads != nil {
+		itr.stats.Add(*itr.reads)
+	}
 	itr.statsLock.Unlock()
 }
 
@@ -2215,6 +2292,7 @@ type booleanAscendingCursor struct {
 		values Values
 		pos    int
 	}
+	reads *query.IteratorStats
 
 	tsm struct {
 		values    []BooleanValue
@@ -2224,7 +2302,7 @@ type booleanAscendingCursor struct {
 }
 
 func newBooleanAscendingCursor(seek int64, cacheValues Values, tsmKeyCursor *KeyCursor) *booleanAscendingCursor {
-	c := &booleanAscendingCursor{}
+	c := &booleanAscendingCursor{reads: tsmKeyCursor.stats}
 
 	c.cache.values = cacheValues
 	c.cache.pos = sort.Search(len(c.cache.values), func(i int) bool {
@@ -2306,6 +2384,9 @@ func (c *booleanAscendingCursor) nextCache() {
 		return
 	}
 	c.cache.pos++
+	if c.reads != nil {
+		c.reads.CacheHitN++
+	}
 }
 
 // nextTSM returns the next value from the TSM files.
@@ -2326,6 +2407,7 @@ type booleanDescendingCursor struct {
 		values Values
 		pos    int
 	}
+	reads *query.IteratorStats
 
 	tsm struct {
 		values    []BooleanValue
@@ -2335,7 +2417,7 @@ type booleanDescendingCursor struct {
 }
 
 func newBooleanDescendingCursor(seek int64, cacheValues Values, tsmKeyCursor *KeyCursor) *booleanDescendingCursor {
-	c := &booleanDescendingCursor{}
+	c := &booleanDescendingCursor{reads: tsmKeyCursor.stats}
 
 	c.cache.values = cacheValues
 	c.cache.pos = sort.Search(len(c.cache.values), func(i int) bool {
@@ -2423,6 +2505,9 @@ func (c *booleanDescendingCursor) nextCache() {
 		return
 	}
 	c.cache.pos--
+	if c.reads != nil {
+		c.reads.CacheHitN++
+	}
 }
 
 // nextTSM returns the next value from the TSM files.
@@ -2438,4 +2523,1027 @@ func (c *booleanDescendingCursor) nextTSM() {
 	}
 }
 
+// floatWindow holds the aggregates of the values of a series within an interval.
+type floatWindow struct {
+	start int64
+	count int64
+
+	sum, min, max, first, last            float64
+	minTime, maxTime, firstTime, lastTime int64
+}
+
+// add adds a value to the window.  Values must be added in time order.
+func (w *floatWindow) add(t int64, v float64) {
+	if w.count == 0 {
+		w.sum = v
+		w.min, w.minTime = v, t
+		w.max, w.maxTime = v, t
+		w.first, w.firstTime = v, t
+	} else {
+		w.sum += v
+		if v < w.min {
+			w.min, w.minTime = v, t
+		}
+		if v > w.max {
+			w.max, w.maxTime = v, t
+		}
+	}
+	w.last, w.lastTime = v, t
+	w.count++
+}
+
+// addStats adds the values of the block of e to the window using its statistics.
+func (w *floatWindow) addStats(e *IndexEntry) {
+	s := &e.Stats
+	sum, min, max := floatFromBits(s.Sum), floatFromBits(s.Min), floatFromBits(s.Max)
+	if w.count == 0 {
+		w.sum = sum
+		w.min, w.minTime = min, s.MinTime
+		w.max, w.maxTime = max, s.MaxTime
+		w.first, w.firstTime = floatFromBits(s.First), e.MinTime
+	} else {
+		w.sum += sum
+		if min < w.min {
+			w.min, w.minTime = min, s.MinTime
+		}
+		if max > w.max {
+			w.max, w.maxTime = max, s.MaxTime
+		}
+	}
+	w.last, w.lastTime = floatFromBits(s.Last), e.MaxTime
+	w.count += int64(s.Count)
+}
+
+// floatWindowReader reduces the values of a single float series for each interval
+// of an ascending call.  Blocks lying entirely within an interval are summarised by
+// their statistics and only the blocks crossing an interval boundary are decoded.
+type floatWindowReader struct {
+	opt    query.IteratorOptions
+	window floatWindow
+
+	cache struct {
+		values Values
+		pos    int
+	}
+
+	tsm struct {
+		values    []FloatValue
+		pos       int
+		block     *IndexEntry
+		eof       bool
+		keyCursor *KeyCursor
+	}
+
+	statsLock sync.Mutex
+	stats     query.IteratorStats
+	statsBuf  query.IteratorStats
+	reads     *query.IteratorStats // blocks and cache values read
+}
+
+func newFloatWindowReader(opt query.IteratorOptions, cacheValues Values, tsmKeyCursor *KeyCursor) *floatWindowReader {
+	r := &floatWindowReader{
+		opt: opt,
+		statsBuf: query.IteratorStats{
+			SeriesN: 1,
+		},
+		reads: tsmKeyCursor.stats,
+	}
+	r.stats = r.statsBuf
+
+	seek := opt.SeekTime()
+	r.cache.values = cacheValues
+	r.cache.pos = sort.Search(len(r.cache.values), func(i int) bool {
+		return r.cache.values[i].UnixNano() >= seek
+	})
+
+	r.tsm.keyCursor = tsmKeyCursor
+	return r
+}
+
+// peekCache returns the current time/value from the cache.
+func (r *floatWindowReader) peekCache() (t int64, v float64) {
+	if r.cache.pos >= len(r.cache.values) {
+		return tsdb.EOF, 0
+	}
+
+	item := r.cache.values[r.cache.pos]
+	return item.UnixNano(), item.(FloatValue).value
+}
+
+// peekTSM returns the current time/value from the decoded tsm values.
+func (r *floatWindowReader) peekTSM() (t int64, v float64) {
+	if r.tsm.pos >= len(r.tsm.values) {
+		return tsdb.EOF, 0
+	}
+
+	item := r.tsm.values[r.tsm.pos]
+	return item.UnixNano(), item.value
+}
+
+// cacheOverlaps returns true if the cache holds unread values between min and max.
+func (r *floatWindowReader) cacheOverlaps(min, max int64) bool {
+	values := r.cache.values[r.cache.pos:]
+	i := sort.Search(len(values), func(i int) bool {
+		return values[i].UnixNano() >= min
+	})
+	return i < len(values) && values[i].UnixNano() <= max
+}
+
+// fill makes the next tsm data available once the decoded values are exhausted.
+// The next block is kept undecoded if it can be summarised by its statistics.
+func (r *floatWindowReader) fill() error {
+	if r.tsm.pos < len(r.tsm.values) || r.tsm.block != nil || r.tsm.eof {
+		return nil
+	}
+
+	if e := r.tsm.keyCursor.peekStats(r.opt.StartTime, r.opt.EndTime); e != nil && !r.cacheOverlaps(e.MinTime, e.MaxTime) {
+		r.tsm.block = e
+		return nil
+	}
+	return r.decode()
+}
+
+// decode decodes the next block.
+func (r *floatWindowReader) decode() error {
+	values, err := r.tsm.keyCursor.ReadFloatBlock(&r.tsm.values)
+	if err != nil {
+		return err
+	}
+	r.tsm.keyCursor.Next()
+
+	// The first block read may begin before the start of the query, whose values are
+	// not aggregated.
+	pos := sort.Search(len(values), func(i int) bool {
+		return values[i].UnixNano() >= r.opt.StartTime
+	})
+	r.tsm.values, r.tsm.pos, r.tsm.block = values, pos, nil
+	if len(values) == 0 {
+		r.tsm.eof = true
+	}
+	return nil
+}
+
+// next returns the aggregates of the next interval holding values, or nil once the
+// series has no more values within the time range of the query.
+func (r *floatWindowReader) next() (*floatWindow, error) {
+	w := &r.window
+	*w = floatWindow{}
+
+	var end int64
+	for {
+		if err := r.fill(); err != nil {
+			return nil, err
+		}
+
+		// Summarise the next block if no cached values precede it.
+		if e := r.tsm.block; e != nil {
+			if ckey, _ := r.peekCache(); ckey == tsdb.EOF || ckey > e.MaxTime {
+				if w.count == 0 {
+					w.start, end = r.opt.Window(e.MinTime)
+				} else if e.MinTime >= end {
+					return r.emit(w), nil
+				}
+
+				if e.MaxTime >= end {
+					// The block crosses the end of the interval.
+					if err := r.decode(); err != nil {
+						return nil, err
+					}
+					continue
+				}
+
+				w.addStats(e)
+				r.statsBuf.PointN += int(e.Stats.Count)
+				r.tsm.block = nil
+				r.tsm.keyCursor.skipBlock()
+				continue
+			}
+		}
+
+		ckey, cvalue := r.peekCache()
+		tkey, tvalue := r.peekTSM()
+
+		// No more data in cache or in TSM files.
+		if ckey == tsdb.EOF && tkey == tsdb.EOF {
+			return r.emit(w), nil
+		}
+
+		// Cache values take precedence over tsm values with the same time.
+		t, v := tkey, tvalue
+		if ckey != tsdb.EOF && (ckey <= tkey || tkey == tsdb.EOF) {
+			t, v = ckey, cvalue
+		}
+
+		// Exit if we are outside our time range or the interval.
+		if t > r.opt.EndTime {
+			return r.emit(w), nil
+		} else if w.count == 0 {
+			w.start, end = r.opt.Window(t)
+		} else if t >= end {
+			return r.emit(w), nil
+		}
+
+		if t == ckey {
+			r.cache.pos++
+			if r.reads != nil {
+				r.reads.CacheHitN++
+			}
+		}
+		if t == tkey {
+			r.tsm.pos++
+		}
+		w.add(t, v)
+		r.statsBuf.PointN++
+	}
+}
+
+// emit returns w if it holds any values and copies the stats buffer.
+func (r *floatWindowReader) emit(w *floatWindow) *floatWindow {
+	r.statsLock.Lock()
+	r.stats = r.statsBuf
+	if r.reads != nil {
+		r.stats.Add(*r.reads)
+	}
+	r.statsLock.Unlock()
+
+	if w.count == 0 {
+		return nil
+	}
+	return w
+}
+
+// Stats returns stats on the points processed.
+func (r *floatWindowReader) Stats() query.IteratorStats {
+	r.statsLock.Lock()
+	stats := r.stats
+	r.statsLock.Unlock()
+	return stats
+}
+
+// Close closes the reader and its key cursor.
+func (r *floatWindowReader) Close() error {
+	if r.tsm.keyCursor != nil {
+		r.tsm.keyCursor.Close()
+		r.tsm.keyCursor = nil
+	}
+	r.cache.values = nil
+	r.tsm.values = nil
+	r.tsm.block = nil
+	return nil
+}
+
+// newFloatStatsIterator returns an iterator answering the call of opt over a single
+// float series using block statistics.
+func newFloatStatsIterator(name string, tags query.Tags, opt query.IteratorOptions, cacheValues Values, tsmKeyCursor *KeyCursor) statsIterator {
+	r := newFloatWindowReader(opt, cacheValues, tsmKeyCursor)
+
+	call := opt.Expr.(*influxql.Call).Name
+	if call == "count" {
+		return &floatStatsCountIterator{
+			floatWindowReader: r,
+			point:             query.IntegerPoint{Name: name, Tags: tags},
+		}
+	}
+	return &floatStatsIterator{
+		floatWindowReader: r,
+		call:              call,
+		point:             query.FloatPoint{Name: name, Tags: tags},
+	}
+}
+
+// floatStatsIterator answers sum, min, max, first and last calls over a single
+// float series.
+type floatStatsIterator struct {
+	*floatWindowReader
+	call  string
+	point query.FloatPoint
+}
+
+func (itr *floatStatsIterator) reducedByStats() {}
+
+// Next returns the reduced point of the next interval.
+func (itr *floatStatsIterator) Next() (*query.FloatPoint, error) {
+	w, err := itr.next()
+	if w == nil || err != nil {
+		return nil, err
+	}
+
+	switch itr.call {
+	case "sum":
+		itr.point.Time, itr.point.Value = w.start, w.sum
+	case "min":
+		itr.point.Time, itr.point.Value = w.minTime, w.min
+	case "max":
+		itr.point.Time, itr.point.Value = w.maxTime, w.max
+	case "first":
+		itr.point.Time, itr.point.Value = w.firstTime, w.first
+	case "last":
+		itr.point.Time, itr.point.Value = w.lastTime, w.last
+	}
+	itr.point.Aggregated = uint32(w.count)
+	return &itr.point, nil
+}
+
+// floatStatsCountIterator answers count calls over a single float series.
+type floatStatsCountIterator struct {
+	*floatWindowReader
+	point query.IntegerPoint
+}
+
+func (itr *floatStatsCountIterator) reducedByStats() {}
+
+// Next returns the reduced point of the next interval.
+func (itr *floatStatsCountIterator) Next() (*query.IntegerPoint, error) {
+	w, err := itr.next()
+	if w == nil || err != nil {
+		return nil, err
+	}
+
+	itr.point.Time, itr.point.Value = w.start, w.count
+	itr.point.Aggregated = uint32(w.count)
+	return &itr.point, nil
+}
+
+// integerWindow holds the aggregates of the values of a series within an interval.
+type integerWindow struct {
+	start int64
+	count int64
+
+	sum, min, max, first, last            int64
+	minTime, maxTime, firstTime, lastTime int64
+}
+
+// add adds a value to the window.  Values must be added in time order.
+func (w *integerWindow) add(t int64, v int64) {
+	if w.count == 0 {
+		w.sum = v
+		w.min, w.minTime = v, t
+		w.max, w.maxTime = v, t
+		w.first, w.firstTime = v, t
+	} else {
+		w.sum += v
+		if v < w.min {
+			w.min, w.minTime = v, t
+		}
+		if v > w.max {
+			w.max, w.maxTime = v, t
+		}
+	}
+	w.last, w.lastTime = v, t
+	w.count++
+}
+
+// addStats adds the values of the block of e to the window using its statistics.
+func (w *integerWindow) addStats(e *IndexEntry) {
+	s := &e.Stats
+	sum, min, max := integerFromBits(s.Sum), integerFromBits(s.Min), integerFromBits(s.Max)
+	if w.count == 0 {
+		w.sum = sum
+		w.min, w.minTime = min, s.MinTime
+		w.max, w.maxTime = max, s.MaxTime
+		w.first, w.firstTime = integerFromBits(s.First), e.MinTime
+	} else {
+		w.sum += sum
+		if min < w.min {
+			w.min, w.minTime = min, s.MinTime
+		}
+		if max > w.max {
+			w.max, w.maxTime = max, s.MaxTime
+		}
+	}
+	w.last, w.lastTime = integerFromBits(s.Last), e.MaxTime
+	w.count += int64(s.Count)
+}
+
+// integerWindowReader reduces the values of a single integer series for each interval
+// of an ascending call.  Blocks lying entirely within an interval are summarised by
+// their statistics and only the blocks crossing an interval boundary are decoded.
+type integerWindowReader struct {
+	opt    query.IteratorOptions
+	window integerWindow
+
+	cache struct {
+		values Values
+		pos    int
+	}
+
+	tsm struct {
+		values    []IntegerValue
+		pos       int
+		block     *IndexEntry
+		eof       bool
+		keyCursor *KeyCursor
+	}
+
+	statsLock sync.Mutex
+	stats     query.IteratorStats
+	statsBuf  query.IteratorStats
+	reads     *query.IteratorStats // blocks and cache values read
+}
+
+func newIntegerWindowReader(opt query.IteratorOptions, cacheValues Values, tsmKeyCursor *KeyCursor) *integerWindowReader {
+	r := &integerWindowReader{
+		opt: opt,
+		statsBuf: query.IteratorStats{
+			SeriesN: 1,
+		},
+		reads: tsmKeyCursor.stats,
+	}
+	r.stats = r.statsBuf
+
+	seek := opt.SeekTime()
+	r.cache.values = cacheValues
+	r.cache.pos = sort.Search(len(r.cache.values), func(i int) bool {
+		return r.cache.values[i].UnixNano() >= seek
+	})
+
+	r.tsm.keyCursor = tsmKeyCursor
+	return r
+}
+
+// peekCache returns the current time/value from the cache.
+func (r *integerWindowReader) peekCache() (t int64, v int64) {
+	if r.cache.pos >= len(r.cache.values) {
+		return tsdb.EOF, 0
+	}
+
+	item := r.cache.values[r.cache.pos]
+	return item.UnixNano(), item.(IntegerValue).value
+}
+
+// peekTSM returns the current time/value from the decoded tsm values.
+func (r *integerWindowReader) peekTSM() (t int64, v int64) {
+	if r.tsm.pos >= len(r.tsm.values) {
+		return tsdb.EOF, 0
+	}
+
+	item := r.tsm.values[r.tsm.pos]
+	return item.UnixNano(), item.value
+}
+
+// cacheOverlaps returns true if the cache holds unread values between min and max.
+func (r *integerWindowReader) cacheOverlaps(min, max int64) bool {
+	values := r.cache.values[r.cache.pos:]
+	i := sort.Search(len(values), func(i int) bool {
+		return values[i].UnixNano() >= min
+	})
+	return i < len(values) && values[i].UnixNano() <= max
+}
+
+// fill makes the next tsm data available once the decoded values are exhausted.
+// The next block is kept undecoded if it can be summarised by its statistics.
+func (r *integerWindowReader) fill() error {
+	if r.tsm.pos < len(r.tsm.values) || r.tsm.block != nil || r.tsm.eof {
+		return nil
+	}
+
+	if e := r.tsm.keyCursor.peekStats(r.opt.StartTime, r.opt.EndTime); e != nil && !r.cacheOverlaps(e.MinTime, e.MaxTime) {
+		r.tsm.block = e
+		return nil
+	}
+	return r.decode()
+}
+
+// decode decodes the next block.
+func (r *integerWindowReader) decode() error {
+	values, err := r.tsm.keyCursor.ReadIntegerBlock(&r.tsm.values)
+	if err != nil {
+		return err
+	}
+	r.tsm.keyCursor.Next()
+
+	// The first block read may begin before the start of the query, whose values are
+	// not aggregated.
+	pos := sort.Search(len(values), func(i int) bool {
+		return values[i].UnixNano() >= r.opt.StartTime
+	})
+	r.tsm.values, r.tsm.pos, r.tsm.block = values, pos, nil
+	if len(values) == 0 {
+		r.tsm.eof = true
+	}
+	return nil
+}
+
+// next returns the aggregates of the next interval holding values, or nil once the
+// series has no more values within the time range of the query.
+func (r *integerWindowReader) next() (*integerWindow, error) {
+	w := &r.window
+	*w = integerWindow{}
+
+	var end int64
+	for {
+		if err := r.fill(); err != nil {
+			return nil, err
+		}
+
+		// Summarise the next block if no cached values precede it.
+		if e := r.tsm.block; e != nil {
+			if ckey, _ := r.peekCache(); ckey == tsdb.EOF || ckey > e.MaxTime {
+				if w.count == 0 {
+					w.start, end = r.opt.Window(e.MinTime)
+				} else if e.MinTime >= end {
+					return r.emit(w), nil
+				}
+
+				if e.MaxTime >= end {
+					// The block crosses the end of the interval.
+					if err := r.decode(); err != nil {
+						return nil, err
+					}
+					continue
+				}
+
+				w.addStats(e)
+				r.statsBuf.PointN += int(e.Stats.Count)
+				r.tsm.block = nil
+				r.tsm.keyCursor.skipBlock()
+				continue
+			}
+		}
+
+		ckey, cvalue := r.peekCache()
+		tkey, tvalue := r.peekTSM()
+
+		// No more data in cache or in TSM files.
+		if ckey == tsdb.EOF && tkey == tsdb.EOF {
+			return r.emit(w), nil
+		}
+
+		// Cache values take precedence over tsm values with the same time.
+		t, v := tkey, tvalue
+		if ckey != tsdb.EOF && (ckey <= tkey || tkey == tsdb.EOF) {
+			t, v = ckey, cvalue
+		}
+
+		// Exit if we are outside our time range or the interval.
+		if t > r.opt.EndTime {
+			return r.emit(w), nil
+		} else if w.count == 0 {
+			w.start, end = r.opt.Window(t)
+		} else if t >= end {
+			return r.emit(w), nil
+		}
+
+		if t == ckey {
+			r.cache.pos++
+			if r.reads != nil {
+				r.reads.CacheHitN++
+			}
+		}
+		if t == tkey {
+			r.tsm.pos++
+		}
+		w.add(t, v)
+		r.statsBuf.PointN++
+	}
+}
+
+// emit returns w if it holds any values and copies the stats buffer.
+func (r *integerWindowReader) emit(w *integerWindow) *integerWindow {
+	r.statsLock.Lock()
+	r.stats = r.statsBuf
+	if r.reads != nil {
+		r.stats.Add(*r.reads)
+	}
+	r.statsLock.Unlock()
+
+	if w.count == 0 {
+		return nil
+	}
+	return w
+}
+
+// Stats returns stats on the points processed.
+func (r *integerWindowReader) Stats() query.IteratorStats {
+	r.statsLock.Lock()
+	stats := r.stats
+	r.statsLock.Unlock()
+	return stats
+}
+
+// Close closes the reader and its key cursor.
+func (r *integerWindowReader) Close() error {
+	if r.tsm.keyCursor != nil {
+		r.tsm.keyCursor.Close()
+		r.tsm.keyCursor = nil
+	}
+	r.cache.values = nil
+	r.tsm.values = nil
+	r.tsm.block = nil
+	return nil
+}
+
+// newIntegerStatsIterator returns an iterator answering the call of opt over a single
+// integer series using block statistics.
+func newIntegerStatsIterator(name string, tags query.Tags, opt query.IteratorOptions, cacheValues Values, tsmKeyCursor *KeyCursor) statsIterator {
+	r := newIntegerWindowReader(opt, cacheValues, tsmKeyCursor)
+
+	call := opt.Expr.(*influxql.Call).Name
+	if call == "count" {
+		return &integerStatsCountIterator{
+			integerWindowReader: r,
+			point:               query.IntegerPoint{Name: name, Tags: tags},
+		}
+	}
+	return &integerStatsIterator{
+		integerWindowReader: r,
+		call:                call,
+		point:               query.IntegerPoint{Name: name, Tags: tags},
+	}
+}
+
+// integerStatsIterator answers sum, min, max, first and last calls over a single
+// integer series.
+type integerStatsIterator struct {
+	*integerWindowReader
+	call  string
+	point query.IntegerPoint
+}
+
+func (itr *integerStatsIterator) reducedByStats() {}
+
+// Next returns the reduced point of the next interval.
+func (itr *integerStatsIterator) Next() (*query.IntegerPoint, error) {
+	w, err := itr.next()
+	if w == nil || err != nil {
+		return nil, err
+	}
+
+	switch itr.call {
+	case "sum":
+		itr.point.Time, itr.point.Value = w.start, w.sum
+	case "min":
+		itr.point.Time, itr.point.Value = w.minTime, w.min
+	case "max":
+		itr.point.Time, itr.point.Value = w.maxTime, w.max
+	case "first":
+		itr.point.Time, itr.point.Value = w.firstTime, w.first
+	case "last":
+		itr.point.Time, itr.point.Value = w.lastTime, w.last
+	}
+	itr.point.Aggregated = uint32(w.count)
+	return &itr.point, nil
+}
+
+// integerStatsCountIterator answers count calls over a single integer series.
+type integerStatsCountIterator struct {
+	*integerWindowReader
+	point query.IntegerPoint
+}
+
+func (itr *integerStatsCountIterator) reducedByStats() {}
+
+// Next returns the reduced point of the next interval.
+func (itr *integerStatsCountIterator) Next() (*query.IntegerPoint, error) {
+	w, err := itr.next()
+	if w == nil || err != nil {
+		return nil, err
+	}
+
+	itr.point.Time, itr.point.Value = w.start, w.count
+	itr.point.Aggregated = uint32(w.count)
+	return &itr.point, nil
+}
+
+// unsignedWindow holds the aggregates of the values of a series within an interval.
+type unsignedWindow struct {
+	start int64
+	count int64
+
+	sum, min, max, first, last            uint64
+	minTime, maxTime, firstTime, lastTime int64
+}
+
+// add adds a value to the window.  Values must be added in time order.
+func (w *unsignedWindow) add(t int64, v uint64) {
+	if w.count == 0 {
+		w.sum = v
+		w.min, w.minTime = v, t
+		w.max, w.maxTime = v, t
+		w.first, w.firstTime = v, t
+	} else {
+		w.sum += v
+		if v < w.min {
+			w.min, w.minTime = v, t
+		}
+		if v > w.max {
+			w.max, w.maxTime = v, t
+		}
+	}
+	w.last, w.lastTime = v, t
+	w.count++
+}
+
+// addStats adds the values of the block of e to the window using its statistics.
+func (w *unsignedWindow) addStats(e *IndexEntry) {
+	s := &e.Stats
+	sum, min, max := unsignedFromBits(s.Sum), unsignedFromBits(s.Min), unsignedFromBits(s.Max)
+	if w.count == 0 {
+		w.sum = sum
+		w.min, w.minTime = min, s.MinTime
+		w.max, w.maxTime = max, s.MaxTime
+		w.first, w.firstTime = unsignedFromBits(s.First), e.MinTime
+	} else {
+		w.sum += sum
+		if min < w.min {
+			w.min, w.minTime = min, s.MinTime
+		}
+		if max > w.max {
+			w.max, w.maxTime = max, s.MaxTime
+		}
+	}
+	w.last, w.lastTime = unsignedFromBits(s.Last), e.MaxTime
+	w.count += int64(s.Count)
+}
+
+// unsignedWindowReader reduces the values of a single unsigned series for each interval
+// of an ascending call.  Blocks lying entirely within an interval are summarised by
+// their statistics and only the blocks crossing an interval boundary are decoded.
+type unsignedWindowReader struct {
+	opt    query.IteratorOptions
+	window unsignedWindow
+
+	cache struct {
+		values Values
+		pos    int
+	}
+
+	tsm struct {
+		values    []UnsignedValue
+		pos       int
+		block     *IndexEntry
+		eof       bool
+		keyCursor *KeyCursor
+	}
+
+	statsLock sync.Mutex
+	stats     query.IteratorStats
+	statsBuf  query.IteratorStats
+	reads     *query.IteratorStats // blocks and cache values read
+}
+
+func newUnsignedWindowReader(opt query.IteratorOptions, cacheValues Values, tsmKeyCursor *KeyCursor) *unsignedWindowReader {
+	r := &unsignedWindowReader{
+		opt: opt,
+		statsBuf: query.IteratorStats{
+			SeriesN: 1,
+		},
+		reads: tsmKeyCursor.stats,
+	}
+	r.stats = r.statsBuf
+
+	seek := opt.SeekTime()
+	r.cache.values = cacheValues
+	r.cache.pos = sort.Search(len(r.cache.values), func(i int) bool {
+		return r.cache.values[i].UnixNano() >= seek
+	})
+
+	r.tsm.keyCursor = tsmKeyCursor
+	return r
+}
+
+// peekCache returns the current time/value from the cache.
+func (r *unsignedWindowReader) peekCache() (t int64, v uint64) {
+	if r.cache.pos >= len(r.cache.values) {
+		return tsdb.EOF, 0
+	}
+
+	item := r.cache.values[r.cache.pos]
+	return item.UnixNano(), item.(UnsignedValue).value
+}
+
+// peekTSM returns the current time/value from the decoded tsm values.
+func (r *unsignedWindowReader) peekTSM() (t int64, v uint64) {
+	if r.tsm.pos >= len(r.tsm.values) {
+		return tsdb.EOF, 0
+	}
+
+	item := r.tsm.values[r.tsm.pos]
+	return item.UnixNano(), item.value
+}
+
+// cacheOverlaps returns true if the cache holds unread values between min and max.
+func (r *unsignedWindowReader) cacheOverlaps(min, max int64) bool {
+	values := r.cache.values[r.cache.pos:]
+	i := sort.Search(len(values), func(i int) bool {
+		return values[i].UnixNano() >= min
+	})
+	return i < len(values) && values[i].UnixNano() <= max
+}
+
+// fill makes the next tsm data available once the decoded values are exhausted.
+// The next block is kept undecoded if it can be summarised by its statistics.
+func (r *unsignedWindowReader) fill() error {
+	if r.tsm.pos < len(r.tsm.values) || r.tsm.block != nil || r.tsm.eof {
+		return nil
+	}
+
+	if e := r.tsm.keyCursor.peekStats(r.opt.StartTime, r.opt.EndTime); e != nil && !r.cacheOverlaps(e.MinTime, e.MaxTime) {
+		r.tsm.block = e
+		return nil
+	}
+	return r.decode()
+}
+
+// decode decodes the next block.
+func (r *unsignedWindowReader) decode() error {
+	values, err := r.tsm.keyCursor.ReadUnsignedBlock(&r.tsm.values)
+	if err != nil {
+		return err
+	}
+	r.tsm.keyCursor.Next()
+
+	// The first block read may begin before the start of the query, whose values are
+	// not aggregated.
+	pos := sort.Search(len(values), func(i int) bool {
+		return values[i].UnixNano() >= r.opt.StartTime
+	})
+	r.tsm.values, r.tsm.pos, r.tsm.block = values, pos, nil
+	if len(values) == 0 {
+		r.tsm.eof = true
+	}
+	return nil
+}
+
+// next returns the aggregates of the next interval holding values, or nil once the
+// series has no more values within the time range of the query.
+func (r *unsignedWindowReader) next() (*unsignedWindow, error) {
+	w := &r.window
+	*w = unsignedWindow{}
+
+	var end int64
+	for {
+		if err := r.fill(); err != nil {
+			return nil, err
+		}
+
+		// Summarise the next block if no cached values precede it.
+		if e := r.tsm.block; e != nil {
+			if ckey, _ := r.peekCache(); ckey == tsdb.EOF || ckey > e.MaxTime {
+				if w.count == 0 {
+					w.start, end = r.opt.Window(e.MinTime)
+				} else if e.MinTime >= end {
+					return r.emit(w), nil
+				}
+
+				if e.MaxTime >= end {
+					// The block crosses the end of the interval.
+					if err := r.decode(); err != nil {
+						return nil, err
+					}
+					continue
+				}
+
+				w.addStats(e)
+				r.statsBuf.PointN += int(e.Stats.Count)
+				r.tsm.block = nil
+				r.tsm.keyCursor.skipBlock()
+				continue
+			}
+		}
+
+		ckey, cvalue := r.peekCache()
+		tkey, tvalue := r.peekTSM()
+
+		// No more data in cache or in TSM files.
+		if ckey == tsdb.EOF && tkey == tsdb.EOF {
+			return r.emit(w), nil
+		}
+
+		// Cache values take precedence over tsm values with the same time.
+		t, v := tkey, tvalue
+		if ckey != tsdb.EOF && (ckey <= tkey || tkey == tsdb.EOF) {
+			t, v = ckey, cvalue
+		}
+
+		// Exit if we are outside our time range or the interval.
+		if t > r.opt.EndTime {
+			return r.emit(w), nil
+		} else if w.count == 0 {
+			w.start, end = r.opt.Window(t)
+		} else if t >= end {
+			return r.emit(w), nil
+		}
+
+		if t == ckey {
+			r.cache.pos++
+			if r.reads != nil {
+				r.reads.CacheHitN++
+			}
+		}
+		if t == tkey {
+			r.tsm.pos++
+		}
+		w.add(t, v)
+		r.statsBuf.PointN++
+	}
+}
+
+// emit returns w if it holds any values and copies the stats buffer.
+func (r *unsignedWindowReader) emit(w *unsignedWindow) *unsignedWindow {
+	r.statsLock.Lock()
+	r.stats = r.statsBuf
+	if r.reads != nil {
+		r.stats.Add(*r.reads)
+	}
+	r.statsLock.Unlock()
+
+	if w.count == 0 {
+		return nil
+	}
+	return w
+}
+
+// Stats returns stats on the points processed.
+func (r *unsignedWindowReader) Stats() query.IteratorStats {
+	r.statsLock.Lock()
+	stats := r.stats
+	r.statsLock.Unlock()
+	return stats
+}
+
+// Close closes the reader and its key cursor.
+func (r *unsignedWindowReader) Close() error {
+	if r.tsm.keyCursor != nil {
+		r.tsm.keyCursor.Close()
+		r.tsm.keyCursor = nil
+	}
+	r.cache.values = nil
+	r.tsm.values = nil
+	r.tsm.block = nil
+	return nil
+}
+
+// newUnsignedStatsIterator returns an iterator answering the call of opt over a single
+// unsigned series using block statistics.
+func newUnsignedStatsIterator(name string, tags query.Tags, opt query.IteratorOptions, cacheValues Values, tsmKeyCursor *KeyCursor) statsIterator {
+	r := newUnsignedWindowReader(opt, cacheValues, tsmKeyCursor)
+
+	call := opt.Expr.(*influxql.Call).Name
+	if call == "count" {
+		return &unsignedStatsCountIterator{
+			unsignedWindowReader: r,
+			point:                query.IntegerPoint{Name: name, Tags: tags},
+		}
+	}
+	return &unsignedStatsIterator{
+		unsignedWindowReader: r,
+		call:                 call,
+		point:                query.UnsignedPoint{Name: name, Tags: tags},
+	}
+}
+
+// unsignedStatsIterator answers sum, min, max, first and last calls over a single
+// unsigned series.
+type unsignedStatsIterator struct {
+	*unsignedWindowReader
+	call  string
+	point query.UnsignedPoint
+}
+
+func (itr *unsignedStatsIterator) reducedByStats() {}
+
+// Next returns the reduced point of the next interval.
+func (itr *unsignedStatsIterator) Next() (*query.UnsignedPoint, error) {
+	w, err := itr.next()
+	if w == nil || err != nil {
+		return nil, err
+	}
+
+	switch itr.call {
+	case "sum":
+		itr.point.Time, itr.point.Value = w.start, w.sum
+	case "min":
+		itr.point.Time, itr.point.Value = w.minTime, w.min
+	case "max":
+		itr.point.Time, itr.point.Value = w.maxTime, w.max
+	case "first":
+		itr.point.Time, itr.point.Value = w.firstTime, w.first
+	case "last":
+		itr.point.Time, itr.point.Value = w.lastTime, w.last
+	}
+	itr.point.Aggregated = uint32(w.count)
+	return &itr.point, nil
+}
+
+// unsignedStatsCountIterator answers count calls over a single unsigned series.
+type unsignedStatsCountIterator struct {
+	*unsignedWindowReader
+	point query.IntegerPoint
+}
+
+func (itr *unsignedStatsCountIterator) reducedByStats() {}
+
+// Next returns the reduced point of the next interval.
+func (itr *unsignedStatsCountIterator) Next() (*query.IntegerPoint, error) {
+	w, err := itr.next()
+	if w == nil || err != nil {
+		return nil, err
+	}
+
+	itr.point.Time, itr.point.Value = w.start, w.count
+	itr.point.Aggregated = uint32(w.count)
+	return &itr.point, nil
+}
+
 var _ = fmt.Print
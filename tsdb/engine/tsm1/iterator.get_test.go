@@ -18,7 +18,7 @@ func BenchmarkIntegerIterator_Next(b *testing.B) {
 		&literalValueCursor{value: true},
 	}
 
-	cur := newIntegerIterator("m0", query.Tags{}, opt, &infiniteIntegerCursor{}, aux, nil, nil)
+	cur := newIntegerIterator("m0", query.Tags{}, opt, &infiniteIntegerCursor{}, aux, nil, nil, nil)
 
 	b.ResetTimer()
 	b.ReportAllocs()
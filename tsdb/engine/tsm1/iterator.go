@@ -28,6 +28,27 @@ func newLimitIterator(input query.Iterator, opt query.IteratorOptions) query.Ite
 	}
 }
 
+// statsIterator is implemented by the iterators that reduce a series for a call
+// using block statistics.  They are not wrapped in a call iterator.
+type statsIterator interface {
+	query.Iterator
+	reducedByStats()
+}
+
+// newContextWithCursorStats returns a new context counting the blocks and cache values
+// read by the cursors created with it into stats.  The cursors of a series are read by
+// a single goroutine, so the counts are not synchronized.
+func newContextWithCursorStats(ctx context.Context, stats *query.IteratorStats) context.Context {
+	return context.WithValue(ctx, cursorStatsKey, stats)
+}
+
+// cursorStatsFromContext returns the stats counting the reads of cursors created with
+// ctx, or nil.
+func cursorStatsFromContext(ctx context.Context) *query.IteratorStats {
+	stats, _ := ctx.Value(cursorStatsKey).(*query.IteratorStats)
+	return stats
+}
+
 type floatCastIntegerCursor struct {
 	cursor integerCursor
 }
@@ -0,0 +1,102 @@
+package tsm1
+
+import (
+	"context"
+	"sync"
+
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+type contextKey int
+
+const (
+	readSnapshotsKey contextKey = iota
+	cursorStatsKey
+)
+
+// ReadSnapshot is a consistent, read-only view of an engine at the time it was taken.
+// It pins the engine's TSM files and the stores and runs of its cache by reference,
+// and reads the files through views of their indexes, so neither writes nor deletes
+// change what it reads while it is open.
+type ReadSnapshot struct {
+	engine *Engine
+	files  []TSMFile // views of the pinned files
+	pinned []TSMFile
+	cache  *cacheView
+	once   sync.Once
+}
+
+// ReadSnapshot returns a snapshot of the engine's TSM files, cache and tombstones.
+// Iterators and cursors created with a context holding the snapshot read from it
+// instead of the engine, so a query sees one version of the shard even while
+// compactions replace its files.  The snapshot must be released when done.
+func (e *Engine) ReadSnapshot() (tsdb.ReadSnapshot, error) {
+	// A delete holds the lock for writing, so it is applied to all of the snapshot
+	// or to none of it.
+	e.readSnapshotMu.RLock()
+	defer e.readSnapshotMu.RUnlock()
+
+	// The cache is viewed before the files are pinned.  Values flushed from the cache
+	// in between are read from both, which deduplicates them, rather than from neither.
+	cache, err := e.Cache.view()
+	if err != nil {
+		return nil, err
+	}
+	files, pinned := e.FileStore.pinFiles()
+
+	return &ReadSnapshot{
+		engine: e,
+		files:  files,
+		pinned: pinned,
+		cache:  cache,
+	}, nil
+}
+
+// NewContext returns a new context holding s along with any snapshots of other engines
+// held by ctx.
+func (s *ReadSnapshot) NewContext(ctx context.Context) context.Context {
+	return NewContextWithReadSnapshot(ctx, s)
+}
+
+// Release unpins the files, stores and runs read by the snapshot.  Cursors already
+// created from the snapshot keep the files they read referenced until they are closed.
+func (s *ReadSnapshot) Release() {
+	s.once.Do(func() {
+		for _, f := range s.pinned {
+			f.Unref()
+		}
+		// Runs that cannot be removed are removed when the engine is next opened.
+		s.cache.release()
+		s.files, s.pinned = nil, nil
+	})
+}
+
+// NewContextWithReadSnapshot returns a new context holding s along with any snapshots
+// of other engines held by ctx, so a query over several shards can read each of
+// them from its own snapshot.
+func NewContextWithReadSnapshot(ctx context.Context, s *ReadSnapshot) context.Context {
+	prev, _ := ctx.Value(readSnapshotsKey).([]*ReadSnapshot)
+	snapshots := make([]*ReadSnapshot, 0, len(prev)+1)
+	snapshots = append(append(snapshots, prev...), s)
+	return context.WithValue(ctx, readSnapshotsKey, snapshots)
+}
+
+// readSnapshot returns the snapshot of the engine held by ctx, or nil.
+func (e *Engine) readSnapshot(ctx context.Context) *ReadSnapshot {
+	snapshots, _ := ctx.Value(readSnapshotsKey).([]*ReadSnapshot)
+	for _, s := range snapshots {
+		if s.engine == e {
+			return s
+		}
+	}
+	return nil
+}
+
+// cacheValues returns the values of key in the cache, or in the view of the cache held
+// by the snapshot of the engine in ctx.
+func (e *Engine) cacheValues(ctx context.Context, key []byte) (Values, error) {
+	if s := e.readSnapshot(ctx); s != nil {
+		return s.cache.values(key)
+	}
+	return e.Cache.ReadValues(key)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"hash/crc32"
 	"io"
 	"math"
 	"os"
@@ -12,6 +13,7 @@ import (
 	"sync"
 	"sync/atomic"
 
+	"github.com/influxdata/influxdb/pkg/bloom"
 	"github.com/influxdata/influxdb/pkg/bytesutil"
 )
 
@@ -46,6 +48,15 @@ type TSMReader struct {
 
 	// deleteMu limits concurrent deletes
 	deleteMu sync.Mutex
+
+	// parent is the reader a read view was taken of.  References to the view are
+	// counted on the parent, so its file is not closed while the view is read.
+	parent *TSMReader
+
+	// blocksEvicted is set once the blocks of the file are evicted from the block cache,
+	// so blocks read after it's removed from the file store aren't cached again.  It's
+	// protected by the mutex of the block cache.
+	blocksEvicted bool
 }
 
 // TSMIndex represent the index section of a TSM file.  The index records all
@@ -199,6 +210,11 @@ func (b *BlockIterator) Read() (key []byte, minTime int64, maxTime int64, typ by
 	return b.key, b.entries[0].MinTime, b.entries[0].MaxTime, b.typ, checksum, buf, err
 }
 
+// stats returns the statistics recorded in the index for the block to be read.
+func (b *BlockIterator) stats() BlockStats {
+	return b.entries[0].Stats
+}
+
 // Err returns any errors encounter during iteration.
 func (b *BlockIterator) Err() error {
 	return b.err
@@ -209,14 +225,16 @@ func (b *BlockIterator) Err() error {
 type blockAccessor interface {
 	init() (*indirectIndex, error)
 	read(key []byte, timestamp int64) ([]Value, error)
-	readAll(key []byte) ([]Value, error)
+	readAll(index TSMIndex, key []byte) ([]Value, error)
 	readBlock(entry *IndexEntry, values []Value) ([]Value, error)
 	readFloatBlock(entry *IndexEntry, values *[]FloatValue) ([]FloatValue, error)
 	readIntegerBlock(entry *IndexEntry, values *[]IntegerValue) ([]IntegerValue, error)
 	readUnsignedBlock(entry *IndexEntry, values *[]UnsignedValue) ([]UnsignedValue, error)
 	readStringBlock(entry *IndexEntry, values *[]StringValue) ([]StringValue, error)
+	readStringBlockMatching(entry *IndexEntry, values *[]StringValue, fn func(string) bool) ([]StringValue, error)
 	readBooleanBlock(entry *IndexEntry, values *[]BooleanValue) ([]BooleanValue, error)
 	readBytes(entry *IndexEntry, buf []byte) (uint32, []byte, error)
+	mayContainKey(key []byte) bool
 	rename(path string) error
 	path() string
 	close() error
@@ -360,6 +378,15 @@ func (t *TSMReader) ReadStringBlockAt(entry *IndexEntry, vals *[]StringValue) ([
 	return v, err
 }
 
+// ReadStringBlockMatchingAt returns the string values corresponding to the given index
+// entry for which fn returns true.
+func (t *TSMReader) ReadStringBlockMatchingAt(entry *IndexEntry, vals *[]StringValue, fn func(string) bool) ([]StringValue, error) {
+	t.mu.RLock()
+	v, err := t.accessor.readStringBlockMatching(entry, vals, fn)
+	t.mu.RUnlock()
+	return v, err
+}
+
 // ReadBooleanBlockAt returns the boolean values corresponding to the given index entry.
 func (t *TSMReader) ReadBooleanBlockAt(entry *IndexEntry, vals *[]BooleanValue) ([]BooleanValue, error) {
 	t.mu.RLock()
@@ -379,7 +406,7 @@ func (t *TSMReader) Read(key []byte, timestamp int64) ([]Value, error) {
 // ReadAll returns all values for a key in all blocks.
 func (t *TSMReader) ReadAll(key []byte) ([]Value, error) {
 	t.mu.RLock()
-	v, err := t.accessor.readAll(key)
+	v, err := t.accessor.readAll(t.index, key)
 	t.mu.RUnlock()
 	return v, err
 }
@@ -416,6 +443,10 @@ func (t *TSMReader) Close() error {
 // when the reader is closed or removed, the reader will remain open until
 // there are no more references.
 func (t *TSMReader) Ref() {
+	if t.parent != nil {
+		t.parent.Ref()
+		return
+	}
 	atomic.AddInt64(&t.refs, 1)
 }
 
@@ -423,15 +454,44 @@ func (t *TSMReader) Ref() {
 // by another goroutine while there were active references, the file will
 // be closed and remove
 func (t *TSMReader) Unref() {
+	if t.parent != nil {
+		t.parent.Unref()
+		return
+	}
 	atomic.AddInt64(&t.refs, -1)
 }
 
 // InUse returns whether the TSMReader currently has any active references.
 func (t *TSMReader) InUse() bool {
+	if t.parent != nil {
+		return t.parent.InUse()
+	}
 	refs := atomic.LoadInt64(&t.refs)
 	return refs > 0
 }
 
+// readView returns a reader of the file that later deletes from t do not change.  Blocks
+// are read through t, which must stay referenced while the view is read, as references
+// to the view are.  The view must not be closed or removed.
+func (t *TSMReader) readView() *TSMReader {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	index := t.index
+	if d, ok := index.(*indirectIndex); ok {
+		index = d.view()
+	}
+
+	return &TSMReader{
+		accessor:     t.accessor,
+		index:        index,
+		tombstoner:   t.tombstoner,
+		size:         t.size,
+		lastModified: t.lastModified,
+		parent:       t,
+	}
+}
+
 // Remove removes any underlying files stored on disk for this reader.
 func (t *TSMReader) Remove() error {
 	t.mu.Lock()
@@ -469,6 +529,17 @@ func (t *TSMReader) Contains(key []byte) bool {
 	return t.index.Contains(key)
 }
 
+// MayContainKey returns false if the bloom filter of the file shows that key is not
+// in the file.  Unlike Contains it does not search the index, so it may return true
+// for keys that are not in the file.  The bloom filter is read on first use.  Files
+// written without a bloom filter always return true.
+func (t *TSMReader) MayContainKey(key []byte) bool {
+	t.mu.RLock()
+	v := t.accessor.mayContainKey(key)
+	t.mu.RUnlock()
+	return v
+}
+
 // ContainsValue returns true if key and time might exists in this file.  This function could
 // return true even though the actual point does not exist.  For example, the key may
 // exist in this file, but not have a point exactly at time t.
@@ -594,7 +665,7 @@ func (t *TSMReader) TombstoneRange(key []byte) []TimeRange {
 func (t *TSMReader) Stats() FileStat {
 	minTime, maxTime := t.index.TimeRange()
 	minKey, maxKey := t.index.KeyRange()
-	return FileStat{
+	stat := FileStat{
 		Path:         t.Path(),
 		Size:         t.Size(),
 		LastModified: t.LastModified(),
@@ -602,8 +673,15 @@ func (t *TSMReader) Stats() FileStat {
 		MaxTime:      maxTime,
 		MinKey:       minKey,
 		MaxKey:       maxKey,
+		KeyCount:     t.index.KeyCount(),
 		HasTombstone: t.tombstoner.HasTombstones(),
 	}
+
+	for _, ts := range t.tombstoner.TombstoneFiles() {
+		stat.TombstoneSize += ts.Size
+	}
+	stat.TombstoneCount, stat.TombstoneMinTime, stat.TombstoneMaxTime = t.tombstoner.TombstoneStats()
+	return stat
 }
 
 // BlockIterator returns a BlockIterator for the underlying TSM file.
@@ -775,6 +853,13 @@ type indirectIndex struct {
 	// entry would exist here if a subset of the points for a key were deleted and the file
 	// had not be re-compacted to remove the points on disk.
 	tombstones map[string][]TimeRange
+
+	// shared is set once a view of the index is taken.  The offsets and tombstones are
+	// shared with the view, so they are copied before they are next changed.
+	shared bool
+
+	// mapped holds the offsets as mapped by UnmarshalBinary, which are unmapped on close.
+	mapped []byte
 }
 
 // TimeRange holds a min and max timestamp.
@@ -793,6 +878,39 @@ func NewIndirectIndex() *indirectIndex {
 	}
 }
 
+// view returns a copy of the index that later deletes do not change.
+func (d *indirectIndex) view() *indirectIndex {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.shared = true
+	return &indirectIndex{
+		b:          d.b,
+		offsets:    d.offsets,
+		minKey:     d.minKey,
+		maxKey:     d.maxKey,
+		minTime:    d.minTime,
+		maxTime:    d.maxTime,
+		tombstones: d.tombstones,
+	}
+}
+
+// copyOnWrite copies the offsets and tombstones shared with a view before they are
+// changed.  The caller must hold the write lock.
+func (d *indirectIndex) copyOnWrite() {
+	if !d.shared {
+		return
+	}
+
+	d.offsets = append([]byte(nil), d.offsets...)
+	tombstones := make(map[string][]TimeRange, len(d.tombstones))
+	for k, v := range d.tombstones {
+		tombstones[k] = v
+	}
+	d.tombstones = tombstones
+	d.shared = false
+}
+
 func (d *indirectIndex) offset(i int) int {
 	if i < 0 || i+4 > len(d.offsets) {
 		return -1
@@ -941,7 +1059,7 @@ func (d *indirectIndex) Key(idx int, entries *[]IndexEntry) ([]byte, byte, []Ind
 	ofs := binary.BigEndian.Uint32(d.offsets[idx*4 : idx*4+4])
 	n, key := readKey(d.b[ofs:])
 
-	typ := d.b[int(ofs)+n]
+	typ := d.b[int(ofs)+n] &^ indexStatsFlag
 
 	var ie indexEntries
 	if entries != nil {
@@ -969,7 +1087,7 @@ func (d *indirectIndex) KeyAt(idx int) ([]byte, byte) {
 
 	n, key := readKey(d.b[ofs:])
 	ofs = ofs + int32(n)
-	typ := d.b[ofs]
+	typ := d.b[ofs] &^ indexStatsFlag
 	d.mu.RUnlock()
 	return key, typ
 }
@@ -995,6 +1113,7 @@ func (d *indirectIndex) Delete(keys [][]byte) {
 	// Both keys and offsets are sorted.  Walk both in order and skip
 	// any keys that exist in both.
 	d.mu.Lock()
+	d.copyOnWrite()
 	start := d.searchOffset(keys[0])
 	for i := start * 4; i+4 <= len(d.offsets) && len(keys) > 0; i += 4 {
 		offset := binary.BigEndian.Uint32(d.offsets[i : i+4])
@@ -1082,8 +1201,9 @@ func (d *indirectIndex) DeleteRange(keys [][]byte, minTime, maxTime int64) {
 		existing := d.tombstones[string(k)]
 		d.mu.RUnlock()
 
-		// Append the new tombonstes to the existing ones
-		newTs := append(existing, append(tombstones[string(k)], TimeRange{minTime, maxTime})...)
+		// Append the new tombonstes to a copy of the existing ones, which may be
+		// shared with a view of the index.
+		newTs := append(append([]TimeRange(nil), existing...), append(tombstones[string(k)], TimeRange{minTime, maxTime})...)
 		fn := func(i, j int) bool {
 			a, b := newTs[i], newTs[j]
 			if a.Min == b.Min {
@@ -1144,6 +1264,7 @@ func (d *indirectIndex) DeleteRange(keys [][]byte, minTime, maxTime int64) {
 	}
 
 	d.mu.Lock()
+	d.copyOnWrite()
 	for k, v := range tombstones {
 		d.tombstones[k] = v
 	}
@@ -1191,7 +1312,7 @@ func (d *indirectIndex) Type(key []byte) (byte, error) {
 	if ofs < len(d.b) {
 		n, _ := readKey(d.b[ofs:])
 		ofs += n
-		return d.b[ofs], nil
+		return d.b[ofs] &^ indexStatsFlag, nil
 	}
 	return 0, fmt.Errorf("key does not exist: %s", key)
 }
@@ -1256,6 +1377,7 @@ func (d *indirectIndex) UnmarshalBinary(b []byte) error {
 			return fmt.Errorf("indirectIndex: not enough data for key length value")
 		}
 		i += 3 + int32(binary.BigEndian.Uint16(b[i:i+2]))
+		entrySize := int32(indexEntrySizeOf(b[i-1]))
 
 		// count of index entries
 		if i+indexCountSize >= iMax {
@@ -1273,7 +1395,7 @@ func (d *indirectIndex) UnmarshalBinary(b []byte) error {
 			minTime = minT
 		}
 
-		i += (count - 1) * indexEntrySize
+		i += (count - 1) * entrySize
 
 		// Find the max time for the block
 		if i+16 >= iMax {
@@ -1284,7 +1406,7 @@ func (d *indirectIndex) UnmarshalBinary(b []byte) error {
 			maxTime = maxT
 		}
 
-		i += indexEntrySize
+		i += entrySize
 	}
 
 	firstOfs := offsets[0]
@@ -1303,6 +1425,7 @@ func (d *indirectIndex) UnmarshalBinary(b []byte) error {
 	if err != nil {
 		return err
 	}
+	d.mapped = d.offsets
 	for i, v := range offsets {
 		binary.BigEndian.PutUint32(d.offsets[i*4:i*4+4], uint32(v))
 	}
@@ -1319,11 +1442,12 @@ func (d *indirectIndex) Size() uint32 {
 }
 
 func (d *indirectIndex) Close() error {
-	// Windows doesn't use the anonymous map for the offsets index
-	if runtime.GOOS == "windows" {
+	// Windows doesn't use the anonymous map for the offsets index.  Views of the
+	// index don't own the offsets they share.
+	if runtime.GOOS == "windows" || d.mapped == nil {
 		return nil
 	}
-	return munmap(d.offsets[:cap(d.offsets)])
+	return munmap(d.mapped[:cap(d.mapped)])
 }
 
 // mmapAccess is mmap based block accessor.  It access blocks through an
@@ -1339,6 +1463,11 @@ type mmapAccessor struct {
 	f     *os.File
 	b     []byte
 	index *indirectIndex
+
+	// bloom is the bloom filter of the keys in the file, loaded by bloomOnce
+	// and backed by b.
+	bloomOnce sync.Once
+	bloom     *bloom.Filter
 }
 
 func (m *mmapAccessor) init() (*indirectIndex, error) {
@@ -1428,6 +1557,9 @@ func (m *mmapAccessor) rename(path string) error {
 		return err
 	}
 
+	// The bloom filter refers to the old mapping so reload it on next use.
+	m.bloom, m.bloomOnce = nil, sync.Once{}
+
 	if err := m.f.Close(); err != nil {
 		return err
 	}
@@ -1562,6 +1694,25 @@ func (m *mmapAccessor) readStringBlock(entry *IndexEntry, values *[]StringValue)
 	return a, nil
 }
 
+func (m *mmapAccessor) readStringBlockMatching(entry *IndexEntry, values *[]StringValue, fn func(string) bool) ([]StringValue, error) {
+	m.incAccess()
+
+	m.mu.RLock()
+	if int64(len(m.b)) < entry.Offset+int64(entry.Size) {
+		m.mu.RUnlock()
+		return nil, ErrTSMClosed
+	}
+
+	a, err := DecodeStringBlockMatching(m.b[entry.Offset+4:entry.Offset+int64(entry.Size)], values, fn)
+	m.mu.RUnlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
 func (m *mmapAccessor) readBooleanBlock(entry *IndexEntry, values *[]BooleanValue) ([]BooleanValue, error) {
 	m.incAccess()
 
@@ -1597,16 +1748,17 @@ func (m *mmapAccessor) readBytes(entry *IndexEntry, b []byte) (uint32, []byte, e
 	return crc, block, nil
 }
 
-// readAll returns all values for a key in all blocks.
-func (m *mmapAccessor) readAll(key []byte) ([]Value, error) {
+// readAll returns the values of key in the blocks listed by index, which is the index of
+// the file or a view of it.
+func (m *mmapAccessor) readAll(index TSMIndex, key []byte) ([]Value, error) {
 	m.incAccess()
 
-	blocks := m.index.Entries(key)
+	blocks := index.Entries(key)
 	if len(blocks) == 0 {
 		return nil, nil
 	}
 
-	tombstones := m.index.TombstoneRange(key)
+	tombstones := index.TombstoneRange(key)
 
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -1666,10 +1818,65 @@ func (m *mmapAccessor) close() error {
 		return err
 	}
 
-	m.b = nil
+	m.b, m.bloom = nil, nil
 	return m.f.Close()
 }
 
+// mayContainKey returns false if the bloom filter of the file shows that key is not
+// in the file.
+func (m *mmapAccessor) mayContainKey(key []byte) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.b == nil {
+		return true
+	}
+
+	m.bloomOnce.Do(func() { m.bloom = m.readBloomFilter() })
+	if m.bloom == nil {
+		return true
+	}
+	m.incAccess()
+	return m.bloom.ContainsKey(key)
+}
+
+// readBloomFilter returns the bloom filter section preceding the index, or nil if
+// the file was written without one.
+func (m *mmapAccessor) readBloomFilter() *bloom.Filter {
+	indexOfsPos := len(m.b) - 8
+	indexStart := int64(binary.BigEndian.Uint64(m.b[indexOfsPos : indexOfsPos+8]))
+
+	// The section must fit between the header and the index.
+	trailerPos := indexStart - bloomTrailerSize
+	if trailerPos < 5 {
+		return nil
+	}
+
+	trailer := m.b[trailerPos:indexStart]
+	if binary.BigEndian.Uint32(trailer[20:24]) != bloomMagicNumber {
+		return nil
+	}
+
+	k := binary.BigEndian.Uint64(trailer[0:8])
+	size := binary.BigEndian.Uint64(trailer[8:16])
+	if size > uint64(trailerPos-5) {
+		return nil
+	}
+
+	// The end of the blocks of older files can match the magic number, so only
+	// use the filter if its checksum matches.
+	b := m.b[trailerPos-int64(size) : trailerPos]
+	if crc32.ChecksumIEEE(b) != binary.BigEndian.Uint32(trailer[16:20]) {
+		return nil
+	}
+
+	filter, err := bloom.NewFilterBuffer(b, k)
+	if err != nil {
+		return nil
+	}
+	return filter
+}
+
 type indexEntries struct {
 	Type    byte
 	entries []IndexEntry
@@ -1681,23 +1888,50 @@ func (a *indexEntries) Less(i, j int) bool {
 	return a.entries[i].MinTime < a.entries[j].MinTime
 }
 
+// hasStats returns true if every entry carries block statistics.
+func (a *indexEntries) hasStats() bool {
+	for i := range a.entries {
+		if !a.entries[i].HasStats() {
+			return false
+		}
+	}
+	return len(a.entries) > 0
+}
+
 func (a *indexEntries) MarshalBinary() ([]byte, error) {
-	buf := make([]byte, len(a.entries)*indexEntrySize)
+	size := indexEntrySize
+	stats := a.hasStats()
+	if stats {
+		size += indexStatsSize
+	}
+	buf := make([]byte, len(a.entries)*size)
 
 	for i, entry := range a.entries {
-		entry.AppendTo(buf[indexEntrySize*i:])
+		entry.AppendTo(buf[size*i:])
+		if stats {
+			entry.Stats.AppendTo(buf[size*i+indexEntrySize:])
+		}
 	}
 
 	return buf, nil
 }
 
 func (a *indexEntries) WriteTo(w io.Writer) (total int64, err error) {
-	var buf [indexEntrySize]byte
+	var buf [indexEntrySize + indexStatsSize]byte
 	var n int
 
+	size := indexEntrySize
+	stats := a.hasStats()
+	if stats {
+		size += indexStatsSize
+	}
+
 	for _, entry := range a.entries {
 		entry.AppendTo(buf[:])
-		n, err = w.Write(buf[:])
+		if stats {
+			entry.Stats.AppendTo(buf[indexEntrySize:])
+		}
+		n, err = w.Write(buf[:size])
 		total += int64(n)
 		if err != nil {
 			return total, err
@@ -1718,13 +1952,24 @@ func readKey(b []byte) (n int, key []byte) {
 	return
 }
 
+// indexEntrySizeOf returns the size in bytes of each index entry of a key with
+// the given block type, including any block statistics.
+func indexEntrySizeOf(typ byte) int {
+	if typ&indexStatsFlag != 0 {
+		return indexEntrySize + indexStatsSize
+	}
+	return indexEntrySize
+}
+
 func readEntries(b []byte, entries *indexEntries) (n int, err error) {
 	if len(b) < 1+indexCountSize {
 		return 0, fmt.Errorf("readEntries: data too short for headers")
 	}
 
 	// 1 byte block type
-	entries.Type = b[n]
+	entries.Type = b[n] &^ indexStatsFlag
+	stats := b[n]&indexStatsFlag != 0
+	size := indexEntrySizeOf(b[n])
 	n++
 
 	// 2 byte count of index entries
@@ -1739,13 +1984,21 @@ func readEntries(b []byte, entries *indexEntries) (n int, err error) {
 
 	b = b[indexCountSize+indexTypeSize:]
 	for i := 0; i < len(entries.entries); i++ {
-		if err = entries.entries[i].UnmarshalBinary(b); err != nil {
+		e := &entries.entries[i]
+		if err = e.UnmarshalBinary(b); err != nil {
 			return 0, fmt.Errorf("readEntries: unmarshal error: %v", err)
 		}
-		b = b[indexEntrySize:]
+
+		e.Stats = BlockStats{}
+		if stats {
+			if err = e.Stats.UnmarshalBinary(b[indexEntrySize:]); err != nil {
+				return 0, fmt.Errorf("readEntries: unmarshal stats error: %v", err)
+			}
+		}
+		b = b[size:]
 	}
 
-	n += count * indexEntrySize
+	n += count * size
 
 	return
 }
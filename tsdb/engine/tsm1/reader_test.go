@@ -1,6 +1,7 @@
 package tsm1
 
 import (
+	"encoding/binary"
 	"fmt"
 	"io/ioutil"
 	"math"
@@ -1114,6 +1115,43 @@ func TestIndirectIndex_Entries(t *testing.T) {
 	}
 }
 
+// Ensure a view of an index is not changed by later deletes.
+func TestIndirectIndex_View_Delete(t *testing.T) {
+	index := NewIndexWriter()
+	index.Add([]byte("cpu"), BlockFloat64, 0, 1, 10, 100)
+	index.Add([]byte("cpu"), BlockFloat64, 2, 3, 20, 200)
+	index.Add([]byte("mem"), BlockFloat64, 0, 1, 10, 100)
+
+	b, err := index.MarshalBinary()
+	if err != nil {
+		t.Fatalf("unexpected error marshaling index: %v", err)
+	}
+
+	indirect := NewIndirectIndex()
+	if err := indirect.UnmarshalBinary(b); err != nil {
+		t.Fatalf("unexpected error unmarshaling index: %v", err)
+	}
+	defer indirect.Close()
+
+	view := indirect.view()
+	indirect.DeleteRange([][]byte{[]byte("cpu")}, 0, 1)
+	indirect.Delete([][]byte{[]byte("mem")})
+
+	if got, exp := len(indirect.TombstoneRange([]byte("cpu"))), 1; got != exp {
+		t.Fatalf("tombstones length mismatch: got %v, exp %v", got, exp)
+	} else if got, exp := len(indirect.Entries([]byte("mem"))), 0; got != exp {
+		t.Fatalf("entries length mismatch: got %v, exp %v", got, exp)
+	}
+
+	if got, exp := len(view.TombstoneRange([]byte("cpu"))), 0; got != exp {
+		t.Fatalf("view tombstones length mismatch: got %v, exp %v", got, exp)
+	} else if got, exp := len(view.Entries([]byte("cpu"))), 2; got != exp {
+		t.Fatalf("view entries length mismatch: got %v, exp %v", got, exp)
+	} else if got, exp := len(view.Entries([]byte("mem"))), 1; got != exp {
+		t.Fatalf("view entries length mismatch: got %v, exp %v", got, exp)
+	}
+}
+
 func TestIndirectIndex_Entries_NonExistent(t *testing.T) {
 	index := NewIndexWriter()
 	index.Add([]byte("cpu"), BlockFloat64, 0, 1, 10, 100)
@@ -1869,6 +1907,121 @@ func TestTSMReader_References(t *testing.T) {
 	}
 }
 
+func TestTSMReader_MayContainKey(t *testing.T) {
+	dir := mustTempDir()
+	defer os.RemoveAll(dir)
+	f := mustTempFile(dir)
+
+	w, err := NewTSMWriter(f)
+	if err != nil {
+		t.Fatalf("unexpected error creating writer: %v", err)
+	}
+
+	for i := 0; i < 1000; i++ {
+		if err := w.Write([]byte(fmt.Sprintf("cpu,host=server-%04d#!~#value", i)), []Value{NewValue(1, 1.0)}); err != nil {
+			t.Fatalf("unexpected error writing: %v", err)
+		}
+	}
+
+	if err := w.WriteIndex(); err != nil {
+		t.Fatalf("unexpected error writing index: %v", err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected error closing: %v", err)
+	}
+
+	f, err = os.Open(f.Name())
+	if err != nil {
+		t.Fatalf("unexpected error open file: %v", err)
+	}
+
+	r, err := NewTSMReader(f)
+	if err != nil {
+		t.Fatalf("unexpected error created reader: %v", err)
+	}
+	defer r.Close()
+
+	for i := 0; i < 1000; i++ {
+		if key := []byte(fmt.Sprintf("cpu,host=server-%04d#!~#value", i)); !r.MayContainKey(key) {
+			t.Fatalf("expected file to contain %s", key)
+		}
+	}
+
+	// The filter is sized for a 1% false positive rate.
+	var n int
+	for i := 1000; i < 2000; i++ {
+		if r.MayContainKey([]byte(fmt.Sprintf("cpu,host=server-%04d#!~#value", i))) {
+			n++
+		}
+	}
+	if n > 50 {
+		t.Fatalf("too many false positives: %d", n)
+	}
+}
+
+func TestTSMReader_MayContainKey_NoBloomFilter(t *testing.T) {
+	dir := mustTempDir()
+	defer os.RemoveAll(dir)
+	f := mustTempFile(dir)
+
+	w, err := NewTSMWriter(f)
+	if err != nil {
+		t.Fatalf("unexpected error creating writer: %v", err)
+	}
+
+	if err := w.Write([]byte("cpu"), []Value{NewValue(1, 1.0)}); err != nil {
+		t.Fatalf("unexpected error writing: %v", err)
+	}
+
+	if err := w.WriteIndex(); err != nil {
+		t.Fatalf("unexpected error writing index: %v", err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected error closing: %v", err)
+	}
+
+	// Remove the bloom filter section to produce a file in the format used before
+	// bloom filters were added.
+	b, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		t.Fatalf("unexpected error reading file: %v", err)
+	}
+
+	indexStart := binary.BigEndian.Uint64(b[len(b)-8:])
+	trailer := b[indexStart-bloomTrailerSize : indexStart]
+	bloomStart := indexStart - bloomTrailerSize - binary.BigEndian.Uint64(trailer[8:16])
+
+	old := append(append([]byte(nil), b[:bloomStart]...), b[indexStart:]...)
+	binary.BigEndian.PutUint64(old[len(old)-8:], bloomStart)
+	if err := ioutil.WriteFile(f.Name(), old, 0666); err != nil {
+		t.Fatalf("unexpected error writing file: %v", err)
+	}
+
+	f, err = os.Open(f.Name())
+	if err != nil {
+		t.Fatalf("unexpected error open file: %v", err)
+	}
+
+	r, err := NewTSMReader(f)
+	if err != nil {
+		t.Fatalf("unexpected error created reader: %v", err)
+	}
+	defer r.Close()
+
+	if !r.MayContainKey([]byte("cpu")) || !r.MayContainKey([]byte("mem")) {
+		t.Fatal("expected file without bloom filter to possibly contain every key")
+	}
+
+	values, err := r.ReadAll([]byte("cpu"))
+	if err != nil {
+		t.Fatalf("unexpected error reading: %v", err)
+	} else if len(values) != 1 || values[0].Value() != 1.0 {
+		t.Fatalf("unexpected values: %v", values)
+	}
+}
+
 func BenchmarkIndirectIndex_UnmarshalBinary(b *testing.B) {
 	index := NewIndexWriter()
 	for i := 0; i < 100000; i++ {
@@ -0,0 +1,464 @@
+package tsm1
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// RollupFileName is the name of the file in the shard directory recording the
+// rollup rule that has been applied to the shard.
+const RollupFileName = "rollup"
+
+// rollupPendingFileName is the name of the file in the shard directory recording a
+// rollup compaction whose files are being replaced.
+const rollupPendingFileName = "rollup.pending"
+
+// pendingRollup records the files of a rollup compaction while they are replaced, so
+// a crash during the replacement can be completed or undone when the shard is opened.
+type pendingRollup struct {
+	Rule     *tsdb.RollupRule `json:"rule"`
+	OldFiles []string         `json:"oldFiles"` // raw files being replaced
+	NewFiles []string         `json:"newFiles"` // rolled up files, with their tmp extension
+}
+
+// rollupKeyIterator wraps a KeyIterator and replaces the raw values of each field with
+// aggregates computed over fixed windows of time.  All of the blocks of a series are
+// buffered while the series is rolled up, so memory use is bounded by the number of
+// values stored for the largest series in the shard.
+type rollupKeyIterator struct {
+	iter KeyIterator
+
+	interval int64
+	funcs    []string
+
+	// size is the maximum number of values to encode in a single block
+	size int
+
+	// stringCompression is the encoding type used to compress string blocks
+	stringCompression byte
+
+	// next is the block read from iter that belongs to the following series
+	next    rollupBlock
+	hasNext bool
+
+	// blocks are the encoded rollup blocks of the current series
+	blocks []rollupBlock
+	pos    int
+
+	err error
+}
+
+type rollupBlock struct {
+	key              []byte
+	minTime, maxTime int64
+	data             []byte
+}
+
+// rollupField is the set of values to write for a single key of a series.
+type rollupField struct {
+	key    []byte
+	values []Value
+}
+
+// newRollupKeyIterator returns a KeyIterator that rolls up the values read from iter
+// using rule.
+func newRollupKeyIterator(iter KeyIterator, rule *tsdb.RollupRule, size int, stringCompression byte) *rollupKeyIterator {
+	return &rollupKeyIterator{
+		iter:              iter,
+		interval:          int64(rule.Interval),
+		funcs:             rule.Functions,
+		size:              size,
+		stringCompression: stringCompression,
+	}
+}
+
+// Next returns true if there are any blocks remaining in the iterator.
+func (k *rollupKeyIterator) Next() bool {
+	k.pos++
+	for k.err == nil && k.pos >= len(k.blocks) {
+		k.blocks, k.pos = k.blocks[:0], 0
+		if !k.hasNext && !k.readNext() {
+			return false
+		}
+		k.err = k.rollupSeries()
+	}
+	return k.err == nil
+}
+
+// Read returns the key, time range and encoded data of the current block.
+func (k *rollupKeyIterator) Read() ([]byte, int64, int64, []byte, error) {
+	if k.err != nil {
+		return nil, 0, 0, nil, k.err
+	}
+	b := k.blocks[k.pos]
+	return b.key, b.minTime, b.maxTime, b.data, nil
+}
+
+// Close closes the underlying iterator.
+func (k *rollupKeyIterator) Close() error {
+	k.blocks = nil
+	return k.iter.Close()
+}
+
+// Err returns any errors encountered during iteration.
+func (k *rollupKeyIterator) Err() error {
+	if k.err != nil {
+		return k.err
+	}
+	return k.iter.Err()
+}
+
+// readNext reads the next block from the underlying iterator.  It returns false
+// if the iterator is exhausted or an error occurred.
+func (k *rollupKeyIterator) readNext() bool {
+	if !k.iter.Next() {
+		return false
+	}
+
+	key, minTime, maxTime, data, err := k.iter.Read()
+	if err != nil {
+		k.err = err
+		return false
+	}
+
+	k.next = rollupBlock{
+		key:     append([]byte(nil), key...),
+		minTime: minTime,
+		maxTime: maxTime,
+		data:    data,
+	}
+	k.hasNext = true
+	return true
+}
+
+// rollupSeries reads all of the blocks of the next series and encodes its rolled up
+// values.  Keys for the same series are adjacent in the underlying iterator since the
+// series key is always followed by the field separator.
+func (k *rollupKeyIterator) rollupSeries() error {
+	seriesKey, _ := SeriesAndFieldFromCompositeKey(k.next.key)
+
+	var fields []rollupField
+	for k.hasNext {
+		sk, field := SeriesAndFieldFromCompositeKey(k.next.key)
+		if !bytes.Equal(sk, seriesKey) {
+			break
+		}
+
+		values, err := DecodeBlock(k.next.data, nil)
+		if err != nil {
+			return err
+		}
+
+		// Blocks of the same key are returned in time order.
+		if n := len(fields); n > 0 && bytes.Equal(fields[n-1].key, field) {
+			fields[n-1].values = append(fields[n-1].values, values...)
+		} else {
+			fields = append(fields, rollupField{key: field, values: values})
+		}
+
+		k.hasNext = false
+		if !k.readNext() && k.err != nil {
+			return k.err
+		}
+	}
+
+	// Replace each raw field with its aggregates.
+	var out []rollupField
+	for _, f := range fields {
+		var rolledUp bool
+		for _, fn := range k.funcs {
+			values := rollupValues(f.values, fn, k.interval)
+			if values == nil {
+				continue
+			}
+			out = append(out, rollupField{
+				key:    SeriesFieldKeyBytes(string(seriesKey), fn+"_"+string(f.key)),
+				values: values,
+			})
+			rolledUp = true
+		}
+
+		// Keep the raw values of fields that none of the functions apply to.
+		if !rolledUp {
+			out = append(out, rollupField{
+				key:    SeriesFieldKeyBytes(string(seriesKey), string(f.key)),
+				values: f.values,
+			})
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool { return bytes.Compare(out[i].key, out[j].key) < 0 })
+	for i := 1; i < len(out); i++ {
+		if bytes.Equal(out[i-1].key, out[i].key) {
+			return fmt.Errorf("rollup of %s conflicts with an existing field", out[i].key)
+		}
+	}
+
+	for _, f := range out {
+		for i := 0; i < len(f.values); i += k.size {
+			j := i + k.size
+			if j > len(f.values) {
+				j = len(f.values)
+			}
+
+			data, err := k.encode(f.values[i:j])
+			if err != nil {
+				return err
+			}
+
+			k.blocks = append(k.blocks, rollupBlock{
+				key:     f.key,
+				minTime: f.values[i].UnixNano(),
+				maxTime: f.values[j-1].UnixNano(),
+				data:    data,
+			})
+		}
+	}
+	return nil
+}
+
+// encode encodes values into a block, compressing string blocks using the
+// iterator's string compression.
+func (k *rollupKeyIterator) encode(values Values) ([]byte, error) {
+	return encodeValuesBlock(values, k.stringCompression)
+}
+
+// encodeValuesBlock encodes values into a block, compressing string blocks using
+// stringCompression.
+func encodeValuesBlock(values Values, stringCompression byte) ([]byte, error) {
+	if _, ok := values[0].(StringValue); !ok {
+		return values.Encode(nil)
+	}
+
+	a := make([]StringValue, 0, len(values))
+	for _, v := range values {
+		if sv, ok := v.(StringValue); ok {
+			a = append(a, sv)
+		}
+	}
+	return encodeCompressedStringValuesBlock(nil, a, stringCompression)
+}
+
+// rollupValues applies the rollup function fn to each window of interval in values,
+// which must be sorted by time.  Each aggregate is timestamped with the start of its
+// window.  It returns nil if fn cannot be applied to the type of values.
+func rollupValues(values []Value, fn string, interval int64) []Value {
+	if len(values) == 0 {
+		return nil
+	}
+
+	switch fn {
+	case "mean", "min", "max", "sum":
+		switch values[0].(type) {
+		case FloatValue, IntegerValue, UnsignedValue:
+		default:
+			return nil
+		}
+	}
+
+	var a []Value
+	for i := 0; i < len(values); {
+		start := windowStart(values[i].UnixNano(), interval)
+		j := i + 1
+		for j < len(values) && windowStart(values[j].UnixNano(), interval) == start {
+			j++
+		}
+
+		if v := rollupWindow(values[i:j], fn, start); v != nil {
+			a = append(a, v)
+		}
+		i = j
+	}
+	return a
+}
+
+// windowStart returns the start of the window of interval that t falls within.
+func windowStart(t, interval int64) int64 {
+	return t - ((t%interval)+interval)%interval
+}
+
+// rollupWindow returns the aggregate of the values in a single window.
+func rollupWindow(values []Value, fn string, start int64) Value {
+	switch fn {
+	case "count":
+		return NewIntegerValue(start, int64(len(values)))
+	case "first":
+		return NewValue(start, values[0].Value())
+	case "last":
+		return NewValue(start, values[len(values)-1].Value())
+	}
+
+	switch values[0].(type) {
+	case FloatValue:
+		var sum float64
+		min, max := math.Inf(1), math.Inf(-1)
+		for _, v := range values {
+			f, _ := v.Value().(float64)
+			sum += f
+			min, max = math.Min(min, f), math.Max(max, f)
+		}
+		return numericAggregate(fn, start, len(values), sum,
+			NewFloatValue(start, sum), NewFloatValue(start, min), NewFloatValue(start, max))
+
+	case IntegerValue:
+		var sum int64
+		min, max := int64(math.MaxInt64), int64(math.MinInt64)
+		for _, v := range values {
+			n, _ := v.Value().(int64)
+			sum += n
+			if n < min {
+				min = n
+			}
+			if n > max {
+				max = n
+			}
+		}
+		return numericAggregate(fn, start, len(values), float64(sum),
+			NewIntegerValue(start, sum), NewIntegerValue(start, min), NewIntegerValue(start, max))
+
+	case UnsignedValue:
+		var sum uint64
+		min, max := uint64(math.MaxUint64), uint64(0)
+		for _, v := range values {
+			n, _ := v.Value().(uint64)
+			sum += n
+			if n < min {
+				min = n
+			}
+			if n > max {
+				max = n
+			}
+		}
+		return numericAggregate(fn, start, len(values), float64(sum),
+			NewUnsignedValue(start, sum), NewUnsignedValue(start, min), NewUnsignedValue(start, max))
+	}
+	return nil
+}
+
+// numericAggregate selects the result of fn from the aggregates of a window.  The mean
+// is always a float, as it is for queries.
+func numericAggregate(fn string, start int64, n int, total float64, sum, min, max Value) Value {
+	switch fn {
+	case "mean":
+		return NewFloatValue(start, total/float64(n))
+	case "sum":
+		return sum
+	case "min":
+		return min
+	case "max":
+		return max
+	}
+	return nil
+}
+
+// readRollupFile returns the rollup rule recorded in dir, or nil if the shard has
+// not been rolled up.
+func readRollupFile(dir string) (*tsdb.RollupRule, error) {
+	buf, err := ioutil.ReadFile(filepath.Join(dir, RollupFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var rule tsdb.RollupRule
+	if err := json.Unmarshal(buf, &rule); err != nil {
+		return nil, fmt.Errorf("invalid rollup file: %s", err)
+	}
+	return &rule, nil
+}
+
+// writeRollupFile records in dir that rule has been applied to the shard.
+func writeRollupFile(dir string, rule *tsdb.RollupRule) error {
+	return writeJSONFile(filepath.Join(dir, RollupFileName), rule)
+}
+
+// writePendingRollup records in dir that oldFiles are being replaced by the rolled up
+// newFiles.
+func writePendingRollup(dir string, rule *tsdb.RollupRule, oldFiles, newFiles []string) error {
+	return writeJSONFile(filepath.Join(dir, rollupPendingFileName), &pendingRollup{
+		Rule:     rule,
+		OldFiles: oldFiles,
+		NewFiles: newFiles,
+	})
+}
+
+// resolvePendingRollup completes or undoes a rollup compaction that was interrupted
+// while its files were replaced.  If any rolled up file was made live, the replacement
+// is completed by making the rest live, removing the raw files and recording the rule.
+// Otherwise the rollup is discarded so it runs again.
+func resolvePendingRollup(dir string) error {
+	path := filepath.Join(dir, rollupPendingFileName)
+	buf, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var p pendingRollup
+	if err := json.Unmarshal(buf, &p); err != nil {
+		return fmt.Errorf("invalid pending rollup file: %s", err)
+	}
+
+	var replaced bool
+	for _, file := range p.NewFiles {
+		if _, err := os.Stat(strings.TrimSuffix(file, "."+CompactionTempExtension)); err == nil {
+			replaced = true
+			break
+		}
+	}
+
+	if replaced {
+		for _, file := range p.NewFiles {
+			if !strings.HasSuffix(file, "."+CompactionTempExtension) {
+				continue
+			} else if err := os.Rename(file, strings.TrimSuffix(file, "."+CompactionTempExtension)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		for _, file := range p.OldFiles {
+			tombstone := (&Tombstoner{Path: file}).tombstonePath()
+			if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+				return err
+			} else if err := os.Remove(tombstone); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		if err := writeRollupFile(dir, p.Rule); err != nil {
+			return err
+		}
+	}
+
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// writeJSONFile atomically replaces the file at path with the JSON encoding of v.
+func writeJSONFile(path string, v interface{}) error {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	tmp := path + "." + CompactionTempExtension
+	if err := ioutil.WriteFile(tmp, buf, 0666); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
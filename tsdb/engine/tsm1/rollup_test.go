@@ -0,0 +1,87 @@
+package tsm1
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/tsdb"
+)
+
+// Ensure a rollup interrupted while its files were replaced is completed on open if
+// any rolled up file was made live, and discarded otherwise.
+func TestResolvePendingRollup(t *testing.T) {
+	rule := &tsdb.RollupRule{After: time.Hour, Interval: time.Minute, Functions: []string{"mean"}}
+
+	for _, tt := range []struct {
+		name     string
+		replaced bool
+	}{
+		{name: "replaced", replaced: true},
+		{name: "not replaced", replaced: false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := mustTempDir()
+			defer os.RemoveAll(dir)
+
+			oldFiles := []string{filepath.Join(dir, "000000001-000000001.tsm"), filepath.Join(dir, "000000002-000000001.tsm")}
+			newFiles := []string{filepath.Join(dir, "000000002-000000002.tsm.tmp"), filepath.Join(dir, "000000002-000000003.tsm.tmp")}
+			for _, path := range append([]string{filepath.Join(dir, "000000001-000000001.tombstone")}, oldFiles...) {
+				if err := ioutil.WriteFile(path, nil, 0666); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// The first rolled up file was made live before the crash.
+			if tt.replaced {
+				if err := ioutil.WriteFile(newFiles[0][:len(newFiles[0])-4], nil, 0666); err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				if err := ioutil.WriteFile(newFiles[0], nil, 0666); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := ioutil.WriteFile(newFiles[1], nil, 0666); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := writePendingRollup(dir, rule, oldFiles, newFiles); err != nil {
+				t.Fatal(err)
+			} else if err := resolvePendingRollup(dir); err != nil {
+				t.Fatal(err)
+			}
+
+			names, err := filepath.Glob(filepath.Join(dir, "*"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range names {
+				names[i] = filepath.Base(names[i])
+			}
+
+			rolledUp, err := readRollupFile(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.replaced {
+				if exp := []string{"000000002-000000002.tsm", "000000002-000000003.tsm", RollupFileName}; !reflect.DeepEqual(names, exp) {
+					t.Fatalf("unexpected files: got %v, exp %v", names, exp)
+				} else if !reflect.DeepEqual(rolledUp, rule) {
+					t.Fatalf("unexpected rollup: %#v", rolledUp)
+				}
+			} else {
+				exp := []string{"000000001-000000001.tombstone", "000000001-000000001.tsm", "000000002-000000001.tsm", "000000002-000000002.tsm.tmp", "000000002-000000003.tsm.tmp"}
+				if !reflect.DeepEqual(names, exp) {
+					t.Fatalf("unexpected files: got %v, exp %v", names, exp)
+				} else if rolledUp != nil {
+					t.Fatalf("unexpected rollup: %#v", rolledUp)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package tsm1
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SealFileName is the name of the file in the shard directory holding the manifest
+// of a sealed, read-only shard.
+const SealFileName = "sealed.manifest"
+
+// SealManifest lists the TSM and tombstone files of a sealed shard along with their
+// checksums.  The files of a sealed shard are never modified, so the manifest can be
+// used to check that they are intact.
+type SealManifest struct {
+	SealedAt time.Time    `json:"sealed_at"`
+	Files    []SealedFile `json:"files"`
+}
+
+// SealedFile is a file of a sealed shard.
+type SealedFile struct {
+	// Name is the base name of the file.
+	Name string `json:"name"`
+
+	// Cold is true if the file is stored in the shard's directory on the cold tier.
+	Cold bool `json:"cold,omitempty"`
+
+	Size   int64       `json:"size"`
+	Mode   os.FileMode `json:"mode"`
+	SHA256 string      `json:"sha256"`
+}
+
+// newSealedFile returns the SealedFile of the file at path.
+func newSealedFile(path string, cold bool) (SealedFile, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return SealedFile{}, err
+	}
+
+	sum, err := fileSHA256(path)
+	if err != nil {
+		return SealedFile{}, err
+	}
+
+	return SealedFile{
+		Name:   filepath.Base(path),
+		Cold:   cold,
+		Size:   fi.Size(),
+		Mode:   fi.Mode().Perm(),
+		SHA256: sum,
+	}, nil
+}
+
+// Verify returns an error if the file in dir does not match the manifest.
+func (f SealedFile) Verify(dir string) error {
+	path := filepath.Join(dir, f.Name)
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	} else if fi.Size() != f.Size {
+		return fmt.Errorf("%s: size is %d but expected %d", path, fi.Size(), f.Size)
+	}
+
+	sum, err := fileSHA256(path)
+	if err != nil {
+		return err
+	} else if sum != f.SHA256 {
+		return fmt.Errorf("%s: checksum is %s but expected %s", path, sum, f.SHA256)
+	}
+	return nil
+}
+
+// fileSHA256 returns the hex encoded SHA-256 checksum of the file at path.
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ReadSealManifest returns the manifest of the sealed shard in dir, or nil if the shard
+// is not sealed.
+func ReadSealManifest(dir string) (*SealManifest, error) {
+	buf, err := ioutil.ReadFile(filepath.Join(dir, SealFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var m SealManifest
+	if err := json.Unmarshal(buf, &m); err != nil {
+		return nil, fmt.Errorf("invalid seal manifest: %s", err)
+	}
+	return &m, nil
+}
+
+// writeSealManifest writes the manifest of the sealed shard to dir.
+func writeSealManifest(dir string, m *SealManifest) error {
+	buf, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, SealFileName)
+	tmp := path + "." + CompactionTempExtension
+	if err := ioutil.WriteFile(tmp, buf, 0444); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
@@ -1,36 +1,104 @@
 package tsm1
 
-// String encoding uses snappy compression to compress each string.  Each string is
-// appended to byte slice prefixed with a variable byte length followed by the string
-// bytes.  The bytes are compressed using snappy compressor and a 1 byte header is used
-// to indicate the type of encoding.
+// String encoding compresses each block of strings.  Each string is appended to
+// byte slice prefixed with a variable byte length followed by the string bytes.
+// The bytes are compressed using snappy compression by default, or optionally left
+// uncompressed or compressed using DEFLATE, and a 1 byte header is used to indicate
+// the type of encoding.
+//
+// Blocks that would be compressed using snappy are instead dictionary encoded when
+// they hold few distinct values, such as the states of an enum.  The header is
+// followed by the count of values and the count of distinct values as variable
+// byte integers, the distinct values in the same format as other encodings, and
+// the index of each value in the distinct values packed into 0, 1, 2, 4 or 8 bits
+// depending on their count.  Readers can compare the distinct values once and
+// then match the remaining values by their index.
 
 import (
+	"bytes"
+	"compress/flate"
 	"encoding/binary"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"sync"
 
 	"github.com/golang/snappy"
 )
 
 const (
 	// stringUncompressed is a an uncompressed format encoding strings as raw bytes.
-	// Not yet implemented.
 	stringUncompressed = 0
 
 	// stringCompressedSnappy is a compressed encoding using Snappy compression
 	stringCompressedSnappy = 1
+
+	// stringCompressedDeflate is a compressed encoding using DEFLATE compression.  It
+	// is slower than Snappy but compresses repetitive, log-like strings much better.
+	stringCompressedDeflate = 2
+
+	// stringCompressedDictionary is an encoding storing each distinct string once and
+	// each value as its index in the distinct strings.
+	stringCompressedDictionary = 3
+)
+
+// maxStringDictionarySize is the maximum number of distinct values of a dictionary
+// encoded block.
+const maxStringDictionarySize = 256
+
+var (
+	flateWriterPool = sync.Pool{
+		New: func() interface{} {
+			w, _ := flate.NewWriter(nil, flate.DefaultCompression)
+			return w
+		},
+	}
+	flateReaderPool = sync.Pool{
+		New: func() interface{} {
+			return flate.NewReader(nil)
+		},
+	}
 )
 
+// parseStringCompression returns the string encoding type for the compression name
+// used by the configuration.  An empty name selects the default, snappy.
+func parseStringCompression(name string) (byte, error) {
+	switch name {
+	case "none":
+		return stringUncompressed, nil
+	case "", "snappy":
+		return stringCompressedSnappy, nil
+	case "deflate":
+		return stringCompressedDeflate, nil
+	}
+	return 0, fmt.Errorf("unknown string compression: %s", name)
+}
+
 // StringEncoder encodes multiple strings into a byte slice.
 type StringEncoder struct {
 	// The encoded bytes
 	bytes []byte
+
+	// The encoding type used to compress the bytes
+	compression byte
+
+	// The distinct values written and the index of each value written in them, used to
+	// dictionary encode blocks with few distinct values when compressing using snappy.
+	// dictFull is set once more than maxStringDictionarySize distinct values have been
+	// written.
+	dict     map[string]int
+	keys     []string
+	codes    []byte
+	dictFull bool
 }
 
 // NewStringEncoder returns a new StringEncoder with an initial buffer ready to hold sz bytes.
 func NewStringEncoder(sz int) StringEncoder {
 	return StringEncoder{
-		bytes: make([]byte, 0, sz),
+		bytes:       make([]byte, 0, sz),
+		compression: stringCompressedSnappy,
+		dict:        make(map[string]int),
 	}
 }
 
@@ -40,6 +108,12 @@ func (e *StringEncoder) Flush() {}
 // Reset sets the encoder back to its initial state.
 func (e *StringEncoder) Reset() {
 	e.bytes = e.bytes[:0]
+	for k := range e.dict {
+		delete(e.dict, k)
+	}
+	e.keys = e.keys[:0]
+	e.codes = e.codes[:0]
+	e.dictFull = false
 }
 
 // Write encodes s to the underlying buffer.
@@ -51,33 +125,159 @@ func (e *StringEncoder) Write(s string) {
 
 	// Append the string bytes
 	e.bytes = append(e.bytes, s...)
+
+	// Track the distinct values until there are too many to dictionary encode them.
+	// Only blocks that would be compressed using snappy are dictionary encoded, so
+	// the compression must be set before the first write.
+	if e.dictFull || e.compression != stringCompressedSnappy {
+		return
+	}
+	code, ok := e.dict[s]
+	if !ok {
+		if len(e.keys) == maxStringDictionarySize {
+			e.dictFull = true
+			return
+		}
+		code = len(e.keys)
+		e.dict[s] = code
+		e.keys = append(e.keys, s)
+	}
+	e.codes = append(e.codes, byte(code))
 }
 
 // Bytes returns a copy of the underlying buffer.
 func (e *StringEncoder) Bytes() ([]byte, error) {
-	// Compress the currently appended bytes using snappy and prefix with
-	// a 1 byte header for future extension
+	// Compress the currently appended bytes and prefix with a 1 byte header
+	// indicating the compression used.
+	switch e.compression {
+	case stringUncompressed:
+		return append([]byte{stringUncompressed << 4}, e.bytes...), nil
+	case stringCompressedDeflate:
+		return deflateEncode(e.bytes)
+	}
+
+	// Dictionary encoding only pays off when values repeat, so it's not used unless
+	// each distinct value is written at least twice on average.
+	if !e.dictFull && len(e.codes) > 0 && 2*len(e.keys) <= len(e.codes) {
+		return e.encodeDictionary(), nil
+	}
+
 	data := snappy.Encode(nil, e.bytes)
 	return append([]byte{stringCompressedSnappy << 4}, data...), nil
 }
 
+func (e *StringEncoder) encodeDictionary() []byte {
+	width := stringDictionaryCodeWidth(len(e.keys))
+
+	var buf [binary.MaxVarintLen64]byte
+	b := make([]byte, 0, 1+2*binary.MaxVarintLen64+(len(e.codes)*int(width)+7)/8)
+
+	// 4 high bits used for the encoding type
+	b = append(b, stringCompressedDictionary<<4)
+
+	// The number of values and the number of distinct values
+	b = append(b, buf[:binary.PutUvarint(buf[:], uint64(len(e.codes)))]...)
+	b = append(b, buf[:binary.PutUvarint(buf[:], uint64(len(e.keys)))]...)
+
+	// The distinct values, each prefixed by its length
+	for _, k := range e.keys {
+		b = append(b, buf[:binary.PutUvarint(buf[:], uint64(len(k)))]...)
+		b = append(b, k...)
+	}
+
+	// The packed codes of the values.  The widths divide a byte, so codes never span
+	// two bytes.
+	if width == 0 {
+		return b
+	}
+	i := len(b)
+	b = append(b, make([]byte, (len(e.codes)*int(width)+7)/8)...)
+	for j, code := range e.codes {
+		bit := uint(j) * width
+		b[i+int(bit/8)] |= code << (8 - width - bit%8)
+	}
+	return b
+}
+
+// stringDictionaryCodeWidth returns the number of bits used to store the codes of a
+// dictionary holding n values.
+func stringDictionaryCodeWidth(n int) uint {
+	switch {
+	case n <= 1:
+		return 0
+	case n <= 2:
+		return 1
+	case n <= 4:
+		return 2
+	case n <= 16:
+		return 4
+	}
+	return 8
+}
+
+// deflateEncode compresses b using DEFLATE and prefixes it with the header byte.
+func deflateEncode(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(stringCompressedDeflate << 4)
+
+	w := flateWriterPool.Get().(*flate.Writer)
+	defer flateWriterPool.Put(w)
+
+	w.Reset(&buf)
+	if _, err := w.Write(b); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// deflateDecode decompresses DEFLATE compressed bytes.
+func deflateDecode(b []byte) ([]byte, error) {
+	r := flateReaderPool.Get().(io.ReadCloser)
+	defer flateReaderPool.Put(r)
+
+	if err := r.(flate.Resetter).Reset(bytes.NewReader(b), nil); err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(r)
+}
+
 // StringDecoder decodes a byte slice into strings.
 type StringDecoder struct {
 	b   []byte
 	l   int
 	i   int
 	err error
+
+	// The distinct values, packed codes, code width and number of values of a
+	// dictionary encoded block.
+	dict  []string
+	codes []byte
+	width uint
+	n     int
 }
 
 // SetBytes initializes the decoder with bytes to read from.
 // This must be called before calling any other method.
 func (e *StringDecoder) SetBytes(b []byte) error {
-	// First byte stores the encoding type, only have snappy format
-	// currently so ignore for now.
+	// First byte stores the encoding type in the upper 4 bits.
 	var data []byte
 	if len(b) > 0 {
 		var err error
-		data, err = snappy.Decode(nil, b[1:])
+		switch b[0] >> 4 {
+		case stringUncompressed:
+			data = b[1:]
+		case stringCompressedSnappy:
+			data, err = snappy.Decode(nil, b[1:])
+		case stringCompressedDeflate:
+			data, err = deflateDecode(b[1:])
+		case stringCompressedDictionary:
+			return e.setDictionary(b[1:])
+		default:
+			return fmt.Errorf("unknown string block encoding: %d", b[0]>>4)
+		}
 		if err != nil {
 			return fmt.Errorf("failed to decode string block: %v", err.Error())
 		}
@@ -87,22 +287,99 @@ func (e *StringDecoder) SetBytes(b []byte) error {
 	e.l = 0
 	e.i = 0
 	e.err = nil
+	e.dict = nil
 
 	return nil
 }
 
+// setDictionary initializes the decoder with the bytes of a dictionary encoded block.
+func (e *StringDecoder) setDictionary(b []byte) error {
+	e.dict = nil
+
+	n, i := binary.Uvarint(b)
+	if i <= 0 || n > math.MaxInt32 {
+		return fmt.Errorf("failed to decode string block: invalid value count")
+	}
+	b = b[i:]
+
+	size, i := binary.Uvarint(b)
+	if i <= 0 || size == 0 || size > maxStringDictionarySize {
+		return fmt.Errorf("failed to decode string block: invalid dictionary size")
+	}
+	b = b[i:]
+
+	dict := make([]string, size)
+	for j := range dict {
+		length, i := binary.Uvarint(b)
+		if i <= 0 || length > uint64(len(b)-i) {
+			return fmt.Errorf("failed to decode string block: invalid dictionary value")
+		}
+		dict[j] = string(b[i : i+int(length)])
+		b = b[i+int(length):]
+	}
+
+	width := stringDictionaryCodeWidth(len(dict))
+	if uint64(len(b))*8 < n*uint64(width) {
+		return fmt.Errorf("failed to decode string block: not enough data for codes")
+	}
+
+	e.b = nil
+	e.l = 0
+	e.i = -1
+	e.err = nil
+	e.dict, e.codes, e.width, e.n = dict, b, width, int(n)
+
+	// Check the codes up front so reading values can't fail.
+	for j := 0; j < e.n; j++ {
+		if e.code(j) >= len(dict) {
+			e.dict = nil
+			return fmt.Errorf("failed to decode string block: invalid dictionary code")
+		}
+	}
+	return nil
+}
+
+// code returns the dictionary code of the value at index i.
+func (e *StringDecoder) code(i int) int {
+	if e.width == 0 {
+		return 0
+	}
+	bit := uint(i) * e.width
+	return int(e.codes[bit/8]>>(8-e.width-bit%8)) & (1<<e.width - 1)
+}
+
 // Next returns true if there are any values remaining to be decoded.
 func (e *StringDecoder) Next() bool {
 	if e.err != nil {
 		return false
 	}
 
+	if e.dict != nil {
+		e.i++
+		return e.i < e.n
+	}
+
 	e.i += e.l
 	return e.i < len(e.b)
 }
 
+// Dictionary returns the distinct values of a dictionary encoded block, or nil if the
+// block is not dictionary encoded.  The current value is the one at index Code().
+func (e *StringDecoder) Dictionary() []string {
+	return e.dict
+}
+
+// Code returns the index of the current value in the values returned by Dictionary.
+func (e *StringDecoder) Code() int {
+	return e.code(e.i)
+}
+
 // Read returns the next value from the decoder.
 func (e *StringDecoder) Read() string {
+	if e.dict != nil {
+		return e.dict[e.code(e.i)]
+	}
+
 	// Read the length of the string
 	length, n := binary.Uvarint(e.b[e.i:])
 	if n <= 0 {
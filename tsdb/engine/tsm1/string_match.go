@@ -0,0 +1,312 @@
+package tsm1
+
+import (
+	"context"
+	"sort"
+
+	"github.com/influxdata/influxdb/query"
+	"github.com/influxdata/influxdb/tsdb"
+	"github.com/influxdata/influxql"
+)
+
+// ReadStringBlockMatching reads the next block as a set of string values, keeping only
+// the values for which fn returns true.  A block that doesn't overlap other blocks is
+// filtered as it's decoded, so the values of a dictionary encoded block are matched by
+// their code.  Blocks without matching values are skipped, so no values are returned
+// only once the cursor is exhausted.
+func (c *KeyCursor) ReadStringBlockMatching(buf *[]StringValue, fn func(string) bool) ([]StringValue, error) {
+	for len(c.current) > 0 {
+		// Overlapping blocks are filtered once merged, since a newer value that doesn't
+		// match replaces any older value that does.
+		if len(c.current) > 1 {
+			values, err := c.ReadStringBlock(buf)
+			if err != nil || len(values) == 0 {
+				return values, err
+			}
+
+			n := 0
+			for _, v := range values {
+				if fn(v.value) {
+					values[n] = v
+					n++
+				}
+			}
+			if n > 0 {
+				return values[:n], nil
+			}
+			c.Next()
+			continue
+		}
+
+		first := c.current[0]
+		*buf = (*buf)[:0]
+		values, err := first.r.ReadStringBlockMatchingAt(&first.entry, buf, fn)
+		if err != nil {
+			return nil, err
+		}
+		if c.col != nil {
+			c.col.GetCounter(stringBlocksDecodedCounter).Add(1)
+			c.col.GetCounter(stringBlocksSizeCounter).Add(int64(first.entry.Size))
+		}
+		c.blockDecoded(&first.entry)
+
+		// Remove values we already read
+		values = StringValues(values).Exclude(first.readMin, first.readMax)
+
+		// Remove any tombstones
+		tombstones := first.r.TombstoneRange(c.key)
+		values = c.filterStringValues(tombstones, values)
+
+		// The whole block has been read, whether or not any of its values matched.
+		first.markRead(first.entry.MinTime, first.entry.MaxTime)
+		if len(values) > 0 {
+			return values, nil
+		}
+		c.Next()
+	}
+	return nil, nil
+}
+
+// stringMatchCursor is a cursor over the string values of a key for which a function
+// returns true.
+type stringMatchCursor struct {
+	ascending bool
+	match     func(string) bool
+
+	cache struct {
+		values Values
+		pos    int
+	}
+	reads *query.IteratorStats
+
+	tsm struct {
+		values    []StringValue
+		pos       int
+		keyCursor *KeyCursor
+	}
+}
+
+func newStringMatchCursor(seek int64, ascending bool, cacheValues Values, tsmKeyCursor *KeyCursor, match func(string) bool) *stringMatchCursor {
+	c := &stringMatchCursor{ascending: ascending, match: match, reads: tsmKeyCursor.stats}
+
+	c.cache.values = cacheValues
+	c.cache.pos = sort.Search(len(c.cache.values), func(i int) bool {
+		return c.cache.values[i].UnixNano() >= seek
+	})
+
+	c.tsm.keyCursor = tsmKeyCursor
+	c.tsm.values, _ = c.tsm.keyCursor.ReadStringBlockMatching(&c.tsm.values, match)
+	c.tsm.pos = sort.Search(len(c.tsm.values), func(i int) bool {
+		return c.tsm.values[i].UnixNano() >= seek
+	})
+
+	if !ascending {
+		if t, _ := c.peekCache(); t != seek {
+			c.cache.pos--
+		}
+		if t, _ := c.peekTSM(); t != seek {
+			c.tsm.pos--
+		}
+	}
+	return c
+}
+
+// peekCache returns the current time/value from the cache.
+func (c *stringMatchCursor) peekCache() (t int64, v string) {
+	if c.cache.pos < 0 || c.cache.pos >= len(c.cache.values) {
+		return tsdb.EOF, ""
+	}
+
+	item := c.cache.values[c.cache.pos]
+	return item.UnixNano(), item.(StringValue).value
+}
+
+// peekTSM returns the current time/value from tsm.
+func (c *stringMatchCursor) peekTSM() (t int64, v string) {
+	if c.tsm.pos < 0 || c.tsm.pos >= len(c.tsm.values) {
+		return tsdb.EOF, ""
+	}
+
+	item := c.tsm.values[c.tsm.pos]
+	return item.UnixNano(), item.value
+}
+
+// close closes the cursor and any dependent cursors.
+func (c *stringMatchCursor) close() error {
+	c.tsm.keyCursor.Close()
+	c.tsm.keyCursor = nil
+	c.cache.values = nil
+	c.tsm.values = nil
+	return nil
+}
+
+// next returns the next key/value for the cursor.
+func (c *stringMatchCursor) next() (int64, interface{}) { return c.nextString() }
+
+// nextString returns the next matching key/value for the cursor.  Values read from
+// TSM files have already been matched, values from the cache are matched as they're
+// returned since they replace any value in the TSM files with the same key.
+func (c *stringMatchCursor) nextString() (int64, string) {
+	for {
+		ckey, cvalue := c.peekCache()
+		tkey, tvalue := c.peekTSM()
+
+		// No more data in cache or in TSM files.
+		if ckey == tsdb.EOF && tkey == tsdb.EOF {
+			return tsdb.EOF, ""
+		}
+
+		// Both cache and tsm files have the same key, cache takes precedence.
+		if ckey == tkey {
+			c.nextCache()
+			c.nextTSM()
+			if c.match(cvalue) {
+				return ckey, cvalue
+			}
+			continue
+		}
+
+		// Buffered cache key precedes that in TSM file.
+		if ckey != tsdb.EOF && (tkey == tsdb.EOF || (c.ascending && ckey < tkey) || (!c.ascending && ckey > tkey)) {
+			c.nextCache()
+			if c.match(cvalue) {
+				return ckey, cvalue
+			}
+			continue
+		}
+
+		// Buffered TSM key precedes that in cache.
+		c.nextTSM()
+		return tkey, tvalue
+	}
+}
+
+// nextCache returns the next value from the cache.
+func (c *stringMatchCursor) nextCache() {
+	if c.ascending {
+		if c.cache.pos >= len(c.cache.values) {
+			return
+		}
+		c.cache.pos++
+	} else {
+		if c.cache.pos < 0 {
+			return
+		}
+		c.cache.pos--
+	}
+	if c.reads != nil {
+		c.reads.CacheHitN++
+	}
+}
+
+// nextTSM returns the next value from the TSM files.
+func (c *stringMatchCursor) nextTSM() {
+	if c.ascending {
+		c.tsm.pos++
+		if c.tsm.pos < len(c.tsm.values) {
+			return
+		}
+	} else {
+		c.tsm.pos--
+		if c.tsm.pos >= 0 {
+			return
+		}
+	}
+
+	c.tsm.keyCursor.Next()
+	c.tsm.values, _ = c.tsm.keyCursor.ReadStringBlockMatching(&c.tsm.values, c.match)
+	if len(c.tsm.values) == 0 {
+		return
+	}
+	if c.ascending {
+		c.tsm.pos = 0
+	} else {
+		c.tsm.pos = len(c.tsm.values) - 1
+	}
+}
+
+// buildStringMatchCursor creates a cursor over the values of a string field matching
+// the comparisons of the field to literals that must be true for cond to be true.  It
+// returns nil if ref isn't a string field or cond doesn't compare it to a literal.
+func (e *Engine) buildStringMatchCursor(ctx context.Context, measurement, seriesKey string, ref *influxql.VarRef, cond influxql.Expr, opt query.IteratorOptions) (stringCursor, error) {
+	if ref.Type != influxql.Unknown && ref.Type != influxql.AnyField && ref.Type != influxql.String {
+		return nil, nil
+	}
+
+	match := conditionStringMatcher(cond, ref.Val)
+	if match == nil {
+		return nil, nil
+	}
+
+	mf := e.fieldset.Fields(measurement)
+	if mf == nil {
+		return nil, nil
+	}
+	if f := mf.Field(ref.Val); f == nil || f.Type != influxql.String {
+		return nil, nil
+	}
+
+	key := SeriesFieldKeyBytes(seriesKey, ref.Val)
+	cacheValues, err := e.cacheValues(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	keyCursor := e.KeyCursor(ctx, key, opt.SeekTime(), opt.Ascending)
+	return newStringMatchCursor(opt.SeekTime(), opt.Ascending, cacheValues, keyCursor, match), nil
+}
+
+// conditionStringMatcher returns a function reporting whether a value of field satisfies
+// every comparison of the field to a string or regex literal that is ANDed into cond.
+// A value that doesn't satisfy one of them can't satisfy cond.  It returns nil if cond
+// has no such comparisons.
+func conditionStringMatcher(cond influxql.Expr, field string) func(string) bool {
+	var exprs []influxql.Expr
+	var walk func(expr influxql.Expr)
+	walk = func(expr influxql.Expr) {
+		switch expr := expr.(type) {
+		case *influxql.ParenExpr:
+			walk(expr.Expr)
+		case *influxql.BinaryExpr:
+			switch expr.Op {
+			case influxql.AND:
+				walk(expr.LHS)
+				walk(expr.RHS)
+			case influxql.EQ, influxql.NEQ, influxql.LT, influxql.LTE, influxql.GT, influxql.GTE, influxql.EQREGEX, influxql.NEQREGEX:
+				if comparesStringField(expr.LHS, expr.RHS, field) || comparesStringField(expr.RHS, expr.LHS, field) {
+					exprs = append(exprs, expr)
+				}
+			}
+		}
+	}
+	walk(cond)
+
+	if len(exprs) == 0 {
+		return nil
+	}
+
+	// Evaluate the comparisons the same way the iterator evaluates the condition.
+	m := map[string]interface{}{field: nil}
+	return func(v string) bool {
+		m[field] = v
+		for _, expr := range exprs {
+			if !influxql.EvalBool(expr, m) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// comparesStringField returns true if ref is a reference to field and lit is a string or
+// regex literal.
+func comparesStringField(ref, lit influxql.Expr, field string) bool {
+	r, ok := ref.(*influxql.VarRef)
+	if !ok || r.Val != field || r.Type == influxql.Tag {
+		return false
+	}
+	switch lit.(type) {
+	case *influxql.StringLiteral, *influxql.RegexLiteral:
+		return true
+	}
+	return false
+}
@@ -86,36 +86,218 @@ func Test_StringEncoder_Multi_Compressed(t *testing.T) {
 	}
 }
 
-func Test_StringEncoder_Quick(t *testing.T) {
-	quick.Check(func(values []string) bool {
-		expected := values
-		if values == nil {
-			expected = []string{}
+func Test_StringEncoder_Multi_Uncompressed(t *testing.T) {
+	enc := NewStringEncoder(1024)
+	enc.compression = stringUncompressed
+
+	values := make([]string, 10)
+	for i := range values {
+		values[i] = fmt.Sprintf("value %d", i)
+		enc.Write(values[i])
+	}
+
+	b, err := enc.Bytes()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if b[0]>>4 != stringUncompressed {
+		t.Fatalf("unexpected encoding: got %v, exp %v", b[0], stringUncompressed)
+	}
+
+	if exp := 81; len(b) != exp {
+		t.Fatalf("unexpected length: got %v, exp %v", len(b), exp)
+	}
+
+	var dec StringDecoder
+	if err := dec.SetBytes(b); err != nil {
+		t.Fatalf("unexpected erorr creating string decoder: %v", err)
+	}
+
+	for i, v := range values {
+		if !dec.Next() {
+			t.Fatalf("unexpected next value: got false, exp true")
 		}
-		// Write values to encoder.
+		if v != dec.Read() {
+			t.Fatalf("unexpected value at pos %d: got %v, exp %v", i, dec.Read(), v)
+		}
+	}
+
+	if dec.Next() {
+		t.Fatalf("unexpected next value: got true, exp false")
+	}
+}
+
+func Test_StringEncoder_Multi_Deflate(t *testing.T) {
+	enc := NewStringEncoder(1024)
+	enc.compression = stringCompressedDeflate
+
+	values := make([]string, 100)
+	for i := range values {
+		values[i] = fmt.Sprintf("GET /api/v2/query?org=%d HTTP/1.1 200 - Mozilla/5.0", i%7)
+		enc.Write(values[i])
+	}
+
+	b, err := enc.Bytes()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if b[0]>>4 != stringCompressedDeflate {
+		t.Fatalf("unexpected encoding: got %v, exp %v", b[0], stringCompressedDeflate)
+	}
+
+	snappyEnc := NewStringEncoder(1024)
+	for _, v := range values {
+		snappyEnc.Write(v)
+	}
+	sb, err := snappyEnc.Bytes()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(b) >= len(sb) {
+		t.Fatalf("expected deflate to compress better than snappy: got %v, snappy %v", len(b), len(sb))
+	}
+
+	var dec StringDecoder
+	if err := dec.SetBytes(b); err != nil {
+		t.Fatalf("unexpected erorr creating string decoder: %v", err)
+	}
+
+	for i, v := range values {
+		if !dec.Next() {
+			t.Fatalf("unexpected next value: got false, exp true")
+		}
+		if v != dec.Read() {
+			t.Fatalf("unexpected value at pos %d: got %v, exp %v", i, dec.Read(), v)
+		}
+	}
+
+	if dec.Next() {
+		t.Fatalf("unexpected next value: got true, exp false")
+	}
+}
+
+func Test_StringEncoder_Dictionary(t *testing.T) {
+	for _, states := range [][]string{
+		{"OK"},
+		{"OK", "CRIT"},
+		{"OK", "WARN", "CRIT"},
+		{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11", "12", "13", "14", "15", "16"},
+	} {
+		enc := NewStringEncoder(1024)
+
+		values := make([]string, 1000)
+		for i := range values {
+			values[i] = states[i%len(states)]
+			enc.Write(values[i])
+		}
+
+		b, err := enc.Bytes()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if b[0]>>4 != stringCompressedDictionary {
+			t.Fatalf("unexpected encoding: got %v, exp %v", b[0], stringCompressedDictionary)
+		}
+
+		var dec StringDecoder
+		if err := dec.SetBytes(b); err != nil {
+			t.Fatalf("unexpected erorr creating string decoder: %v", err)
+		}
+
+		if got := dec.Dictionary(); !reflect.DeepEqual(got, states) {
+			t.Fatalf("unexpected dictionary: got %v, exp %v", got, states)
+		}
+
+		for i, v := range values {
+			if !dec.Next() {
+				t.Fatalf("unexpected next value: got false, exp true")
+			}
+			if got := dec.Dictionary()[dec.Code()]; v != got {
+				t.Fatalf("unexpected code at pos %d: got %v, exp %v", i, got, v)
+			}
+			if v != dec.Read() {
+				t.Fatalf("unexpected value at pos %d: got %v, exp %v", i, dec.Read(), v)
+			}
+		}
+
+		if dec.Next() {
+			t.Fatalf("unexpected next value: got true, exp false")
+		}
+	}
+}
+
+// Ensures distinct values aren't tracked when the compression can't use a dictionary.
+func Test_StringEncoder_Dictionary_NotSnappy(t *testing.T) {
+	for _, compression := range []byte{stringUncompressed, stringCompressedDeflate} {
+		enc := NewStringEncoder(1024)
+		enc.compression = compression
+		for i := 0; i < 100; i++ {
+			enc.Write("OK")
+		}
+
+		if len(enc.dict) != 0 || len(enc.keys) != 0 || len(enc.codes) != 0 {
+			t.Fatalf("unexpected dictionary for compression %d: %d values", compression, len(enc.keys))
+		}
+
+		b, err := enc.Bytes()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		} else if b[0]>>4 != compression {
+			t.Fatalf("unexpected encoding: got %v, exp %v", b[0]>>4, compression)
+		}
+	}
+}
+
+func Test_StringEncoder_Dictionary_TooManyValues(t *testing.T) {
+	enc := NewStringEncoder(1024)
+
+	// Every value is repeated, but there are too many distinct values.
+	for i := 0; i < 2*(maxStringDictionarySize+1); i++ {
+		enc.Write(fmt.Sprintf("value %d", i/2))
+	}
+
+	b, err := enc.Bytes()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if b[0]>>4 != stringCompressedSnappy {
+		t.Fatalf("unexpected encoding: got %v, exp %v", b[0], stringCompressedSnappy)
+	}
+}
+
+func Test_StringEncoder_Dictionary_Quick(t *testing.T) {
+	states := []string{"", "OK", "WARN", "CRIT", "UNKNOWN"}
+	quick.Check(func(codes []uint8) bool {
+		// Write values with few distinct values to the encoder.
 		enc := NewStringEncoder(1024)
-		for _, v := range values {
-			enc.Write(v)
+		expected := make([]string, len(codes))
+		for i, c := range codes {
+			expected[i] = states[int(c)%len(states)]
+			enc.Write(expected[i])
 		}
 
-		// Retrieve encoded bytes from encoder.
 		buf, err := enc.Bytes()
 		if err != nil {
 			t.Fatal(err)
 		}
 
 		// Read values out of decoder.
-		got := make([]string, 0, len(values))
+		got := make([]string, 0, len(codes))
 		var dec StringDecoder
 		if err := dec.SetBytes(buf); err != nil {
 			t.Fatal(err)
 		}
 		for dec.Next() {
-			if err := dec.Error(); err != nil {
-				t.Fatal(err)
-			}
 			got = append(got, dec.Read())
 		}
+		if err := dec.Error(); err != nil {
+			t.Fatal(err)
+		}
 
 		// Verify that input and output values match.
 		if !reflect.DeepEqual(expected, got) {
@@ -126,6 +308,49 @@ func Test_StringEncoder_Quick(t *testing.T) {
 	}, nil)
 }
 
+func Test_StringEncoder_Quick(t *testing.T) {
+	quick.Check(func(values []string) bool {
+		expected := values
+		if values == nil {
+			expected = []string{}
+		}
+		for _, compression := range []byte{stringUncompressed, stringCompressedSnappy, stringCompressedDeflate} {
+			// Write values to encoder.
+			enc := NewStringEncoder(1024)
+			enc.compression = compression
+			for _, v := range values {
+				enc.Write(v)
+			}
+
+			// Retrieve encoded bytes from encoder.
+			buf, err := enc.Bytes()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Read values out of decoder.
+			got := make([]string, 0, len(values))
+			var dec StringDecoder
+			if err := dec.SetBytes(buf); err != nil {
+				t.Fatal(err)
+			}
+			for dec.Next() {
+				if err := dec.Error(); err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, dec.Read())
+			}
+
+			// Verify that input and output values match.
+			if !reflect.DeepEqual(expected, got) {
+				t.Fatalf("mismatch:\n\nexp=%#v\n\ngot=%#v\n\n", expected, got)
+			}
+		}
+
+		return true
+	}, nil)
+}
+
 func Test_StringDecoder_Empty(t *testing.T) {
 	var dec StringDecoder
 	if err := dec.SetBytes([]byte{}); err != nil {
@@ -166,6 +391,11 @@ func Test_StringDecoder_CorruptSetBytes(t *testing.T) {
 			"\x000\x00\x01\x000\x00\x01\x000\x00\x00\x00\xff:\x01\x00\x01\x00\x01" +
 			"\x00\x01\x00\x01\x00\x01\x00\x010\x010\x000\x010\x010\x010\x01" +
 			"0\x010\x010\x010\x010\x010\x010\x010\x010\x010\x010", // Upper slice bounds overflows negative
+		"\x30",                            // Dictionary: missing value count
+		"\x30\x02\x00",                    // Dictionary: empty dictionary
+		"\x30\x02\x01\x05ab",              // Dictionary: value longer than data
+		"\x30\x10\x02\x01a\x01b",          // Dictionary: missing codes
+		"\x30\x01\x03\x01a\x01b\x01c\xc0", // Dictionary: code outside dictionary
 	}
 
 	for _, c := range cases {
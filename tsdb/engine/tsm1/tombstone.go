@@ -43,6 +43,10 @@ type Tombstoner struct {
 	// Tombstones that have been written but not flushed to disk yet.
 	tombstones []Tombstone
 
+	// The number of tombstones read by Walk and the range of time they cover.
+	count            int
+	minTime, maxTime int64
+
 	// These are references used for pending writes that have not been committed.  If
 	// these are nil, then no pending writes are in progress.
 	gz                *gzip.Writer
@@ -156,6 +160,7 @@ func (t *Tombstoner) Delete() error {
 	}
 	t.statsLoaded = false
 	t.lastAppliedOffset = 0
+	t.count, t.minTime, t.maxTime = 0, 0, 0
 
 	return nil
 }
@@ -204,11 +209,36 @@ func (t *Tombstoner) TombstoneFiles() []FileStat {
 	return stats
 }
 
+// TombstoneStats returns the number of tombstones read by Walk and the range of time
+// they cover.  Tombstones of whole keys cover every time.
+func (t *Tombstoner) TombstoneStats() (n int, min, max int64) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.count, t.minTime, t.maxTime
+}
+
 // Walk calls fn for every Tombstone under the Tombstoner.
 func (t *Tombstoner) Walk(fn func(t Tombstone) error) error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
+	// Only v4 files are read from the last applied offset, every other version is
+	// read from the start and counted again.
+	if t.lastAppliedOffset == 0 {
+		t.count, t.minTime, t.maxTime = 0, 0, 0
+	}
+	walkFn := fn
+	fn = func(ts Tombstone) error {
+		if t.count == 0 || ts.Min < t.minTime {
+			t.minTime = ts.Min
+		}
+		if t.count == 0 || ts.Max > t.maxTime {
+			t.maxTime = ts.Max
+		}
+		t.count++
+		return walkFn(ts)
+	}
+
 	f, err := os.Open(t.tombstonePath())
 	if os.IsNotExist(err) {
 		return nil
@@ -3,6 +3,7 @@ package tsm1
 import (
 	"bufio"
 	"bytes"
+	"compress/flate"
 	"encoding/binary"
 	"fmt"
 	"io"
@@ -42,6 +43,21 @@ const (
 	booleanEntryType  = 3
 	stringEntryType   = 4
 	unsignedEntryType = 5
+
+	// walCompressionNone leaves the entries of a frame uncompressed.
+	walCompressionNone = 0
+
+	// walCompressionSnappy compresses entries using Snappy compression.  Segments
+	// that are not framed always compress each entry with Snappy.
+	walCompressionSnappy = 1
+
+	// walCompressionDeflate compresses entries using DEFLATE compression.  It is
+	// slower than Snappy but uses less disk bandwidth.
+	walCompressionDeflate = 2
+
+	// walFrameHeaderSize is the size of the type and length written before each
+	// entry within a frame.
+	walFrameHeaderSize = 5
 )
 
 // WalEntryType is a byte written to a wal segment file that indicates what the following compressed block contains.
@@ -56,6 +72,11 @@ const (
 
 	// DeleteRangeWALEntryType indicates a delete range entry.
 	DeleteRangeWALEntryType WalEntryType = 0x03
+
+	// FrameWALEntryType indicates a frame batching multiple uncompressed entries,
+	// which are compressed together.  The first byte of a frame is the compression
+	// used.
+	FrameWALEntryType WalEntryType = 0x04
 )
 
 var (
@@ -111,6 +132,13 @@ type WAL struct {
 	// SegmentSize is the file size at which a segment file will be rotated
 	SegmentSize int
 
+	// compression is the compression used for frames and frameSize the number of
+	// bytes of entries batched into each frame.  Entries are only batched into frames
+	// if compression is not snappy or frameSize is greater than 0, otherwise segments
+	// remain readable by older versions.  These must be set before the WAL is opened.
+	compression byte
+	frameSize   int
+
 	// statistics for the WAL
 	stats   *WALStatistics
 	limiter limiter.Fixed
@@ -124,6 +152,7 @@ func NewWAL(path string) *WAL {
 
 		// these options should be overriden by any options in the config
 		SegmentSize: DefaultSegmentSize,
+		compression: walCompressionSnappy,
 		closing:     make(chan struct{}),
 		syncWaiters: make(chan chan error, 1024),
 		stats:       &WALStatistics{},
@@ -395,10 +424,16 @@ func (l *WAL) writeToLog(entry WALEntry) (int, error) {
 		return -1, err
 	}
 
-	encBuf := bytesPool.Get(snappy.MaxEncodedLen(len(b)))
-
-	compressed := snappy.Encode(encBuf, b)
-	bytesPool.Put(bytes)
+	// Framed entries are compressed together when the frame is written, so they
+	// are added to the segment uncompressed.
+	var encBuf, data []byte
+	if l.framed() {
+		data = b
+	} else {
+		encBuf = bytesPool.Get(snappy.MaxEncodedLen(len(b)))
+		data = snappy.Encode(encBuf, b)
+		bytesPool.Put(bytes)
+	}
 
 	syncErr := make(chan error)
 
@@ -419,7 +454,13 @@ func (l *WAL) writeToLog(entry WALEntry) (int, error) {
 		}
 
 		// write and sync
-		if err := l.currentSegmentWriter.Write(entry.Type(), compressed); err != nil {
+		var err error
+		if l.framed() {
+			err = l.currentSegmentWriter.WriteEntry(entry.Type(), data)
+		} else {
+			err = l.currentSegmentWriter.Write(entry.Type(), data)
+		}
+		if err != nil {
 			return -1, fmt.Errorf("error writing WAL entry: %v", err)
 		}
 
@@ -439,7 +480,11 @@ func (l *WAL) writeToLog(entry WALEntry) (int, error) {
 
 	}()
 
-	bytesPool.Put(encBuf)
+	if l.framed() {
+		bytesPool.Put(bytes)
+	} else {
+		bytesPool.Put(encBuf)
+	}
 
 	if err != nil {
 		return segID, err
@@ -449,6 +494,11 @@ func (l *WAL) writeToLog(entry WALEntry) (int, error) {
 	return segID, <-syncErr
 }
 
+// framed returns true if entries are batched into frames.
+func (l *WAL) framed() bool {
+	return l.compression != walCompressionSnappy || l.frameSize > 0
+}
+
 // rollSegment checks if the current segment is due to roll over to a new segment;
 // and if so, opens a new segment file for future writes.
 func (l *WAL) rollSegment() error {
@@ -468,7 +518,7 @@ func (l *WAL) rollSegment() error {
 func (l *WAL) CloseSegment() error {
 	l.mu.Lock()
 	defer l.mu.Unlock()
-	if l.currentSegmentWriter == nil || l.currentSegmentWriter.size > 0 {
+	if l.currentSegmentWriter == nil || !l.currentSegmentWriter.empty() {
 		if err := l.newSegmentFile(); err != nil {
 			// A drop database or RP call could trigger this error if writes were in-flight
 			// when the drop statement executes.
@@ -561,7 +611,11 @@ func (l *WAL) newSegmentFile() error {
 	if err != nil {
 		return err
 	}
-	l.currentSegmentWriter = NewWALSegmentWriter(fd)
+	if l.framed() {
+		l.currentSegmentWriter = newFramedWALSegmentWriter(fd, l.compression, l.frameSize)
+	} else {
+		l.currentSegmentWriter = NewWALSegmentWriter(fd)
+	}
 
 	if stat, err := fd.Stat(); err == nil {
 		l.lastWriteTime = stat.ModTime()
@@ -1022,6 +1076,13 @@ type WALSegmentWriter struct {
 	bw   *bufio.Writer
 	w    io.WriteCloser
 	size int
+
+	// framed writers batch entries into frame, which is compressed and written once it
+	// reaches frameSize bytes or the writer is flushed.
+	framed      bool
+	compression byte
+	frameSize   int
+	frame       []byte
 }
 
 // NewWALSegmentWriter returns a new WALSegmentWriter writing to w.
@@ -1032,6 +1093,26 @@ func NewWALSegmentWriter(w io.WriteCloser) *WALSegmentWriter {
 	}
 }
 
+// NewFramedWALSegmentWriter returns a new WALSegmentWriter writing to w that batches
+// entries into frames of up to frameSize bytes, compressed with the named compression.
+func NewFramedWALSegmentWriter(w io.WriteCloser, compression string, frameSize int) (*WALSegmentWriter, error) {
+	c, err := parseWALCompression(compression)
+	if err != nil {
+		return nil, err
+	}
+	return newFramedWALSegmentWriter(w, c, frameSize), nil
+}
+
+func newFramedWALSegmentWriter(w io.WriteCloser, compression byte, frameSize int) *WALSegmentWriter {
+	return &WALSegmentWriter{
+		bw:          bufio.NewWriter(w),
+		w:           w,
+		framed:      true,
+		compression: compression,
+		frameSize:   frameSize,
+	}
+}
+
 func (w *WALSegmentWriter) path() string {
 	if f, ok := w.w.(*os.File); ok {
 		return f.Name()
@@ -1039,6 +1120,11 @@ func (w *WALSegmentWriter) path() string {
 	return ""
 }
 
+// empty returns true if nothing has been written to the segment.
+func (w *WALSegmentWriter) empty() bool {
+	return w.size == 0 && len(w.frame) == 0
+}
+
 // Write writes entryType and the buffer containing compressed entry data.
 func (w *WALSegmentWriter) Write(entryType WalEntryType, compressed []byte) error {
 	var buf [5]byte
@@ -1058,10 +1144,48 @@ func (w *WALSegmentWriter) Write(entryType WalEntryType, compressed []byte) erro
 	return nil
 }
 
+// WriteEntry writes entryType and the buffer containing uncompressed entry data.
+// A framed writer adds the entry to the current frame, which is written once it is
+// full or the writer is flushed.  Otherwise the entry is compressed with snappy
+// and written on its own.
+func (w *WALSegmentWriter) WriteEntry(entryType WalEntryType, b []byte) error {
+	if !w.framed {
+		return w.Write(entryType, snappy.Encode(nil, b))
+	}
+
+	var buf [walFrameHeaderSize]byte
+	buf[0] = byte(entryType)
+	binary.BigEndian.PutUint32(buf[1:5], uint32(len(b)))
+	w.frame = append(w.frame, buf[:]...)
+	w.frame = append(w.frame, b...)
+
+	if len(w.frame) >= w.frameSize {
+		return w.flushFrame()
+	}
+	return nil
+}
+
+// flushFrame compresses and writes the entries of the current frame.
+func (w *WALSegmentWriter) flushFrame() error {
+	if len(w.frame) == 0 {
+		return nil
+	}
+
+	compressed, err := encodeWALFrame(w.compression, w.frame)
+	if err != nil {
+		return err
+	}
+	if err := w.Write(FrameWALEntryType, compressed); err != nil {
+		return err
+	}
+	w.frame = w.frame[:0]
+	return nil
+}
+
 // Sync flushes the file systems in-memory copy of recently written data to disk,
 // if w is writing to an os.File.
 func (w *WALSegmentWriter) sync() error {
-	if err := w.bw.Flush(); err != nil {
+	if err := w.Flush(); err != nil {
 		return err
 	}
 
@@ -1072,6 +1196,9 @@ func (w *WALSegmentWriter) sync() error {
 }
 
 func (w *WALSegmentWriter) Flush() error {
+	if err := w.flushFrame(); err != nil {
+		return err
+	}
 	return w.bw.Flush()
 }
 
@@ -1082,13 +1209,77 @@ func (w *WALSegmentWriter) close() error {
 	return w.w.Close()
 }
 
-// WALSegmentReader reads WAL segments.
+// parseWALCompression returns the frame compression for the compression name used by
+// the configuration.  An empty name selects the default, snappy.
+func parseWALCompression(name string) (byte, error) {
+	switch name {
+	case "none":
+		return walCompressionNone, nil
+	case "", "snappy":
+		return walCompressionSnappy, nil
+	case "deflate":
+		return walCompressionDeflate, nil
+	}
+	return 0, fmt.Errorf("unknown wal compression: %s", name)
+}
+
+// encodeWALFrame compresses the entries of a frame and prefixes them with the
+// compression used.
+func encodeWALFrame(compression byte, b []byte) ([]byte, error) {
+	switch compression {
+	case walCompressionNone:
+		return append([]byte{compression}, b...), nil
+	case walCompressionSnappy:
+		dst := make([]byte, 1+snappy.MaxEncodedLen(len(b)))
+		dst[0] = compression
+		return dst[:1+len(snappy.Encode(dst[1:], b))], nil
+	case walCompressionDeflate:
+		var buf bytes.Buffer
+		buf.WriteByte(compression)
+
+		fw := flateWriterPool.Get().(*flate.Writer)
+		defer flateWriterPool.Put(fw)
+
+		fw.Reset(&buf)
+		if _, err := fw.Write(b); err != nil {
+			return nil, err
+		}
+		if err := fw.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	return nil, fmt.Errorf("unknown wal compression: %d", compression)
+}
+
+// decodeWALFrame returns the uncompressed entries of a frame in a new byte slice.
+func decodeWALFrame(b []byte) ([]byte, error) {
+	if len(b) == 0 {
+		return nil, ErrWALCorrupt
+	}
+
+	switch b[0] {
+	case walCompressionNone:
+		return append([]byte(nil), b[1:]...), nil
+	case walCompressionSnappy:
+		return snappy.Decode(nil, b[1:])
+	case walCompressionDeflate:
+		return deflateDecode(b[1:])
+	}
+	return nil, fmt.Errorf("unknown wal compression: %d", b[0])
+}
+
+// WALSegmentReader reads WAL segments.  Segments may contain entries written on
+// their own as well as frames batching multiple entries.
 type WALSegmentReader struct {
 	rc    io.ReadCloser
 	r     *bufio.Reader
 	entry WALEntry
 	n     int64
 	err   error
+
+	// entries holds the entries of the last frame read that have not been returned.
+	entries []WALEntry
 }
 
 // NewWALSegmentReader returns a new WALSegmentReader reading from r.
@@ -1105,10 +1296,17 @@ func (r *WALSegmentReader) Reset(rc io.ReadCloser) {
 	r.entry = nil
 	r.n = 0
 	r.err = nil
+	r.entries = nil
 }
 
 // Next indicates if there is a value to read.
 func (r *WALSegmentReader) Next() bool {
+	// Return the remaining entries of the last frame before reading further.
+	if len(r.entries) > 0 {
+		r.entry, r.entries = r.entries[0], r.entries[1:]
+		return true
+	}
+
 	var nReadOK int
 
 	// read the type and the length of the entry
@@ -1140,6 +1338,20 @@ func (r *WALSegmentReader) Next() bool {
 	}
 	nReadOK += n
 
+	if WalEntryType(entryType) == FrameWALEntryType {
+		entries, err := unmarshalWALFrame(b[:length])
+		if err != nil {
+			r.err = err
+			return true
+		}
+
+		// The frame is only counted once all of its entries were decoded, so a
+		// corrupt frame is truncated as a whole.
+		r.n += int64(nReadOK)
+		r.entry, r.entries, r.err = entries[0], entries[1:], nil
+		return true
+	}
+
 	decLen, err := snappy.DecodedLen(b[:length])
 	if err != nil {
 		r.err = err
@@ -1155,26 +1367,68 @@ func (r *WALSegmentReader) Next() bool {
 	}
 
 	// and marshal it and send it to the cache
-	switch WalEntryType(entryType) {
+	r.entry, r.err = unmarshalWALEntry(WalEntryType(entryType), data)
+	if r.err == nil {
+		// Read and decode of this entry was successful.
+		r.n += int64(nReadOK)
+	}
+
+	return true
+}
+
+// unmarshalWALEntry decodes an uncompressed entry of type entryType.
+func unmarshalWALEntry(entryType WalEntryType, b []byte) (WALEntry, error) {
+	var entry WALEntry
+	switch entryType {
 	case WriteWALEntryType:
-		r.entry = &WriteWALEntry{
+		entry = &WriteWALEntry{
 			Values: make(map[string][]Value),
 		}
 	case DeleteWALEntryType:
-		r.entry = &DeleteWALEntry{}
+		entry = &DeleteWALEntry{}
 	case DeleteRangeWALEntryType:
-		r.entry = &DeleteRangeWALEntry{}
+		entry = &DeleteRangeWALEntry{}
 	default:
-		r.err = fmt.Errorf("unknown wal entry type: %v", entryType)
-		return true
+		return nil, fmt.Errorf("unknown wal entry type: %v", entryType)
 	}
-	r.err = r.entry.UnmarshalBinary(data)
-	if r.err == nil {
-		// Read and decode of this entry was successful.
-		r.n += int64(nReadOK)
+	if err := entry.UnmarshalBinary(b); err != nil {
+		return nil, err
 	}
+	return entry, nil
+}
 
-	return true
+// unmarshalWALFrame decompresses a frame and decodes all of its entries.
+func unmarshalWALFrame(b []byte) ([]WALEntry, error) {
+	data, err := decodeWALFrame(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []WALEntry
+	for i := 0; i < len(data); {
+		if i+walFrameHeaderSize > len(data) {
+			return nil, ErrWALCorrupt
+		}
+		entryType := WalEntryType(data[i])
+		length := int(binary.BigEndian.Uint32(data[i+1 : i+walFrameHeaderSize]))
+		i += walFrameHeaderSize
+
+		if i+length > len(data) {
+			return nil, ErrWALCorrupt
+		}
+
+		entry, err := unmarshalWALEntry(entryType, data[i:i+length])
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+		i += length
+	}
+
+	if len(entries) == 0 {
+		return nil, ErrWALCorrupt
+	}
+	return entries, nil
 }
 
 // Read returns the next entry in the reader.
@@ -1,6 +1,7 @@
 package tsm1_test
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"os"
@@ -458,6 +459,68 @@ func TestWALWriter_WriteMultiDeleteRange_Multiple(t *testing.T) {
 
 }
 
+func TestWALWriter_WriteEntry_Framed(t *testing.T) {
+	for _, compression := range []string{"none", "snappy", "deflate"} {
+		t.Run(compression, func(t *testing.T) {
+			dir := MustTempDir()
+			defer os.RemoveAll(dir)
+			f := MustTempFile(dir)
+
+			// A frame size of 1 writes each entry in its own frame.
+			w, err := tsm1.NewFramedWALSegmentWriter(f, compression, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			entries := []tsm1.WALEntry{
+				&tsm1.WriteWALEntry{Values: map[string][]tsm1.Value{"cpu,host=A#!~#value": {tsm1.NewValue(1, 1.1)}}},
+				&tsm1.DeleteWALEntry{Keys: [][]byte{[]byte("cpu,host=A#!~#value")}},
+			}
+			for _, entry := range entries {
+				b, err := entry.MarshalBinary()
+				if err != nil {
+					t.Fatal(err)
+				} else if err := w.WriteEntry(entry.Type(), b); err != nil {
+					fatal(t, "write entry", err)
+				}
+			}
+
+			if err := w.Flush(); err != nil {
+				fatal(t, "flush", err)
+			}
+
+			if _, err := f.Seek(0, io.SeekStart); err != nil {
+				fatal(t, "seek", err)
+			}
+
+			r := tsm1.NewWALSegmentReader(f)
+			for i, exp := range entries {
+				if !r.Next() {
+					t.Fatalf("expected next for entry %d, got false", i)
+				}
+
+				entry, err := r.Read()
+				if err != nil {
+					fatal(t, "read entry", err)
+				}
+				if got, err := entry.MarshalBinary(); err != nil {
+					t.Fatal(err)
+				} else if expb, _ := exp.MarshalBinary(); !bytes.Equal(got, expb) {
+					t.Fatalf("entry %d mismatch: got %v, exp %v", i, entry, exp)
+				}
+			}
+
+			if r.Next() {
+				t.Fatal("expected no more entries")
+			}
+
+			if n := r.Count(); n != MustReadFileSize(f) {
+				t.Fatalf("wrong count of bytes read, got %d, exp %d", n, MustReadFileSize(f))
+			}
+		})
+	}
+}
+
 func TestWAL_ClosedSegments(t *testing.T) {
 	dir := MustTempDir()
 	defer os.RemoveAll(dir)
@@ -1,12 +1,13 @@
 package tsm1
 
 /*
-A TSM file is composed for four sections: header, blocks, index and the footer.
+A TSM file is composed for five sections: header, blocks, bloom filter, index and
+the footer.
 
-┌────────┬────────────────────────────────────┬─────────────┬──────────────┐
-│ Header │               Blocks               │    Index    │    Footer    │
-│5 bytes │              N bytes               │   N bytes   │   4 bytes    │
-└────────┴────────────────────────────────────┴─────────────┴──────────────┘
+┌────────┬──────────────────────────────┬──────────────┬─────────────┬──────────────┐
+│ Header │            Blocks            │ Bloom Filter │    Index    │    Footer    │
+│5 bytes │           N bytes            │   N bytes    │   N bytes   │   4 bytes    │
+└────────┴──────────────────────────────┴──────────────┴─────────────┴──────────────┘
 
 Header is composed of a magic number to identify the file type and a version
 number.
@@ -31,7 +32,22 @@ is stored in the index.
 │ 4 bytes │ N bytes │ 4 bytes │ N bytes │ 4 bytes │ N bytes │
 └─────────┴─────────┴─────────┴─────────┴─────────┴─────────┘
 
-Following the blocks is the index for the blocks in the file.  The index is
+Following the blocks is a bloom filter of every key in the file.  It allows
+readers to skip files that cannot contain a key without searching the index.
+The filter is followed by a trailer holding the number of hash functions, the
+size of the filter, a CRC32 of the filter and a magic number identifying the
+section.  Files written before the bloom filter was added end their blocks
+where the index starts, so readers treat a missing or invalid trailer as a file
+without a bloom filter.
+
+┌───────────────────────────────────────────────────────────┐
+│                       Bloom Filter                        │
+├─────────┬─────────┬─────────┬─────────┬───────────────────┤
+│ Filter  │    K    │  Size   │   CRC   │       Magic       │
+│ N bytes │ 8 bytes │ 8 bytes │ 4 bytes │      4 bytes      │
+└─────────┴─────────┴─────────┴─────────┴───────────────────┘
+
+Following the bloom filter is the index for the blocks in the file.  The index is
 composed of a sequence of index entries ordered lexicographically by key and
 then by time.  Each index entry starts with a key length and key followed by a
 count of the number of blocks in the file.  Each block entry is composed of
@@ -52,6 +68,19 @@ file, we can use the size to determine how much to read in a given IO.
 │ 2 bytes │ N bytes │1 byte│2 bytes│ 8 bytes │ 8 bytes │8 bytes │4 bytes │   │
 └─────────┴─────────┴──────┴───────┴─────────┴─────────┴────────┴────────┴───┘
 
+Since version 2, each entry of a numeric block is followed by aggregate
+statistics of the values in the block and the high bit of the type is set for
+keys whose entries carry statistics.  Statistics let queries answer aggregates
+over blocks that lie entirely within an interval without decoding them.  The
+first and last values are those at the min and max time of the block.
+
+┌────────────────────────────────────────────────────────────────────────────┐
+│                                Block Stats                                 │
+├─────────┬───────┬───────┬────────┬───────┬────────┬─────────┬──────────────┤
+│  Count  │  Sum  │  Min  │Min Time│  Max  │Max Time│  First  │    Last      │
+│ 4 bytes │8 bytes│8 bytes│8 bytes │8 bytes│8 bytes │ 8 bytes │   8 bytes    │
+└─────────┴───────┴───────┴────────┴───────┴────────┴─────────┴──────────────┘
+
 The last section is the footer that stores the offset of the start of the index.
 
 ┌─────────┐
@@ -69,10 +98,13 @@ import (
 	"fmt"
 	"hash/crc32"
 	"io"
+	"io/ioutil"
 	"os"
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/influxdata/influxdb/pkg/bloom"
 )
 
 const (
@@ -81,11 +113,18 @@ const (
 	MagicNumber uint32 = 0x16D116D1
 
 	// Version indicates the version of the TSM file format.
-	Version byte = 1
+	Version byte = 2
 
 	// Size in bytes of an index entry
 	indexEntrySize = 28
 
+	// Size in bytes of the block statistics following an index entry
+	indexStatsSize = 60
+
+	// indexStatsFlag is set in the block type of keys whose index entries are
+	// followed by block statistics.
+	indexStatsFlag byte = 0x80
+
 	// Size in bytes used to store the count of index entries for a key
 	indexCountSize = 2
 
@@ -97,6 +136,17 @@ const (
 
 	// max length of a key in an index entry (measurement + tags)
 	maxKeyLength = (1 << (2 * 8)) - 1
+
+	// bloomMagicNumber is written as the last 4 bytes of the bloom filter section
+	// to identify the section.
+	bloomMagicNumber uint32 = 0x16D1B101
+
+	// Size in bytes of the trailer following the bloom filter bits
+	bloomTrailerSize = 24
+
+	// bloomFalsePositiveRate is the false positive rate that the bloom filter of
+	// a file is sized for.
+	bloomFalsePositiveRate = 0.01
 )
 
 var (
@@ -151,6 +201,10 @@ type IndexWriter interface {
 	// Add records a new block entry for a key in the index.
 	Add(key []byte, blockType byte, minTime, maxTime int64, offset int64, size uint32)
 
+	// AddEntry records a new block entry, which may carry block statistics, for a
+	// key in the index.
+	AddEntry(key []byte, blockType byte, entry IndexEntry)
+
 	// Entries returns all index entries for a key.
 	Entries(key []byte) []IndexEntry
 
@@ -181,6 +235,10 @@ type IndexEntry struct {
 
 	// The size in bytes of the block in the file.
 	Size uint32
+
+	// Stats holds aggregate statistics of the values in the block, if the file
+	// recorded them.
+	Stats BlockStats
 }
 
 // UnmarshalBinary decodes an IndexEntry from a byte slice.
@@ -214,6 +272,11 @@ func (e *IndexEntry) AppendTo(b []byte) []byte {
 	return b
 }
 
+// HasStats returns true if the entry carries statistics of the values in its block.
+func (e *IndexEntry) HasStats() bool {
+	return e.Stats.Count > 0
+}
+
 // Contains returns true if this IndexEntry may contain values for the given time.
 // The min and max times are inclusive.
 func (e *IndexEntry) Contains(t int64) bool {
@@ -263,6 +326,21 @@ type directIndex struct {
 }
 
 func (d *directIndex) Add(key []byte, blockType byte, minTime, maxTime int64, offset int64, size uint32) {
+	d.AddEntry(key, blockType, IndexEntry{
+		MinTime: minTime,
+		MaxTime: maxTime,
+		Offset:  offset,
+		Size:    size,
+	})
+}
+
+func (d *directIndex) AddEntry(key []byte, blockType byte, entry IndexEntry) {
+	// size of the encoded index entry
+	entrySize := uint32(indexEntrySize)
+	if entry.HasStats() {
+		entrySize += indexStatsSize
+	}
+
 	// Is this the first block being added?
 	if len(d.key) == 0 {
 		// size of the key stored in the index
@@ -275,15 +353,9 @@ func (d *directIndex) Add(key []byte, blockType byte, minTime, maxTime int64, of
 			d.indexEntries = &indexEntries{}
 		}
 		d.indexEntries.Type = blockType
-		d.indexEntries.entries = append(d.indexEntries.entries, IndexEntry{
-			MinTime: minTime,
-			MaxTime: maxTime,
-			Offset:  offset,
-			Size:    size,
-		})
-
-		// size of the encoded index entry
-		d.size += indexEntrySize
+		d.indexEntries.entries = append(d.indexEntries.entries, entry)
+
+		d.size += entrySize
 		d.keyCount++
 		return
 	}
@@ -292,15 +364,9 @@ func (d *directIndex) Add(key []byte, blockType byte, minTime, maxTime int64, of
 	cmp := bytes.Compare(d.key, key)
 	if cmp == 0 {
 		// The last block is still this key
-		d.indexEntries.entries = append(d.indexEntries.entries, IndexEntry{
-			MinTime: minTime,
-			MaxTime: maxTime,
-			Offset:  offset,
-			Size:    size,
-		})
+		d.indexEntries.entries = append(d.indexEntries.entries, entry)
 
-		// size of the encoded index entry
-		d.size += indexEntrySize
+		d.size += entrySize
 
 	} else if cmp < 0 {
 		d.flush(d.w)
@@ -314,15 +380,9 @@ func (d *directIndex) Add(key []byte, blockType byte, minTime, maxTime int64, of
 
 		d.key = key
 		d.indexEntries.Type = blockType
-		d.indexEntries.entries = append(d.indexEntries.entries, IndexEntry{
-			MinTime: minTime,
-			MaxTime: maxTime,
-			Offset:  offset,
-			Size:    size,
-		})
-
-		// size of the encoded index entry
-		d.size += indexEntrySize
+		d.indexEntries.entries = append(d.indexEntries.entries, entry)
+
+		d.size += entrySize
 		d.keyCount++
 	} else {
 		// Keys can't be added out of order.
@@ -405,6 +465,9 @@ func (d *directIndex) flush(w io.Writer) (int64, error) {
 
 	binary.BigEndian.PutUint16(buf[0:2], uint16(len(key)))
 	buf[2] = entries.Type
+	if entries.hasStats() {
+		buf[2] |= indexStatsFlag
+	}
 	binary.BigEndian.PutUint16(buf[3:5], uint16(entries.Len()))
 
 	// Append the key length and key
@@ -439,6 +502,60 @@ func (d *directIndex) flush(w io.Writer) (int64, error) {
 
 }
 
+// forEachKey calls fn with each key in the index in order.  The key is only valid
+// for the duration of the call.
+func (d *directIndex) forEachKey(fn func(key []byte)) error {
+	if _, err := d.flush(d.w); err != nil {
+		return err
+	}
+
+	if err := d.w.Flush(); err != nil {
+		return err
+	}
+
+	var r io.Reader
+	if d.fd == nil {
+		r = bytes.NewReader(d.buf.Bytes())
+	} else {
+		if _, err := d.fd.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		r = bufio.NewReaderSize(d.fd, 1024*1024)
+	}
+
+	var (
+		buf [indexTypeSize + indexCountSize]byte
+		key []byte
+	)
+	for {
+		// Read the key length and key
+		if _, err := io.ReadFull(r, buf[:2]); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		n := int(binary.BigEndian.Uint16(buf[:2]))
+		if cap(key) < n {
+			key = make([]byte, n)
+		}
+		key = key[:n]
+		if _, err := io.ReadFull(r, key); err != nil {
+			return err
+		}
+		fn(key)
+
+		// Skip the block type, count and each index entry
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			return err
+		}
+		count := int64(binary.BigEndian.Uint16(buf[indexTypeSize:]))
+		if _, err := io.CopyN(ioutil.Discard, r, count*int64(indexEntrySizeOf(buf[0]))); err != nil {
+			return err
+		}
+	}
+}
+
 func (d *directIndex) MarshalBinary() ([]byte, error) {
 	var b bytes.Buffer
 	if _, err := d.WriteTo(&b); err != nil {
@@ -486,6 +603,9 @@ type tsmWriter struct {
 	w       *bufio.Writer
 	index   IndexWriter
 	n       int64
+
+	// stats decodes the blocks written by WriteBlock to compute their statistics.
+	stats blockStatsDecoder
 }
 
 // NewTSMWriter returns a new TSMWriter writing to w.
@@ -560,7 +680,13 @@ func (t *tsmWriter) Write(key []byte, values Values) error {
 	n += len(checksum)
 
 	// Record this block in index
-	t.index.Add(key, blockType, values[0].UnixNano(), values[len(values)-1].UnixNano(), t.n, uint32(n))
+	t.index.AddEntry(key, blockType, IndexEntry{
+		MinTime: values[0].UnixNano(),
+		MaxTime: values[len(values)-1].UnixNano(),
+		Offset:  t.n,
+		Size:    uint32(n),
+		Stats:   newBlockStats(values),
+	})
 
 	// Increment file position pointer
 	t.n += int64(n)
@@ -571,6 +697,12 @@ func (t *tsmWriter) Write(key []byte, values Values) error {
 // exceeds max entries for a given key, ErrMaxBlocksExceeded is returned.  This indicates
 // that the index is now full for this key and no future writes to this key will succeed.
 func (t *tsmWriter) WriteBlock(key []byte, minTime, maxTime int64, block []byte) error {
+	return t.writeBlockStats(key, minTime, maxTime, block, nil)
+}
+
+// writeBlockStats writes block like WriteBlock, recording stats as the statistics of its
+// values.  The block is decoded to compute them if stats is nil.
+func (t *tsmWriter) writeBlockStats(key []byte, minTime, maxTime int64, block []byte, stats *BlockStats) error {
 	if len(key) > maxKeyLength {
 		return ErrMaxKeyLengthExceeded
 	}
@@ -585,6 +717,14 @@ func (t *tsmWriter) WriteBlock(key []byte, minTime, maxTime int64, block []byte)
 		return err
 	}
 
+	if stats == nil {
+		s, err := t.stats.blockStats(block)
+		if err != nil {
+			return err
+		}
+		stats = &s
+	}
+
 	// Write header only after we have some data to write.
 	if t.n == 0 {
 		if err := t.writeHeader(); err != nil {
@@ -607,7 +747,13 @@ func (t *tsmWriter) WriteBlock(key []byte, minTime, maxTime int64, block []byte)
 	n += len(checksum)
 
 	// Record this block in index
-	t.index.Add(key, blockType, minTime, maxTime, t.n, uint32(n))
+	t.index.AddEntry(key, blockType, IndexEntry{
+		MinTime: minTime,
+		MaxTime: maxTime,
+		Offset:  t.n,
+		Size:    uint32(n),
+		Stats:   *stats,
+	})
 
 	// Increment file position pointer (checksum + block len)
 	t.n += int64(n)
@@ -622,12 +768,16 @@ func (t *tsmWriter) WriteBlock(key []byte, minTime, maxTime int64, block []byte)
 // WriteIndex writes the index section of the file.  If there are no index entries to write,
 // this returns ErrNoValues.
 func (t *tsmWriter) WriteIndex() error {
-	indexPos := t.n
-
 	if t.index.KeyCount() == 0 {
 		return ErrNoValues
 	}
 
+	if err := t.writeBloomFilter(); err != nil {
+		return err
+	}
+
+	indexPos := t.n
+
 	// Write the index
 	if _, err := t.index.WriteTo(t.w); err != nil {
 		return err
@@ -641,6 +791,40 @@ func (t *tsmWriter) WriteIndex() error {
 	return err
 }
 
+// writeBloomFilter writes the bloom filter section containing every key in the index.
+func (t *tsmWriter) writeBloomFilter() error {
+	d, ok := t.index.(*directIndex)
+	if !ok {
+		return nil
+	}
+
+	m, k := bloom.Estimate(uint64(d.KeyCount()), bloomFalsePositiveRate)
+	filter := bloom.NewFilter(m, k)
+	if err := d.forEachKey(filter.InsertKey); err != nil {
+		return err
+	}
+
+	b := filter.Bytes()
+	var trailer [bloomTrailerSize]byte
+	binary.BigEndian.PutUint64(trailer[0:8], filter.K())
+	binary.BigEndian.PutUint64(trailer[8:16], uint64(len(b)))
+	binary.BigEndian.PutUint32(trailer[16:20], crc32.ChecksumIEEE(b))
+	binary.BigEndian.PutUint32(trailer[20:24], bloomMagicNumber)
+
+	n, err := t.w.Write(b)
+	if err != nil {
+		return err
+	}
+	t.n += int64(n)
+
+	n, err = t.w.Write(trailer[:])
+	if err != nil {
+		return err
+	}
+	t.n += int64(n)
+	return nil
+}
+
 func (t *tsmWriter) Flush() error {
 	if err := t.w.Flush(); err != nil {
 		return err
@@ -690,7 +874,7 @@ func (t *tsmWriter) Size() uint32 {
 }
 
 // verifyVersion verifies that the reader's bytes are a TSM byte
-// stream of the correct version (1)
+// stream of a supported version (1 or 2)
 func verifyVersion(r io.ReadSeeker) error {
 	_, err := r.Seek(0, 0)
 	if err != nil {
@@ -708,8 +892,8 @@ func verifyVersion(r io.ReadSeeker) error {
 	if err != nil {
 		return fmt.Errorf("init: error reading version: %v", err)
 	}
-	if b[0] != Version {
-		return fmt.Errorf("init: file is version %b. expected %b", b[0], Version)
+	if b[0] < 1 || b[0] > Version {
+		return fmt.Errorf("init: file is version %b. expected at most %b", b[0], Version)
 	}
 
 	return nil
@@ -5,7 +5,9 @@ import (
 	"encoding/binary"
 	"io"
 	"io/ioutil"
+	"math"
 	"os"
+	"reflect"
 	"testing"
 
 	"github.com/influxdata/influxdb/tsdb/engine/tsm1"
@@ -544,6 +546,126 @@ func TestTSMWriter_WriteBlock_Multiple(t *testing.T) {
 	}
 }
 
+func TestTSMWriter_Write_BlockStats(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+	f := MustTempFile(dir)
+
+	w, err := tsm1.NewTSMWriter(f)
+	if err != nil {
+		t.Fatalf("unexpected error creating writer: %v", err)
+	}
+
+	var data = []struct {
+		key    string
+		typ    byte
+		values []tsm1.Value
+		exp    tsm1.BlockStats
+	}{
+		{"cpu", tsm1.BlockFloat64, []tsm1.Value{tsm1.NewValue(1, 2.0), tsm1.NewValue(2, 1.0), tsm1.NewValue(3, 4.0), tsm1.NewValue(4, 1.0)}, tsm1.BlockStats{
+			Count: 4,
+			Sum:   math.Float64bits(8), Min: math.Float64bits(1), Max: math.Float64bits(4),
+			First: math.Float64bits(2), Last: math.Float64bits(1),
+			MinTime: 2, MaxTime: 3,
+		}},
+		{"disk", tsm1.BlockInteger, []tsm1.Value{tsm1.NewValue(1, int64(-3)), tsm1.NewValue(2, int64(5))}, tsm1.BlockStats{
+			Count: 2,
+			Sum:   2, Min: uint64(1<<64 - 3), Max: 5,
+			First: uint64(1<<64 - 3), Last: 5,
+			MinTime: 1, MaxTime: 2,
+		}},
+		{"mem", tsm1.BlockString, []tsm1.Value{tsm1.NewValue(1, "a"), tsm1.NewValue(2, "b")}, tsm1.BlockStats{}},
+	}
+
+	for _, d := range data {
+		if err := w.Write([]byte(d.key), d.values); err != nil {
+			t.Fatalf("unexpected error writing: %v", err)
+		}
+	}
+
+	if err := w.WriteIndex(); err != nil {
+		t.Fatalf("unexpected error closing: %v", err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected error closing: %v", err)
+	}
+
+	fd, err := os.Open(f.Name())
+	if err != nil {
+		t.Fatalf("unexpected error open file: %v", err)
+	}
+
+	r, err := tsm1.NewTSMReader(fd)
+	if err != nil {
+		t.Fatalf("unexpected error created reader: %v", err)
+	}
+
+	// Copying the blocks with WriteBlock must compute the same statistics.
+	f = MustTempFile(dir)
+	w, err = tsm1.NewTSMWriter(f)
+	if err != nil {
+		t.Fatalf("unexpected error creating writer: %v", err)
+	}
+
+	iter := r.BlockIterator()
+	for iter.Next() {
+		key, minTime, maxTime, _, _, b, err := iter.Read()
+		if err != nil {
+			t.Fatalf("unexpected error reading block: %v", err)
+		}
+		if err := w.WriteBlock([]byte(key), minTime, maxTime, b); err != nil {
+			t.Fatalf("unexpected error writing block: %v", err)
+		}
+	}
+	if err := w.WriteIndex(); err != nil {
+		t.Fatalf("unexpected error closing: %v", err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected error closing: %v", err)
+	}
+
+	fd, err = os.Open(f.Name())
+	if err != nil {
+		t.Fatalf("unexpected error open file: %v", err)
+	}
+
+	copied, err := tsm1.NewTSMReader(fd)
+	if err != nil {
+		t.Fatalf("unexpected error created reader: %v", err)
+	}
+	defer copied.Close()
+
+	for _, r := range []*tsm1.TSMReader{r, copied} {
+		for _, d := range data {
+			entries := r.Entries([]byte(d.key))
+			if len(entries) != 1 {
+				t.Fatalf("entries length mismatch: got %v, exp %v", len(entries), 1)
+			}
+			if got := entries[0].Stats; !reflect.DeepEqual(got, d.exp) {
+				t.Fatalf("stats mismatch for %s: got %+v, exp %+v", d.key, got, d.exp)
+			}
+
+			// The flag marking statistics must not leak into the block type.
+			typ, err := r.Type([]byte(d.key))
+			if err != nil {
+				t.Fatalf("unexpected error reading type: %v", err)
+			} else if typ != d.typ {
+				t.Fatalf("type mismatch for %s: got %v, exp %v", d.key, typ, d.typ)
+			}
+
+			values, err := r.ReadAll([]byte(d.key))
+			if err != nil {
+				t.Fatalf("unexpected error readin: %v", err)
+			} else if len(values) != len(d.values) {
+				t.Fatalf("read values length mismatch: got %v, exp %v", len(values), len(d.values))
+			}
+		}
+	}
+	r.Close()
+}
+
 func TestTSMWriter_WriteBlock_MaxKey(t *testing.T) {
 	dir := MustTempDir()
 	defer os.RemoveAll(dir)
@@ -159,6 +159,13 @@ that value. Series iterators can be built around a single tag key value or
 multiple iterators can be merged with set operators such as union or
 intersection.
 
+Optionally, the hash index of the values of each key is followed by a trigram
+index. It holds a sorted list of every three byte sequence found in the values,
+each pointing to a list of the offsets of the values containing it. Regular
+expressions are matched against the values holding every trigram of the
+literals the expression requires, instead of all values of the key. Keys with a
+trigram index are flagged, and tag blocks holding any are written with version 2.
+
 
 Measurement block
 
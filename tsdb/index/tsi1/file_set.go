@@ -435,6 +435,30 @@ func (fs *FileSet) TagValueIterator(name, key []byte) TagValueIterator {
 	return MergeTagValueIterators(a...)
 }
 
+// matchTagValueIterator returns a value iterator for a tag key over the values that may
+// match value.  Values are narrowed down using the trigram indexes of index files where
+// the trigrams of value can be determined, and must still be matched against value.
+func (fs *FileSet) matchTagValueIterator(name, key []byte, value *regexp.Regexp) TagValueIterator {
+	trigrams := RegexTrigrams(value)
+	if len(trigrams) == 0 {
+		return fs.TagValueIterator(name, key)
+	}
+
+	a := make([]TagValueIterator, 0, len(fs.files))
+	for _, f := range fs.files {
+		var itr TagValueIterator
+		if f, ok := f.(*IndexFile); ok {
+			itr = f.TrigramTagValueIterator(name, key, trigrams)
+		} else {
+			itr = f.TagValueIterator(name, key)
+		}
+		if itr != nil {
+			a = append(a, itr)
+		}
+	}
+	return MergeTagValueIterators(a...)
+}
+
 // TagValueSeriesIterator returns a series iterator for a single tag value.
 func (fs *FileSet) TagValueSeriesIterator(name, key, value []byte) tsdb.SeriesIterator {
 	a := make([]tsdb.SeriesIterator, 0, len(fs.files))
@@ -485,7 +509,7 @@ func (fs *FileSet) matchTagValueEqualEmptySeriesIterator(name, key []byte, value
 }
 
 func (fs *FileSet) matchTagValueEqualNotEmptySeriesIterator(name, key []byte, value *regexp.Regexp) tsdb.SeriesIterator {
-	vitr := fs.TagValueIterator(name, key)
+	vitr := fs.matchTagValueIterator(name, key, value)
 	if vitr == nil {
 		return nil
 	}
@@ -515,7 +539,7 @@ func (fs *FileSet) matchTagValueNotEqualEmptySeriesIterator(name, key []byte, va
 }
 
 func (fs *FileSet) matchTagValueNotEqualNotEmptySeriesIterator(name, key []byte, value *regexp.Regexp) tsdb.SeriesIterator {
-	vitr := fs.TagValueIterator(name, key)
+	vitr := fs.matchTagValueIterator(name, key, value)
 	if vitr == nil {
 		return fs.MeasurementSeriesIterator(name)
 	}
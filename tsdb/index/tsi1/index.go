@@ -72,6 +72,11 @@ type Index struct {
 	fileSet       *FileSet // current file set
 	seq           int      // file id sequence
 
+	// Rebuild management.
+	seriesKeys     SeriesKeysFunc // series keys of the shard's data
+	rebuildNeeded  bool           // manifest missing or invalid on open
+	rebuildLogFile *LogFile       // log file of a running rebuild
+
 	// Compaction management
 	levels          []CompactionLevel // compaction levels
 	levelCompacting []bool            // level compaction status
@@ -136,16 +141,28 @@ func (i *Index) Open() error {
 	}
 
 	// Create directory if it doesn't exist.
+	_, err := os.Stat(i.Path)
+	existed := err == nil
 	if err := os.MkdirAll(i.Path, 0777); err != nil {
 		return err
 	}
 
-	// Read manifest file.
+	// Read manifest file.  The files of an existing index without a valid manifest
+	// can't be trusted, so the index must be rebuilt from the shard's data.
 	m, err := ReadManifestFile(filepath.Join(i.Path, ManifestFileName))
-	if os.IsNotExist(err) {
-		m = NewManifest()
-	} else if err != nil {
-		return err
+	switch err.(type) {
+	case nil:
+	case *json.SyntaxError, *json.UnmarshalTypeError:
+		i.logger.Info("invalid index manifest, rebuild required", zap.String("path", i.Path), zap.Error(err))
+		m, i.rebuildNeeded = NewManifest(), true
+	default:
+		if !os.IsNotExist(err) {
+			return err
+		}
+		if existed {
+			i.logger.Info("missing index manifest, rebuild required", zap.String("path", i.Path))
+		}
+		m, i.rebuildNeeded = NewManifest(), existed
 	}
 
 	// Check to see if the MANIFEST file is compatible with the current Index.
@@ -254,7 +271,7 @@ func (i *Index) deleteNonManifestFiles(m *Manifest) error {
 			continue
 		}
 
-		if err := os.RemoveAll(filename); err != nil {
+		if err := os.RemoveAll(filepath.Join(i.Path, filename)); err != nil {
 			return err
 		}
 	}
@@ -318,8 +335,13 @@ func (i *Index) Manifest() *Manifest {
 	return m
 }
 
-// writeManifestFile writes the manifest to the appropriate file path.
+// writeManifestFile writes the manifest to the appropriate file path.  No manifest is
+// written while a rebuild is needed, so the index is still rebuilt if it's reopened
+// before the rebuild completes.
 func (i *Index) writeManifestFile() error {
+	if i.rebuildNeeded {
+		return nil
+	}
 	return WriteManifestFile(i.ManifestPath(), i.Manifest())
 }
 
@@ -465,6 +487,18 @@ func (i *Index) DropMeasurement(name []byte) error {
 		}
 	}
 
+	// Delete the measurement from a running rebuild too.
+	if err := func() error {
+		i.mu.RLock()
+		defer i.mu.RUnlock()
+		if i.rebuildLogFile == nil {
+			return nil
+		}
+		return i.rebuildLogFile.DeleteMeasurement(name)
+	}(); err != nil {
+		return err
+	}
+
 	// Delete all series in measurement.
 	if sitr := fs.MeasurementSeriesIterator(name); sitr != nil {
 		for s := sitr.Next(); s != nil; s = sitr.Next() {
@@ -504,6 +538,11 @@ func (i *Index) CreateSeriesListIfNotExists(_, names [][]byte, tagsSlice []model
 		return errors.New("names/tags length mismatch")
 	}
 
+	// Add the series to a running rebuild too.
+	if err := i.addRebuildSeriesList(names, tagsSlice); err != nil {
+		return err
+	}
+
 	// Maintain reference count on files in file set.
 	fs := i.RetainFileSet()
 	defer fs.Release()
@@ -533,6 +572,11 @@ func (i *Index) InitializeSeries(key, name []byte, tags models.Tags) error {
 
 // CreateSeriesIfNotExists creates a series if it doesn't exist or is deleted.
 func (i *Index) CreateSeriesIfNotExists(key, name []byte, tags models.Tags) error {
+	// Add the series to a running rebuild too.
+	if err := i.addRebuildSeriesList([][]byte{name}, []models.Tags{tags}); err != nil {
+		return err
+	}
+
 	if err := func() error {
 		i.mu.RLock()
 		defer i.mu.RUnlock()
@@ -571,6 +615,13 @@ func (i *Index) DropSeries(key []byte, ts int64) error {
 			return err
 		}
 
+		// Delete the series from a running rebuild too.
+		if i.rebuildLogFile != nil {
+			if err := i.rebuildLogFile.DeleteSeries(mname, tags); err != nil {
+				return err
+			}
+		}
+
 		// Obtain file set after deletion because that may add a new log file.
 		fs := i.retainFileSet()
 		defer fs.Release()
@@ -897,7 +948,8 @@ func (i *Index) Compact() {
 
 // compact compacts continguous groups of files that are not currently compacting.
 func (i *Index) compact() {
-	if !i.CompactionEnabled {
+	// Compactions replace files of the file set a rebuild replaces.
+	if !i.CompactionEnabled || i.rebuildLogFile != nil {
 		return
 	}
 
@@ -983,7 +1035,7 @@ func (i *Index) compactToLevel(files []*IndexFile, level int) {
 
 	// Compact all index files to new index file.
 	lvl := i.levels[level]
-	n, err := IndexFiles(files).CompactTo(f, lvl.M, lvl.K)
+	n, err := IndexFiles(files).CompactTo(f, lvl.M, lvl.K, i.options.Config.TrigramIndexEnabled)
 	if err != nil {
 		logger.Error("cannot compact index files", zap.Error(err))
 		return
@@ -1047,7 +1099,195 @@ func (i *Index) compactToLevel(files []*IndexFile, level int) {
 	}
 }
 
-func (i *Index) Rebuild() {}
+// SeriesKeysFunc calls fn with the key of each series with data in a shard.  A series
+// may be passed more than once.
+type SeriesKeysFunc func(fn func(key []byte) error) error
+
+// SetSeriesKeysFunc sets the function returning the series of the shard's data, which
+// the index is rebuilt from.
+func (i *Index) SetSeriesKeysFunc(fn SeriesKeysFunc) {
+	i.mu.Lock()
+	i.seriesKeys = fn
+	i.mu.Unlock()
+}
+
+// RebuildNeeded returns true if the index was opened without a valid manifest, so it
+// must be rebuilt from the shard's data.
+func (i *Index) RebuildNeeded() bool {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.rebuildNeeded
+}
+
+// Rebuild rebuilds the index from the shard's data, logging any error.
+func (i *Index) Rebuild() {
+	if err := i.Regenerate(); err != nil {
+		i.logger.Error("cannot rebuild index", zap.Error(err))
+	}
+}
+
+// Regenerate rebuilds the index from the series of the shard's data.  The series are
+// written to a new log file while the current files continue to serve queries, and the
+// new file replaces them all at once when the manifest is written.  Series created and
+// dropped during the rebuild are applied to both.  The replaced files are removed in the
+// background once they're no longer in use.
+func (i *Index) Regenerate() error {
+	start := time.Now()
+
+	f, err := i.startRebuild()
+	if err != nil {
+		return err
+	}
+
+	// Wait for running compactions, since they replace files in the current file set.
+	i.wg.Wait()
+
+	logger := i.logger.With(zap.String("path", f.Path()))
+	logger.Info("rebuilding index")
+
+	n, err := i.writeRebuildLogFile(f)
+	if err != nil {
+		i.mu.Lock()
+		i.rebuildLogFile = nil
+		i.mu.Unlock()
+
+		f.Close()
+		os.Remove(f.Path())
+		return err
+	}
+
+	// Swap in the new log file and write the manifest.
+	i.mu.Lock()
+	files := i.fileSet.files
+	fs, err := NewFileSet(i.Database, i.levels, []File{f})
+	if err != nil {
+		i.rebuildLogFile = nil
+		i.mu.Unlock()
+
+		f.Close()
+		os.Remove(f.Path())
+		return err
+	}
+	prevFileSet, prevLogFile, rebuildNeeded := i.fileSet, i.activeLogFile, i.rebuildNeeded
+	i.fileSet, i.activeLogFile, i.rebuildLogFile, i.rebuildNeeded = fs, f, nil, false
+	if err := i.writeManifestFile(); err != nil {
+		i.fileSet, i.activeLogFile, i.rebuildNeeded = prevFileSet, prevLogFile, rebuildNeeded
+		i.mu.Unlock()
+
+		f.Close()
+		os.Remove(f.Path())
+		return err
+	}
+
+	// Compact the new log file if it's large enough.
+	err = i.checkLogFile()
+	i.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	logger.Info("index rebuilt",
+		zap.Int("series", n),
+		zap.String("elapsed", time.Since(start).String()),
+	)
+
+	// Close and delete the replaced files in the background, since closing a file
+	// waits until it's no longer in use.
+	i.wg.Add(1)
+	go func() {
+		defer i.wg.Done()
+
+		for _, f := range files {
+			logger.Info("removing index file", zap.String("path", f.Path()))
+
+			if err := f.Close(); err != nil {
+				logger.Error("cannot close index file", zap.Error(err))
+				return
+			} else if err := os.Remove(f.Path()); err != nil {
+				logger.Error("cannot remove index file", zap.Error(err))
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// startRebuild opens the log file of a new rebuild.
+func (i *Index) startRebuild() (*LogFile, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.seriesKeys == nil {
+		return nil, errors.New("index has no series to rebuild from")
+	} else if i.rebuildLogFile != nil {
+		return nil, errors.New("index rebuild already running")
+	}
+
+	f, err := i.openLogFile(filepath.Join(i.Path, FormatLogFileName(i.nextSequence())))
+	if err != nil {
+		return nil, err
+	}
+	i.rebuildLogFile = f
+	return f, nil
+}
+
+// writeRebuildLogFile adds the series of the shard's data to f in batches, returning
+// the number of series read.
+func (i *Index) writeRebuildLogFile(f *LogFile) (int, error) {
+	const batchSize = 10000
+
+	var n int
+	names, tagsSlice := make([][]byte, 0, batchSize), make([]models.Tags, 0, batchSize)
+	flush := func() error {
+		names, tagsSlice = f.FilterNamesTags(names, tagsSlice)
+		if len(names) > 0 {
+			if err := f.AddSeriesList(names, tagsSlice); err != nil {
+				return err
+			}
+		}
+		names, tagsSlice = names[:0], tagsSlice[:0]
+		return nil
+	}
+
+	if err := i.seriesKeys(func(key []byte) error {
+		// Keys may be reused by the caller, so the batch holds copies.
+		name, tags := models.ParseKeyBytes(append([]byte(nil), key...))
+		names, tagsSlice = append(names, name), append(tagsSlice, tags)
+		n++
+
+		if len(names) < batchSize {
+			return nil
+		}
+		return flush()
+	}); err != nil {
+		return 0, err
+	}
+	if err := flush(); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// addRebuildSeriesList adds series created while a rebuild is running to its log file,
+// so they aren't lost when the new file replaces the current files.
+func (i *Index) addRebuildSeriesList(names [][]byte, tagsSlice []models.Tags) error {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	if i.rebuildLogFile == nil {
+		return nil
+	}
+
+	// Filter copies, since filtering modifies the slices.
+	names, tagsSlice = i.rebuildLogFile.FilterNamesTags(
+		append([][]byte(nil), names...),
+		append([]models.Tags(nil), tagsSlice...),
+	)
+	if len(names) == 0 {
+		return nil
+	}
+	return i.rebuildLogFile.AddSeriesList(names, tagsSlice)
+}
 
 func (i *Index) CheckLogFile() error {
 	// Check log file size under read lock.
@@ -1066,7 +1306,7 @@ func (i *Index) CheckLogFile() error {
 }
 
 func (i *Index) checkLogFile() error {
-	if i.activeLogFile.Size() < i.MaxLogFileSize {
+	if i.activeLogFile.Size() < i.MaxLogFileSize || i.rebuildLogFile != nil {
 		return nil
 	}
 
@@ -1116,7 +1356,7 @@ func (i *Index) compactLogFile(logFile *LogFile) {
 
 	// Compact log file to new index file.
 	lvl := i.levels[1]
-	n, err := logFile.CompactTo(f, lvl.M, lvl.K)
+	n, err := logFile.CompactTo(f, lvl.M, lvl.K, i.options.Config.TrigramIndexEnabled)
 	if err != nil {
 		logger.Error("cannot compact log file", zap.Error(err), zap.String("path", logFile.Path()))
 		return
@@ -1352,7 +1592,8 @@ func ReadManifestFile(path string) (*Manifest, error) {
 	return &m, nil
 }
 
-// WriteManifestFile writes a manifest to a file path.
+// WriteManifestFile writes a manifest to a file path.  The manifest is written to a
+// temporary file that's renamed over the path, so it's replaced atomically.
 func WriteManifestFile(path string, m *Manifest) error {
 	buf, err := json.MarshalIndent(m, "", "  ")
 	if err != nil {
@@ -1360,11 +1601,22 @@ func WriteManifestFile(path string, m *Manifest) error {
 	}
 	buf = append(buf, '\n')
 
-	if err := ioutil.WriteFile(path, buf, 0666); err != nil {
+	tmpPath := path + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf); err != nil {
+		f.Close()
+		return err
+	} else if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	} else if err := f.Close(); err != nil {
 		return err
 	}
 
-	return nil
+	return os.Rename(tmpPath, path)
 }
 
 func joinIntSlice(a []int, sep string) string {
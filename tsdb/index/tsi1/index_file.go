@@ -232,6 +232,27 @@ func (f *IndexFile) TagValueIterator(name, key []byte) TagValueIterator {
 	return ke.TagValueIterator()
 }
 
+// TrigramTagValueIterator returns a value iterator for a tag key over the values
+// containing all of trigrams.  All values are returned if the values of the key have
+// no trigram index.
+func (f *IndexFile) TrigramTagValueIterator(name, key []byte, trigrams [][]byte) TagValueIterator {
+	tblk := f.tblks[string(name)]
+	if tblk == nil {
+		return nil
+	}
+
+	// Find key element.
+	ke, _ := tblk.TagKeyElem(key).(*TagBlockKeyElem)
+	if ke == nil {
+		return nil
+	}
+
+	if itr, ok := ke.TrigramTagValueIterator(trigrams); ok {
+		return itr
+	}
+	return ke.TagValueIterator()
+}
+
 // TagKeySeriesIterator returns a series iterator for a tag key and a flag
 // indicating if a tombstone exists on the measurement or key.
 func (f *IndexFile) TagKeySeriesIterator(name, key []byte) tsdb.SeriesIterator {
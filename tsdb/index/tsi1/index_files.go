@@ -128,8 +128,9 @@ func (p IndexFiles) TagValueSeriesIterator(name, key, value []byte) tsdb.SeriesI
 	return MergeSeriesIterators(a...)
 }
 
-// CompactTo merges all index files and writes them to w.
-func (p IndexFiles) CompactTo(w io.Writer, m, k uint64) (n int64, err error) {
+// CompactTo merges all index files and writes them to w.  If trigrams is true, the
+// values of each tag key are indexed by trigram.
+func (p IndexFiles) CompactTo(w io.Writer, m, k uint64, trigrams bool) (n int64, err error) {
 	var t IndexFileTrailer
 
 	// Wrap writer in buffered I/O.
@@ -138,6 +139,7 @@ func (p IndexFiles) CompactTo(w io.Writer, m, k uint64) (n int64, err error) {
 	// Setup context object to track shared data for this compaction.
 	var info indexCompactInfo
 	info.tagSets = make(map[string]indexTagSetPos)
+	info.trigrams = trigrams
 
 	// Write magic number.
 	if err := writeTo(bw, []byte(FileSignature), &n); err != nil {
@@ -246,6 +248,7 @@ func (p IndexFiles) writeTagsetTo(w io.Writer, name []byte, info *indexCompactIn
 	}
 
 	enc := NewTagBlockEncoder(w)
+	enc.Trigrams = info.trigrams
 	for ke := kitr.Next(); ke != nil; ke = kitr.Next() {
 		// Encode key.
 		if err := enc.EncodeKey(ke.Key(), ke.Deleted()); err != nil {
@@ -365,6 +368,9 @@ type indexCompactInfo struct {
 
 	// Tracks offset/size for each measurement's tagset.
 	tagSets map[string]indexTagSetPos
+
+	// Index the trigrams of tag values.
+	trigrams bool
 }
 
 // indexTagSetPos stores the offset/size of tagsets.
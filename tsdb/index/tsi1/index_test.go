@@ -310,6 +310,60 @@ func TestIndex_Manifest(t *testing.T) {
 	})
 }
 
+// Ensure an index opened without its manifest is rebuilt from the shard's series.
+func TestIndex_Regenerate(t *testing.T) {
+	idx := MustOpenIndex()
+	defer idx.Close()
+
+	if err := idx.CreateSeriesSliceIfNotExists([]Series{
+		{Name: []byte("cpu"), Tags: models.NewTags(map[string]string{"region": "east"})},
+		{Name: []byte("mem"), Tags: models.NewTags(map[string]string{"region": "east"})},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopen the index without its manifest.
+	if err := os.Remove(idx.ManifestPath()); err != nil {
+		t.Fatal(err)
+	} else if err := idx.Reopen(); err != nil {
+		t.Fatal(err)
+	} else if !idx.RebuildNeeded() {
+		t.Fatal("expected rebuild to be needed")
+	} else if _, err := os.Stat(idx.ManifestPath()); !os.IsNotExist(err) {
+		t.Fatal("expected no manifest until the index is rebuilt")
+	}
+
+	idx.SetSeriesKeysFunc(func(fn func(key []byte) error) error {
+		for _, key := range []string{"cpu,region=east", "disk,region=west", "cpu,region=east"} {
+			if err := fn([]byte(key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err := idx.Regenerate(); err != nil {
+		t.Fatal(err)
+	} else if idx.RebuildNeeded() {
+		t.Fatal("expected rebuild not to be needed")
+	}
+
+	idx.Run(t, func(t *testing.T) {
+		var names []string
+		if err := idx.ForEachMeasurementName(func(name []byte) error {
+			names = append(names, string(name))
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(names, []string{"cpu", "disk"}) {
+			t.Fatalf("unexpected names: %#v", names)
+		} else if n := idx.SeriesN(); n != 2 {
+			t.Fatalf("unexpected series count: %d", n)
+		}
+	})
+}
+
 // Index is a test wrapper for tsi1.Index.
 type Index struct {
 	*tsi1.Index
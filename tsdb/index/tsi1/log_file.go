@@ -795,8 +795,9 @@ func (f *LogFile) MeasurementSeriesIterator(name []byte) tsdb.SeriesIterator {
 	return newLogSeriesIterator(mm.series)
 }
 
-// CompactTo compacts the log file and writes it to w.
-func (f *LogFile) CompactTo(w io.Writer, m, k uint64) (n int64, err error) {
+// CompactTo compacts the log file and writes it to w.  If trigrams is true, the values
+// of each tag key are indexed by trigram.
+func (f *LogFile) CompactTo(w io.Writer, m, k uint64, trigrams bool) (n int64, err error) {
 	f.mu.RLock()
 	defer f.mu.RUnlock()
 
@@ -806,6 +807,7 @@ func (f *LogFile) CompactTo(w io.Writer, m, k uint64) (n int64, err error) {
 	// Setup compaction offset tracking data.
 	var t IndexFileTrailer
 	info := newLogFileCompactInfo()
+	info.trigrams = trigrams
 
 	// Write magic number.
 	if err := writeTo(bw, []byte(FileSignature), &n); err != nil {
@@ -953,6 +955,7 @@ func (f *LogFile) writeTagsetTo(w io.Writer, name string, info *logFileCompactIn
 	mmInfo := info.mms[name]
 
 	enc := NewTagBlockEncoder(w)
+	enc.Trigrams = info.trigrams
 	for _, k := range mm.keys() {
 		tag := mm.tagSet[k]
 
@@ -1023,7 +1026,8 @@ func (f *LogFile) writeMeasurementBlockTo(w io.Writer, names []string, info *log
 
 // logFileCompactInfo is a context object to track compaction position info.
 type logFileCompactInfo struct {
-	mms map[string]*logFileMeasurementCompactInfo
+	mms      map[string]*logFileMeasurementCompactInfo
+	trigrams bool // index the trigrams of tag values
 }
 
 // newLogFileCompactInfo returns a new instance of logFileCompactInfo.
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"sort"
 
 	"github.com/influxdata/influxdb/pkg/rhh"
 )
@@ -13,9 +14,14 @@ import (
 // TagBlockVersion is the version of the tag block.
 const TagBlockVersion = 1
 
+// TagBlockTrigramVersion is the version of tag blocks holding trigram indexes of the
+// values of their keys.
+const TagBlockTrigramVersion = 2
+
 // Tag key flag constants.
 const (
 	TagKeyTombstoneFlag = 0x01
+	TagKeyTrigramFlag   = 0x02
 )
 
 // Tag value flag constants.
@@ -32,6 +38,12 @@ const (
 	// TagBlock value block fields.
 	TagValueNSize      = 8
 	TagValueOffsetSize = 8
+
+	// TagBlock trigram index fields.
+	TagTrigramNSize      = 8
+	TagTrigramSize       = 3
+	TagTrigramOffsetSize = 8
+	TagTrigramEntrySize  = TagTrigramSize + TagTrigramOffsetSize
 )
 
 // TagBlock errors.
@@ -83,6 +95,7 @@ func (blk *TagBlock) UnmarshalBinary(data []byte) error {
 
 	// Save entire block.
 	blk.data = data
+	blk.version = t.Version
 
 	return nil
 }
@@ -246,6 +259,13 @@ type TagBlockKeyElem struct {
 		buf    []byte
 	}
 
+	// Value trigram index data, if the trigram flag is set.
+	trigramIndex struct {
+		offset uint64
+		size   uint64
+		buf    []byte
+	}
+
 	size int
 
 	// Reusable iterator.
@@ -263,6 +283,46 @@ func (e *TagBlockKeyElem) TagValueIterator() TagValueIterator {
 	return &tagBlockValueIterator{data: e.data.buf}
 }
 
+// HasTrigramIndex returns true if the key's values have a trigram index.
+func (e *TagBlockKeyElem) HasTrigramIndex() bool { return (e.flag & TagKeyTrigramFlag) != 0 }
+
+// TrigramTagValueIterator returns an iterator over the key's values containing all of
+// trigrams.  It returns false if the values have no trigram index.
+func (e *TagBlockKeyElem) TrigramTagValueIterator(trigrams [][]byte) (TagValueIterator, bool) {
+	if !e.HasTrigramIndex() {
+		return nil, false
+	} else if len(trigrams) == 0 {
+		return e.TagValueIterator(), true
+	}
+
+	buf := e.trigramIndex.buf
+	n := int(binary.BigEndian.Uint64(buf[:TagTrigramNSize]))
+	entries := buf[TagTrigramNSize:]
+
+	// Intersect the postings of each trigram.
+	var offsets []uint64
+	for i, trigram := range trigrams {
+		j := sort.Search(n, func(j int) bool {
+			return bytes.Compare(entries[j*TagTrigramEntrySize:][:TagTrigramSize], trigram) >= 0
+		})
+		if j == n || !bytes.Equal(entries[j*TagTrigramEntrySize:][:TagTrigramSize], trigram) {
+			return &tagBlockOffsetValueIterator{}, true
+		}
+
+		pos := binary.BigEndian.Uint64(entries[j*TagTrigramEntrySize+TagTrigramSize:])
+		postings := decodeTrigramPostings(buf[pos:])
+		if i == 0 {
+			offsets = postings
+		} else {
+			offsets = intersectUint64Slices(offsets, postings)
+		}
+		if len(offsets) == 0 {
+			break
+		}
+	}
+	return &tagBlockOffsetValueIterator{data: e.data.buf, offsets: offsets}, true
+}
+
 // unmarshal unmarshals buf into e.
 // The data argument represents the entire block data.
 func (e *TagBlockKeyElem) unmarshal(buf, data []byte) {
@@ -287,6 +347,16 @@ func (e *TagBlockKeyElem) unmarshal(buf, data []byte) {
 	e.hashIndex.buf = data[e.hashIndex.offset:]
 	e.hashIndex.buf = e.hashIndex.buf[:e.hashIndex.size]
 
+	// Parse & slice trigram index data.
+	if e.HasTrigramIndex() {
+		e.trigramIndex.offset, buf = binary.BigEndian.Uint64(buf), buf[8:]
+		e.trigramIndex.size, buf = binary.BigEndian.Uint64(buf), buf[8:]
+		e.trigramIndex.buf = data[e.trigramIndex.offset:]
+		e.trigramIndex.buf = e.trigramIndex.buf[:e.trigramIndex.size]
+	} else {
+		e.trigramIndex.offset, e.trigramIndex.size, e.trigramIndex.buf = 0, 0, nil
+	}
+
 	// Parse key.
 	n, sz := binary.Uvarint(buf)
 	e.key, buf = buf[sz:sz+int(n)], buf[int(n)+sz:]
@@ -295,6 +365,25 @@ func (e *TagBlockKeyElem) unmarshal(buf, data []byte) {
 	e.size = start - len(buf)
 }
 
+// tagBlockOffsetValueIterator represents an iterator over the values of a tag key at
+// the given offsets within the key's value data.
+type tagBlockOffsetValueIterator struct {
+	data    []byte
+	offsets []uint64
+	e       TagBlockValueElem
+}
+
+// Next returns the next element in the iterator.
+func (itr *tagBlockOffsetValueIterator) Next() TagValueElem {
+	if len(itr.offsets) == 0 {
+		return nil
+	}
+
+	itr.e.unmarshal(itr.data[itr.offsets[0]:])
+	itr.offsets = itr.offsets[1:]
+	return &itr.e
+}
+
 // TagBlockValueElem represents a tag value element.
 type TagBlockValueElem struct {
 	flag   byte
@@ -428,7 +517,7 @@ func (t *TagBlockTrailer) WriteTo(w io.Writer) (n int64, err error) {
 	// Write total size & encoding version.
 	if err := writeUint64To(w, uint64(t.Size), &n); err != nil {
 		return n, err
-	} else if err := writeUint16To(w, IndexFileVersion, &n); err != nil {
+	} else if err := writeUint16To(w, uint16(t.Version), &n); err != nil {
 		return n, err
 	}
 
@@ -441,7 +530,7 @@ func ReadTagBlockTrailer(data []byte) (TagBlockTrailer, error) {
 
 	// Read version.
 	t.Version = int(binary.BigEndian.Uint16(data[len(data)-2:]))
-	if t.Version != TagBlockVersion {
+	if t.Version != TagBlockVersion && t.Version != TagBlockTrigramVersion {
 		return t, ErrUnsupportedTagBlockVersion
 	}
 
@@ -471,9 +560,16 @@ type TagBlockEncoder struct {
 	w   io.Writer
 	buf bytes.Buffer
 
+	// Trigrams enables writing an index of the trigrams of each key's values, which
+	// is used to find the values matching regular expressions without scanning them.
+	Trigrams bool
+
 	// Track value offsets.
 	offsets *rhh.HashMap
 
+	// Track value offsets by trigram, relative to the start of the key's values.
+	trigrams map[string][]uint64
+
 	// Track bytes written, sections.
 	n       int64
 	trailer TagBlockTrailer
@@ -553,6 +649,20 @@ func (enc *TagBlockEncoder) EncodeValue(value []byte, deleted bool, seriesIDs []
 	// Save offset to hash map.
 	enc.offsets.Put(value, enc.n)
 
+	// Save offset to trigram postings.
+	if enc.Trigrams {
+		if enc.trigrams == nil {
+			enc.trigrams = make(map[string][]uint64)
+		}
+		offset := uint64(enc.n - enc.keys[len(enc.keys)-1].data.offset)
+		for i := 0; i+TagTrigramSize <= len(value); i++ {
+			trigram := string(value[i : i+TagTrigramSize])
+			if a := enc.trigrams[trigram]; len(a) == 0 || a[len(a)-1] != offset {
+				enc.trigrams[trigram] = append(a, offset)
+			}
+		}
+	}
+
 	// Write flag.
 	if err := writeUint8To(enc.w, encodeTagValueFlag(deleted), &enc.n); err != nil {
 		return err
@@ -609,6 +719,14 @@ func (enc *TagBlockEncoder) Close() error {
 		return err
 	}
 
+	// Mark blocks with trigram indexes, which earlier versions can't read.
+	for i := range enc.keys {
+		if enc.keys[i].trigramIndex.size > 0 {
+			enc.trailer.Version = TagBlockTrigramVersion
+			break
+		}
+	}
+
 	// Save ending position of entire data block.
 	enc.trailer.ValueData.Size = enc.n - enc.trailer.ValueData.Offset
 
@@ -674,6 +792,56 @@ func (enc *TagBlockEncoder) flushValueHashIndex() error {
 	// Clear offsets.
 	enc.offsets = rhh.NewHashMap(rhh.Options{LoadFactor: LoadFactor})
 
+	return enc.flushValueTrigramIndex()
+}
+
+// flushValueTrigramIndex writes the trigram index at the end of a value set.  The index
+// holds the trigrams in sorted order, each with the offset of its postings, followed by
+// the postings.  Postings are the delta encoded offsets of the values holding a trigram.
+func (enc *TagBlockEncoder) flushValueTrigramIndex() error {
+	if len(enc.trigrams) == 0 {
+		return nil
+	}
+	key := &enc.keys[len(enc.keys)-1]
+
+	trigrams := make([]string, 0, len(enc.trigrams))
+	for trigram := range enc.trigrams {
+		trigrams = append(trigrams, trigram)
+	}
+	sort.Strings(trigrams)
+
+	// Build postings in buffer to determine their offsets.
+	enc.buf.Reset()
+	offsets := make([]uint64, len(trigrams))
+	pos := uint64(TagTrigramNSize + len(trigrams)*TagTrigramEntrySize)
+	for i, trigram := range trigrams {
+		offsets[i] = pos + uint64(enc.buf.Len())
+		enc.buf.Write(appendTrigramPostings(nil, enc.trigrams[trigram]))
+	}
+
+	// Encode trigram count & entries.
+	key.trigramIndex.offset = enc.n
+	if err := writeUint64To(enc.w, uint64(len(trigrams)), &enc.n); err != nil {
+		return err
+	}
+	for i, trigram := range trigrams {
+		if err := writeTo(enc.w, []byte(trigram), &enc.n); err != nil {
+			return err
+		} else if err := writeUint64To(enc.w, offsets[i], &enc.n); err != nil {
+			return err
+		}
+	}
+
+	// Encode postings.
+	nn, err := enc.buf.WriteTo(enc.w)
+	if enc.n += nn; err != nil {
+		return err
+	}
+	key.trigramIndex.size = enc.n - key.trigramIndex.offset
+
+	// Clear trigrams.
+	enc.trigrams = nil
+
 	return nil
 }
 
@@ -689,7 +857,7 @@ func (enc *TagBlockEncoder) encodeTagKeyBlock() error {
 		// Save current offset so we can use it in the hash index.
 		offsets.Put(entry.key, enc.n)
 
-		if err := writeUint8To(enc.w, encodeTagKeyFlag(entry.deleted), &enc.n); err != nil {
+		if err := writeUint8To(enc.w, encodeTagKeyFlag(entry.deleted, entry.trigramIndex.size > 0), &enc.n); err != nil {
 			return err
 		}
 
@@ -707,6 +875,15 @@ func (enc *TagBlockEncoder) encodeTagKeyBlock() error {
 			return err
 		}
 
+		// Write value trigram index offset & size, if one was written.
+		if entry.trigramIndex.size > 0 {
+			if err := writeUint64To(enc.w, uint64(entry.trigramIndex.offset), &enc.n); err != nil {
+				return err
+			} else if err := writeUint64To(enc.w, uint64(entry.trigramIndex.size), &enc.n); err != nil {
+				return err
+			}
+		}
+
 		// Write key length and data.
 		if err := writeUvarintTo(enc.w, uint64(len(entry.key)), &enc.n); err != nil {
 			return err
@@ -747,16 +924,70 @@ type tagKeyEncodeEntry struct {
 		offset int64
 		size   int64
 	}
+	trigramIndex struct {
+		offset int64
+		size   int64
+	}
 }
 
-func encodeTagKeyFlag(deleted bool) byte {
+func encodeTagKeyFlag(deleted, trigrams bool) byte {
 	var flag byte
 	if deleted {
 		flag |= TagKeyTombstoneFlag
 	}
+	if trigrams {
+		flag |= TagKeyTrigramFlag
+	}
 	return flag
 }
 
+// appendTrigramPostings appends the count and delta encoded offsets to dst.
+func appendTrigramPostings(dst []byte, offsets []uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	dst = append(dst, buf[:binary.PutUvarint(buf[:], uint64(len(offsets)))]...)
+
+	var prev uint64
+	for _, offset := range offsets {
+		dst = append(dst, buf[:binary.PutUvarint(buf[:], offset-prev)]...)
+		prev = offset
+	}
+	return dst
+}
+
+// decodeTrigramPostings returns the offsets of the postings at the start of buf.
+func decodeTrigramPostings(buf []byte) []uint64 {
+	n, sz := binary.Uvarint(buf)
+	buf = buf[sz:]
+
+	offsets := make([]uint64, n)
+	var prev uint64
+	for i := range offsets {
+		delta, sz := binary.Uvarint(buf)
+		buf = buf[sz:]
+
+		offsets[i] = prev + delta
+		prev = offsets[i]
+	}
+	return offsets
+}
+
+// intersectUint64Slices returns the values of the sorted slices a and b found in both.
+// The result reuses the memory of a.
+func intersectUint64Slices(a, b []uint64) []uint64 {
+	other := a[:0]
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		if a[i] < b[j] {
+			i++
+		} else if a[i] > b[j] {
+			j++
+		} else {
+			other = append(other, a[i])
+			i, j = i+1, j+1
+		}
+	}
+	return other
+}
+
 func encodeTagValueFlag(deleted bool) byte {
 	var flag byte
 	if deleted {
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"reflect"
+	"regexp"
 	"testing"
 
 	"github.com/influxdata/influxdb/tsdb/index/tsi1"
@@ -75,6 +76,96 @@ func TestTagBlockWriter(t *testing.T) {
 	}
 }
 
+// Ensure tag values can be found by the trigrams of a regular expression.
+func TestTagBlockWriter_Trigrams(t *testing.T) {
+	var buf bytes.Buffer
+	enc := tsi1.NewTagBlockEncoder(&buf)
+	enc.Trigrams = true
+
+	if err := enc.EncodeKey([]byte("host"), false); err != nil {
+		t.Fatal(err)
+	} else if err := enc.EncodeValue([]byte("db-1-prod"), false, []uint32{1}); err != nil {
+		t.Fatal(err)
+	} else if err := enc.EncodeValue([]byte("web-1-prod"), false, []uint32{2}); err != nil {
+		t.Fatal(err)
+	} else if err := enc.EncodeValue([]byte("web-2-dev"), false, []uint32{3}); err != nil {
+		t.Fatal(err)
+	} else if err := enc.EncodeValue([]byte("web-3-prod"), false, []uint32{4}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	} else if int(enc.N()) != buf.Len() {
+		t.Fatalf("bytes written mismatch: %d, expected %d", enc.N(), buf.Len())
+	}
+
+	var blk tsi1.TagBlock
+	if err := blk.UnmarshalBinary(buf.Bytes()); err != nil {
+		t.Fatal(err)
+	} else if v := blk.Version(); v != tsi1.TagBlockTrigramVersion {
+		t.Fatalf("unexpected version: %d", v)
+	}
+
+	e := blk.TagKeyElem([]byte("host")).(*tsi1.TagBlockKeyElem)
+	if !e.HasTrigramIndex() {
+		t.Fatal("expected trigram index")
+	}
+
+	for _, tt := range []struct {
+		re  string
+		exp []string
+	}{
+		{re: `web-.*-prod`, exp: []string{"web-1-prod", "web-3-prod"}},
+		{re: `^web`, exp: []string{"web-1-prod", "web-2-dev", "web-3-prod"}},
+		{re: `-dev$`, exp: []string{"web-2-dev"}},
+		{re: `staging`, exp: nil},
+		{re: `db|dev`, exp: []string{"db-1-prod", "web-1-prod", "web-2-dev", "web-3-prod"}},
+	} {
+		itr, ok := e.TrigramTagValueIterator(tsi1.RegexTrigrams(regexp.MustCompile(tt.re)))
+		if !ok {
+			t.Fatalf("%s: expected trigram iterator", tt.re)
+		}
+
+		var a []string
+		for ve := itr.Next(); ve != nil; ve = itr.Next() {
+			a = append(a, string(ve.Value()))
+		}
+		if !reflect.DeepEqual(a, tt.exp) {
+			t.Fatalf("%s: unexpected values: %v", tt.re, a)
+		}
+	}
+
+	// Series IDs are still read from the values found.
+	if e := blk.TagValueElem([]byte("host"), []byte("web-2-dev")); e == nil {
+		t.Fatal("expected element")
+	} else if a := e.(*tsi1.TagBlockValueElem).SeriesIDs(); !reflect.DeepEqual(a, []uint32{3}) {
+		t.Fatalf("unexpected series ids: %#v", a)
+	}
+}
+
+// Ensure tag blocks without trigrams keep the original version.
+func TestTagBlockWriter_NoTrigrams(t *testing.T) {
+	var buf bytes.Buffer
+	enc := tsi1.NewTagBlockEncoder(&buf)
+	if err := enc.EncodeKey([]byte("host"), false); err != nil {
+		t.Fatal(err)
+	} else if err := enc.EncodeValue([]byte("server0"), false, []uint32{1}); err != nil {
+		t.Fatal(err)
+	} else if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var blk tsi1.TagBlock
+	if err := blk.UnmarshalBinary(buf.Bytes()); err != nil {
+		t.Fatal(err)
+	} else if v := blk.Version(); v != tsi1.TagBlockVersion {
+		t.Fatalf("unexpected version: %d", v)
+	} else if _, ok := blk.TagKeyElem([]byte("host")).(*tsi1.TagBlockKeyElem).TrigramTagValueIterator(nil); ok {
+		t.Fatal("expected no trigram index")
+	}
+}
+
 var benchmarkTagBlock10x1000 *tsi1.TagBlock
 var benchmarkTagBlock100x1000 *tsi1.TagBlock
 var benchmarkTagBlock1000x1000 *tsi1.TagBlock
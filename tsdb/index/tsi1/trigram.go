@@ -0,0 +1,50 @@
+package tsi1
+
+import (
+	"regexp"
+	"regexp/syntax"
+)
+
+// RegexTrigrams returns the trigrams every value matching re must contain.  It returns
+// nil if re has no literal of at least three bytes that every match must contain, such
+// as when the literals are alternatives or matched case-insensitively.
+func RegexTrigrams(re *regexp.Regexp) [][]byte {
+	expr, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return nil
+	}
+
+	var trigrams [][]byte
+	set := make(map[string]struct{})
+	for _, lit := range requiredLiterals(expr.Simplify(), nil) {
+		for i := 0; i+TagTrigramSize <= len(lit); i++ {
+			trigram := lit[i : i+TagTrigramSize]
+			if _, ok := set[string(trigram)]; !ok {
+				set[string(trigram)] = struct{}{}
+				trigrams = append(trigrams, trigram)
+			}
+		}
+	}
+	return trigrams
+}
+
+// requiredLiterals appends the case-sensitive literals every match of re contains to lits.
+func requiredLiterals(re *syntax.Regexp, lits [][]byte) [][]byte {
+	switch re.Op {
+	case syntax.OpLiteral:
+		if re.Flags&syntax.FoldCase == 0 {
+			lits = append(lits, []byte(string(re.Rune)))
+		}
+	case syntax.OpCapture, syntax.OpPlus:
+		lits = requiredLiterals(re.Sub[0], lits)
+	case syntax.OpRepeat:
+		if re.Min > 0 {
+			lits = requiredLiterals(re.Sub[0], lits)
+		}
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			lits = requiredLiterals(sub, lits)
+		}
+	}
+	return lits
+}
@@ -0,0 +1,318 @@
+package tsdb
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/influxdb/models"
+	"github.com/influxdata/influxdb/pkg/limiter"
+	"go.uber.org/zap"
+)
+
+// Statistics gathered by index conversions.
+const (
+	statIndexConversionShardsConverted = "shardsConverted" // number of shards converted to tsi1
+	statIndexConversionShardsFailed    = "shardsFailed"    // number of shards that failed to convert
+	statIndexConversionShardsRemaining = "shardsRemaining" // number of shards waiting to be converted
+	statIndexConversionSeriesIndexed   = "seriesIndexed"   // number of series added to converted indexes
+)
+
+// Index conversion errors.
+var (
+	// ErrIndexConversionRunning is returned when converting the index of a shard that is
+	// already being converted.
+	ErrIndexConversionRunning = errors.New("index conversion already running")
+
+	// errIndexConversionDeleted is returned when series are deleted from a shard while
+	// its index is converted, since the new index may still hold them.
+	errIndexConversionDeleted = errors.New("series deleted during index conversion")
+)
+
+// indexConversionAttempts is the number of times the index of a shard is built before
+// the conversion fails because of series deleted while it's built.
+const indexConversionAttempts = 3
+
+// indexConversionBatchSize is the number of series added to a new index at a time.
+const indexConversionBatchSize = 1000
+
+// IndexConversionStatistics holds the progress of index conversions.
+type IndexConversionStatistics struct {
+	ShardsConverted int64
+	ShardsFailed    int64
+	ShardsRemaining int64
+	SeriesIndexed   int64
+}
+
+// indexConversion is the tsi1 index being built for a shard.  Series written to the shard
+// while the index is built are added to it as well.
+type indexConversion struct {
+	index   Index
+	deletes int64 // number of deletes from the shard in progress or started since it began
+
+	mu  sync.Mutex
+	err error // first error adding written series
+}
+
+// createSeriesList adds series written to the shard to the new index.  The index filters
+// the slices it's passed, so it's passed copies.
+func (c *indexConversion) createSeriesList(keys, names [][]byte, tagsSlice []models.Tags) {
+	err := c.index.CreateSeriesListIfNotExists(
+		append([][]byte(nil), keys...),
+		append([][]byte(nil), names...),
+		append([]models.Tags(nil), tagsSlice...),
+	)
+	if err == nil {
+		return
+	}
+
+	c.mu.Lock()
+	if c.err == nil {
+		c.err = err
+	}
+	c.mu.Unlock()
+}
+
+// startDelete records a delete from the shard with any running index conversion.
+// endDelete must be called once the delete completes.
+func (s *Shard) startDelete() {
+	s.mu.RLock()
+	atomic.AddInt64(&s.deleting, 1)
+	if s.conversion != nil {
+		atomic.AddInt64(&s.conversion.deletes, 1)
+	}
+	s.mu.RUnlock()
+}
+
+// endDelete records that a delete from the shard completed.
+func (s *Shard) endDelete() {
+	atomic.AddInt64(&s.deleting, -1)
+}
+
+// ConvertIndex converts the "inmem" index of the shard to "tsi1" while the shard remains
+// in use.  The new index is built in a separate directory from the series of the shard's
+// data, and replaces the old index once complete.  Building the index is restarted if
+// series are deleted meanwhile.  The shard keeps its old index if the conversion fails.
+// Series are indexed at the rate allowed by lim, and added to indexed as they are.
+func (s *Shard) ConvertIndex(lim *limiter.Rate, indexed *int64) error {
+	for attempt := 1; ; attempt++ {
+		err := s.convertIndex(lim, indexed)
+		if err != errIndexConversionDeleted || attempt == indexConversionAttempts {
+			return err
+		}
+		s.logger.Info("Restarting index conversion after series were deleted", zap.Uint64("shard", s.id))
+	}
+}
+
+func (s *Shard) convertIndex(lim *limiter.Rate, indexed *int64) error {
+	path := filepath.Join(s.path, "index")
+	tmpPath := path + ".converting"
+
+	// Start the conversion.
+	s.mu.Lock()
+	if err := s.ready(); err != nil {
+		s.mu.Unlock()
+		return err
+	} else if s.index.Type() != "inmem" {
+		s.mu.Unlock()
+		return nil
+	} else if s.conversion != nil {
+		s.mu.Unlock()
+		return ErrIndexConversionRunning
+	}
+	engine := s._engine
+
+	opt := s.options
+	opt.IndexVersion, opt.InmemIndex = "tsi1", nil
+
+	// Remove the files of an interrupted conversion.
+	if err := os.RemoveAll(tmpPath); err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	idx, err := NewIndex(s.id, s.database, tmpPath, opt)
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	idx.WithLogger(s.baseLogger)
+	if err := idx.Open(); err != nil {
+		s.mu.Unlock()
+		os.RemoveAll(tmpPath)
+		return err
+	}
+	// Deletes still in progress may remove series after they are indexed, so they
+	// count as deletes during the conversion.
+	c := &indexConversion{index: idx, deletes: atomic.LoadInt64(&s.deleting)}
+	s.conversion = c
+	s.mu.Unlock()
+
+	start := time.Now()
+	s.logger.Info("Converting shard index", zap.Uint64("shard", s.id), zap.String("path", tmpPath))
+
+	n, err := s.indexSeries(engine, idx, lim, indexed)
+
+	// Swap in the new index, if it's complete.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.conversion = nil
+
+	if err == nil {
+		c.mu.Lock()
+		err = c.err
+		c.mu.Unlock()
+	}
+	if err == nil && atomic.LoadInt64(&c.deletes) > 0 {
+		err = errIndexConversionDeleted
+	}
+	if err == nil && s._engine != engine {
+		err = ErrEngineClosed
+	}
+	if err != nil {
+		idx.Close()
+		os.RemoveAll(tmpPath)
+		return err
+	}
+
+	// Move the index to the shard's index directory, so the shard is opened with it.
+	if err := idx.Close(); err != nil {
+		os.RemoveAll(tmpPath)
+		return err
+	} else if err := os.Rename(tmpPath, path); err != nil {
+		os.RemoveAll(tmpPath)
+		return err
+	}
+
+	idx, err = NewIndex(s.id, s.database, path, opt)
+	if err != nil {
+		os.RemoveAll(path)
+		return err
+	}
+	idx.WithLogger(s.baseLogger)
+	if err := idx.Open(); err != nil {
+		os.RemoveAll(path)
+		return err
+	}
+
+	engine.SetIndex(idx)
+	s.index.RemoveShard(s.id)
+	s.index = idx
+	s.options = opt
+
+	s.logger.Info("Converted shard index",
+		zap.Uint64("shard", s.id),
+		zap.Int("series", n),
+		zap.String("elapsed", time.Since(start).String()),
+	)
+	return nil
+}
+
+// indexSeries adds the series of the engine's data to idx in batches, returning the
+// number of series added.
+func (s *Shard) indexSeries(engine Engine, idx Index, lim *limiter.Rate, indexed *int64) (int, error) {
+	var n int
+	keys := make([][]byte, 0, indexConversionBatchSize)
+	names := make([][]byte, 0, indexConversionBatchSize)
+	tagsSlice := make([]models.Tags, 0, indexConversionBatchSize)
+	flush := func() error {
+		select {
+		case <-s.closing:
+			return ErrEngineClosed
+		default:
+		}
+
+		lim.WaitN(len(keys))
+		if err := idx.CreateSeriesListIfNotExists(keys, names, tagsSlice); err != nil {
+			return err
+		}
+		n += len(keys)
+		atomic.AddInt64(indexed, int64(len(keys)))
+		keys, names, tagsSlice = keys[:0], names[:0], tagsSlice[:0]
+		return nil
+	}
+
+	if err := engine.ForEachSeriesKey(func(key []byte) error {
+		// Keys may be reused by the engine, so the batch holds copies.
+		key = append([]byte(nil), key...)
+		name, tags := models.ParseKeyBytes(key)
+		keys, names, tagsSlice = append(keys, key), append(names, name), append(tagsSlice, tags)
+		if len(keys) < indexConversionBatchSize {
+			return nil
+		}
+		return flush()
+	}); err != nil {
+		return 0, err
+	}
+	if len(keys) > 0 {
+		if err := flush(); err != nil {
+			return 0, err
+		}
+	}
+	return n, nil
+}
+
+// ConvertShardIndex converts the "inmem" index of a shard to "tsi1" while the shard
+// remains in use.  The index version of the store must be "tsi1", so the shard is opened
+// with its new index.
+func (s *Store) ConvertShardIndex(id uint64) error {
+	if s.EngineOptions.IndexVersion != "tsi1" {
+		return fmt.Errorf("cannot convert shard index: index version is %s", s.EngineOptions.IndexVersion)
+	}
+
+	sh := s.Shard(id)
+	if sh == nil {
+		return ErrShardNotFound
+	}
+	return s.convertShardIndex(sh)
+}
+
+func (s *Store) convertShardIndex(sh *Shard) error {
+	if err := sh.ConvertIndex(s.indexConversionLimiter, &s.indexConversionStats.SeriesIndexed); err != nil {
+		atomic.AddInt64(&s.indexConversionStats.ShardsFailed, 1)
+		return err
+	}
+	atomic.AddInt64(&s.indexConversionStats.ShardsConverted, 1)
+	return nil
+}
+
+// convertShardIndexes converts the "inmem" index of each shard to "tsi1", one shard at a
+// time, until the store is closed.
+func (s *Store) convertShardIndexes() {
+	defer s.wg.Done()
+
+	s.mu.RLock()
+	shards := s.filterShards(func(sh *Shard) bool {
+		return sh.IndexType() == "inmem"
+	})
+	s.mu.RUnlock()
+	sort.Slice(shards, func(i, j int) bool { return shards[i].id < shards[j].id })
+
+	atomic.StoreInt64(&s.indexConversionStats.ShardsRemaining, int64(len(shards)))
+	for _, sh := range shards {
+		select {
+		case <-s.closing:
+			return
+		default:
+		}
+
+		if err := s.convertShardIndex(sh); err != nil {
+			s.Logger.Error("Cannot convert shard index", zap.Uint64("shard", sh.id), zap.Error(err))
+		}
+		atomic.AddInt64(&s.indexConversionStats.ShardsRemaining, -1)
+	}
+}
+
+// IndexConversionStatistics returns the progress of index conversions.
+func (s *Store) IndexConversionStatistics() IndexConversionStatistics {
+	return IndexConversionStatistics{
+		ShardsConverted: atomic.LoadInt64(&s.indexConversionStats.ShardsConverted),
+		ShardsFailed:    atomic.LoadInt64(&s.indexConversionStats.ShardsFailed),
+		ShardsRemaining: atomic.LoadInt64(&s.indexConversionStats.ShardsRemaining),
+		SeriesIndexed:   atomic.LoadInt64(&s.indexConversionStats.SeriesIndexed),
+	}
+}
@@ -0,0 +1,44 @@
+package tsdb
+
+import "context"
+
+type contextKey int
+
+const readSnapshotsKey contextKey = iota
+
+// ReadSnapshot is a consistent, read-only view of the data of a shard at the time it was
+// taken.  Iterators and cursors created with a context holding the snapshot read from it
+// instead of the shard's current data.
+type ReadSnapshot interface {
+	// NewContext returns a new context holding the snapshot.
+	NewContext(ctx context.Context) context.Context
+
+	// Release releases the files and cache values held by the snapshot.
+	Release()
+}
+
+// ReadSnapshots holds a read snapshot of each of several shards by shard id.
+type ReadSnapshots map[uint64]ReadSnapshot
+
+// Release releases each of the snapshots.
+func (a ReadSnapshots) Release() {
+	for _, s := range a {
+		s.Release()
+	}
+}
+
+// NewContextWithReadSnapshots returns a new context holding snapshots, so that the
+// shards they were taken of read from them.
+func NewContextWithReadSnapshots(ctx context.Context, snapshots ReadSnapshots) context.Context {
+	return context.WithValue(ctx, readSnapshotsKey, snapshots)
+}
+
+// readSnapshotContext returns ctx holding the read snapshot of the shard with id, if
+// ctx holds one.
+func readSnapshotContext(ctx context.Context, id uint64) context.Context {
+	snapshots, _ := ctx.Value(readSnapshotsKey).(ReadSnapshots)
+	if s := snapshots[id]; s != nil {
+		return s.NewContext(ctx)
+	}
+	return ctx
+}
@@ -0,0 +1,54 @@
+package tsdb
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RollupFunctions is the list of aggregate functions that can be used by a RollupRule.
+var RollupFunctions = []string{"count", "first", "last", "max", "mean", "min", "sum"}
+
+// RollupRule describes how the raw values of a shard are downsampled once all of its
+// data is older than After.  Every function in Functions is applied to the values of
+// each field over windows of Interval and written to a new field named
+// <function>_<field>, the same naming used by continuous queries selecting a
+// wildcard.  The raw values are removed by the rollup.
+type RollupRule struct {
+	After     time.Duration `json:"after"`
+	Interval  time.Duration `json:"interval"`
+	Functions []string      `json:"functions"`
+}
+
+// Validate returns an error if the rule is invalid.
+func (r *RollupRule) Validate() error {
+	if r.Interval <= 0 {
+		return errors.New("rollup interval must be greater than 0")
+	} else if r.After < 0 {
+		return errors.New("rollup age must not be negative")
+	} else if len(r.Functions) == 0 {
+		return errors.New("rollup requires at least one function")
+	}
+
+	seen := make(map[string]struct{}, len(r.Functions))
+	for _, fn := range r.Functions {
+		if !isRollupFunction(fn) {
+			return fmt.Errorf("unknown rollup function: %s", fn)
+		}
+		if _, ok := seen[fn]; ok {
+			return fmt.Errorf("duplicate rollup function: %s", fn)
+		}
+		seen[fn] = struct{}{}
+	}
+	return nil
+}
+
+// isRollupFunction returns true if fn is a supported rollup function.
+func isRollupFunction(fn string) bool {
+	for _, name := range RollupFunctions {
+		if fn == name {
+			return true
+		}
+	}
+	return false
+}
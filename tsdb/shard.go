@@ -63,6 +63,10 @@ var (
 	// ErrShardDisabled is returned when a the shard is not available for
 	// queries or writes.
 	ErrShardDisabled = errors.New("shard is disabled")
+
+	// ErrShardReadOnly is returned when writing to or deleting from a shard that
+	// has been sealed.
+	ErrShardReadOnly = errors.New("shard is read-only")
 )
 
 var (
@@ -109,6 +113,10 @@ func (e PartialWriteError) Error() string {
 // Data can be split across many shards. The query engine in TSDB is responsible
 // for combining the output of many shards into a single query result.
 type Shard struct {
+	// deleting is the number of deletes from the shard in progress.  It's accessed
+	// atomically, so it's kept first for 64-bit alignment on 32-bit systems.
+	deleting int64
+
 	path    string
 	walPath string
 	id      uint64
@@ -122,6 +130,9 @@ type Shard struct {
 	_engine Engine
 	index   Index
 
+	// conversion is the conversion of the shard's index to tsi1, if one is running.
+	conversion *indexConversion
+
 	closing chan struct{}
 	enabled bool
 
@@ -200,6 +211,50 @@ func (s *Shard) ScheduleFullCompaction() error {
 	return engine.ScheduleFullCompaction()
 }
 
+// SetRollupRule sets the rule used to downsample the shard's raw values.  The rule
+// is ignored if the shard has already been rolled up.
+func (s *Shard) SetRollupRule(rule *RollupRule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	engine, err := s.engine()
+	if err != nil {
+		return err
+	}
+	return engine.SetRollupRule(rule)
+}
+
+// SetReadOnly seals or unseals the shard.  A sealed shard rejects writes and deletes
+// with ErrShardReadOnly, has no WAL or cache, and keeps its TSM files unchanged
+// along with a manifest of their checksums.
+func (s *Shard) SetReadOnly(readOnly bool) error {
+	engine, err := s.engine()
+	if err != nil {
+		return err
+	}
+	return engine.SetReadOnly(readOnly)
+}
+
+// ReadOnly returns true if the shard is sealed.
+func (s *Shard) ReadOnly() bool {
+	engine, err := s.engine()
+	if err != nil {
+		return false
+	}
+	return engine.ReadOnly()
+}
+
+// Rollup returns the rollup rule that has been applied to the shard, or nil if the
+// shard contains raw values.
+func (s *Shard) Rollup() *RollupRule {
+	engine, err := s.engine()
+	if err != nil {
+		return nil
+	}
+	return engine.Rollup()
+}
+
 // ID returns the shards ID.
 func (s *Shard) ID() uint64 {
 	return s.id
@@ -489,6 +544,12 @@ func (s *Shard) WritePoints(points []models.Point) error {
 	var writeError error
 	atomic.AddInt64(&s.stats.WriteReq, 1)
 
+	// Reject the write before any series or fields are created.
+	if engine.ReadOnly() {
+		atomic.AddInt64(&s.stats.WriteReqErr, 1)
+		return ErrShardReadOnly
+	}
+
 	points, fieldsToCreate, err := s.validateSeriesAndFields(points)
 	if err != nil {
 		if _, ok := err.(PartialWriteError); !ok {
@@ -555,6 +616,11 @@ func (s *Shard) validateSeriesAndFields(points []models.Point) ([]models.Point,
 		return nil, nil, err
 	}
 
+	// Add new series to the index being built by a conversion too.
+	if s.conversion != nil {
+		s.conversion.createSeriesList(keys, names, tagsSlice)
+	}
+
 	// Add new series. Check for partial writes.
 	var droppedKeys map[string]struct{}
 	if err := engine.CreateSeriesListIfNotExists(keys, names, tagsSlice); err != nil {
@@ -696,6 +762,9 @@ func (s *Shard) createFieldsAndMeasurements(fieldsToCreate []*FieldCreate) error
 
 // DeleteSeriesRange deletes all values from for seriesKeys between min and max (inclusive)
 func (s *Shard) DeleteSeriesRange(itr SeriesIterator, min, max int64) error {
+	s.startDelete()
+	defer s.endDelete()
+
 	engine, err := s.engine()
 	if err != nil {
 		return err
@@ -705,6 +774,9 @@ func (s *Shard) DeleteSeriesRange(itr SeriesIterator, min, max int64) error {
 
 // DeleteMeasurement deletes a measurement and all underlying series.
 func (s *Shard) DeleteMeasurement(name []byte) error {
+	s.startDelete()
+	defer s.endDelete()
+
 	engine, err := s.engine()
 	if err != nil {
 		return err
@@ -795,6 +867,69 @@ func (s *Shard) MeasurementTagKeyValuesByExpr(auth query.Authorizer, name []byte
 	return engine.MeasurementTagKeyValuesByExpr(auth, name, key, expr, keysSorted)
 }
 
+// MeasurementTagKeyValuesInRange returns the values of keys of the series matching expr
+// that have data between min and max, inclusive.  Each slice of values is sorted.
+func (s *Shard) MeasurementTagKeyValuesInRange(auth query.Authorizer, name []byte, keys []string, expr influxql.Expr, min, max int64) ([][]string, error) {
+	engine, err := s.engine()
+	if err != nil {
+		return nil, err
+	}
+
+	seriesKeys, err := engine.MeasurementSeriesKeysByExpr(name, expr)
+	if err != nil {
+		return nil, err
+	}
+
+	sets := make([]map[string]struct{}, len(keys))
+	for i := range sets {
+		sets[i] = make(map[string]struct{})
+	}
+	for _, key := range seriesKeys {
+		if !engine.SeriesHasData(key, min, max) {
+			continue
+		}
+
+		_, tags := models.ParseKeyBytes(key)
+		if auth != nil && !auth.AuthorizeSeriesRead(s.database, name, tags) {
+			continue
+		}
+		for i, k := range keys {
+			if v := tags.GetString(k); v != "" {
+				sets[i][v] = struct{}{}
+			}
+		}
+	}
+
+	values := make([][]string, len(keys))
+	for i, set := range sets {
+		for v := range set {
+			values[i] = append(values[i], v)
+		}
+		sort.Strings(values[i])
+	}
+	return values, nil
+}
+
+// MeasurementHasDataInRange returns true if any series of the measurement matching expr
+// has data between min and max, inclusive.
+func (s *Shard) MeasurementHasDataInRange(name []byte, expr influxql.Expr, min, max int64) (bool, error) {
+	engine, err := s.engine()
+	if err != nil {
+		return false, err
+	}
+
+	seriesKeys, err := engine.MeasurementSeriesKeysByExpr(name, expr)
+	if err != nil {
+		return false, err
+	}
+	for _, key := range seriesKeys {
+		if engine.SeriesHasData(key, min, max) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // MeasurementFields returns fields for a measurement.
 // TODO(edd): This method is currently only being called from tests; do we
 // really need it?
@@ -817,7 +952,8 @@ func (s *Shard) MeasurementExists(name []byte) (bool, error) {
 	return engine.MeasurementExists(name)
 }
 
-// WriteTo writes the shard's data to w.
+// WriteTo streams an image of the shard's data to w without pausing writes.  The
+// image can be loaded into another shard with Import.
 func (s *Shard) WriteTo(w io.Writer) (int64, error) {
 	engine, err := s.engine()
 	if err != nil {
@@ -843,7 +979,7 @@ func (s *Shard) CreateIterator(ctx context.Context, m *influxql.Measurement, opt
 	case "_tagKeys":
 		return NewTagKeysIterator(engine, opt)
 	}
-	return engine.CreateIterator(ctx, m.Name, opt)
+	return engine.CreateIterator(readSnapshotContext(ctx, s.id), m.Name, opt)
 }
 
 func (s *Shard) CreateCursor(ctx context.Context, r *CursorRequest) (Cursor, error) {
@@ -851,7 +987,16 @@ func (s *Shard) CreateCursor(ctx context.Context, r *CursorRequest) (Cursor, err
 	if err != nil {
 		return nil, err
 	}
-	return engine.CreateCursor(ctx, r)
+	return engine.CreateCursor(readSnapshotContext(ctx, s.id), r)
+}
+
+// ReadSnapshot returns a snapshot of the shard's data, which must be released when done.
+func (s *Shard) ReadSnapshot() (ReadSnapshot, error) {
+	engine, err := s.engine()
+	if err != nil {
+		return nil, err
+	}
+	return engine.ReadSnapshot()
 }
 
 // createSeriesIterator returns a new instance of SeriesIterator.
@@ -1105,6 +1250,16 @@ func (s *Shard) Import(r io.Reader, basePath string) error {
 	return s._engine.Import(r, basePath)
 }
 
+// IngestTSMFiles attaches prebuilt TSM files to the shard.  Field types in the files
+// are validated against the shard's existing fields before any file is attached.
+func (s *Shard) IngestTSMFiles(paths []string) error {
+	engine, err := s.engine()
+	if err != nil {
+		return err
+	}
+	return engine.IngestTSMFiles(paths)
+}
+
 // CreateSnapshot will return a path to a temp directory
 // containing hard links to the underlying shard files.
 func (s *Shard) CreateSnapshot() (string, error) {
@@ -1115,6 +1270,15 @@ func (s *Shard) CreateSnapshot() (string, error) {
 	return engine.CreateSnapshot()
 }
 
+// RemoveSnapshot removes a snapshot directory created by CreateSnapshot.
+func (s *Shard) RemoveSnapshot(path string) error {
+	engine, err := s.engine()
+	if err != nil {
+		return err
+	}
+	return engine.RemoveSnapshot(path)
+}
+
 // ForEachMeasurementName iterates over each measurement in the shard.
 func (s *Shard) ForEachMeasurementName(fn func(name []byte) error) error {
 	engine, err := s.engine()
@@ -1279,6 +1443,21 @@ func (a Shards) CreateIterator(ctx context.Context, measurement *influxql.Measur
 	return query.Iterators(itrs).Merge(opt)
 }
 
+// ReadSnapshots returns a snapshot of the data of each shard, which must be released
+// when done.
+func (a Shards) ReadSnapshots() (ReadSnapshots, error) {
+	snapshots := make(ReadSnapshots, len(a))
+	for _, sh := range a {
+		snap, err := sh.ReadSnapshot()
+		if err != nil {
+			snapshots.Release()
+			return nil, err
+		}
+		snapshots[sh.id] = snap
+	}
+	return snapshots, nil
+}
+
 func (a Shards) IteratorCost(measurement string, opt query.IteratorOptions) (query.IteratorCost, error) {
 	var costs query.IteratorCost
 	var costerr error
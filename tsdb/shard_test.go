@@ -379,7 +379,7 @@ func TestShard_WritePoints_FieldConflictConcurrent(t *testing.T) {
 
 			_ = sh.WritePoints(points[:500])
 			if f, err := sh.CreateSnapshot(); err == nil {
-				os.RemoveAll(f)
+				sh.RemoveSnapshot(f)
 			}
 
 		}
@@ -395,7 +395,7 @@ func TestShard_WritePoints_FieldConflictConcurrent(t *testing.T) {
 
 			_ = sh.WritePoints(points[500:])
 			if f, err := sh.CreateSnapshot(); err == nil {
-				os.RemoveAll(f)
+				sh.RemoveSnapshot(f)
 			}
 		}
 	}()
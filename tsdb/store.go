@@ -51,8 +51,15 @@ type Store struct {
 	// shards is a map of shard IDs to the associated Shard.
 	shards map[uint64]*Shard
 
+	// onDeleteSeries is called before series are deleted from shards.
+	onDeleteSeries func(database string, shardIDs []uint64, sources []influxql.Source, condition influxql.Expr) error
+
 	EngineOptions EngineOptions
 
+	// limits the rate at which series are added to converted indexes.
+	indexConversionLimiter *limiter.Rate
+	indexConversionStats   IndexConversionStatistics
+
 	baseLogger *zap.Logger
 	Logger     *zap.Logger
 
@@ -116,6 +123,24 @@ func (s *Store) Statistics(tags map[string]string) []models.Statistic {
 		})
 	}
 
+	if s.EngineOptions.Config.IndexConversionEnabled {
+		stats := s.IndexConversionStatistics()
+		statistics = append(statistics, models.Statistic{
+			Name: "indexConversion",
+			Tags: tags,
+			Values: map[string]interface{}{
+				statIndexConversionShardsConverted: stats.ShardsConverted,
+				statIndexConversionShardsFailed:    stats.ShardsFailed,
+				statIndexConversionShardsRemaining: stats.ShardsRemaining,
+				statIndexConversionSeriesIndexed:   stats.SeriesIndexed,
+			},
+		})
+	}
+
+	if s.EngineOptions.BlockCache != nil {
+		statistics = append(statistics, s.EngineOptions.BlockCache.Statistics(tags)...)
+	}
+
 	// Gather all statistics for all shards.
 	for _, shard := range shards {
 		statistics = append(statistics, shard.Statistics(tags)...)
@@ -126,6 +151,15 @@ func (s *Store) Statistics(tags map[string]string) []models.Statistic {
 // Path returns the store's root path.
 func (s *Store) Path() string { return s.path }
 
+// coldPath returns the path of elem within the cold storage tier.  It returns
+// an empty string if no cold tier is configured.
+func (s *Store) coldPath(elem ...string) string {
+	if s.EngineOptions.Config.ColdDir == "" {
+		return ""
+	}
+	return filepath.Join(append([]string{s.EngineOptions.Config.ColdDir}, elem...)...)
+}
+
 // Open initializes the store, creating all necessary directories, loading all
 // shards as well as initializing periodic maintenance of them.
 func (s *Store) Open() error {
@@ -151,10 +185,21 @@ func (s *Store) Open() error {
 		return err
 	}
 
+	// Setup the limiter for the rate series are added to converted indexes.
+	if rate := s.EngineOptions.Config.IndexConversionThroughput; rate > 0 {
+		s.indexConversionLimiter = limiter.NewRate(rate, rate)
+	}
+
 	s.opened = true
 	s.wg.Add(1)
 	go s.monitorShards()
 
+	// Convert the indexes of existing "inmem" shards in the background.
+	if s.EngineOptions.Config.IndexConversionEnabled && s.EngineOptions.IndexVersion == "tsi1" {
+		s.wg.Add(1)
+		go s.convertShardIndexes()
+	}
+
 	return nil
 }
 
@@ -181,6 +226,17 @@ func (s *Store) loadShards() error {
 
 	s.EngineOptions.CompactionLimiter = limiter.NewFixed(lim)
 
+	// Setup a shared limiter for the rate compactions write at.  Bursts of up to
+	// a second allow blocks to be written without waiting on every call.
+	if rate := int(s.EngineOptions.Config.CompactThroughput); rate > 0 {
+		s.EngineOptions.CompactionThroughputLimiter = limiter.NewRate(rate, rate)
+	}
+
+	// Setup the block cache shared by all shards.
+	if size := int(s.EngineOptions.Config.BlockCacheMaxMemorySize); size > 0 && NewBlockCache != nil {
+		s.EngineOptions.BlockCache = NewBlockCache(size)
+	}
+
 	t := limiter.NewFixed(runtime.GOMAXPROCS(0))
 	resC := make(chan *res)
 	var n int
@@ -240,6 +296,7 @@ func (s *Store) loadShards() error {
 					// Copy options and assign shared index.
 					opt := s.EngineOptions
 					opt.InmemIndex = idx
+					opt.ColdPath = s.coldPath(db, rp, sh)
 
 					// Existing shards should continue to use inmem index.
 					if _, err := os.Stat(filepath.Join(path, "index")); os.IsNotExist(err) {
@@ -346,6 +403,16 @@ func (s *Store) Shard(id uint64) *Shard {
 	return sh
 }
 
+// ShardLastModified returns the time the shard was last written to, or the zero time if
+// the shard doesn't exist.
+func (s *Store) ShardLastModified(id uint64) time.Time {
+	sh := s.Shard(id)
+	if sh == nil {
+		return time.Time{}
+	}
+	return sh.LastModified()
+}
+
 // Shards returns a list of shards by id.
 func (s *Store) Shards(ids []uint64) []*Shard {
 	s.mu.RLock()
@@ -409,6 +476,7 @@ func (s *Store) CreateShard(database, retentionPolicy string, shardID uint64, en
 	// Copy index options and pass in shared index.
 	opt := s.EngineOptions
 	opt.InmemIndex = idx
+	opt.ColdPath = s.coldPath(database, retentionPolicy, strconv.FormatUint(shardID, 10))
 
 	path := filepath.Join(s.path, database, retentionPolicy, strconv.FormatUint(shardID, 10))
 	shard := NewShard(shardID, path, walPath, opt)
@@ -426,7 +494,7 @@ func (s *Store) CreateShard(database, retentionPolicy string, shardID uint64, en
 }
 
 // CreateShardSnapShot will create a hard link to the underlying shard and return a path.
-// The caller is responsible for cleaning up (removing) the file path returned.
+// The caller is responsible for cleaning up the path returned with RemoveShardSnapshot.
 func (s *Store) CreateShardSnapshot(id uint64) (string, error) {
 	sh := s.Shard(id)
 	if sh == nil {
@@ -436,6 +504,18 @@ func (s *Store) CreateShardSnapshot(id uint64) (string, error) {
 	return sh.CreateSnapshot()
 }
 
+// RemoveShardSnapshot removes a snapshot of a shard created by CreateShardSnapshot.
+// Files of the shard in the cold tier are linked from a separate directory, so the
+// snapshot must not be removed by simply removing its path.
+func (s *Store) RemoveShardSnapshot(id uint64, path string) error {
+	sh := s.Shard(id)
+	if sh == nil {
+		// The files of a deleted shard, and so its snapshots, have been removed.
+		return os.RemoveAll(path)
+	}
+	return sh.RemoveSnapshot(path)
+}
+
 // SetShardEnabled enables or disables a shard for read and writes.
 func (s *Store) SetShardEnabled(shardID uint64, enabled bool) error {
 	sh := s.Shard(shardID)
@@ -446,6 +526,36 @@ func (s *Store) SetShardEnabled(shardID uint64, enabled bool) error {
 	return nil
 }
 
+// SetShardReadOnly seals or unseals a shard.  Sealed shards reject writes and
+// deletes, and their TSM files are immutable.
+func (s *Store) SetShardReadOnly(shardID uint64, readOnly bool) error {
+	sh := s.Shard(shardID)
+	if sh == nil {
+		return ErrShardNotFound
+	}
+	return sh.SetReadOnly(readOnly)
+}
+
+// IngestShardFiles atomically attaches prebuilt TSM files to a shard and adds their
+// series to the index.  The files are copied into the shard, so the originals can be
+// removed afterwards.
+func (s *Store) IngestShardFiles(shardID uint64, paths []string) error {
+	sh := s.Shard(shardID)
+	if sh == nil {
+		return ErrShardNotFound
+	}
+	return sh.IngestTSMFiles(paths)
+}
+
+// SetShardRollupRule sets the rollup rule of a shard.
+func (s *Store) SetShardRollupRule(shardID uint64, rule *RollupRule) error {
+	sh := s.Shard(shardID)
+	if sh == nil {
+		return ErrShardNotFound
+	}
+	return sh.SetRollupRule(rule)
+}
+
 // DeleteShard removes a shard from disk.
 func (s *Store) DeleteShard(shardID uint64) error {
 	sh := s.Shard(shardID)
@@ -470,6 +580,12 @@ func (s *Store) DeleteShard(shardID uint64) error {
 		return err
 	}
 
+	if path := s.coldPath(sh.database, sh.retentionPolicy, strconv.FormatUint(shardID, 10)); path != "" {
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+	}
+
 	s.mu.Lock()
 	delete(s.shards, shardID)
 	s.mu.Unlock()
@@ -514,6 +630,11 @@ func (s *Store) DeleteDatabase(name string) error {
 	if err := os.RemoveAll(filepath.Join(s.EngineOptions.Config.WALDir, name)); err != nil {
 		return err
 	}
+	if path := s.coldPath(name); path != "" {
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+	}
 
 	s.mu.Lock()
 	for _, sh := range shards {
@@ -575,6 +696,13 @@ func (s *Store) DeleteRetentionPolicy(database, name string) error {
 		return err
 	}
 
+	// Remove the retention policy folder from the cold tier.
+	if path := s.coldPath(database, name); path != "" {
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+	}
+
 	s.mu.Lock()
 	for _, sh := range shards {
 		delete(s.shards, sh.id)
@@ -583,11 +711,38 @@ func (s *Store) DeleteRetentionPolicy(database, name string) error {
 	return nil
 }
 
+// OnDeleteSeries sets fn to be called with the shards that series matching sources and
+// condition are about to be deleted from, by DeleteSeries, DeleteShardSeries or
+// DeleteMeasurement.  Nothing is deleted if fn returns an error.  A nil fn removes it.
+func (s *Store) OnDeleteSeries(fn func(database string, shardIDs []uint64, sources []influxql.Source, condition influxql.Expr) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onDeleteSeries = fn
+}
+
+// notifyDeleteSeries calls the function set by OnDeleteSeries, if any, with the IDs
+// of shards.  s.mu must be held.
+func (s *Store) notifyDeleteSeries(database string, shards []*Shard, sources []influxql.Source, condition influxql.Expr) error {
+	if s.onDeleteSeries == nil || len(shards) == 0 {
+		return nil
+	}
+
+	ids := make([]uint64, len(shards))
+	for i, sh := range shards {
+		ids[i] = sh.id
+	}
+	return s.onDeleteSeries(database, ids, sources, condition)
+}
+
 // DeleteMeasurement removes a measurement and all associated series from a database.
 func (s *Store) DeleteMeasurement(database, name string) error {
 	s.mu.RLock()
 	shards := s.filterShards(byDatabase(database))
+	err := s.notifyDeleteSeries(database, shards, []influxql.Source{&influxql.Measurement{Name: name}}, nil)
 	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
 
 	// Limit to 1 delete for each shard since expanding the measurement into the list
 	// of series keys can be very memory intensive if run concurrently.
@@ -837,6 +992,21 @@ func (s *Store) ShardRelativePath(id uint64) (string, error) {
 // DeleteSeries loops through the local shards and deletes the series data for
 // the passed in series keys.
 func (s *Store) DeleteSeries(database string, sources []influxql.Source, condition influxql.Expr) error {
+	return s.deleteSeries(database, nil, sources, condition)
+}
+
+// DeleteShardSeries deletes the series data matching sources and condition from the
+// given local shards of the database only.
+func (s *Store) DeleteShardSeries(database string, shardIDs []uint64, sources []influxql.Source, condition influxql.Expr) error {
+	if len(shardIDs) == 0 {
+		return nil
+	}
+	return s.deleteSeries(database, shardIDs, sources, condition)
+}
+
+// deleteSeries deletes the series data matching sources and condition from the shards
+// of the database, or only from the given shards if shardIDs isn't nil.
+func (s *Store) deleteSeries(database string, shardIDs []uint64, sources []influxql.Source, condition influxql.Expr) error {
 	// Expand regex expressions in the FROM clause.
 	a, err := s.ExpandSources(sources)
 	if err != nil {
@@ -847,6 +1017,7 @@ func (s *Store) DeleteSeries(database string, sources []influxql.Source, conditi
 	sources = a
 
 	// Determine deletion time range.
+	expr := condition
 	condition, timeRange, err := influxql.ConditionExpr(condition, nil)
 	if err != nil {
 		return err
@@ -864,13 +1035,29 @@ func (s *Store) DeleteSeries(database string, sources []influxql.Source, conditi
 		max = influxql.MaxTime
 	}
 
+	filter := byDatabase(database)
+	if shardIDs != nil {
+		ids := make(map[uint64]struct{}, len(shardIDs))
+		for _, id := range shardIDs {
+			ids[id] = struct{}{}
+		}
+		filter = func(sh *Shard) bool {
+			_, ok := ids[sh.id]
+			return ok && sh.database == database
+		}
+	}
+
 	s.mu.RLock()
-	shards := s.filterShards(byDatabase(database))
+	shards := s.filterShards(filter)
 	s.mu.RUnlock()
 
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	if err := s.notifyDeleteSeries(database, shards, sources, expr); err != nil {
+		return err
+	}
+
 	// Limit to 1 delete for each shard since expanding the measurement into the list
 	// of series keys can be very memory intensive if run concurrently.
 	limit := limiter.NewFixed(1)
@@ -955,19 +1142,50 @@ func (s *Store) WriteToShard(shardID uint64, points []models.Point) error {
 
 // MeasurementNames returns a slice of all measurements. Measurements accepts an
 // optional condition expression. If cond is nil, then all measurements for the
-// database will be returned.
-func (s *Store) MeasurementNames(database string, cond influxql.Expr) ([][]byte, error) {
+// database will be returned.  If shardIDs is nil, the measurements of all shards of
+// the database are returned, otherwise only those of the given shards.  Measurements
+// without series in the time range are excluded, as described by metadataTimeRange.
+func (s *Store) MeasurementNames(database string, shardIDs []uint64, tr influxql.TimeRange, cond influxql.Expr) ([][]byte, error) {
 	s.mu.RLock()
-	shards := s.filterShards(byDatabase(database))
+	var shards []*Shard
+	if shardIDs == nil {
+		shards = s.filterShards(byDatabase(database))
+	} else {
+		for _, sid := range shardIDs {
+			if sh, ok := s.shards[sid]; ok && sh.database == database {
+				shards = append(shards, sh)
+			}
+		}
+	}
 	s.mu.RUnlock()
 
 	// If we're using the inmem index then all shards contain a duplicate
 	// version of the global index. We don't need to iterate over all shards
-	// since we have everything we need from the first shard.
-	if len(shards) > 0 && shards[0].IndexType() == "inmem" {
+	// since we have everything we need from the first shard, unless the series
+	// of each shard are checked for data.
+	min, max, checkData := s.metadataTimeRange(shards, tr)
+	if !checkData && len(shards) > 0 && shards[0].IndexType() == "inmem" {
 		shards = shards[:1]
 	}
 
+	// Series are checked against the tag conditions only.
+	var filterExpr influxql.Expr
+	if checkData {
+		filterExpr = influxql.Reduce(influxql.RewriteExpr(influxql.CloneExpr(cond), func(e influxql.Expr) influxql.Expr {
+			switch e := e.(type) {
+			case *influxql.BinaryExpr:
+				switch e.Op {
+				case influxql.EQ, influxql.NEQ, influxql.EQREGEX, influxql.NEQREGEX:
+					tag, ok := e.LHS.(*influxql.VarRef)
+					if !ok || strings.HasPrefix(tag.Val, "_") {
+						return nil
+					}
+				}
+			}
+			return e
+		}), nil)
+	}
+
 	// Map to deduplicate measurement names across all shards.  This is kind of naive
 	// and could be improved using a sorted merge of the already sorted measurements in
 	// each shard.
@@ -980,10 +1198,20 @@ func (s *Store) MeasurementNames(database string, cond influxql.Expr) ([][]byte,
 		}
 
 		for _, m := range a {
-			if _, ok := set[string(m)]; !ok {
-				set[string(m)] = struct{}{}
-				names = append(names, m)
+			if _, ok := set[string(m)]; ok {
+				continue
 			}
+
+			if checkData {
+				if ok, err := sh.MeasurementHasDataInRange(m, filterExpr, min, max); err != nil {
+					return nil, err
+				} else if !ok {
+					continue
+				}
+			}
+
+			set[string(m)] = struct{}{}
+			names = append(names, m)
 		}
 	}
 	bytesutil.Sort(names)
@@ -991,6 +1219,21 @@ func (s *Store) MeasurementNames(database string, cond influxql.Expr) ([][]byte,
 	return names, nil
 }
 
+// metadataTimeRange returns the time range series of shards must have data in to be
+// included in the results of metadata queries bounded by tr, and false if series don't
+// need to be checked for data.
+//
+// Series are only checked for data in tr when the MetadataTimeCheckEnabled option is set.
+// Otherwise the results are only restricted to the shards of the time range.
+func (s *Store) metadataTimeRange(shards []*Shard, tr influxql.TimeRange) (min, max int64, ok bool) {
+	if tr.Min.IsZero() && tr.Max.IsZero() {
+		return 0, 0, false
+	} else if s.EngineOptions.Config.MetadataTimeCheckEnabled {
+		return tr.MinTimeNano(), tr.MaxTimeNano(), true
+	}
+	return 0, 0, false
+}
+
 // MeasurementSeriesCounts returns the number of measurements and series in all
 // the shards' indices.
 func (s *Store) MeasurementSeriesCounts(database string) (measuments int, series int) {
@@ -1020,8 +1263,9 @@ func (a tagKeysSlice) Len() int           { return len(a) }
 func (a tagKeysSlice) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 func (a tagKeysSlice) Less(i, j int) bool { return bytes.Compare(a[i].name, a[j].name) == -1 }
 
-// TagKeys returns the tag keys in the given database, matching the condition.
-func (s *Store) TagKeys(auth query.Authorizer, shardIDs []uint64, cond influxql.Expr) ([]TagKeys, error) {
+// TagKeys returns the tag keys in the given database, matching the condition.  Tag keys
+// without series in the time range are excluded, as described by metadataTimeRange.
+func (s *Store) TagKeys(auth query.Authorizer, shardIDs []uint64, tr influxql.TimeRange, cond influxql.Expr) ([]TagKeys, error) {
 	measurementExpr := influxql.CloneExpr(cond)
 	measurementExpr = influxql.Reduce(influxql.RewriteExpr(measurementExpr, func(e influxql.Expr) influxql.Expr {
 		switch e := e.(type) {
@@ -1066,8 +1310,10 @@ func (s *Store) TagKeys(auth query.Authorizer, shardIDs []uint64, cond influxql.
 
 	// If we're using the inmem index then all shards contain a duplicate
 	// version of the global index. We don't need to iterate over all shards
-	// since we have everything we need from the first shard.
-	if len(shards) > 0 && shards[0].IndexType() == "inmem" {
+	// since we have everything we need from the first shard, unless the series
+	// of each shard are checked for data.
+	min, max, checkData := s.metadataTimeRange(shards, tr)
+	if !checkData && len(shards) > 0 && shards[0].IndexType() == "inmem" {
 		shards = shards[:1]
 	}
 
@@ -1111,7 +1357,12 @@ func (s *Store) TagKeys(auth query.Authorizer, shardIDs []uint64, cond influxql.
 			sort.Strings(shardKeys)
 
 			// Filter against tag values, skip if no values exist.
-			shardValues, err := sh.MeasurementTagKeyValuesByExpr(auth, []byte(name), shardKeys, filterExpr, true)
+			var shardValues [][]string
+			if checkData {
+				shardValues, err = sh.MeasurementTagKeyValuesInRange(auth, []byte(name), shardKeys, filterExpr, min, max)
+			} else {
+				shardValues, err = sh.MeasurementTagKeyValuesByExpr(auth, []byte(name), shardKeys, filterExpr, true)
+			}
 			if err != nil {
 				return nil, err
 			}
@@ -1124,6 +1375,11 @@ func (s *Store) TagKeys(auth query.Authorizer, shardIDs []uint64, cond influxql.
 			}
 		}
 
+		// Measurements without series in the time range have no keys.
+		if checkData && len(keySet) == 0 {
+			continue
+		}
+
 		// Sort key set.
 		keys := make([]string, 0, len(keySet))
 		for key := range keySet {
@@ -1168,8 +1424,9 @@ func (a tagValuesSlice) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 func (a tagValuesSlice) Less(i, j int) bool { return bytes.Compare(a[i].name, a[j].name) == -1 }
 
 // TagValues returns the tag keys and values for the provided shards, where the
-// tag values satisfy the provided condition.
-func (s *Store) TagValues(auth query.Authorizer, shardIDs []uint64, cond influxql.Expr) ([]TagValues, error) {
+// tag values satisfy the provided condition.  Tag values without series in the time
+// range are excluded, as described by metadataTimeRange.
+func (s *Store) TagValues(auth query.Authorizer, shardIDs []uint64, tr influxql.TimeRange, cond influxql.Expr) ([]TagValues, error) {
 	if cond == nil {
 		return nil, errors.New("a condition is required")
 	}
@@ -1218,8 +1475,10 @@ func (s *Store) TagValues(auth query.Authorizer, shardIDs []uint64, cond influxq
 
 	// If we're using the inmem index then all shards contain a duplicate
 	// version of the global index. We don't need to iterate over all shards
-	// since we have everything we need from the first shard.
-	if len(shards) > 0 && shards[0].IndexType() == "inmem" {
+	// since we have everything we need from the first shard, unless the series
+	// of each shard are checked for data.
+	min, max, checkData := s.metadataTimeRange(shards, tr)
+	if !checkData && len(shards) > 0 && shards[0].IndexType() == "inmem" {
 		shards = shards[:1]
 	}
 
@@ -1272,7 +1531,12 @@ func (s *Store) TagValues(auth query.Authorizer, shardIDs []uint64, cond influxq
 			// get all the tag values for each key in the keyset.
 			// Each slice in the results contains the sorted values associated
 			// associated with each tag key for the measurement from the key set.
-			if result.values, err = sh.MeasurementTagKeyValuesByExpr(auth, name, result.keys, filterExpr, true); err != nil {
+			if checkData {
+				result.values, err = sh.MeasurementTagKeyValuesInRange(auth, name, result.keys, filterExpr, min, max)
+			} else {
+				result.values, err = sh.MeasurementTagKeyValuesByExpr(auth, name, result.keys, filterExpr, true)
+			}
+			if err != nil {
 				return nil, err
 			}
 
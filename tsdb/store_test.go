@@ -3,6 +3,7 @@ package tsdb_test
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"math"
@@ -167,6 +168,57 @@ func TestStore_DeleteShard(t *testing.T) {
 	}
 }
 
+// Ensure the store notifies of the shards series are deleted from, and doesn't delete
+// them if the notification fails.
+func TestStore_OnDeleteSeries(t *testing.T) {
+	t.Parallel()
+
+	test := func(index string) {
+		s := MustOpenStore(index)
+		defer s.Close()
+
+		s.MustCreateShardWithData("db0", "rp0", 1, `cpu,host=serverA value=1 0`)
+		s.MustCreateShardWithData("db1", "rp0", 2, `cpu,host=serverA value=1 0`)
+
+		var notified []string
+		s.OnDeleteSeries(func(database string, shardIDs []uint64, sources []influxql.Source, condition influxql.Expr) error {
+			notified = append(notified, fmt.Sprintf("%s %v %s %v", database, shardIDs, influxql.Sources(sources), condition))
+			return errors.New("notify failed")
+		})
+
+		cond, err := influxql.ParseExpr(`host = 'serverA'`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.DeleteSeries("db0", []influxql.Source{&influxql.Measurement{Name: "cpu"}}, cond); err == nil {
+			t.Fatal("expected delete error")
+		} else if err := s.DeleteMeasurement("db0", "cpu"); err == nil {
+			t.Fatal("expected delete error")
+		}
+
+		exp := []string{"db0 [1] cpu host = 'serverA'", "db0 [1] cpu <nil>"}
+		if !reflect.DeepEqual(notified, exp) {
+			t.Fatalf("unexpected notifications:\ngot  %v\nexp  %v", notified, exp)
+		}
+
+		// The series weren't deleted.
+		if n, err := s.SeriesCardinality("db0"); err != nil {
+			t.Fatal(err)
+		} else if n != 1 {
+			t.Fatalf("unexpected series cardinality: %d", n)
+		}
+
+		s.OnDeleteSeries(nil)
+		if err := s.DeleteMeasurement("db0", "cpu"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, index := range tsdb.RegisteredIndexes() {
+		t.Run(index, func(t *testing.T) { test(index) })
+	}
+}
+
 // Ensure the store can create a snapshot to a shard.
 func TestStore_CreateShardSnapShot(t *testing.T) {
 	t.Parallel()
@@ -513,7 +565,7 @@ func TestStore_MeasurementNames_Deduplicate(t *testing.T) {
 			`cpu value=3 20`,
 		)
 
-		meas, err := s.MeasurementNames("db0", nil)
+		meas, err := s.MeasurementNames("db0", nil, influxql.TimeRange{}, nil)
 		if err != nil {
 			t.Fatalf("unexpected error with MeasurementNames: %v", err)
 		}
@@ -532,6 +584,61 @@ func TestStore_MeasurementNames_Deduplicate(t *testing.T) {
 	}
 }
 
+// Ensure the index of a shard can be converted from inmem to tsi1 while it's open.
+func TestStore_ConvertShardIndex(t *testing.T) {
+	t.Parallel()
+
+	s := MustOpenStore("inmem")
+	defer s.Close()
+
+	s.MustCreateShardWithData("db0", "rp0", 1,
+		`cpu,host=serverA value=1 0`,
+		`cpu,host=serverB value=2 10`,
+		`mem,host=serverA value=3 20`,
+	)
+
+	// Shards are only converted when the store uses tsi1.
+	if err := s.ConvertShardIndex(1); err == nil {
+		t.Fatal("expected error converting shard with inmem store")
+	}
+
+	s.EngineOptions.IndexVersion = "tsi1"
+	if err := s.ConvertShardIndex(2); err != tsdb.ErrShardNotFound {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := s.ConvertShardIndex(1); err != nil {
+		t.Fatal(err)
+	} else if typ := s.Shard(1).IndexType(); typ != "tsi1" {
+		t.Fatalf("unexpected index type: %s", typ)
+	} else if stats := s.IndexConversionStatistics(); stats.ShardsConverted != 1 || stats.SeriesIndexed != 3 {
+		t.Fatalf("unexpected statistics: %+v", stats)
+	}
+
+	// Series written after the conversion are added to the new index.
+	s.MustWriteToShardString(1, `disk,host=serverA value=4 30`)
+
+	for i := 0; i < 2; i++ {
+		names, err := s.MeasurementNames("db0", nil, influxql.TimeRange{}, nil)
+		if err != nil {
+			t.Fatal(err)
+		} else if got, exp := names, [][]byte{[]byte("cpu"), []byte("disk"), []byte("mem")}; !reflect.DeepEqual(got, exp) {
+			t.Fatalf("unexpected measurement names(%d): got %s, exp %s", i, got, exp)
+		}
+
+		// The shard is opened with the new index.
+		if err := s.Store.Close(); err != nil {
+			t.Fatal(err)
+		}
+		s.Store = tsdb.NewStore(s.Path())
+		s.EngineOptions.Config.WALDir = filepath.Join(s.Path(), "wal")
+		s.EngineOptions.IndexVersion = "tsi1"
+		if err := s.Open(); err != nil {
+			t.Fatal(err)
+		} else if typ := s.Shard(1).IndexType(); typ != "tsi1" {
+			t.Fatalf("unexpected index type after reopen: %s", typ)
+		}
+	}
+}
+
 func testStoreCardinalityTombstoning(t *testing.T, store *Store) {
 	// Generate point data to write to the shards.
 	series := genTestSeries(10, 2, 4) // 160 series
@@ -554,7 +661,7 @@ func testStoreCardinalityTombstoning(t *testing.T, store *Store) {
 	}
 
 	// Delete all the series for each measurement.
-	mnames, err := store.MeasurementNames("db", nil)
+	mnames, err := store.MeasurementNames("db", nil, influxql.TimeRange{}, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -944,7 +1051,7 @@ func TestStore_TagValues(t *testing.T) {
 		for _, index := range tsdb.RegisteredIndexes() {
 			shardIDs := setup(index)
 			t.Run(example.Name+"_"+index, func(t *testing.T) {
-				got, err := s.TagValues(nil, shardIDs, example.Expr)
+				got, err := s.TagValues(nil, shardIDs, influxql.TimeRange{}, example.Expr)
 				if err != nil {
 					t.Fatal(err)
 				}
@@ -959,6 +1066,83 @@ func TestStore_TagValues(t *testing.T) {
 	}
 }
 
+// Ensure metadata queries bounded by time only return the series of the given shards
+// and, when checked, the series with data in the time range.
+func TestStore_TagValues_TimeRange(t *testing.T) {
+	t.Parallel()
+
+	cond := &influxql.BinaryExpr{
+		Op:  influxql.EQ,
+		LHS: &influxql.VarRef{Val: "_tagKey"},
+		RHS: &influxql.StringLiteral{Val: "host"},
+	}
+	tr := func(min, max int64) influxql.TimeRange {
+		return influxql.TimeRange{Min: time.Unix(min, 0), Max: time.Unix(max, 0)}
+	}
+
+	test := func(index string) {
+		s := MustOpenStore(index)
+		defer s.Close()
+
+		s.MustCreateShardWithData("db0", "rp0", 0, `cpu,host=a value=1 0`)
+		s.MustCreateShardWithData("db0", "rp0", 1,
+			`cpu,host=b value=1 100`,
+			`cpu,host=c value=1 200`,
+			`mem,host=d value=1 200`,
+		)
+
+		// Only the series of the given shards are returned.  Shards using the inmem
+		// index share the index of the database, so they hold the series of all shards.
+		if index != "inmem" {
+			if got, err := s.TagValues(nil, []uint64{1}, tr(50, 300), cond); err != nil {
+				t.Fatal(err)
+			} else if exp := []tsdb.TagValues{
+				createTagValues("cpu", map[string][]string{"host": {"b", "c"}}),
+				createTagValues("mem", map[string][]string{"host": {"d"}}),
+			}; !reflect.DeepEqual(got, exp) {
+				t.Fatalf("unexpected tag values: got %v, exp %v", got, exp)
+			}
+
+			if names, err := s.MeasurementNames("db0", []uint64{0}, tr(0, 50), nil); err != nil {
+				t.Fatal(err)
+			} else if exp := [][]byte{[]byte("cpu")}; !reflect.DeepEqual(names, exp) {
+				t.Fatalf("unexpected measurement names: got %s, exp %s", names, exp)
+			}
+		}
+
+		// Series without data in the time range are excluded when checked.
+		s.EngineOptions.Config.MetadataTimeCheckEnabled = true
+		if got, err := s.TagValues(nil, []uint64{1}, tr(150, 300), cond); err != nil {
+			t.Fatal(err)
+		} else if exp := []tsdb.TagValues{
+			createTagValues("cpu", map[string][]string{"host": {"c"}}),
+			createTagValues("mem", map[string][]string{"host": {"d"}}),
+		}; !reflect.DeepEqual(got, exp) {
+			t.Fatalf("unexpected tag values: got %v, exp %v", got, exp)
+		}
+
+		if got, err := s.TagKeys(nil, []uint64{0, 1}, tr(0, 150), nil); err != nil {
+			t.Fatal(err)
+		} else if exp := []tsdb.TagKeys{{Measurement: "cpu", Keys: []string{"host"}}}; !reflect.DeepEqual(got, exp) {
+			t.Fatalf("unexpected tag keys: got %v, exp %v", got, exp)
+		}
+
+		if names, err := s.MeasurementNames("db0", nil, tr(150, 300), nil); err != nil {
+			t.Fatal(err)
+		} else if exp := [][]byte{[]byte("cpu"), []byte("mem")}; !reflect.DeepEqual(names, exp) {
+			t.Fatalf("unexpected measurement names: got %s, exp %s", names, exp)
+		} else if names, err := s.MeasurementNames("db0", nil, tr(50, 150), nil); err != nil {
+			t.Fatal(err)
+		} else if exp := [][]byte{[]byte("cpu")}; !reflect.DeepEqual(names, exp) {
+			t.Fatalf("unexpected measurement names: got %s, exp %s", names, exp)
+		}
+	}
+
+	for _, index := range tsdb.RegisteredIndexes() {
+		t.Run(index, func(t *testing.T) { test(index) })
+	}
+}
+
 // Helper to create some tag values
 func createTagValues(mname string, kvs map[string][]string) tsdb.TagValues {
 	var sz int
@@ -1177,7 +1361,7 @@ func BenchmarkStore_TagValues(b *testing.B) {
 					}
 					b.Run("random_values="+fmt.Sprint(useRand == 1)+"_index="+index+"_"+cnd+"_"+bm.name, func(b *testing.B) {
 						for i := 0; i < b.N; i++ {
-							if tvResult, err = s.TagValues(nil, shardIDs, condition); err != nil {
+							if tvResult, err = s.TagValues(nil, shardIDs, influxql.TimeRange{}, condition); err != nil {
 								b.Fatal(err)
 							}
 						}